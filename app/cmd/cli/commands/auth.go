@@ -1,27 +1,202 @@
 package cli
 
 import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/PHRaulino/phengineer/internal/infrastructure/auth"
+	"github.com/PHRaulino/phengineer/internal/infrastructure/auth/storage"
+	"github.com/PHRaulino/phengineer/internal/infrastructure/config"
 	"github.com/PHRaulino/phengineer/internal/presentation/tui"
 	"github.com/spf13/cobra"
 )
 
-var authSetupCmd = &cobra.Command{
-	Use:   "setup",
-	Short: "Configure authentication interactively",
+var authCmd = &cobra.Command{
+	Use:   "auth",
+	Short: "Gerenciar autenticação",
+	Long: `Comandos para configurar e gerenciar autenticação com diferentes provedores.
+
+As credenciais ficam no keyring (ou no arquivo cifrado, quando não há keyring) separadas por perfil. O perfil ativo é escolhido por
+--profile, pela variável PHENGINEER_PROFILE, por auth.profile no .phengineer/settings.yml
+do repositório ou pelo padrão definido com 'auth use'.`,
+}
+
+var authLoginCmd = &cobra.Command{
+	Use:     "login",
+	Aliases: []string{"setup"},
+	Short:   "Configure authentication interactively",
 	Long: `Configure authentication for different providers:
 	
 • StackSpot User (Client ID/Secret)
 • StackSpot Service (via HashiCorp Vault + AWS)
 • GitHub (Personal Access Token)
 
-This command opens an interactive TUI for credential configuration.`,
+Without a subcommand this opens an interactive TUI. For scripts and CI use
+'auth login stackspot', 'auth login github' or 'auth login vault'.
+Credentials are saved in the active profile (--profile).`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		app := tui.NewApp()
 		return app.Start()
 	},
 }
 
-// GetAuthSetupCmd returns the auth setup command for external use
-func GetAuthSetupCmd() *cobra.Command {
-	return authSetupCmd
+var authListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Listar perfis de credenciais",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		registry := auth.Profiles()
+		profiles, err := registry.List()
+		if err != nil {
+			return err
+		}
+		if !slices.Contains(profiles, storage.DefaultProfile) {
+			profiles = append([]string{storage.DefaultProfile}, profiles...)
+		}
+
+		active := auth.ActiveProfile()
+		fmt.Printf("=== Perfis (%d) ===\n", len(profiles))
+		for _, profile := range profiles {
+			marker := " "
+			if profile == active {
+				marker = "*"
+			}
+
+			providers := configuredProviders(registry.Adapter(profile))
+			description := "sem credenciais"
+			if len(providers) > 0 {
+				description = strings.Join(providers, ", ")
+			}
+			fmt.Printf("%s %s: %s\n", marker, profile, description)
+		}
+
+		backend, _ := auth.StorageBackend()
+		fmt.Printf("\nPerfil ativo: %s (%s)\n", active, auth.ActiveProfileSource())
+		fmt.Printf("Armazenamento: %s\n", backend)
+		return nil
+	},
+}
+
+var authUseCmd = &cobra.Command{
+	Use:   "use <perfil>",
+	Short: "Definir o perfil padrão do usuário ou vincular um perfil ao repositório",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		profile := args[0]
+		if err := storage.ValidateProfileName(profile); err != nil {
+			return err
+		}
+
+		registry := auth.Profiles()
+		if profile != storage.DefaultProfile && !registry.Exists(profile) {
+			fmt.Fprintf(os.Stderr, "Aviso: o perfil %s ainda não tem credenciais; use 'phengineer auth login --profile %s'\n", profile, profile)
+		}
+
+		project, _ := cmd.Flags().GetBool("project")
+		if !project {
+			if err := registry.SetDefault(profile); err != nil {
+				return err
+			}
+			fmt.Printf("Perfil padrão: %s\n", profile)
+			return nil
+		}
+
+		settingsPath, err := config.ProjectSettingsPath(cmd.Context(), ".phengineer")
+		if err != nil {
+			return fmt.Errorf("--project requer um repositório git: %w", err)
+		}
+
+		// O perfil default remove o vínculo
+		value := profile
+		if profile == storage.DefaultProfile {
+			value = ""
+		}
+		if err := config.SetSettingsValue(settingsPath, "auth.profile", value); err != nil {
+			return err
+		}
+		fmt.Printf("Repositório vinculado ao perfil %s (%s)\n", profile, settingsPath)
+		return nil
+	},
+}
+
+var authRemoveCmd = &cobra.Command{
+	Use:   "remove <perfil>",
+	Short: "Remover um perfil e todas as suas credenciais",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		profile := args[0]
+		if err := storage.ValidateProfileName(profile); err != nil {
+			return err
+		}
+
+		registry := auth.Profiles()
+		if profile != storage.DefaultProfile && !registry.Exists(profile) {
+			return fmt.Errorf("perfil %s não encontrado", profile)
+		}
+
+		if err := registry.Remove(profile); err != nil {
+			return fmt.Errorf("erro ao remover perfil %s: %w", profile, err)
+		}
+		fmt.Printf("Perfil %s removido\n", profile)
+		return nil
+	},
+}
+
+// configuredProviders lista os provedores com credenciais salvas no perfil
+func configuredProviders(adapter storage.StorageAdapter) []string {
+	checks := []struct {
+		name string
+		key  string
+	}{
+		{"stackspot user", "stackspot_client_id"},
+		{"vault", "vault_url"},
+		{"github", "github_token"},
+	}
+
+	var configured []string
+	for _, check := range checks {
+		if adapter.Exists(check.key) {
+			configured = append(configured, check.name)
+		}
+	}
+	return configured
+}
+
+// rootProfileFlag lê a flag global --profile
+func rootProfileFlag(cmd *cobra.Command) string {
+	profile, _ := cmd.Flags().GetString("profile")
+	return profile
+}
+
+func init() {
+	authUseCmd.Flags().Bool("project", false, "Vincular o perfil ao repositório em .phengineer/settings.yml")
+
+	authCmd.AddCommand(authLoginCmd)
+	authCmd.AddCommand(authListCmd)
+	authCmd.AddCommand(authUseCmd)
+	authCmd.AddCommand(authRemoveCmd)
+}
+
+// GetAuthCmd returns the auth command group for external use
+func GetAuthCmd() *cobra.Command {
+	return authCmd
+}
+
+// SelectProfile resolve o perfil ativo a partir da flag --profile e configura o cliente HTTP
+// e os geradores de token
+func SelectProfile(cmd *cobra.Command) error {
+	if _, err := auth.StorageBackend(); err != nil {
+		return err
+	}
+	if err := auth.ConfigureHTTP(cmd.Context()); err != nil {
+		return err
+	}
+
+	profile, source := auth.ResolveProfile(cmd.Context(), rootProfileFlag(cmd))
+	if err := auth.UseProfile(profile, source); err != nil {
+		return err
+	}
+	auth.SetupGenerators(cmd.Context())
+	return nil
 }
@@ -0,0 +1,440 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/PHRaulino/phengineer/internal/infrastructure/auth"
+	"github.com/PHRaulino/phengineer/internal/infrastructure/auth/providers"
+	"github.com/PHRaulino/phengineer/internal/infrastructure/auth/token"
+	"github.com/PHRaulino/phengineer/internal/infrastructure/config"
+	"github.com/spf13/cobra"
+)
+
+var authLoginStackSpotCmd = &cobra.Command{
+	Use:   "stackspot",
+	Short: "Salvar as credenciais de usuário da StackSpot",
+	Long: `Salva as credenciais de usuário da StackSpot.
+
+Com --device o login é feito pelo navegador (OAuth device authorization): nenhum
+client secret é salvo, apenas o refresh token emitido pelo IdM.`,
+	Example: `  phengineer auth login stackspot --device
+  echo "$STK_CLIENT_SECRET" | phengineer auth login stackspot --client-id my-client --client-secret-stdin`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := auth.RequirePersistentStorage(); err != nil {
+			return err
+		}
+		clientID, _ := cmd.Flags().GetString("client-id")
+		if device, _ := cmd.Flags().GetBool("device"); device {
+			provider := auth.GetStackSpotProvider(cmd.Context()).WithClientID(clientID)
+			if err := deviceLogin(cmd, provider); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.ErrOrStderr(), "Login StackSpot concluído no perfil %s\n", auth.ActiveProfile())
+			return nil
+		}
+
+		if clientID == "" {
+			return fmt.Errorf("informe --client-id ou use --device")
+		}
+		secret, err := secretFromStdin(cmd, "client-secret-stdin", "client secret")
+		if err != nil {
+			return err
+		}
+
+		if err := auth.GetStackSpotProvider(cmd.Context()).SaveCredentials(clientID, secret); err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.ErrOrStderr(), "Credenciais StackSpot salvas no perfil %s\n", auth.ActiveProfile())
+		return nil
+	},
+}
+
+var authLoginGitHubCmd = &cobra.Command{
+	Use:   "github",
+	Short: "Validar e salvar um token do GitHub",
+	Long: `Valida e salva um token do GitHub.
+
+Com --device o login é feito pelo navegador com o OAuth App ou GitHub App configurado
+em auth.github.client_id (ou --client-id).
+
+Com --app-id a CLI se autentica como GitHub App: tokens de instalação de curta duração,
+com commits e comentários atribuídos ao bot. Sem --installation-id a instalação é
+descoberta pelo remote origin do repositório.`,
+	Example: `  phengineer auth login github --device
+  echo "$GH_TOKEN" | phengineer auth login github --token-stdin
+  phengineer auth login github --app-id 123456 --private-key-file ~/.config/phengineer/app.pem
+  cat app.pem | phengineer auth login github --app-id 123456 --installation-id 789 --private-key-stdin`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := auth.RequirePersistentStorage(); err != nil {
+			return err
+		}
+		if appID, _ := cmd.Flags().GetInt64("app-id"); appID != 0 {
+			return loginGitHubApp(cmd, appID)
+		}
+
+		if device, _ := cmd.Flags().GetBool("device"); device {
+			clientID, _ := cmd.Flags().GetString("client-id")
+			provider := auth.GetGitHubProvider(cmd.Context()).WithClientID(clientID)
+			if err := deviceLogin(cmd, provider); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.ErrOrStderr(), "Login GitHub concluído no perfil %s\n", auth.ActiveProfile())
+			return nil
+		}
+
+		githubToken, err := secretFromStdin(cmd, "token-stdin", "token")
+		if err != nil {
+			return err
+		}
+
+		// As permissões ausentes são avisadas antes de salvar; o token continua utilizável
+		// nas funcionalidades que ele atende
+		provider := auth.GetGitHubProvider(cmd.Context())
+		report, err := provider.VerifyToken(cmd.Context(), githubToken)
+		if err != nil {
+			return fmt.Errorf("token inválido: %w", err)
+		}
+		for _, warning := range report.Warnings() {
+			fmt.Fprintf(cmd.ErrOrStderr(), "Aviso: %s\n", warning)
+		}
+
+		if err := provider.SaveToken(githubToken); err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.ErrOrStderr(), "Token GitHub salvo no perfil %s\n", auth.ActiveProfile())
+		return nil
+	},
+}
+
+var authLoginVaultCmd = &cobra.Command{
+	Use:   "vault",
+	Short: "Salvar a configuração do Vault usada no modo service",
+	Long: `Salva a configuração do HashiCorp Vault de onde as credenciais StackSpot são lidas.
+
+Segredos (secret_id do AppRole ou token do método token) são lidos de --secret-stdin.`,
+	Example: `  phengineer auth login vault --url https://vault.empresa.com --role stackspot-role
+  phengineer auth login vault --url https://vault.empresa.com --method jwt --role ci --audience https://github.com/empresa
+  echo "$SECRET_ID" | phengineer auth login vault --url https://vault.empresa.com --method approle --role-id my-role --secret-stdin`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := auth.RequirePersistentStorage(); err != nil {
+			return err
+		}
+		flags := cmd.Flags()
+		str := func(name string) string {
+			value, _ := flags.GetString(name)
+			return value
+		}
+		kvVersion, _ := flags.GetInt("kv-version")
+
+		cfg := config.VaultSettings{
+			Address:    str("url"),
+			Namespace:  str("namespace"),
+			Method:     str("method"),
+			Mount:      str("mount"),
+			Role:       str("role"),
+			SecretPath: str("secret-path"),
+			KVVersion:  kvVersion,
+			Fields: config.VaultFields{
+				ClientID:     str("client-id-field"),
+				ClientSecret: str("client-secret-field"),
+			},
+			AWS:     config.VaultAWS{ServerID: str("server-id"), STSRegion: str("sts-region")},
+			AppRole: config.VaultAppRole{RoleID: str("role-id")},
+			JWT:     config.VaultJWT{TokenFile: str("jwt-file"), GitHubAudience: str("audience")},
+			Kubernetes: config.VaultKubernetes{
+				TokenFile: str("kubernetes-token-file"),
+			},
+		}
+
+		if cfg.Address == "" {
+			return fmt.Errorf("informe o endereço do Vault com --url ou VAULT_ADDR")
+		}
+
+		var secret string
+		if stdin, _ := flags.GetBool("secret-stdin"); stdin {
+			var err error
+			if secret, err = readStdin(cmd); err != nil {
+				return err
+			}
+		}
+		if (cfg.Method == config.VaultMethodAppRole || cfg.Method == config.VaultMethodToken) && secret == "" {
+			fmt.Fprintf(cmd.ErrOrStderr(), "Aviso: sem --secret-stdin o método %s usará VAULT_SECRET_ID/VAULT_TOKEN\n", cfg.Method)
+		}
+
+		if err := auth.GetVaultProvider(cmd.Context()).SaveConfig(cfg, secret); err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.ErrOrStderr(), "Configuração do Vault salva no perfil %s\n", auth.ActiveProfile())
+		return nil
+	},
+}
+
+var authStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Mostrar o perfil ativo, as credenciais configuradas e os tokens em cache",
+	Long: `Mostra o perfil ativo, o armazenamento das credenciais e, para cada gerador e escopo,
+se há token em cache, quando expira e a identidade que ele representa.
+
+Nenhum segredo é exibido. Para o GitHub também são listadas as permissões do token que
+faltam no repositório do remote origin. Com --offline a API do GitHub não é consultada.`,
+	Example: `  phengineer auth status
+  phengineer auth status --json | jq '.tokens[] | select(.valid)'`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		offline, _ := cmd.Flags().GetBool("offline")
+		status := auth.GetStatus(cmd.Context(), auth.StatusOptions{Offline: offline})
+
+		out := cmd.OutOrStdout()
+		if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+			encoder := json.NewEncoder(out)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(status)
+		}
+
+		fmt.Fprintf(out, "Perfil: %s (%s)\n", status.Profile, status.ProfileSource)
+		fmt.Fprintf(out, "Armazenamento: %s\n", status.Backend)
+		fmt.Fprintf(out, "Modo: %s\n", status.Mode)
+
+		fmt.Fprintln(out, "\n=== Provedores ===")
+		for _, provider := range status.Providers {
+			mark := "✗"
+			if provider.Configured {
+				mark = "✓"
+			}
+			fmt.Fprintf(out, "%s %s%s\n", mark, provider.Provider, formatDetails(provider.Details))
+			for _, warning := range provider.Warnings {
+				fmt.Fprintf(out, "  ! %s\n", warning)
+			}
+		}
+
+		fmt.Fprintln(out, "\n=== Tokens ===")
+		for _, entry := range status.Tokens {
+			fmt.Fprintf(out, "• %s [%s]: %s\n", entry.Alias, entry.Scope, formatTokenState(entry))
+		}
+		return nil
+	},
+}
+
+// formatTokenState resume validade, armazenamento e identidade de um token
+func formatTokenState(entry auth.TokenStatus) string {
+	if !entry.Cached {
+		return "sem token"
+	}
+
+	state := "expirado em " + entry.ExpiresAt.Local().Format(time.DateTime)
+	if entry.Valid {
+		state = fmt.Sprintf("expira em %s (%s)", entry.Remaining(), entry.ExpiresAt.Local().Format(time.DateTime))
+	}
+	state += ", " + entry.Storage
+	if entry.Refreshable {
+		state += ", renovável"
+	}
+
+	if identity := entry.Identity; identity != nil {
+		switch {
+		case identity.Error != "":
+			state += ", identidade indisponível: " + identity.Error
+		case identity.Login != "":
+			state += ", " + identity.Login
+		case identity.Subject != "":
+			state += ", " + identity.Subject
+		}
+	}
+	return state
+}
+
+var authLogoutCmd = &cobra.Command{
+	Use:       "logout [stackspot|vault|github]",
+	Short:     "Remover credenciais e tokens em cache do perfil ativo",
+	Long:      "Sem argumentos remove as credenciais de todos os provedores do perfil ativo.",
+	Args:      cobra.MatchAll(cobra.MaximumNArgs(1), cobra.OnlyValidArgs),
+	ValidArgs: auth.Providers,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		providers := auth.Providers
+		if len(args) == 1 {
+			providers = args
+		}
+
+		for _, provider := range providers {
+			if err := auth.Logout(cmd.Context(), provider); err != nil {
+				return fmt.Errorf("erro ao remover credenciais %s: %w", provider, err)
+			}
+		}
+		fmt.Fprintf(cmd.ErrOrStderr(), "Credenciais removidas do perfil %s: %s\n", auth.ActiveProfile(), strings.Join(providers, ", "))
+		return nil
+	},
+}
+
+var authTokenCmd = &cobra.Command{
+	Use:       "token <stackspot|vault|github>",
+	Short:     "Imprimir um token válido, gerando um novo se necessário",
+	Example:   `  export STK_TOKEN=$(phengineer auth token stackspot --scope execution)`,
+	Args:      cobra.ExactArgs(1),
+	ValidArgs: []string{"stackspot", "vault", "github"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		alias, err := token.ParseAlias(args[0])
+		if err != nil {
+			return err
+		}
+
+		scopeName, _ := cmd.Flags().GetString("scope")
+		scope, err := token.ParseScope(scopeName)
+		if err != nil {
+			return err
+		}
+
+		accessToken, err := token.GetService().Get(scope, alias)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), accessToken)
+		return nil
+	},
+}
+
+// loginGitHubApp valida e salva a configuração do GitHub App
+func loginGitHubApp(cmd *cobra.Command, appID int64) error {
+	installationID, _ := cmd.Flags().GetInt64("installation-id")
+	keyFile, _ := cmd.Flags().GetString("private-key-file")
+
+	cfg := providers.GitHubAppConfig{AppID: appID, InstallationID: installationID, PrivateKeyFile: keyFile}
+	if stdin, _ := cmd.Flags().GetBool("private-key-stdin"); stdin {
+		key, err := readStdin(cmd)
+		if err != nil {
+			return err
+		}
+		cfg.PrivateKey = key
+	} else if keyFile == "" {
+		return fmt.Errorf("informe a chave privada com --private-key-file ou --private-key-stdin")
+	} else if abs, err := filepath.Abs(keyFile); err == nil {
+		// O caminho salvo precisa funcionar a partir de qualquer repositório
+		cfg.PrivateKeyFile = abs
+	}
+
+	if err := auth.GetGitHubProvider(cmd.Context()).SaveAppConfig(cfg); err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.ErrOrStderr(), "GitHub App %d salvo no perfil %s\n", appID, auth.ActiveProfile())
+	return nil
+}
+
+// deviceFlow provedor com login pelo navegador (OAuth device authorization)
+type deviceFlow interface {
+	StartDeviceLogin(ctx context.Context) (providers.DeviceAuthorization, error)
+	CompleteDeviceLogin(ctx context.Context, authorization providers.DeviceAuthorization) error
+}
+
+// deviceLogin exibe o código de verificação e aguarda a autorização; Ctrl+C cancela
+func deviceLogin(cmd *cobra.Command, flow deviceFlow) error {
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+	defer stop()
+
+	authorization, err := flow.StartDeviceLogin(ctx)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.ErrOrStderr()
+	fmt.Fprintf(out, "Acesse %s e informe o código: %s\n", authorization.VerificationURI, authorization.UserCode)
+	if authorization.VerificationURIComplete != "" {
+		fmt.Fprintf(out, "Ou abra diretamente: %s\n", authorization.VerificationURIComplete)
+	}
+	fmt.Fprintln(out, "Aguardando autorização...")
+
+	return flow.CompleteDeviceLogin(ctx, authorization)
+}
+
+// secretFromStdin exige a flag de stdin para que segredos não apareçam no histórico do shell
+func secretFromStdin(cmd *cobra.Command, flag, name string) (string, error) {
+	if stdin, _ := cmd.Flags().GetBool(flag); !stdin {
+		return "", fmt.Errorf("informe o %s via stdin com --%s", name, flag)
+	}
+	return readStdin(cmd)
+}
+
+func readStdin(cmd *cobra.Command) (string, error) {
+	data, err := io.ReadAll(cmd.InOrStdin())
+	if err != nil {
+		return "", fmt.Errorf("erro ao ler stdin: %w", err)
+	}
+
+	value := strings.TrimSpace(string(data))
+	if value == "" {
+		return "", fmt.Errorf("stdin vazio")
+	}
+	return value, nil
+}
+
+func formatDetails(details map[string]string) string {
+	if len(details) == 0 {
+		return ""
+	}
+
+	keys := []string{"client_id", "login", "app_id", "installation_id", "token_kind", "repository", "address", "namespace", "method", "mount", "role", "secret_path"}
+	parts := make([]string, 0, len(details))
+	for _, key := range keys {
+		if value, ok := details[key]; ok {
+			parts = append(parts, key+"="+value)
+		}
+	}
+	return " (" + strings.Join(parts, ", ") + ")"
+}
+
+func init() {
+	authLoginStackSpotCmd.Flags().String("client-id", "", "Client ID da StackSpot (com --device, o client público do login pelo navegador)")
+	authLoginStackSpotCmd.Flags().Bool("client-secret-stdin", false, "Ler o client secret do stdin")
+	authLoginStackSpotCmd.Flags().Bool("device", false, "Fazer login pelo navegador")
+	authLoginStackSpotCmd.MarkFlagsMutuallyExclusive("device", "client-secret-stdin")
+
+	authLoginGitHubCmd.Flags().Bool("token-stdin", false, "Ler o token do stdin")
+	authLoginGitHubCmd.Flags().Bool("device", false, "Fazer login pelo navegador")
+	authLoginGitHubCmd.Flags().String("client-id", "", "Client ID do app usado com --device")
+	authLoginGitHubCmd.Flags().Int64("app-id", 0, "ID do GitHub App")
+	authLoginGitHubCmd.Flags().Int64("installation-id", 0, "ID da instalação do GitHub App (padrão: descoberta pelo remote origin)")
+	authLoginGitHubCmd.Flags().String("private-key-file", "", "Arquivo PEM com a chave privada do GitHub App (apenas o caminho é salvo)")
+	authLoginGitHubCmd.Flags().Bool("private-key-stdin", false, "Ler a chave privada do GitHub App do stdin")
+	authLoginGitHubCmd.MarkFlagsMutuallyExclusive("device", "token-stdin", "app-id")
+	authLoginGitHubCmd.MarkFlagsMutuallyExclusive("private-key-file", "private-key-stdin")
+
+	flags := authLoginVaultCmd.Flags()
+	flags.String("url", os.Getenv("VAULT_ADDR"), "Endereço do Vault")
+	flags.String("namespace", "", "Namespace do Vault Enterprise")
+	flags.String("method", config.VaultMethodAWS, "Método de autenticação: "+strings.Join(config.VaultMethods, ", "))
+	flags.String("mount", "", "Caminho do método de auth (padrão: nome do método)")
+	flags.String("role", "", "Role do Vault (aws, jwt, kubernetes)")
+	flags.String("secret-path", "", "Caminho das credenciais StackSpot (padrão: secret/data/stackspot)")
+	flags.Int("kv-version", 0, "Versão do KV (1 ou 2); 0 detecta via sys/mounts")
+	flags.String("client-id-field", "", "Campo do client_id no secret")
+	flags.String("client-secret-field", "", "Campo do client_secret no secret")
+	flags.String("server-id", "", "Valor do X-Vault-AWS-IAM-Server-ID")
+	flags.String("sts-region", "", "Região do STS configurada no mount AWS")
+	flags.String("role-id", "", "Role ID do AppRole")
+	flags.String("jwt-file", "", "Arquivo com o JWT (padrão: ID token do GitHub Actions)")
+	flags.String("audience", "", "Audience do ID token do GitHub Actions")
+	flags.String("kubernetes-token-file", "", "Token da service account")
+	flags.Bool("secret-stdin", false, "Ler o secret_id (approle) ou o token (token) do stdin")
+
+	authStatusCmd.Flags().Bool("json", false, "Imprimir o status em JSON")
+	authStatusCmd.Flags().Bool("offline", false, "Não consultar APIs externas para identificar os tokens")
+
+	authTokenCmd.Flags().String("scope", string(token.ScopeExecution), "Escopo do token: execution, creation, read ou write")
+
+	authLoginCmd.AddCommand(authLoginStackSpotCmd)
+	authLoginCmd.AddCommand(authLoginGitHubCmd)
+	authLoginCmd.AddCommand(authLoginVaultCmd)
+
+	authCmd.AddCommand(authStatusCmd)
+	authCmd.AddCommand(authLogoutCmd)
+	authCmd.AddCommand(authTokenCmd)
+}
@@ -0,0 +1,512 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/PHRaulino/phengineer/internal/infrastructure/config"
+	"github.com/PHRaulino/phengineer/internal/infrastructure/gitrepo"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Consultar, alterar e validar a configuração",
+	Long: `A configuração é montada em camadas, da menor para a maior precedência:
+
+  1. padrões da CLI
+  2. ~/.config/phengineer/config.yml (usuário)
+  3. .phengineer/settings.yml (repositório)
+  4. variáveis PHENGINEER_<CHAVE> (ex.: PHENGINEER_AUTH_MODE)
+  5. flags --config-set chave=valor`,
+	// Só injeta o repositório e registra as flags: os comandos de config precisam rodar mesmo
+	// com a configuração inválida
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		// Uma falha ao abrir o repositório é reportada pelos subcomandos que dependem dele
+		_ = openRepository(cmd)
+		flags, err := setFlagValues(cmd)
+		if err != nil {
+			return err
+		}
+		config.SetFlagOverrides(flags)
+		return nil
+	},
+}
+
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Mostrar a configuração resolvida e o diagnóstico dos arquivos",
+	Long: `Imprime a configuração resolvida em YAML. O diagnóstico (arquivos usados, versão
+do settings.yml, repositório e avisos) sai como comentários no início, então a saída
+continua sendo um YAML válido.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out := cmd.OutOrStdout()
+		printDiagnostics(out, config.Diagnose(cmd.Context(), ".phengineer"), "# ")
+
+		layered, err := config.LoadLayered(cmd.Context(), ".phengineer")
+		if err != nil {
+			return fmt.Errorf("erro ao carregar a configuração: %w", err)
+		}
+
+		if origin, _ := cmd.Flags().GetBool("origin"); origin {
+			printOrigins(out, layered)
+			return nil
+		}
+
+		encoder := yaml.NewEncoder(out)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(layered.Settings); err != nil {
+			return fmt.Errorf("erro ao serializar a configuração: %w", err)
+		}
+		return encoder.Close()
+	},
+}
+
+var configGetCmd = &cobra.Command{
+	Use:     "get <chave>",
+	Short:   "Mostrar o valor resolvido de uma chave",
+	Example: "  phengineer config get analysis.file_limits.max_files --origin",
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		key := args[0]
+		if !slices.Contains(config.SettingsKeys(), key) {
+			return fmt.Errorf("chave '%s' desconhecida, veja 'phengineer config show --origin'", key)
+		}
+
+		layered, err := config.LoadLayered(cmd.Context(), ".phengineer")
+		if err != nil {
+			return fmt.Errorf("erro ao carregar a configuração: %w", err)
+		}
+
+		value := layered.FormatValue(key)
+		if origin, _ := cmd.Flags().GetBool("origin"); origin {
+			if o, ok := layered.Origins[key]; ok {
+				value += "  (" + o.String() + ")"
+			}
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), value)
+		return nil
+	},
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set <chave> <valor>",
+	Short: "Alterar uma chave do settings.yml",
+	Long: `Grava uma chave no .phengineer/settings.yml preservando comentários e formatação.
+Listas são separadas por vírgula. O arquivo só é alterado se a configuração
+resultante for válida.`,
+	Example: `  phengineer config set project.type lambda
+  phengineer config set http.ca_cert_files /certs/a.pem,/certs/b.pem`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		key, value := args[0], args[1]
+		settingsPath, err := existingSettingsPath(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		if err := config.SetSettingsValue(settingsPath, key, value); err != nil {
+			return problemsError(cmd.ErrOrStderr(), err)
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "✅ %s = %s (%s)\n", key, value, settingsPath)
+		warnOverridden(cmd.Context(), cmd.ErrOrStderr(), key)
+		return nil
+	},
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Validar a configuração e listar todos os problemas",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		diagnostics := config.Diagnose(cmd.Context(), ".phengineer")
+		for _, warning := range diagnostics.Warnings {
+			fmt.Fprintf(cmd.ErrOrStderr(), "! %s\n", warning)
+		}
+
+		layered, err := config.LoadLayered(cmd.Context(), ".phengineer")
+		if err != nil {
+			return problemsError(cmd.ErrOrStderr(), err)
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "✅ Configuração válida (%d chaves resolvidas)\n", len(layered.Keys()))
+		return nil
+	},
+}
+
+var configEditCmd = &cobra.Command{
+	Use:   "edit",
+	Short: "Abrir o settings.yml no editor e validar ao sair",
+	Long: `Abre o .phengineer/settings.yml em $VISUAL ou $EDITOR (padrão: vi) e valida o
+arquivo ao fechar o editor. Se houver problemas, eles são listados e o editor
+pode ser reaberto para corrigi-los.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		settingsPath, err := existingSettingsPath(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		reader := bufio.NewReader(cmd.InOrStdin())
+		for {
+			if err := runEditor(settingsPath); err != nil {
+				return err
+			}
+
+			err := config.ValidateSettingsFile(settingsPath)
+			if err == nil {
+				fmt.Fprintf(cmd.OutOrStdout(), "✅ %s válido\n", settingsPath)
+				return nil
+			}
+			printProblems(cmd.ErrOrStderr(), err)
+			if !confirm(cmd, reader, "Abrir o editor novamente? [S/n] ", true) {
+				return fmt.Errorf("%s continua inválido", settingsPath)
+			}
+		}
+	},
+}
+
+var configResetCmd = &cobra.Command{
+	Use:   "reset [chave...]",
+	Short: "Voltar chaves ou o settings.yml inteiro aos padrões",
+	Long: `Com chaves, remove cada uma do settings.yml: o valor volta a vir das camadas
+anteriores (arquivo do usuário ou padrões da CLI). Sem chaves, recria o
+settings.yml com os padrões e guarda o original em settings.yml.bak.`,
+	Example: `  phengineer config reset analysis.file_limits.max_files
+  phengineer config reset --yes`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		settingsPath, err := config.ProjectSettingsPath(cmd.Context(), ".phengineer")
+		if err != nil {
+			return fmt.Errorf("não foi possível localizar o settings.yml: %w", err)
+		}
+
+		if len(args) > 0 {
+			values := make(map[string]string, len(args))
+			for _, key := range args {
+				values[key] = ""
+			}
+			if err := config.SetSettingsValues(settingsPath, values); err != nil {
+				return problemsError(cmd.ErrOrStderr(), err)
+			}
+			layered, err := config.LoadLayered(cmd.Context(), ".phengineer")
+			if err != nil {
+				return fmt.Errorf("erro ao carregar a configuração: %w", err)
+			}
+			for _, key := range args {
+				fmt.Fprintf(cmd.OutOrStdout(), "↺ %s = %s  (%s)\n", key, layered.FormatValue(key), layered.Origins[key])
+			}
+			return nil
+		}
+
+		if yes, _ := cmd.Flags().GetBool("yes"); !yes {
+			reader := bufio.NewReader(cmd.InOrStdin())
+			if !confirm(cmd, reader, fmt.Sprintf("Recriar %s com os padrões? [s/N] ", settingsPath), false) {
+				return fmt.Errorf("reset cancelado")
+			}
+		}
+
+		backupPath, err := config.ResetSettingsFile(settingsPath, ".phengineer")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "✅ %s recriado com os padrões\n", settingsPath)
+		if backupPath != "" {
+			fmt.Fprintf(cmd.OutOrStdout(), "   Backup do original: %s\n", backupPath)
+		}
+		return nil
+	},
+}
+
+var configMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Atualizar o settings.yml para a versão atual do formato",
+	Long: `Converte layouts antigos do .phengineer/settings.yml (files_include_path,
+project.language em texto etc.) para o formato atual, preservando comentários.
+O arquivo original é copiado para settings.yml.v<versão>.bak.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		settingsPath, err := config.ProjectSettingsPath(cmd.Context(), ".phengineer")
+		if err != nil {
+			return fmt.Errorf("não foi possível localizar o settings.yml: %w", err)
+		}
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		result, data, err := config.MigrateSettingsFile(settingsPath, ".phengineer", dryRun)
+		if err != nil {
+			return err
+		}
+		out := cmd.OutOrStdout()
+		if !result.Changed() {
+			fmt.Fprintf(out, "✅ %s já está na versão %d\n", settingsPath, result.ToVersion)
+			return nil
+		}
+
+		for _, change := range result.Changes {
+			fmt.Fprintf(out, "  - %s\n", change)
+		}
+		if dryRun {
+			fmt.Fprintf(out, "\n%s", data)
+			return nil
+		}
+		fmt.Fprintf(out, "✅ %s migrado da versão %d para a %d (backup: %s)\n",
+			settingsPath, result.FromVersion, result.ToVersion, result.BackupPath)
+		return nil
+	},
+}
+
+var configSchemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Gerar o JSON Schema do settings.yml para o editor",
+	Long: `Grava o JSON Schema do settings.yml (por padrão em .phengineer/settings.schema.json)
+e acrescenta ao settings.yml o comentário lido pela extensão YAML do VS Code:
+
+  # yaml-language-server: $schema=settings.schema.json
+
+Com isso o editor completa as chaves, mostra a descrição de cada uma e marca
+valores inválidos. Use --output - para imprimir o schema.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		output, _ := cmd.Flags().GetString("output")
+		if output == "-" {
+			_, err := cmd.OutOrStdout().Write(config.SettingsSchema())
+			return err
+		}
+
+		settingsPath, err := config.ProjectSettingsPath(cmd.Context(), ".phengineer")
+		if err != nil {
+			return fmt.Errorf("não foi possível localizar o settings.yml: %w", err)
+		}
+		if output == "" {
+			output = filepath.Join(filepath.Dir(settingsPath), config.SchemaFileName)
+		}
+
+		linked, err := config.WriteSettingsSchema(output, settingsPath)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "✅ Schema gravado em %s\n", output)
+		if linked {
+			fmt.Fprintf(cmd.OutOrStdout(), "✅ %s agora referencia o schema\n", settingsPath)
+		}
+		return nil
+	},
+}
+
+// printOrigins lista cada chave com o valor e a camada que a definiu
+func printOrigins(w io.Writer, layered *config.LayeredSettings) {
+	keys := layered.Keys()
+	width := 0
+	for _, key := range keys {
+		width = max(width, len(key))
+	}
+
+	for _, key := range keys {
+		fmt.Fprintf(w, "%-*s = %s  (%s)\n", width, key, layered.FormatValue(key), layered.Origins[key])
+	}
+}
+
+// printDiagnostics imprime os arquivos e o repositório usados na configuração; prefix
+// permite emitir as linhas como comentários YAML
+func printDiagnostics(w io.Writer, d *config.Diagnostics, prefix string) {
+	found := func(path string, ok bool) string {
+		if ok {
+			return path
+		}
+		return path + " (não encontrado)"
+	}
+
+	if d.SettingsPath != "" {
+		settings := found(d.SettingsPath, d.SettingsFound)
+		if d.SettingsFound {
+			settings += fmt.Sprintf(" (versão %d)", d.SettingsVersion)
+		}
+		fmt.Fprintf(w, "%ssettings.yml: %s\n", prefix, settings)
+	}
+	if d.UserConfigPath != "" {
+		fmt.Fprintf(w, "%sconfig do usuário: %s\n", prefix, found(d.UserConfigPath, d.UserConfigFound))
+	}
+	if d.Auto != nil {
+		fmt.Fprintf(w, "%srepositório: %s (%s)\n", prefix, d.Auto.AppName, d.Auto.RootAppPath)
+		if d.Auto.RemoteURL != "" {
+			fmt.Fprintf(w, "%sremote: %s\n", prefix, d.Auto.RemoteURL)
+		}
+	}
+	for _, warning := range d.Warnings {
+		fmt.Fprintf(w, "%s! %s\n", prefix, warning)
+	}
+}
+
+// printProblems lista um problema por linha quando o erro vem da validação
+func printProblems(w io.Writer, err error) {
+	var settingsErr *config.SettingsError
+	if !errors.As(err, &settingsErr) {
+		fmt.Fprintf(w, "✗ %v\n", err)
+		return
+	}
+	for _, problem := range settingsErr.Problems {
+		fmt.Fprintf(w, "✗ %s\n", problem.Error())
+	}
+}
+
+// problemsError imprime os problemas de validação e resume o erro; outros erros seguem como estão
+func problemsError(w io.Writer, err error) error {
+	var settingsErr *config.SettingsError
+	if !errors.As(err, &settingsErr) {
+		return err
+	}
+	printProblems(w, err)
+	return fmt.Errorf("configuração inválida: %d problema(s)", len(settingsErr.Problems))
+}
+
+// warnOverridden avisa quando uma variável ou flag sobrescreve o valor gravado no arquivo
+func warnOverridden(ctx context.Context, w io.Writer, key string) {
+	layered, err := config.LoadLayered(ctx, ".phengineer")
+	if err != nil {
+		return
+	}
+	if origin := layered.Origins[key]; origin.Layer == config.LayerEnv || origin.Layer == config.LayerFlag {
+		fmt.Fprintf(w, "! %s continua sobrescrito por %s\n", key, origin)
+	}
+}
+
+// existingSettingsPath retorna o settings.yml do repositório, criando o padrão se ainda não existir
+func existingSettingsPath(ctx context.Context) (string, error) {
+	settingsPath, err := config.ProjectSettingsPath(ctx, ".phengineer")
+	if err != nil {
+		return "", fmt.Errorf("não foi possível localizar o settings.yml: %w", err)
+	}
+	if _, err := os.Stat(settingsPath); os.IsNotExist(err) {
+		if _, err := config.LoadOrCreateSettings(filepath.Dir(settingsPath), ".phengineer"); err != nil {
+			return "", err
+		}
+	}
+	return settingsPath, nil
+}
+
+// runEditor abre o arquivo em $VISUAL ou $EDITOR; o valor pode ter argumentos (ex.: "code --wait")
+func runEditor(path string) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+
+	parts := strings.Fields(editor)
+	editorCmd := exec.Command(parts[0], append(parts[1:], path)...)
+	editorCmd.Stdin = os.Stdin
+	editorCmd.Stdout = os.Stdout
+	editorCmd.Stderr = os.Stderr
+	if err := editorCmd.Run(); err != nil {
+		return fmt.Errorf("erro ao executar o editor %s: %w", parts[0], err)
+	}
+	return nil
+}
+
+// confirm pergunta sim ou não; sem resposta (Enter ou fim da entrada) vale o padrão
+func confirm(cmd *cobra.Command, reader *bufio.Reader, question string, defaultYes bool) bool {
+	fmt.Fprint(cmd.ErrOrStderr(), question)
+	answer, err := reader.ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	if answer == "" {
+		return defaultYes && err == nil
+	}
+	return answer == "s" || answer == "sim" || answer == "y" || answer == "yes"
+}
+
+// ConfigContext adiciona a configuração ao context e avisa no stderr quando o
+// settings.yml foi migrado de um formato antigo
+func ConfigContext(ctx context.Context) (context.Context, error) {
+	ctx, err := config.WithConfig(ctx, ".phengineer")
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize config: %w", err)
+	}
+
+	if migration := config.FromContext(ctx).Migration; migration != nil {
+		fmt.Fprintf(os.Stderr, "Aviso: settings.yml migrado da versão %d para a %d (backup: %s)\n",
+			migration.FromVersion, migration.ToVersion, migration.BackupPath)
+		for _, change := range migration.Changes {
+			fmt.Fprintf(os.Stderr, "  - %s\n", change)
+		}
+	}
+	return ctx, nil
+}
+
+// ConfigSetFlag flag global que sobrescreve chaves da configuração. Não se chama --set para
+// não colidir com as flags dos subcomandos (ex.: variáveis do prompts render).
+const ConfigSetFlag = "config-set"
+
+// LoadConfig abre o repositório, resolve a configuração em camadas com os valores de
+// --config-set e a publica no viper
+func LoadConfig(cmd *cobra.Command) error {
+	if err := openRepository(cmd); err != nil {
+		return err
+	}
+	flags, err := setFlagValues(cmd)
+	if err != nil {
+		return err
+	}
+	if _, err := config.InitConfig(cmd.Context(), ".phengineer", flags); err != nil {
+		return fmt.Errorf("erro ao carregar a configuração: %w", err)
+	}
+	return nil
+}
+
+// openRepository abre o repositório do diretório atual uma única vez e o injeta no
+// context do comando, de onde a configuração e a descoberta o leem. Fora do git não falha.
+func openRepository(cmd *cobra.Command) error {
+	repo, err := gitrepo.FromContext(cmd.Context())
+	if errors.Is(err, gitrepo.ErrNotRepository) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	cmd.SetContext(gitrepo.WithRepository(cmd.Context(), repo))
+	return nil
+}
+
+// setFlagValues converte as flags --config-set chave=valor em mapa
+func setFlagValues(cmd *cobra.Command) (map[string]string, error) {
+	values, _ := cmd.Flags().GetStringArray(ConfigSetFlag)
+	flags := make(map[string]string, len(values))
+	for _, value := range values {
+		key, val, ok := strings.Cut(value, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("--%s '%s' inválido, use chave=valor", ConfigSetFlag, value)
+		}
+		flags[strings.TrimSpace(key)] = val
+	}
+	return flags, nil
+}
+
+func init() {
+	configShowCmd.Flags().Bool("origin", false, "Mostrar a camada que definiu cada chave")
+	configGetCmd.Flags().Bool("origin", false, "Mostrar a camada que definiu o valor")
+	configResetCmd.Flags().BoolP("yes", "y", false, "Recriar o settings.yml sem confirmação")
+
+	configMigrateCmd.Flags().Bool("dry-run", false, "Mostrar o resultado sem alterar o arquivo")
+
+	configSchemaCmd.Flags().StringP("output", "o", "", "Arquivo do schema (padrão: .phengineer/settings.schema.json; - para stdout)")
+
+	configCmd.AddCommand(configShowCmd)
+	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configSetCmd)
+	configCmd.AddCommand(configValidateCmd)
+	configCmd.AddCommand(configEditCmd)
+	configCmd.AddCommand(configResetCmd)
+	configCmd.AddCommand(configMigrateCmd)
+	configCmd.AddCommand(configSchemaCmd)
+}
+
+// GetConfigCmd returns the config command group for external use
+func GetConfigCmd() *cobra.Command {
+	return configCmd
+}
@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/PHRaulino/phengineer/internal/domain/agent"
+	"github.com/PHRaulino/phengineer/internal/domain/assembler"
+	"github.com/spf13/cobra"
+)
+
+var contextCmd = &cobra.Command{
+	Use:   "context",
+	Short: "Gerenciar os contextos gerados pela análise",
+}
+
+var contextSummaryCmd = &cobra.Command{
+	Use:   "summary",
+	Short: "Gerar o índice summary.md/summary.json dos contextos disponíveis",
+	Long: `Gera o índice usado no Fluxo de Discovery: o agente lê o summary.md junto
+com a issue, escolhe os contextos necessários e só então recebe o conteúdo deles.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir, _ := cmd.Flags().GetString("dir")
+		model, _ := cmd.Flags().GetString("model")
+
+		index, err := agent.BuildContextIndex(dir, assembler.GetProfile(model))
+		if err != nil {
+			return err
+		}
+
+		if err := index.WriteSummary(); err != nil {
+			return err
+		}
+
+		fmt.Print(index.Markdown())
+		fmt.Printf("\n✅ Índice gravado em %s\n", filepath.Join(dir, agent.SummaryMarkdownFile))
+		return nil
+	},
+}
+
+func init() {
+	contextSummaryCmd.Flags().String("dir", filepath.Join(".phengineer", "context"), "Diretório dos contextos")
+	contextSummaryCmd.Flags().String("model", "default", "Perfil do modelo usado na estimativa de tokens")
+
+	contextCmd.AddCommand(contextSummaryCmd)
+}
+
+// GetContextCmd returns the context command for external use
+func GetContextCmd() *cobra.Command {
+	return contextCmd
+}
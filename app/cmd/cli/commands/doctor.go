@@ -0,0 +1,87 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/PHRaulino/phengineer/internal/infrastructure/auth"
+	"github.com/PHRaulino/phengineer/internal/infrastructure/auth/storage"
+	"github.com/PHRaulino/phengineer/internal/infrastructure/config"
+	"github.com/spf13/cobra"
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Verificar o ambiente e listar as funcionalidades disponíveis",
+	Long: `Confere os pré-requisitos (git, repositório, remote e pasta de configuração) e
+mostra quais funcionalidades estão disponíveis no diretório atual e onde as
+credenciais são armazenadas.
+
+Fora de um repositório git a descoberta continua funcionando e detecta mudanças
+pela data de modificação e pelo conteúdo dos arquivos. Os recursos ligados ao
+remote (instalação do GitHub App, permissões do token, issues e pull requests)
+falham apenas quando usados.`,
+	Args: cobra.NoArgs,
+	// Roda mesmo fora do git e com a configuração inválida
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		// Uma falha ao abrir o repositório é reportada pelos subcomandos que dependem dele
+		_ = openRepository(cmd)
+		flags, err := setFlagValues(cmd)
+		if err != nil {
+			return err
+		}
+		config.SetFlagOverrides(flags)
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out := cmd.OutOrStdout()
+		validator := config.NewRequirementsValidator(".phengineer").WithContext(cmd.Context())
+
+		fmt.Fprintln(out, "Pré-requisitos:")
+		results, _ := validator.ValidateWithDetails()
+		for _, result := range results {
+			if result.Passed {
+				fmt.Fprintf(out, "  ✅ %s\n", result.Name)
+				continue
+			}
+			fmt.Fprintf(out, "  ✗ %s: %s\n", result.Name, result.ErrorMsg)
+		}
+
+		fmt.Fprintln(out, "\nFuncionalidades:")
+		for _, capability := range validator.Capabilities() {
+			if capability.Available {
+				fmt.Fprintf(out, "  ✅ %s: %s\n", capability.Feature, capability.Feature.Description())
+				continue
+			}
+			fmt.Fprintf(out, "  ✗ %s: %s\n", capability.Feature, capability.Feature.Description())
+			for _, missing := range capability.Missing {
+				fmt.Fprintf(out, "      falta: %s\n", missing.Description)
+			}
+		}
+
+		fmt.Fprintln(out, "\nCredenciais:")
+		switch backend, err := auth.StorageBackend(); {
+		case err != nil:
+			fmt.Fprintf(out, "  ✗ Armazenamento: %v\n", err)
+		case backend == storage.BackendMemory:
+			fmt.Fprintf(out, "  ✗ Armazenamento: %s (as credenciais não persistem)\n", backend)
+			fmt.Fprintf(out, "      %s ou habilite o keyring do sistema\n", storage.ErrNoStorageKey)
+		default:
+			fmt.Fprintf(out, "  ✅ Armazenamento: %s\n", backend)
+		}
+
+		fmt.Fprintln(out, "\nConfiguração:")
+		printDiagnostics(out, config.Diagnose(cmd.Context(), ".phengineer"), "  ")
+		layered, err := config.LoadLayered(cmd.Context(), ".phengineer")
+		if err != nil {
+			printProblems(out, err)
+			return fmt.Errorf("configuração inválida, veja 'phengineer config validate'")
+		}
+		fmt.Fprintf(out, "  ✅ Configuração válida (%d chaves resolvidas)\n", len(layered.Keys()))
+		return nil
+	},
+}
+
+// GetDoctorCmd returns the doctor command for external use
+func GetDoctorCmd() *cobra.Command {
+	return doctorCmd
+}
@@ -0,0 +1,104 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/PHRaulino/phengineer/internal/domain/knowledge"
+	"github.com/PHRaulino/phengineer/internal/domain/spec"
+	"github.com/PHRaulino/phengineer/internal/infrastructure/config"
+	"github.com/spf13/cobra"
+)
+
+var knowledgeCmd = &cobra.Command{
+	Use:   "knowledge",
+	Short: "Gerenciar fontes de conhecimento",
+	Long: `Fontes de conhecimento são documentos compartilhados entre projetos
+(conventions.md, security-policies.md, performance-standards.md, company-patterns.md)
+incluídos nos prompts dos agentes.
+
+As fontes são lidas de ~/.config/phengineer/config.yml e de .phengineer/settings.yml.`,
+}
+
+var knowledgeListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Listar documentos de conhecimento aplicáveis ao projeto",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, err := ConfigContext(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		registry, err := knowledge.FromContext(ctx)
+		if err != nil {
+			return err
+		}
+
+		projectType, _ := cmd.Flags().GetString("project-type")
+		if projectType == "" {
+			projectType = config.GetSettings(ctx).Project.Type
+		}
+		generationType, _ := cmd.Flags().GetString("generation-type")
+		all, _ := cmd.Flags().GetBool("all")
+
+		documents, err := registry.Documents(ctx)
+		if err != nil {
+			return err
+		}
+		if !all {
+			documents = knowledge.Select(documents, projectType, spec.GenerationType(generationType))
+		}
+
+		fmt.Printf("=== Fontes de Conhecimento (%d) ===\n", len(registry.Sources()))
+		for _, source := range registry.Sources() {
+			location := source.Path
+			if source.Git != "" {
+				location = source.Git + "@" + source.Ref
+			}
+			fmt.Printf("• %s: %s\n", source.Name, location)
+		}
+
+		fmt.Printf("\n=== Documentos (%s) ===\n", projectType)
+		for _, doc := range documents {
+			fmt.Printf("- %s/%s [%s]\n", doc.Source, doc.Path, strings.Join(doc.Tags, ", "))
+		}
+		return nil
+	},
+}
+
+var knowledgeSyncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Atualizar o cache das fontes git",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, err := ConfigContext(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		registry, err := knowledge.FromContext(ctx)
+		if err != nil {
+			return err
+		}
+
+		if err := registry.Sync(ctx); err != nil {
+			return err
+		}
+
+		fmt.Println("✅ Fontes de conhecimento atualizadas")
+		return nil
+	},
+}
+
+func init() {
+	knowledgeListCmd.Flags().String("project-type", "", "Tipo de projeto (padrão: project.type do settings.yml)")
+	knowledgeListCmd.Flags().String("generation-type", "", "Tipo de geração: feature, test, fix, doc, refactor")
+	knowledgeListCmd.Flags().Bool("all", false, "Listar todos os documentos sem filtrar")
+
+	knowledgeCmd.AddCommand(knowledgeListCmd)
+	knowledgeCmd.AddCommand(knowledgeSyncCmd)
+}
+
+// GetKnowledgeCmd returns the knowledge command for external use
+func GetKnowledgeCmd() *cobra.Command {
+	return knowledgeCmd
+}
@@ -0,0 +1,139 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/PHRaulino/phengineer/internal/domain/prompts"
+	"github.com/spf13/cobra"
+)
+
+var promptsCmd = &cobra.Command{
+	Use:   "prompts",
+	Short: "Gerenciar os templates de prompt dos agentes",
+	Long: `Os templates padrão são embutidos no binário e podem ser substituídos
+por arquivos <nome>.md em ~/.config/phengineer/prompts ou .phengineer/prompts
+(o projeto tem precedência sobre o usuário).`,
+}
+
+var promptsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Listar templates e suas origens",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		registry, err := promptsRegistry(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		templates, err := registry.List()
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("=== Prompts (%d) ===\n", len(templates))
+		for _, tmpl := range templates {
+			fmt.Printf("• %s@%s [%s] %s\n", tmpl.Name, tmpl.Version, tmpl.Source, tmpl.Description)
+			if len(tmpl.Variables) > 0 {
+				fmt.Printf("  variáveis: %s\n", strings.Join(tmpl.Variables, ", "))
+			}
+			if len(tmpl.Optional) > 0 {
+				fmt.Printf("  opcionais: %s\n", strings.Join(tmpl.Optional, ", "))
+			}
+		}
+		return nil
+	},
+}
+
+var promptsRenderCmd = &cobra.Command{
+	Use:   "render <name>",
+	Short: "Mostrar o prompt renderizado sem chamar o modelo",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		registry, err := promptsRegistry(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		vars, err := renderVars(cmd)
+		if err != nil {
+			return err
+		}
+
+		rendered, err := registry.Render(args[0], vars)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(os.Stderr, "# %s\n", rendered.Stamp)
+		fmt.Println(rendered.Text)
+		return nil
+	},
+}
+
+// promptsRegistry cria o registro com os overrides do usuário e do projeto
+func promptsRegistry(ctx context.Context) (*prompts.Registry, error) {
+	ctx, err := ConfigContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return prompts.FromContext(ctx)
+}
+
+// renderVars monta as variáveis a partir das flags --request, --set e --set-file
+func renderVars(cmd *cobra.Command) (map[string]any, error) {
+	vars := make(map[string]any)
+
+	request, _ := cmd.Flags().GetString("request")
+	requestFile, _ := cmd.Flags().GetString("request-file")
+	if requestFile != "" {
+		data, err := os.ReadFile(requestFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read request file: %w", err)
+		}
+		request = string(data)
+	}
+	if request != "" {
+		vars["user_request"] = request
+	}
+
+	sets, _ := cmd.Flags().GetStringArray("set")
+	for _, set := range sets {
+		key, value, ok := strings.Cut(set, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid --set '%s', expected key=value", set)
+		}
+		vars[key] = value
+	}
+
+	setFiles, _ := cmd.Flags().GetStringArray("set-file")
+	for _, set := range setFiles {
+		key, path, ok := strings.Cut(set, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid --set-file '%s', expected key=path", set)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		vars[key] = string(data)
+	}
+
+	return vars, nil
+}
+
+func init() {
+	promptsRenderCmd.Flags().String("request", "", "Texto da solicitação (variável user_request)")
+	promptsRenderCmd.Flags().String("request-file", "", "Arquivo com o texto da solicitação")
+	promptsRenderCmd.Flags().StringArray("set", nil, "Variável no formato chave=valor")
+	promptsRenderCmd.Flags().StringArray("set-file", nil, "Variável lida de arquivo no formato chave=caminho")
+
+	promptsCmd.AddCommand(promptsListCmd)
+	promptsCmd.AddCommand(promptsRenderCmd)
+}
+
+// GetPromptsCmd returns the prompts command for external use
+func GetPromptsCmd() *cobra.Command {
+	return promptsCmd
+}
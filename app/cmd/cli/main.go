@@ -1,7 +1,6 @@
 package main
 
 import (
-	"context"
 	"fmt"
 	"os"
 	"time"
@@ -9,7 +8,6 @@ import (
 	cli "github.com/PHRaulino/phengineer/cmd/cli/commands"
 	"github.com/PHRaulino/phengineer/internal/domain/discovery"
 	"github.com/PHRaulino/phengineer/internal/infrastructure/auth"
-	"github.com/PHRaulino/phengineer/internal/infrastructure/config"
 	"github.com/PHRaulino/phengineer/internal/infrastructure/utils/logger"
 	"github.com/spf13/cobra"
 )
@@ -36,30 +34,33 @@ com correspondência inteligente de padrões e integração Git.`,
 	RunE: runDiscovery,
 }
 
-var authCmd = &cobra.Command{
-	Use:   "auth",
-	Short: "Gerenciar autenticação",
-	Long:  "Comandos para configurar e gerenciar autenticação com diferentes provedores",
-}
-
 func init() {
 	logger.SetupLogger()
-	auth.SetupGenerators()
-	
-	// Adicionar comando auth
-	rootCmd.AddCommand(authCmd)
-	authCmd.AddCommand(cli.GetAuthSetupCmd())
+
+	rootCmd.PersistentFlags().String("profile", "", "Perfil de credenciais (padrão: PHENGINEER_PROFILE, auth.profile do settings.yml ou 'auth use')")
+	rootCmd.PersistentFlags().StringArray(cli.ConfigSetFlag, nil, "Sobrescreve uma chave da configuração (ex.: --config-set auth.mode=stackspot_service)")
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		if err := cli.LoadConfig(cmd); err != nil {
+			return err
+		}
+		return cli.SelectProfile(cmd)
+	}
+
+	rootCmd.AddCommand(cli.GetAuthCmd())
+	rootCmd.AddCommand(cli.GetKnowledgeCmd())
+	rootCmd.AddCommand(cli.GetContextCmd())
+	rootCmd.AddCommand(cli.GetPromptsCmd())
+	rootCmd.AddCommand(cli.GetConfigCmd())
+	rootCmd.AddCommand(cli.GetDoctorCmd())
 }
 
 func runDiscovery(cmd *cobra.Command, args []string) error {
 	showWelcomeScreen()
 
-	ctx := context.Background()
-
-	// Adiciona config ao context com verbose
-	ctx, err := config.WithConfig(ctx, ".phengineer")
+	// Adiciona config ao context, reaproveitando o repositório aberto pelo comando raiz
+	ctx, err := cli.ConfigContext(cmd.Context())
 	if err != nil {
-		return fmt.Errorf("failed to initialize config: %w", err)
+		return err
 	}
 
 	service := discovery.NewService()
@@ -102,7 +103,12 @@ func runDiscovery(cmd *cobra.Command, args []string) error {
 				fmt.Printf("... e mais %d arquivos alterados\n", len(changes.ChangedFiles)-5)
 				break
 			}
-			fmt.Printf("~ %s/%s (%s) [commit: %s]\n", file.Path, file.Name, file.Type, file.CommitHash[:8])
+			if len(file.CommitHash) >= 8 {
+				fmt.Printf("~ %s/%s (%s) [commit: %s]\n", file.Path, file.Name, file.Type, file.CommitHash[:8])
+			} else {
+				// Fora do git ou arquivo ainda não versionado
+				fmt.Printf("~ %s/%s (%s)\n", file.Path, file.Name, file.Type)
+			}
 		}
 	}
 
@@ -135,6 +141,9 @@ func runDiscovery(cmd *cobra.Command, args []string) error {
 func main() {
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintf(os.Stderr, "Erro: %v\n", err)
+		if hint := auth.Remediation(err); hint != "" {
+			fmt.Fprintf(os.Stderr, "Como resolver: %s\n", hint)
+		}
 		os.Exit(1)
 	}
 }
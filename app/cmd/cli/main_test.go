@@ -0,0 +1,52 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+// TestRootFlags testa que o --set dos subcomandos não é confundido com o --config-set global
+func TestRootFlags(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("PHENGINEER_STORAGE", "memory")
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantOut string
+	}{
+		{
+			name: "Prompt variable",
+			args: []string{"prompts", "render", "requirements", "--request", "add x", "--set", "project_context=foo", "--set", "project_structure=."},
+		},
+		{
+			name:    "Config override",
+			args:    []string{"config", "get", "http.timeout_seconds", "--config-set", "http.timeout_seconds=7"},
+			wantOut: "7",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			rootCmd.SetOut(&out)
+			rootCmd.SetArgs(tt.args)
+			if err := rootCmd.Execute(); err != nil {
+				t.Fatalf("Execute(%v) error = %v", tt.args, err)
+			}
+			if tt.wantOut != "" && strings.TrimSpace(out.String()) != tt.wantOut {
+				t.Errorf("Execute(%v) output = %q, want %q", tt.args, out.String(), tt.wantOut)
+			}
+		})
+	}
+}
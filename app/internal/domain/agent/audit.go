@@ -0,0 +1,88 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/PHRaulino/phengineer/internal/domain/prompts"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// StageRecord registra uma etapa da interação com o agente
+type StageRecord struct {
+	Name         string         `json:"name"`
+	StartedAt    time.Time      `json:"started_at"`
+	DurationMS   int64          `json:"duration_ms"`
+	PromptTokens int            `json:"prompt_tokens,omitempty"`
+	InputTokens  int            `json:"input_tokens,omitempty"`
+	OutputTokens int            `json:"output_tokens,omitempty"`
+	Details      map[string]any `json:"details,omitempty"`
+	Error        string         `json:"error,omitempty"`
+}
+
+// Trace representa o registro auditável de uma execução
+type Trace struct {
+	RunID     string          `json:"run_id"`
+	Agent     string          `json:"agent"`
+	StartedAt time.Time       `json:"started_at"`
+	Stages    []StageRecord   `json:"stages"`
+	Prompts   []prompts.Stamp `json:"prompts,omitempty"` // Versões dos prompts usados
+}
+
+// NewTrace inicia o registro de uma execução
+func NewTrace(agentName string) *Trace {
+	return &Trace{
+		RunID:     uuid.New().String(),
+		Agent:     agentName,
+		StartedAt: time.Now().UTC(),
+		Stages:    make([]StageRecord, 0),
+	}
+}
+
+// Record adiciona uma etapa ao trace e a registra no log
+func (t *Trace) Record(stage StageRecord) {
+	t.Stages = append(t.Stages, stage)
+
+	fields := []zap.Field{
+		zap.String("run_id", t.RunID),
+		zap.String("agent", t.Agent),
+		zap.String("stage", stage.Name),
+		zap.Int64("duration_ms", stage.DurationMS),
+	}
+	for key, value := range stage.Details {
+		fields = append(fields, zap.Any(key, value))
+	}
+
+	if stage.Error != "" {
+		zap.L().Error("agent stage failed", append(fields, zap.String("error", stage.Error))...)
+		return
+	}
+	zap.L().Info("agent stage", fields...)
+}
+
+// UsePrompts registra as versões dos prompts usados na execução
+func (t *Trace) UsePrompts(stamps ...prompts.Stamp) {
+	t.Prompts = prompts.MergeStamps(t.Prompts, stamps...)
+}
+
+// Save grava o trace em <dir>/<run_id>.json
+func (t *Trace) Save(dir string) (string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create audit directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(t, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal trace: %w", err)
+	}
+
+	path := filepath.Join(dir, t.RunID+".json")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return "", fmt.Errorf("failed to write trace: %w", err)
+	}
+	return path, nil
+}
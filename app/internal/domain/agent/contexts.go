@@ -0,0 +1,191 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/PHRaulino/phengineer/internal/domain/assembler"
+)
+
+const (
+	SummaryMarkdownFile = "summary.md"
+	SummaryJSONFile     = "summary.json"
+)
+
+// contextDescriptions descrições dos contextos gerados pela análise
+var contextDescriptions = map[string]string{
+	"file-tree":       "Estrutura hierárquica do projeto com diretórios, arquivos importantes e convenções",
+	"statistics":      "Métricas de código por linguagem e diretório",
+	"stack":           "Linguagens, frameworks, bancos de dados e dependências principais",
+	"architecture":    "Padrão arquitetural, camadas, modelos de dados e design patterns",
+	"functions":       "Inventário de funções com assinaturas, propósito e dependências",
+	"project-context": "Arquivos de configuração e documentação relevantes com resumos",
+	"components":      "Componentes de frontend e suas responsabilidades",
+	"styling":         "Padrões de estilo e design system do frontend",
+	"file-contexts":   "Contexto dos arquivos relevantes mapeados",
+	"dependencies":    "Integrações e dependências específicas do projeto",
+}
+
+// ContextEntry representa um contexto disponível no índice
+type ContextEntry struct {
+	ID          string `json:"id"`
+	File        string `json:"file"`
+	Description string `json:"description"`
+	SizeBytes   int64  `json:"size_bytes"`
+	Tokens      int    `json:"tokens"`
+}
+
+// ContextIndex representa o summary.json com os contextos disponíveis
+type ContextIndex struct {
+	GeneratedAt time.Time      `json:"generated_at"`
+	ContextDir  string         `json:"-"`
+	Entries     []ContextEntry `json:"contexts"`
+}
+
+// contextMetadata lê a descrição opcional declarada no próprio contexto
+type contextMetadata struct {
+	Metadata struct {
+		Description string `json:"description"`
+	} `json:"metadata"`
+}
+
+// BuildContextIndex indexa os contextos .json e .md de um diretório
+func BuildContextIndex(contextDir string, profile assembler.ModelProfile) (*ContextIndex, error) {
+	entries, err := os.ReadDir(contextDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read context directory: %w", err)
+	}
+
+	index := &ContextIndex{
+		GeneratedAt: time.Now().UTC(),
+		ContextDir:  contextDir,
+		Entries:     make([]ContextEntry, 0),
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		ext := filepath.Ext(name)
+		if entry.IsDir() || (ext != ".json" && ext != ".md") {
+			continue
+		}
+		if name == SummaryMarkdownFile || name == SummaryJSONFile {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(contextDir, name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read context %s: %w", name, err)
+		}
+
+		id := strings.TrimSuffix(name, ext)
+		index.Entries = append(index.Entries, ContextEntry{
+			ID:          id,
+			File:        name,
+			Description: describeContext(id, ext, data),
+			SizeBytes:   int64(len(data)),
+			Tokens:      profile.EstimateTokens(string(data)),
+		})
+	}
+
+	sort.Slice(index.Entries, func(i, j int) bool {
+		return index.Entries[i].ID < index.Entries[j].ID
+	})
+
+	return index, nil
+}
+
+// Lookup busca um contexto pelo ID
+func (i *ContextIndex) Lookup(id string) (ContextEntry, bool) {
+	for _, entry := range i.Entries {
+		if entry.ID == id {
+			return entry, true
+		}
+	}
+	return ContextEntry{}, false
+}
+
+// Load lê o conteúdo de um contexto do índice
+func (i *ContextIndex) Load(id string) (string, error) {
+	entry, ok := i.Lookup(id)
+	if !ok {
+		return "", fmt.Errorf("context '%s' not found in index", id)
+	}
+
+	data, err := os.ReadFile(filepath.Join(i.ContextDir, entry.File))
+	if err != nil {
+		return "", fmt.Errorf("failed to read context %s: %w", entry.File, err)
+	}
+	return string(data), nil
+}
+
+// Markdown gera o conteúdo do summary.md usado na etapa de seleção
+func (i *ContextIndex) Markdown() string {
+	var doc strings.Builder
+	doc.WriteString("# Contextos Disponíveis\n\n")
+	doc.WriteString("| ID | Descrição | Tamanho | Tokens |\n")
+	doc.WriteString("|----|-----------|---------|--------|\n")
+	for _, entry := range i.Entries {
+		fmt.Fprintf(&doc, "| `%s` | %s | %s | %d |\n", entry.ID, entry.Description, formatSize(entry.SizeBytes), entry.Tokens)
+	}
+	return doc.String()
+}
+
+// WriteSummary grava summary.md e summary.json no diretório de contextos
+func (i *ContextIndex) WriteSummary() error {
+	data, err := json.MarshalIndent(i, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal summary: %w", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(i.ContextDir, SummaryJSONFile), data, 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", SummaryJSONFile, err)
+	}
+
+	if err := os.WriteFile(filepath.Join(i.ContextDir, SummaryMarkdownFile), []byte(i.Markdown()), 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", SummaryMarkdownFile, err)
+	}
+
+	return nil
+}
+
+// describeContext usa a descrição do metadata, a conhecida ou o primeiro título do markdown
+func describeContext(id, ext string, data []byte) string {
+	if ext == ".json" {
+		var meta contextMetadata
+		if err := json.Unmarshal(data, &meta); err == nil && meta.Metadata.Description != "" {
+			return meta.Metadata.Description
+		}
+	}
+
+	if description, ok := contextDescriptions[id]; ok {
+		return description
+	}
+
+	if ext == ".md" {
+		for _, line := range strings.Split(string(data), "\n") {
+			line = strings.TrimSpace(line)
+			if strings.HasPrefix(line, "#") {
+				return strings.TrimSpace(strings.TrimLeft(line, "#"))
+			}
+		}
+	}
+
+	return id
+}
+
+// formatSize formata bytes como "2.3KB"
+func formatSize(size int64) string {
+	switch {
+	case size >= 1<<20:
+		return fmt.Sprintf("%.1fMB", float64(size)/(1<<20))
+	case size >= 1<<10:
+		return fmt.Sprintf("%.1fKB", float64(size)/(1<<10))
+	default:
+		return fmt.Sprintf("%dB", size)
+	}
+}
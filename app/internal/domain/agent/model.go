@@ -0,0 +1,62 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Request representa uma chamada ao modelo de IA
+type Request struct {
+	System string          `json:"system,omitempty"`
+	Prompt string          `json:"prompt"`
+	Schema json.RawMessage `json:"schema,omitempty"` // JSON Schema esperado na resposta
+}
+
+// Response representa a resposta do modelo de IA
+type Response struct {
+	Content      string `json:"content"`
+	InputTokens  int    `json:"input_tokens,omitempty"`
+	OutputTokens int    `json:"output_tokens,omitempty"`
+}
+
+// Model abstrai o cliente do modelo de IA usado pelos agentes
+type Model interface {
+	Complete(ctx context.Context, req Request) (Response, error)
+}
+
+// ModelFunc permite usar uma função como Model
+type ModelFunc func(ctx context.Context, req Request) (Response, error)
+
+// Complete implementa Model
+func (f ModelFunc) Complete(ctx context.Context, req Request) (Response, error) {
+	return f(ctx, req)
+}
+
+// CompleteJSON executa a chamada e decodifica a resposta JSON em out
+func CompleteJSON(ctx context.Context, model Model, req Request, out any) (Response, error) {
+	resp, err := model.Complete(ctx, req)
+	if err != nil {
+		return resp, fmt.Errorf("model call failed: %w", err)
+	}
+
+	if err := json.Unmarshal([]byte(extractJSON(resp.Content)), out); err != nil {
+		return resp, fmt.Errorf("failed to parse model response: %w", err)
+	}
+
+	return resp, nil
+}
+
+// extractJSON remove cercas de código markdown que alguns modelos adicionam
+func extractJSON(content string) string {
+	content = strings.TrimSpace(content)
+	if !strings.HasPrefix(content, "```") {
+		return content
+	}
+
+	content = strings.TrimPrefix(content, "```json")
+	content = strings.TrimPrefix(content, "```")
+	content = strings.TrimSuffix(content, "```")
+	return strings.TrimSpace(content)
+}
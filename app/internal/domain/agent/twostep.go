@@ -0,0 +1,278 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/PHRaulino/phengineer/internal/domain/assembler"
+	"github.com/PHRaulino/phengineer/internal/domain/prompts"
+)
+
+// selectionSchema é o JSON Schema da resposta da etapa de seleção
+var selectionSchema = json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "context_ids": { "type": "array", "items": { "type": "string" } },
+    "reason": { "type": "string" }
+  },
+  "required": ["context_ids", "reason"]
+}`)
+
+// selectionResponse representa a resposta da etapa de seleção
+type selectionResponse struct {
+	ContextIDs []string `json:"context_ids"`
+	Reason     string   `json:"reason"`
+}
+
+// TwoStepInput parâmetros de uma execução summary-first
+type TwoStepInput struct {
+	Agent       string        // Nome do agente, usado na auditoria
+	Request     string        // Texto da issue/solicitação
+	Index       *ContextIndex // Índice dos contextos disponíveis
+	MaxContexts int           // Limite de contextos selecionados (0 = sem limite)
+
+	// Final monta a chamada final a partir dos contextos carregados
+	Final func(contexts string) Request
+	// FinalPrompts versões dos templates usados pela chamada final
+	FinalPrompts []prompts.Stamp
+}
+
+// TwoStepResult resultado de uma execução summary-first
+type TwoStepResult struct {
+	Response    Response `json:"response"`
+	SelectedIDs []string `json:"selected_ids"`
+	LoadedIDs   []string `json:"loaded_ids"`
+	Trace       *Trace   `json:"trace"`
+	TracePath   string   `json:"trace_path,omitempty"`
+}
+
+// TwoStepFlow implementa o Fluxo de Discovery: o agente lê o summary.md com a
+// solicitação, escolhe os contextos necessários e só então gera a resposta final
+type TwoStepFlow struct {
+	model    Model
+	profile  assembler.ModelProfile
+	auditDir string
+	prompts  *prompts.Registry
+}
+
+// NewTwoStepFlow cria o fluxo. Se auditDir não for vazio, cada execução é salva lá.
+func NewTwoStepFlow(model Model, profile assembler.ModelProfile, auditDir string) *TwoStepFlow {
+	return &TwoStepFlow{
+		model:    model,
+		profile:  profile,
+		auditDir: auditDir,
+		prompts:  prompts.Embedded(),
+	}
+}
+
+// WithPrompts usa o registro de prompts com os overrides do projeto e do usuário
+func (f *TwoStepFlow) WithPrompts(registry *prompts.Registry) *TwoStepFlow {
+	f.prompts = registry
+	return f
+}
+
+// Run executa as etapas de índice, seleção, carga e chamada final
+func (f *TwoStepFlow) Run(ctx context.Context, input TwoStepInput) (*TwoStepResult, error) {
+	if input.Index == nil || input.Final == nil {
+		return nil, fmt.Errorf("two-step flow requires an index and a final request builder")
+	}
+
+	trace := NewTrace(input.Agent)
+	trace.UsePrompts(input.FinalPrompts...)
+	result := &TwoStepResult{Trace: trace}
+
+	// Etapa 1: índice
+	available := make([]string, 0, len(input.Index.Entries))
+	totalTokens := 0
+	for _, entry := range input.Index.Entries {
+		available = append(available, entry.ID)
+		totalTokens += entry.Tokens
+	}
+	trace.Record(StageRecord{
+		Name:      "index",
+		StartedAt: time.Now().UTC(),
+		Details: map[string]any{
+			"available_ids":    available,
+			"available_tokens": totalTokens,
+		},
+	})
+
+	// Etapa 2: seleção
+	selected, err := f.selectContexts(ctx, input, trace)
+	if err != nil {
+		return f.finish(result, err)
+	}
+	result.SelectedIDs = selected
+
+	// Etapa 3: carga dos contextos escolhidos
+	contexts, loaded, err := f.loadContexts(input, selected, trace)
+	if err != nil {
+		return f.finish(result, err)
+	}
+	result.LoadedIDs = loaded
+
+	// Etapa 4: chamada final
+	started := time.Now()
+	finalReq := input.Final(contexts)
+	resp, err := f.model.Complete(ctx, finalReq)
+	stage := StageRecord{
+		Name:         "final",
+		StartedAt:    started.UTC(),
+		DurationMS:   time.Since(started).Milliseconds(),
+		PromptTokens: f.profile.EstimateTokens(finalReq.System + finalReq.Prompt),
+		InputTokens:  resp.InputTokens,
+		OutputTokens: resp.OutputTokens,
+		Details:      map[string]any{"context_ids": loaded},
+	}
+	if err != nil {
+		stage.Error = err.Error()
+		trace.Record(stage)
+		return f.finish(result, fmt.Errorf("final call failed: %w", err))
+	}
+	trace.Record(stage)
+
+	result.Response = resp
+	return f.finish(result, nil)
+}
+
+// selectContexts executa a chamada de seleção e valida os IDs retornados
+func (f *TwoStepFlow) selectContexts(ctx context.Context, input TwoStepInput, trace *Trace) ([]string, error) {
+	system, err := f.prompts.Render("context-selection-system", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	vars := map[string]any{
+		"summary":      input.Index.Markdown(),
+		"user_request": input.Request,
+	}
+	if input.MaxContexts > 0 {
+		vars["max_contexts"] = input.MaxContexts
+	}
+	prompt, err := f.prompts.Render("context-selection", vars)
+	if err != nil {
+		return nil, err
+	}
+	trace.UsePrompts(system.Stamp, prompt.Stamp)
+
+	req := Request{
+		System: system.Text,
+		Prompt: prompt.Text,
+		Schema: selectionSchema,
+	}
+
+	started := time.Now()
+	var selection selectionResponse
+	resp, err := CompleteJSON(ctx, f.model, req, &selection)
+	stage := StageRecord{
+		Name:         "selection",
+		StartedAt:    started.UTC(),
+		DurationMS:   time.Since(started).Milliseconds(),
+		PromptTokens: f.profile.EstimateTokens(req.System + req.Prompt),
+		InputTokens:  resp.InputTokens,
+		OutputTokens: resp.OutputTokens,
+	}
+	if err != nil {
+		stage.Error = err.Error()
+		trace.Record(stage)
+		return nil, fmt.Errorf("context selection failed: %w", err)
+	}
+
+	selected := make([]string, 0, len(selection.ContextIDs))
+	rejected := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, id := range selection.ContextIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		if _, ok := input.Index.Lookup(id); !ok {
+			rejected = append(rejected, id)
+			continue
+		}
+		if input.MaxContexts > 0 && len(selected) >= input.MaxContexts {
+			rejected = append(rejected, id)
+			continue
+		}
+		selected = append(selected, id)
+	}
+
+	stage.Details = map[string]any{
+		"selected_ids": selected,
+		"rejected_ids": rejected,
+		"reason":       selection.Reason,
+	}
+	trace.Record(stage)
+
+	return selected, nil
+}
+
+// loadContexts carrega os contextos escolhidos respeitando o orçamento do modelo
+func (f *TwoStepFlow) loadContexts(input TwoStepInput, selected []string, trace *Trace) (string, []string, error) {
+	started := time.Now()
+
+	fragments := make([]assembler.Fragment, 0, len(selected))
+	for _, id := range selected {
+		content, err := input.Index.Load(id)
+		if err != nil {
+			trace.Record(StageRecord{Name: "load", StartedAt: started.UTC(), Error: err.Error()})
+			return "", nil, err
+		}
+		fragments = append(fragments, assembler.Fragment{
+			ID:       id,
+			Kind:     assembler.FragmentKind(id),
+			Title:    id,
+			Content:  content,
+			Priority: 1.0,
+			Required: true,
+		})
+	}
+
+	// Tokens ocupados pela chamada final sem contextos
+	emptyReq := input.Final("")
+	reserved := f.profile.EstimateTokens(emptyReq.System + emptyReq.Prompt)
+
+	assembly := assembler.NewAssembler(f.profile).Assemble(assembler.Request{Text: input.Request}, fragments, reserved)
+
+	var contexts strings.Builder
+	loaded := make([]string, 0, len(assembly.Fragments))
+	for _, fragment := range assembly.Fragments {
+		fmt.Fprintf(&contexts, "### %s\n\n%s\n\n", fragment.ID, strings.TrimSpace(fragment.Content))
+		loaded = append(loaded, fragment.ID)
+	}
+
+	dropped := make([]string, 0, len(assembly.Manifest.Dropped))
+	for _, entry := range assembly.Manifest.Dropped {
+		dropped = append(dropped, entry.ID+": "+entry.Reason)
+	}
+
+	trace.Record(StageRecord{
+		Name:       "load",
+		StartedAt:  started.UTC(),
+		DurationMS: time.Since(started).Milliseconds(),
+		Details: map[string]any{
+			"loaded_ids":  loaded,
+			"dropped":     dropped,
+			"used_tokens": assembly.Manifest.UsedTokens,
+			"budget":      assembly.Manifest.Budget,
+		},
+	})
+
+	return strings.TrimSpace(contexts.String()), loaded, nil
+}
+
+// finish salva o trace de auditoria e retorna o resultado
+func (f *TwoStepFlow) finish(result *TwoStepResult, runErr error) (*TwoStepResult, error) {
+	if f.auditDir != "" {
+		path, err := result.Trace.Save(f.auditDir)
+		if err != nil && runErr == nil {
+			return result, err
+		}
+		result.TracePath = path
+	}
+	return result, runErr
+}
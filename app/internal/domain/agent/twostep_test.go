@@ -0,0 +1,101 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PHRaulino/phengineer/internal/domain/assembler"
+)
+
+// TestTwoStepFlow testa a seleção de contextos e a chamada final com auditoria
+func TestTwoStepFlow(t *testing.T) {
+	contextDir := t.TempDir()
+	files := map[string]string{
+		"stack.json":        `{"metadata": {"description": "Stack do projeto"}, "languages": ["go"]}`,
+		"architecture.json": `{"pattern": "clean architecture"}`,
+		"functions.json":    `{"files": []}`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(contextDir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("Failed to write context: %v", err)
+		}
+	}
+
+	profile := assembler.ModelProfile{Name: "test", ContextTokens: 10000, ReservedOutput: 100, CharsPerToken: 4}
+	index, err := BuildContextIndex(contextDir, profile)
+	if err != nil {
+		t.Fatalf("BuildContextIndex failed: %v", err)
+	}
+	if err := index.WriteSummary(); err != nil {
+		t.Fatalf("WriteSummary failed: %v", err)
+	}
+
+	if entry, _ := index.Lookup("stack"); entry.Description != "Stack do projeto" {
+		t.Errorf("Expected metadata description, got %q", entry.Description)
+	}
+
+	var finalPrompt string
+	model := ModelFunc(func(ctx context.Context, req Request) (Response, error) {
+		if req.Schema != nil {
+			if !strings.Contains(req.Prompt, "`architecture`") {
+				t.Errorf("Selection prompt should list the index, got:\n%s", req.Prompt)
+			}
+			return Response{Content: "```json\n{\"context_ids\": [\"architecture\", \"unknown\", \"architecture\"], \"reason\": \"padrão arquitetural\"}\n```"}, nil
+		}
+		finalPrompt = req.Prompt
+		return Response{Content: "spec"}, nil
+	})
+
+	auditDir := t.TempDir()
+	result, err := NewTwoStepFlow(model, profile, auditDir).Run(context.Background(), TwoStepInput{
+		Agent:   "spec",
+		Request: "Adicionar nova camada de repositório",
+		Index:   index,
+		Final: func(contexts string) Request {
+			return Request{Prompt: "Contextos:\n" + contexts}
+		},
+	})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	if strings.Join(result.SelectedIDs, ",") != "architecture" {
+		t.Errorf("Expected only architecture to be selected, got %v", result.SelectedIDs)
+	}
+	if !strings.Contains(finalPrompt, "clean architecture") || strings.Contains(finalPrompt, "languages") {
+		t.Errorf("Final prompt should contain only selected contexts, got:\n%s", finalPrompt)
+	}
+	if result.Response.Content != "spec" {
+		t.Errorf("Expected final response 'spec', got %q", result.Response.Content)
+	}
+
+	data, err := os.ReadFile(result.TracePath)
+	if err != nil {
+		t.Fatalf("Expected trace to be saved: %v", err)
+	}
+	var trace Trace
+	if err := json.Unmarshal(data, &trace); err != nil {
+		t.Fatalf("Failed to parse trace: %v", err)
+	}
+
+	stages := make([]string, 0, len(trace.Stages))
+	for _, stage := range trace.Stages {
+		stages = append(stages, stage.Name)
+	}
+	if strings.Join(stages, ",") != "index,selection,load,final" {
+		t.Errorf("Unexpected stages %v", stages)
+	}
+
+	if len(trace.Prompts) != 2 || trace.Prompts[1].Name != "context-selection" {
+		t.Errorf("Expected selection prompt stamps in trace, got %v", trace.Prompts)
+	}
+
+	rejected, _ := trace.Stages[1].Details["rejected_ids"].([]any)
+	if len(rejected) != 1 || rejected[0] != "unknown" {
+		t.Errorf("Expected unknown id to be rejected, got %v", trace.Stages[1].Details["rejected_ids"])
+	}
+}
@@ -0,0 +1,149 @@
+package assembler
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Assembler monta o contexto dos prompts dentro do orçamento de tokens do modelo
+type Assembler struct {
+	profile ModelProfile
+}
+
+// NewAssembler cria um assembler para o perfil de modelo
+func NewAssembler(profile ModelProfile) *Assembler {
+	return &Assembler{profile: profile}
+}
+
+// Assemble ranqueia os fragmentos pela relevância à solicitação e os inclui
+// de forma gulosa até esgotar o orçamento. reserved são os tokens já ocupados
+// pelo texto fixo do prompt (instruções, solicitação do usuário).
+func (a *Assembler) Assemble(req Request, fragments []Fragment, reserved int) *Assembly {
+	budget := a.profile.Budget() - reserved
+	if budget < 0 {
+		budget = 0
+	}
+
+	requestKeywords := keywords(req.Text)
+	mentioned := make(map[string]bool)
+	for _, path := range req.Paths {
+		mentioned[filepath.ToSlash(filepath.Clean(path))] = true
+		for keyword := range keywords(path) {
+			requestKeywords[keyword] = true
+		}
+	}
+
+	entries := make([]ManifestEntry, len(fragments))
+	for i, fragment := range fragments {
+		entries[i] = ManifestEntry{
+			ID:     fragment.ID,
+			Kind:   fragment.Kind,
+			Path:   fragment.Path,
+			Tokens: a.profile.EstimateTokens(fragment.Content),
+			Score:  score(fragment, requestKeywords, mentioned),
+		}
+	}
+
+	// Obrigatórios primeiro, depois por score; empate pelo menor custo
+	order := make([]int, len(fragments))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(x, y int) bool {
+		fx, fy := fragments[order[x]], fragments[order[y]]
+		if fx.Required != fy.Required {
+			return fx.Required
+		}
+		ex, ey := entries[order[x]], entries[order[y]]
+		if ex.Score != ey.Score {
+			return ex.Score > ey.Score
+		}
+		return ex.Tokens < ey.Tokens
+	})
+
+	manifest := Manifest{
+		Profile:  a.profile,
+		Budget:   budget,
+		Reserved: reserved,
+		Included: make([]ManifestEntry, 0),
+		Dropped:  make([]ManifestEntry, 0),
+	}
+	included := make([]Fragment, 0)
+
+	for _, i := range order {
+		entry := entries[i]
+		switch {
+		case entry.Tokens == 0:
+			entry.Reason = "empty"
+		case manifest.UsedTokens+entry.Tokens > budget:
+			entry.Reason = fmt.Sprintf("over budget (%d tokens, %d left)", entry.Tokens, budget-manifest.UsedTokens)
+		default:
+			entry.Included = true
+			entry.Reason = "ranked"
+			if fragments[i].Required {
+				entry.Reason = "required"
+			}
+			manifest.UsedTokens += entry.Tokens
+			included = append(included, fragments[i])
+		}
+
+		if entry.Included {
+			manifest.Included = append(manifest.Included, entry)
+		} else {
+			manifest.Dropped = append(manifest.Dropped, entry)
+		}
+	}
+
+	return &Assembly{
+		Fragments: included,
+		Manifest:  manifest,
+	}
+}
+
+// Render monta o texto do contexto agrupado por tipo de fragmento
+func (a *Assembly) Render() string {
+	var doc strings.Builder
+
+	for _, kind := range kindOrder {
+		first := true
+		for _, fragment := range a.Fragments {
+			if fragment.Kind != kind {
+				continue
+			}
+			if first {
+				fmt.Fprintf(&doc, "## %s\n\n", kind)
+				first = false
+			}
+			if title := fragment.Title; title != "" {
+				fmt.Fprintf(&doc, "### %s\n\n", title)
+			} else if fragment.Path != "" {
+				fmt.Fprintf(&doc, "### %s\n\n", fragment.Path)
+			}
+			doc.WriteString(strings.TrimSpace(fragment.Content))
+			doc.WriteString("\n\n")
+		}
+	}
+
+	return strings.TrimSpace(doc.String())
+}
+
+// WriteManifest salva o manifest da montagem para depuração
+func (a *Assembly) WriteManifest(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create manifest directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(a.Manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package assembler
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestEstimateTokens testa a aproximação do tokenizer
+func TestEstimateTokens(t *testing.T) {
+	tests := []struct {
+		name     string
+		profile  ModelProfile
+		text     string
+		expected int
+	}{
+		{
+			name:     "Empty text",
+			profile:  ModelProfile{CharsPerToken: 4},
+			text:     "",
+			expected: 0,
+		},
+		{
+			name:     "Rounds up",
+			profile:  ModelProfile{CharsPerToken: 4},
+			text:     "12345",
+			expected: 2,
+		},
+		{
+			name:     "Counts runes not bytes",
+			profile:  ModelProfile{CharsPerToken: 2},
+			text:     "ação",
+			expected: 2,
+		},
+		{
+			name:     "Zero chars per token falls back to 4",
+			profile:  ModelProfile{},
+			text:     "12345678",
+			expected: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tt.profile.EstimateTokens(tt.text)
+			if result != tt.expected {
+				t.Errorf("EstimateTokens(%q) = %d, expected %d", tt.text, result, tt.expected)
+			}
+		})
+	}
+}
+
+// TestKeywords testa a extração de keywords com camelCase e snake_case
+func TestKeywords(t *testing.T) {
+	result := keywords("GetTokenData parse_file_size HTTPClient para o")
+
+	expected := []string{"get", "token", "data", "gettokendata", "parse", "file", "size", "http", "client", "httpclient"}
+	for _, keyword := range expected {
+		if !result[keyword] {
+			t.Errorf("Expected keyword '%s' in %v", keyword, result)
+		}
+	}
+
+	for _, ignored := range []string{"para", "o"} {
+		if result[ignored] {
+			t.Errorf("Keyword '%s' should be ignored", ignored)
+		}
+	}
+}
+
+// TestAssemble testa o ranking e o empacotamento guloso no orçamento
+func TestAssemble(t *testing.T) {
+	profile := ModelProfile{Name: "test", ContextTokens: 60, ReservedOutput: 10, CharsPerToken: 1}
+
+	fragments := []Fragment{
+		{ID: "file-tree", Kind: KindFileTree, Content: strings.Repeat("t", 10), Required: true},
+		{ID: "token", Kind: KindFileSnippet, Path: "internal/auth/token/service.go", Content: "token service refresh " + strings.Repeat("x", 8)},
+		{ID: "logger", Kind: KindFileSnippet, Path: "internal/utils/logger/logger.go", Content: strings.Repeat("l", 20)},
+		{ID: "huge", Kind: KindFileSnippet, Path: "internal/auth/token/huge.go", Content: "token " + strings.Repeat("h", 100)},
+	}
+
+	req := Request{
+		Text:  "Adicionar refresh ao token service",
+		Paths: []string{"internal/auth/token/service.go"},
+	}
+
+	assembly := NewAssembler(profile).Assemble(req, fragments, 5)
+
+	if assembly.Manifest.Budget != 45 {
+		t.Errorf("Expected budget 45, got %d", assembly.Manifest.Budget)
+	}
+
+	included := make([]string, 0)
+	for _, entry := range assembly.Manifest.Included {
+		included = append(included, entry.ID)
+	}
+	if strings.Join(included, ",") != "file-tree,token" {
+		t.Errorf("Expected file-tree,token to be included in order, got %v", included)
+	}
+
+	dropped := make(map[string]string)
+	for _, entry := range assembly.Manifest.Dropped {
+		dropped[entry.ID] = entry.Reason
+	}
+	if !strings.HasPrefix(dropped["huge"], "over budget") {
+		t.Errorf("Expected huge to be dropped over budget, got %q", dropped["huge"])
+	}
+	if _, ok := dropped["logger"]; !ok {
+		t.Error("Expected logger to be dropped after budget was used")
+	}
+
+	if assembly.Manifest.UsedTokens > assembly.Manifest.Budget {
+		t.Errorf("Used tokens %d exceed budget %d", assembly.Manifest.UsedTokens, assembly.Manifest.Budget)
+	}
+
+	rendered := assembly.Render()
+	if !strings.Contains(rendered, "## file-tree") || !strings.Contains(rendered, "### internal/auth/token/service.go") {
+		t.Errorf("Unexpected rendered context:\n%s", rendered)
+	}
+}
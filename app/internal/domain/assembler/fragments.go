@@ -0,0 +1,142 @@
+package assembler
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/PHRaulino/phengineer/internal/domain/discovery"
+)
+
+// Prioridade dos arquivos descobertos por tipo de pattern do .analyzefiles
+var patternPriority = map[discovery.PatternType]float64{
+	discovery.PatternTypeSnippet: 1.0,
+	discovery.PatternTypeCustom:  0.6,
+}
+
+// functionsContext representa o functions.json gerado na análise
+type functionsContext struct {
+	Files []struct {
+		FilePath  string `json:"file_path"`
+		Functions []struct {
+			Name      string `json:"name"`
+			Signature string `json:"signature"`
+			Purpose   string `json:"purpose"`
+		} `json:"functions"`
+	} `json:"files"`
+}
+
+// FromDiscovery cria fragmentos de árvore de arquivos e snippets a partir da descoberta
+func FromDiscovery(rootPath string, files []discovery.File) []Fragment {
+	fragments := make([]Fragment, 0, len(files)+1)
+
+	paths := make([]string, 0, len(files))
+	for _, file := range files {
+		paths = append(paths, filepath.ToSlash(filepath.Join(file.Path, file.Name)))
+	}
+	sort.Strings(paths)
+
+	if len(paths) > 0 {
+		fragments = append(fragments, Fragment{
+			ID:       "file-tree",
+			Kind:     KindFileTree,
+			Title:    "Estrutura de arquivos",
+			Content:  strings.Join(paths, "\n"),
+			Priority: 1.0,
+			Required: true,
+		})
+	}
+
+	for _, file := range files {
+		relativePath := filepath.ToSlash(filepath.Join(file.Path, file.Name))
+		content, err := os.ReadFile(filepath.Join(rootPath, relativePath))
+		if err != nil {
+			continue
+		}
+
+		fragments = append(fragments, Fragment{
+			ID:       "snippet:" + relativePath,
+			Kind:     KindFileSnippet,
+			Path:     relativePath,
+			Content:  fmt.Sprintf("```%s\n%s\n```", file.Type, strings.TrimSpace(string(content))),
+			Priority: patternPriority[file.PatternType],
+		})
+	}
+
+	return fragments
+}
+
+// FromContextDir cria fragmentos a partir dos contextos gerados (stack.json, architecture.json, functions.json)
+func FromContextDir(contextDir string) ([]Fragment, error) {
+	fragments := make([]Fragment, 0)
+
+	wholeFiles := []struct {
+		name  string
+		kind  FragmentKind
+		title string
+	}{
+		{"stack.json", KindStack, "Stack"},
+		{"architecture.json", KindArchitecture, "Arquitetura"},
+	}
+
+	for _, wf := range wholeFiles {
+		data, err := os.ReadFile(filepath.Join(contextDir, wf.name))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", wf.name, err)
+		}
+
+		fragments = append(fragments, Fragment{
+			ID:       string(wf.kind),
+			Kind:     wf.kind,
+			Title:    wf.title,
+			Content:  string(data),
+			Priority: 1.0,
+		})
+	}
+
+	data, err := os.ReadFile(filepath.Join(contextDir, "functions.json"))
+	if os.IsNotExist(err) {
+		return fragments, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read functions.json: %w", err)
+	}
+
+	var functions functionsContext
+	if err := json.Unmarshal(data, &functions); err != nil {
+		return nil, fmt.Errorf("failed to parse functions.json: %w", err)
+	}
+
+	for _, file := range functions.Files {
+		var content strings.Builder
+		for _, fn := range file.Functions {
+			fmt.Fprintf(&content, "- `%s`: %s\n", firstNonEmpty(fn.Signature, fn.Name), fn.Purpose)
+		}
+
+		fragments = append(fragments, Fragment{
+			ID:       "functions:" + file.FilePath,
+			Kind:     KindFunctionSummary,
+			Path:     file.FilePath,
+			Title:    "Funções de " + file.FilePath,
+			Content:  content.String(),
+			Priority: 0.8,
+		})
+	}
+
+	return fragments, nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,118 @@
+package assembler
+
+import (
+	"math"
+	"unicode/utf8"
+)
+
+// FragmentKind define o tipo de fragmento de contexto
+type FragmentKind string
+
+const (
+	KindFileTree        FragmentKind = "file-tree"
+	KindStack           FragmentKind = "stack"
+	KindArchitecture    FragmentKind = "architecture"
+	KindFunctionSummary FragmentKind = "function-summary"
+	KindFileSnippet     FragmentKind = "file-snippet"
+)
+
+// kindWeights é o peso base de cada tipo de fragmento no ranking
+var kindWeights = map[FragmentKind]float64{
+	KindFileTree:        0.6,
+	KindStack:           0.5,
+	KindArchitecture:    0.5,
+	KindFunctionSummary: 0.3,
+	KindFileSnippet:     0.2,
+}
+
+// kindOrder define a ordem das seções no contexto montado
+var kindOrder = []FragmentKind{
+	KindStack,
+	KindArchitecture,
+	KindFileTree,
+	KindFunctionSummary,
+	KindFileSnippet,
+}
+
+// ModelProfile descreve a janela de contexto de um modelo
+type ModelProfile struct {
+	Name           string  `json:"name"`
+	ContextTokens  int     `json:"context_tokens"`
+	ReservedOutput int     `json:"reserved_output"` // Tokens reservados para a resposta
+	CharsPerToken  float64 `json:"chars_per_token"` // Aproximação do tokenizer
+}
+
+// Profiles perfis conhecidos de modelos
+var Profiles = map[string]ModelProfile{
+	"default":           {Name: "default", ContextTokens: 32000, ReservedOutput: 4000, CharsPerToken: 3.5},
+	"gpt-4o":            {Name: "gpt-4o", ContextTokens: 128000, ReservedOutput: 16000, CharsPerToken: 4.0},
+	"gpt-4o-mini":       {Name: "gpt-4o-mini", ContextTokens: 128000, ReservedOutput: 16000, CharsPerToken: 4.0},
+	"claude-3-5-sonnet": {Name: "claude-3-5-sonnet", ContextTokens: 200000, ReservedOutput: 8000, CharsPerToken: 3.5},
+	"llama-3-8b":        {Name: "llama-3-8b", ContextTokens: 8192, ReservedOutput: 1024, CharsPerToken: 3.2},
+}
+
+// GetProfile retorna o perfil pelo nome ou o perfil padrão
+func GetProfile(name string) ModelProfile {
+	if profile, exists := Profiles[name]; exists {
+		return profile
+	}
+	return Profiles["default"]
+}
+
+// EstimateTokens aproxima a quantidade de tokens de um texto
+func (p ModelProfile) EstimateTokens(text string) int {
+	charsPerToken := p.CharsPerToken
+	if charsPerToken <= 0 {
+		charsPerToken = 4.0
+	}
+	return int(math.Ceil(float64(utf8.RuneCountInString(text)) / charsPerToken))
+}
+
+// Budget retorna os tokens disponíveis para o prompt
+func (p ModelProfile) Budget() int {
+	return p.ContextTokens - p.ReservedOutput
+}
+
+// Fragment representa um pedaço de contexto candidato ao prompt
+type Fragment struct {
+	ID       string       `json:"id"`
+	Kind     FragmentKind `json:"kind"`
+	Path     string       `json:"path,omitempty"`
+	Title    string       `json:"title,omitempty"`
+	Content  string       `json:"-"`
+	Priority float64      `json:"priority"` // Prioridade da descoberta, de 0 a 1
+	Required bool         `json:"required"` // Sempre incluído se couber
+}
+
+// Request representa a solicitação usada para medir relevância
+type Request struct {
+	Text  string   `json:"text"`
+	Paths []string `json:"paths,omitempty"` // Caminhos citados (ex: files_changes e relevant_files)
+}
+
+// ManifestEntry registra a decisão sobre um fragmento
+type ManifestEntry struct {
+	ID       string       `json:"id"`
+	Kind     FragmentKind `json:"kind"`
+	Path     string       `json:"path,omitempty"`
+	Tokens   int          `json:"tokens"`
+	Score    float64      `json:"score"`
+	Included bool         `json:"included"`
+	Reason   string       `json:"reason"`
+}
+
+// Manifest descreve o que foi incluído ou descartado na montagem
+type Manifest struct {
+	Profile    ModelProfile    `json:"profile"`
+	Budget     int             `json:"budget"`
+	Reserved   int             `json:"reserved"` // Tokens do prompt fixo
+	UsedTokens int             `json:"used_tokens"`
+	Included   []ManifestEntry `json:"included"`
+	Dropped    []ManifestEntry `json:"dropped"`
+}
+
+// Assembly representa o contexto montado
+type Assembly struct {
+	Fragments []Fragment `json:"fragments"`
+	Manifest  Manifest   `json:"manifest"`
+}
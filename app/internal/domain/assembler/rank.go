@@ -0,0 +1,101 @@
+package assembler
+
+import (
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// Pesos de cada sinal no score de relevância
+const (
+	keywordWeight  = 1.0
+	pathWeight     = 1.5
+	priorityWeight = 0.5
+	mentionBoost   = 3.0 // Caminho citado explicitamente na solicitação
+)
+
+// stopwords palavras ignoradas na extração de keywords (pt e en)
+var stopwords = map[string]bool{
+	"the": true, "and": true, "for": true, "with": true, "that": true, "this": true, "from": true,
+	"para": true, "com": true, "que": true, "uma": true, "dos": true, "das": true, "por": true,
+	"como": true, "ser": true, "nos": true, "nas": true, "não": true, "mais": true, "func": true,
+	"return": true, "package": true, "import": true, "string": true, "error": true, "nil": true,
+}
+
+// score calcula a relevância de um fragmento para a solicitação
+func score(fragment Fragment, requestKeywords map[string]bool, mentioned map[string]bool) float64 {
+	result := kindWeights[fragment.Kind] + priorityWeight*fragment.Priority
+
+	if fragment.Path != "" {
+		normalized := filepath.ToSlash(filepath.Clean(fragment.Path))
+		if mentioned[normalized] {
+			result += mentionBoost
+		}
+		result += pathWeight * overlap(keywords(normalized), requestKeywords)
+	}
+
+	if len(requestKeywords) > 0 {
+		result += keywordWeight * overlap(keywords(fragment.Title+" "+fragment.Content), requestKeywords)
+	}
+
+	return result
+}
+
+// overlap retorna a fração de keywords da solicitação presentes no fragmento
+func overlap(fragmentKeywords, requestKeywords map[string]bool) float64 {
+	if len(requestKeywords) == 0 {
+		return 0
+	}
+
+	hits := 0
+	for keyword := range requestKeywords {
+		if fragmentKeywords[keyword] {
+			hits++
+		}
+	}
+	return float64(hits) / float64(len(requestKeywords))
+}
+
+// keywords extrai termos normalizados de um texto, quebrando camelCase e snake_case
+func keywords(text string) map[string]bool {
+	result := make(map[string]bool)
+
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	for _, word := range words {
+		for _, part := range splitCamelCase(word) {
+			part = strings.ToLower(part)
+			if len([]rune(part)) < 3 || stopwords[part] {
+				continue
+			}
+			result[part] = true
+		}
+	}
+
+	return result
+}
+
+// splitCamelCase quebra "GetTokenData" em ["Get", "Token", "Data"]
+func splitCamelCase(word string) []string {
+	runes := []rune(word)
+	parts := make([]string, 0)
+	start := 0
+
+	for i := 1; i < len(runes); i++ {
+		lowerToUpper := unicode.IsLower(runes[i-1]) && unicode.IsUpper(runes[i])
+		acronymEnd := i+1 < len(runes) && unicode.IsUpper(runes[i-1]) && unicode.IsUpper(runes[i]) && unicode.IsLower(runes[i+1])
+		if lowerToUpper || acronymEnd {
+			parts = append(parts, string(runes[start:i]))
+			start = i
+		}
+	}
+
+	parts = append(parts, string(runes[start:]))
+	if len(parts) > 1 {
+		// Mantém também a palavra inteira para casar com termos compostos
+		parts = append(parts, word)
+	}
+	return parts
+}
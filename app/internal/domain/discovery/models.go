@@ -15,8 +15,9 @@ type File struct {
 	Size        int64       `json:"size"`
 	Type        string      `json:"type"`
 	PatternType PatternType `json:"pattern_type"`
-	CommitHash  string      `json:"commit_hash"` // Hash do último commit que alterou o arquivo
-	ModTime     int64       `json:"mod_time"`    // Timestamp de modificação
+	CommitHash  string      `json:"commit_hash"`      // Hash do último commit que alterou o arquivo
+	ModTime     int64       `json:"mod_time"`         // Timestamp de modificação
+	Digest      string      `json:"digest,omitempty"` // SHA-256 do conteúdo, quando não há commit do arquivo
 }
 type DicoveredFiles struct {
 	Snippets []File
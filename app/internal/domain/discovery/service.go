@@ -3,10 +3,12 @@ package discovery
 import (
 	"bufio"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
-	"os/exec"
 	"path"
 	"path/filepath"
 	"strconv"
@@ -14,6 +16,7 @@ import (
 	"time"
 
 	"github.com/PHRaulino/phengineer/internal/infrastructure/config"
+	"github.com/PHRaulino/phengineer/internal/infrastructure/gitrepo"
 )
 
 // Service descoberta de arquivos
@@ -47,9 +50,17 @@ func (s *Service) DiscoverFiles(ctx context.Context) (*DiscoveryResult, error) {
 		return nil, fmt.Errorf("failed to discover files: %w", err)
 	}
 
+	// Fora do git (ou sem commits) as mudanças são detectadas pelo conteúdo dos arquivos
+	repo, _ := gitrepo.FromContext(ctx)
+	if err := s.setFileVersions(result, repo, cfg.Auto.RootAppPath); err != nil {
+		return nil, fmt.Errorf("failed to read file history: %w", err)
+	}
+
 	result.MaxSizeBytes = maxSizeBytes
 	result.Timestamp = time.Now().Unix()
-	result.GitCommit = s.getCurrentGitCommit(cfg.Auto.RootAppPath)
+	if repo != nil {
+		result.GitCommit, _ = repo.Head()
+	}
 
 	return result, nil
 }
@@ -255,9 +266,6 @@ func (s *Service) walkAndFilter(rootPath string, patterns []TypedPattern, maxSiz
 
 		result.TotalFiltered++
 
-		// Pega commit hash do arquivo
-		commitHash := s.getFileCommitHash(rootPath, relativePath)
-
 		// Cria objeto File
 		file := File{
 			Name:        info.Name(),
@@ -265,7 +273,6 @@ func (s *Service) walkAndFilter(rootPath string, patterns []TypedPattern, maxSiz
 			Size:        info.Size(),
 			Type:        s.getFileType(info.Name()),
 			PatternType: patternType,
-			CommitHash:  commitHash,
 			ModTime:     info.ModTime().Unix(),
 		}
 
@@ -319,26 +326,53 @@ func (s *Service) parseFileSize(sizeStr string) (int64, error) {
 	return int64(num * float64(multiplier)), nil
 }
 
-// getCurrentGitCommit pega o commit atual do repositório
-func (s *Service) getCurrentGitCommit(rootPath string) string {
-	cmd := exec.Command("git", "rev-parse", "HEAD")
-	cmd.Dir = rootPath
-	output, err := cmd.Output()
-	if err != nil {
-		return ""
+// setFileVersions preenche o último commit de cada arquivo com uma única leitura do histórico.
+// Arquivos sem commit (fora do git ou ainda não versionados) recebem o digest do conteúdo.
+func (s *Service) setFileVersions(result *DiscoveryResult, repo gitrepo.Repository, rootPath string) error {
+	commits := make(map[string]string)
+	if repo != nil {
+		paths := make([]string, 0, len(result.Files)+len(result.OversizedFiles))
+		for _, files := range [][]File{result.Files, result.OversizedFiles} {
+			for _, file := range files {
+				paths = append(paths, filepath.Join(file.Path, file.Name))
+			}
+		}
+
+		var err error
+		if commits, err = repo.LastCommits(paths...); err != nil {
+			return err
+		}
 	}
-	return strings.TrimSpace(string(output))
+
+	for i := range result.Files {
+		file := &result.Files[i]
+		relativePath := filepath.Join(file.Path, file.Name)
+		file.CommitHash = commits[relativePath]
+		if file.CommitHash == "" {
+			file.Digest = s.getFileDigest(filepath.Join(rootPath, relativePath))
+		}
+	}
+	// Arquivos grandes não são lidos
+	for i := range result.OversizedFiles {
+		file := &result.OversizedFiles[i]
+		file.CommitHash = commits[filepath.Join(file.Path, file.Name)]
+	}
+	return nil
 }
 
-// getFileCommitHash pega o hash do último commit que alterou o arquivo
-func (s *Service) getFileCommitHash(rootPath, relativePath string) string {
-	cmd := exec.Command("git", "log", "-1", "--format=%H", "--", relativePath)
-	cmd.Dir = rootPath
-	output, err := cmd.Output()
+// getFileDigest calcula o SHA-256 do conteúdo do arquivo
+func (s *Service) getFileDigest(filePath string) string {
+	file, err := os.Open(filePath)
 	if err != nil {
 		return ""
 	}
-	return strings.TrimSpace(string(output))
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(hash.Sum(nil))
 }
 
 // loadDiscoveryLock carrega o arquivo de lock
@@ -427,7 +461,7 @@ func (s *Service) compareWithLock(current, previous *DiscoveryResult) *ChangedFi
 
 		if prevFile, exists := previousMap[key]; exists {
 			// Arquivo existe nos dois - verifica se mudou
-			if prevFile.CommitHash != file.CommitHash || prevFile.ModTime != file.ModTime {
+			if fileChanged(prevFile, file) {
 				result.ChangedFiles = append(result.ChangedFiles, file)
 				result.HasChanges = true
 			} else {
@@ -452,6 +486,19 @@ func (s *Service) compareWithLock(current, previous *DiscoveryResult) *ChangedFi
 	return result
 }
 
+// fileChanged compara pelo commit quando os dois lados têm histórico no git, senão pelo
+// digest do conteúdo e, na falta dele, pela data de modificação e tamanho
+func fileChanged(previous, current File) bool {
+	switch {
+	case previous.CommitHash != "" && current.CommitHash != "":
+		return previous.CommitHash != current.CommitHash || previous.ModTime != current.ModTime
+	case previous.Digest != "" && current.Digest != "":
+		return previous.Digest != current.Digest
+	default:
+		return previous.CommitHash != current.CommitHash || previous.ModTime != current.ModTime || previous.Size != current.Size
+	}
+}
+
 func (s *Service) getFileType(filename string) string {
 	ext := strings.ToLower(filepath.Ext(filename))
 
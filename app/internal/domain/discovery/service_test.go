@@ -0,0 +1,137 @@
+package discovery
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/PHRaulino/phengineer/internal/infrastructure/gitrepo"
+)
+
+// TestSetFileVersions testa o commit dos arquivos versionados e o digest dos demais
+func TestSetFileVersions(t *testing.T) {
+	tempDir := t.TempDir()
+	files := map[string]string{
+		"main.go":        "package main\n",
+		"pkg/util.go":    "package pkg\n",
+		"pkg/scratch.go": "package main\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(tempDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+
+	// sha256("package main\n")
+	const mainDigest = "df1d036cbbf3df46e2045071e082245ece204c7f53ecf0a4e022bff9bb228f47"
+	tests := []struct {
+		name       string
+		repo       gitrepo.Repository
+		wantCommit map[string]string
+		wantDigest map[string]string
+	}{
+		{
+			name:       "Outside Git",
+			wantCommit: map[string]string{},
+			wantDigest: map[string]string{"main.go": mainDigest, "pkg/scratch.go": mainDigest},
+		},
+		{
+			name: "Committed and untracked files",
+			repo: gitrepo.NewMemoryRepository(tempDir).
+				WithHead("c2").
+				WithFileCommit("main.go", "c1").
+				WithFileCommit("pkg/util.go", "c2"),
+			wantCommit: map[string]string{"main.go": "c1", "pkg/util.go": "c2"},
+			wantDigest: map[string]string{"pkg/scratch.go": mainDigest},
+		},
+	}
+
+	service := NewService()
+	patterns := []TypedPattern{{Pattern: "*.go", Type: PatternTypeSnippet}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := service.walkAndFilter(tempDir, patterns, 1<<20, 10)
+			if err != nil {
+				t.Fatalf("walkAndFilter() error = %v", err)
+			}
+			if err := service.setFileVersions(result, tt.repo, tempDir); err != nil {
+				t.Fatalf("setFileVersions() error = %v", err)
+			}
+			if len(result.Files) != len(files) {
+				t.Fatalf("Expected %d files, got %d", len(files), len(result.Files))
+			}
+
+			for _, file := range result.Files {
+				path := filepath.ToSlash(filepath.Join(file.Path, file.Name))
+				if file.CommitHash != tt.wantCommit[path] {
+					t.Errorf("%s: CommitHash = %q, want %q", path, file.CommitHash, tt.wantCommit[path])
+				}
+				if want, ok := tt.wantDigest[path]; ok && file.Digest != want {
+					t.Errorf("%s: Digest = %q, want %q", path, file.Digest, want)
+				}
+				if file.CommitHash != "" && file.Digest != "" {
+					t.Errorf("%s: committed file should not have a digest", path)
+				}
+			}
+		})
+	}
+}
+
+// TestFileChanged testa a detecção de mudanças por commit, digest e data de modificação
+func TestFileChanged(t *testing.T) {
+	tests := []struct {
+		name     string
+		previous File
+		current  File
+		want     bool
+	}{
+		{
+			name:     "Same commit and mod time",
+			previous: File{CommitHash: "abc", ModTime: 1},
+			current:  File{CommitHash: "abc", ModTime: 1},
+			want:     false,
+		},
+		{
+			name:     "New commit",
+			previous: File{CommitHash: "abc", ModTime: 1},
+			current:  File{CommitHash: "def", ModTime: 1},
+			want:     true,
+		},
+		{
+			name:     "Touched without content change",
+			previous: File{Digest: "d1", ModTime: 1},
+			current:  File{Digest: "d1", ModTime: 2},
+			want:     false,
+		},
+		{
+			name:     "Content changed",
+			previous: File{Digest: "d1", ModTime: 1},
+			current:  File{Digest: "d2", ModTime: 1},
+			want:     true,
+		},
+		{
+			name:     "Lock without digest falls back to size",
+			previous: File{ModTime: 1, Size: 10},
+			current:  File{Digest: "d1", ModTime: 1, Size: 12},
+			want:     true,
+		},
+		{
+			name:     "File committed since the last discovery",
+			previous: File{Digest: "d1", ModTime: 1},
+			current:  File{CommitHash: "abc", ModTime: 1},
+			want:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fileChanged(tt.previous, tt.current); got != tt.want {
+				t.Errorf("fileChanged() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package knowledge
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/PHRaulino/phengineer/internal/infrastructure/config"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+var unsafeNameChars = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+// cachePath retorna o diretório de cache de uma fonte git.
+// A chave inclui URL e ref, então trocar o ref gera um novo checkout.
+func cachePath(cacheDir string, source config.KnowledgeSource) string {
+	sum := sha256.Sum256([]byte(source.Git + "@" + source.Ref))
+	name := unsafeNameChars.ReplaceAllString(source.Name, "-")
+	return filepath.Join(cacheDir, "knowledge", name+"-"+hex.EncodeToString(sum[:])[:12])
+}
+
+// ensureGitSource garante o checkout da fonte no cache.
+// Se o cache já existe ele é usado sem acesso à rede, a menos que refresh seja true.
+func ensureGitSource(ctx context.Context, cacheDir string, source config.KnowledgeSource, refresh bool) (string, error) {
+	path := cachePath(cacheDir, source)
+
+	if _, err := os.Stat(filepath.Join(path, ".git")); err == nil {
+		if !refresh {
+			return path, nil
+		}
+		if err := updateGitSource(ctx, path, source); err != nil {
+			return "", err
+		}
+		return path, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", fmt.Errorf("failed to create knowledge cache: %w", err)
+	}
+
+	repo, err := git.PlainCloneContext(ctx, path, false, &git.CloneOptions{URL: source.Git})
+	if err != nil {
+		os.RemoveAll(path)
+		return "", fmt.Errorf("failed to clone knowledge source '%s': %w", source.Name, err)
+	}
+
+	if err := checkoutRef(repo, source.Ref); err != nil {
+		os.RemoveAll(path)
+		return "", fmt.Errorf("knowledge source '%s': %w", source.Name, err)
+	}
+
+	return path, nil
+}
+
+// updateGitSource busca atualizações e refaz o checkout do ref
+func updateGitSource(ctx context.Context, path string, source config.KnowledgeSource) error {
+	repo, err := git.PlainOpen(path)
+	if err != nil {
+		return fmt.Errorf("failed to open knowledge cache for '%s': %w", source.Name, err)
+	}
+
+	err = repo.FetchContext(ctx, &git.FetchOptions{Tags: git.AllTags, Force: true})
+	if err != nil && err != git.NoErrAlreadyUpToDate {
+		return fmt.Errorf("failed to fetch knowledge source '%s': %w", source.Name, err)
+	}
+
+	if err := checkoutRef(repo, source.Ref); err != nil {
+		return fmt.Errorf("knowledge source '%s': %w", source.Name, err)
+	}
+	return nil
+}
+
+// checkoutRef faz checkout de uma tag, branch ou commit
+func checkoutRef(repo *git.Repository, ref string) error {
+	if ref == "" {
+		return nil
+	}
+
+	candidates := []string{ref, "refs/tags/" + ref, "refs/remotes/origin/" + ref}
+	var hash *plumbing.Hash
+	for _, candidate := range candidates {
+		if h, err := repo.ResolveRevision(plumbing.Revision(candidate)); err == nil {
+			hash = h
+			break
+		}
+	}
+	if hash == nil {
+		return fmt.Errorf("ref '%s' not found", ref)
+	}
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return fmt.Errorf("failed to open worktree: %w", err)
+	}
+
+	if err := worktree.Checkout(&git.CheckoutOptions{Hash: *hash, Force: true}); err != nil {
+		return fmt.Errorf("failed to checkout ref '%s': %w", ref, err)
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package knowledge
+
+// ManifestFileName é o arquivo opcional com metadados dos documentos de uma fonte
+const ManifestFileName = "knowledge.yml"
+
+// Document representa um documento de conhecimento (conventions.md, security-policies.md, ...)
+type Document struct {
+	Source          string   `json:"source"`
+	Path            string   `json:"path"` // Caminho relativo à raiz da fonte
+	Title           string   `json:"title"`
+	Tags            []string `json:"tags,omitempty"`
+	ProjectTypes    []string `json:"project_types,omitempty"`    // Vazio = todos os tipos
+	GenerationTypes []string `json:"generation_types,omitempty"` // Vazio = todas as gerações
+	Content         string   `json:"-"`
+}
+
+// Manifest representa o arquivo knowledge.yml de uma fonte
+type Manifest struct {
+	Documents []ManifestEntry `yaml:"documents"`
+}
+
+// ManifestEntry representa os metadados de um documento no manifest
+type ManifestEntry struct {
+	Path            string   `yaml:"path"`
+	Title           string   `yaml:"title,omitempty"`
+	Tags            []string `yaml:"tags,omitempty"`
+	ProjectTypes    []string `yaml:"project_types,omitempty"`
+	GenerationTypes []string `yaml:"generation_types,omitempty"`
+	Exclude         bool     `yaml:"exclude,omitempty"`
+}
@@ -0,0 +1,22 @@
+package knowledge
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FormatSection formata os documentos como seção de prompt dos agentes
+func FormatSection(documents []Document) string {
+	if len(documents) == 0 {
+		return ""
+	}
+
+	var section strings.Builder
+	section.WriteString("**Padrões e políticas da empresa:**\n")
+	for _, doc := range documents {
+		fmt.Fprintf(&section, "\n### %s (%s/%s)\n\n", doc.Title, doc.Source, doc.Path)
+		section.WriteString(strings.TrimSpace(doc.Content))
+		section.WriteString("\n")
+	}
+	return section.String()
+}
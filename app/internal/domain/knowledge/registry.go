@@ -0,0 +1,275 @@
+package knowledge
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/PHRaulino/phengineer/internal/domain/spec"
+	"github.com/PHRaulino/phengineer/internal/infrastructure/config"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+)
+
+// Registry registro das fontes de conhecimento do usuário e do projeto
+type Registry struct {
+	sources  []config.KnowledgeSource
+	cacheDir string
+}
+
+// NewRegistry cria um registro com as fontes informadas.
+// Fontes posteriores com o mesmo nome substituem as anteriores.
+func NewRegistry(cacheDir string, layers ...[]config.KnowledgeSource) *Registry {
+	byName := make(map[string]int)
+	sources := make([]config.KnowledgeSource, 0)
+
+	for _, layer := range layers {
+		for _, source := range layer {
+			if i, exists := byName[source.Name]; exists {
+				sources[i] = source
+				continue
+			}
+			byName[source.Name] = len(sources)
+			sources = append(sources, source)
+		}
+	}
+
+	return &Registry{
+		sources:  sources,
+		cacheDir: cacheDir,
+	}
+}
+
+// FromContext cria o registro com as fontes do usuário e do settings.yml do projeto
+func FromContext(ctx context.Context) (*Registry, error) {
+	cfg := config.FromContext(ctx)
+
+	userKnowledge, err := config.LoadUserKnowledge()
+	if err != nil {
+		return nil, err
+	}
+
+	projectKnowledge := cfg.Settings.Knowledge
+	projectKnowledge.ResolvePaths(cfg.Auto.RootAppPath)
+
+	cacheDir, err := config.UserCacheDir()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewRegistry(cacheDir, userKnowledge.Sources, projectKnowledge.Sources), nil
+}
+
+// Sources retorna as fontes registradas
+func (r *Registry) Sources() []config.KnowledgeSource {
+	return r.sources
+}
+
+// Sync atualiza o cache de todas as fontes git
+func (r *Registry) Sync(ctx context.Context) error {
+	for _, source := range r.sources {
+		if source.Git == "" {
+			continue
+		}
+		if _, err := ensureGitSource(ctx, r.cacheDir, source, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Documents carrega os documentos de todas as fontes.
+// Fontes git usam o cache local quando disponível, permitindo uso offline.
+func (r *Registry) Documents(ctx context.Context) ([]Document, error) {
+	documents := make([]Document, 0)
+
+	for _, source := range r.sources {
+		root, err := r.sourceRoot(ctx, source)
+		if err != nil {
+			return nil, err
+		}
+
+		sourceDocs, err := loadSourceDocuments(root, source)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load knowledge source '%s': %w", source.Name, err)
+		}
+		documents = append(documents, sourceDocs...)
+	}
+
+	return documents, nil
+}
+
+// Relevant carrega os documentos aplicáveis ao tipo de projeto e de geração
+func (r *Registry) Relevant(ctx context.Context, projectType string, generationType spec.GenerationType) ([]Document, error) {
+	documents, err := r.Documents(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return Select(documents, projectType, generationType), nil
+}
+
+// Select filtra os documentos aplicáveis ao tipo de projeto e de geração
+func Select(documents []Document, projectType string, generationType spec.GenerationType) []Document {
+	selected := make([]Document, 0)
+	for _, doc := range documents {
+		if !matches(doc.ProjectTypes, projectType) {
+			continue
+		}
+		if !matches(doc.GenerationTypes, string(generationType)) {
+			continue
+		}
+		selected = append(selected, doc)
+	}
+	return selected
+}
+
+// sourceRoot resolve o diretório raiz de uma fonte
+func (r *Registry) sourceRoot(ctx context.Context, source config.KnowledgeSource) (string, error) {
+	root := source.Path
+	if source.Git != "" {
+		path, err := ensureGitSource(ctx, r.cacheDir, source, false)
+		if err != nil {
+			return "", err
+		}
+		root = path
+	}
+
+	if source.Subdir != "" {
+		root = filepath.Join(root, source.Subdir)
+	}
+
+	if _, err := os.Stat(root); err != nil {
+		return "", fmt.Errorf("knowledge source '%s' not found at %s", source.Name, root)
+	}
+	return root, nil
+}
+
+// loadSourceDocuments lê os documentos markdown de uma fonte e aplica os metadados
+func loadSourceDocuments(root string, source config.KnowledgeSource) ([]Document, error) {
+	manifest, err := loadManifest(root)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make(map[string]ManifestEntry)
+	for _, entry := range manifest.Documents {
+		entries[filepath.ToSlash(entry.Path)] = entry
+	}
+
+	documents := make([]Document, 0)
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil // Ignora erros e continua
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.EqualFold(filepath.Ext(path), ".md") {
+			return nil
+		}
+
+		relativePath, err := filepath.Rel(root, path)
+		if err != nil {
+			return nil
+		}
+		relativePath = filepath.ToSlash(relativePath)
+
+		entry := entries[relativePath]
+		if entry.Exclude {
+			return nil
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			zap.L().Warn("failed to read knowledge document", zap.String("path", path), zap.Error(err))
+			return nil
+		}
+
+		documents = append(documents, Document{
+			Source:          source.Name,
+			Path:            relativePath,
+			Title:           firstNonEmpty(entry.Title, strings.TrimSuffix(filepath.Base(relativePath), filepath.Ext(relativePath))),
+			Tags:            mergeLists(source.Tags, entry.Tags),
+			ProjectTypes:    firstNonEmptyList(entry.ProjectTypes, source.ProjectTypes),
+			GenerationTypes: firstNonEmptyList(entry.GenerationTypes, source.GenerationTypes),
+			Content:         string(content),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(documents, func(i, j int) bool {
+		return documents[i].Path < documents[j].Path
+	})
+	return documents, nil
+}
+
+// loadManifest carrega o knowledge.yml da fonte, se existir
+func loadManifest(root string) (*Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(root, ManifestFileName))
+	if os.IsNotExist(err) {
+		return &Manifest{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	var manifest Manifest
+	if err := yaml.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+	return &manifest, nil
+}
+
+// matches verifica se o valor é aceito pela lista (lista vazia ou "*" aceita todos).
+// Valor vazio significa que o filtro não foi informado.
+func matches(allowed []string, value string) bool {
+	if len(allowed) == 0 || value == "" {
+		return true
+	}
+	for _, item := range allowed {
+		if item == "*" || strings.EqualFold(item, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func mergeLists(a, b []string) []string {
+	seen := make(map[string]bool)
+	merged := make([]string, 0, len(a)+len(b))
+	for _, item := range append(append([]string{}, a...), b...) {
+		if !seen[item] {
+			seen[item] = true
+			merged = append(merged, item)
+		}
+	}
+	return merged
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+func firstNonEmptyList(lists ...[]string) []string {
+	for _, list := range lists {
+		if len(list) > 0 {
+			return list
+		}
+	}
+	return nil
+}
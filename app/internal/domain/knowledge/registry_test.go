@@ -0,0 +1,118 @@
+package knowledge
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/PHRaulino/phengineer/internal/domain/spec"
+	"github.com/PHRaulino/phengineer/internal/infrastructure/config"
+)
+
+// TestLoadSourceDocuments testa a leitura de documentos e metadados de uma fonte local
+func TestLoadSourceDocuments(t *testing.T) {
+	tempDir := t.TempDir()
+
+	files := map[string]string{
+		"conventions.md":       "# Conventions",
+		"security-policies.md": "# Security",
+		"frontend/styling.md":  "# Styling",
+		"drafts/wip.md":        "# WIP",
+		"notes.txt":            "ignored",
+		ManifestFileName: `documents:
+  - path: frontend/styling.md
+    tags: [frontend]
+    project_types: [frontend]
+  - path: security-policies.md
+    generation_types: [feature, fix]
+  - path: drafts/wip.md
+    exclude: true
+`,
+	}
+	for name, content := range files {
+		path := filepath.Join(tempDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	source := config.KnowledgeSource{Name: "company", Path: tempDir, Tags: []string{"global"}}
+	documents, err := loadSourceDocuments(tempDir, source)
+	if err != nil {
+		t.Fatalf("Failed to load documents: %v", err)
+	}
+
+	if len(documents) != 3 {
+		t.Fatalf("Expected 3 documents, got %d: %+v", len(documents), documents)
+	}
+
+	tests := []struct {
+		name           string
+		projectType    string
+		generationType spec.GenerationType
+		expected       []string
+	}{
+		{
+			name:           "Backend feature",
+			projectType:    "backend",
+			generationType: spec.GenerationFeature,
+			expected:       []string{"conventions.md", "security-policies.md"},
+		},
+		{
+			name:           "Backend doc",
+			projectType:    "backend",
+			generationType: spec.GenerationDoc,
+			expected:       []string{"conventions.md"},
+		},
+		{
+			name:           "Frontend fix",
+			projectType:    "frontend",
+			generationType: spec.GenerationFix,
+			expected:       []string{"conventions.md", "frontend/styling.md", "security-policies.md"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selected := Select(documents, tt.projectType, tt.generationType)
+			if len(selected) != len(tt.expected) {
+				t.Fatalf("Select() returned %d documents, expected %d", len(selected), len(tt.expected))
+			}
+			for i, doc := range selected {
+				if doc.Path != tt.expected[i] {
+					t.Errorf("Select()[%d] = %q, expected %q", i, doc.Path, tt.expected[i])
+				}
+			}
+		})
+	}
+
+	for _, doc := range documents {
+		if doc.Path == "frontend/styling.md" && len(doc.Tags) != 2 {
+			t.Errorf("Expected source and manifest tags to be merged, got %v", doc.Tags)
+		}
+	}
+}
+
+// TestNewRegistryOverrides testa a substituição de fontes do usuário pelas do projeto
+func TestNewRegistryOverrides(t *testing.T) {
+	user := []config.KnowledgeSource{
+		{Name: "company", Git: "https://example.com/knowledge.git", Ref: "v1"},
+		{Name: "personal", Path: "/tmp/personal"},
+	}
+	project := []config.KnowledgeSource{
+		{Name: "company", Git: "https://example.com/knowledge.git", Ref: "v2"},
+	}
+
+	registry := NewRegistry(t.TempDir(), user, project)
+	sources := registry.Sources()
+
+	if len(sources) != 2 {
+		t.Fatalf("Expected 2 sources, got %d", len(sources))
+	}
+	if sources[0].Ref != "v2" {
+		t.Errorf("Expected project source to override user source, got ref '%s'", sources[0].Ref)
+	}
+}
@@ -0,0 +1,60 @@
+package prompts
+
+import "fmt"
+
+// Source origem de um template de prompt
+type Source string
+
+const (
+	SourceEmbedded Source = "embedded" // Padrão embutido no binário
+	SourceUser     Source = "user"     // ~/.config/phengineer/prompts
+	SourceProject  Source = "project"  // .phengineer/prompts
+)
+
+// Template representa um template de prompt com front matter YAML
+type Template struct {
+	Name        string   `yaml:"name" json:"name"`
+	Version     string   `yaml:"version" json:"version"`
+	Description string   `yaml:"description" json:"description"`
+	Variables   []string `yaml:"variables" json:"variables"` // Variáveis obrigatórias
+	Optional    []string `yaml:"optional" json:"optional"`   // Variáveis opcionais
+	Source      Source   `yaml:"-" json:"source"`
+	Path        string   `yaml:"-" json:"path,omitempty"`
+	Body        string   `yaml:"-" json:"-"`
+}
+
+// Stamp identifica a versão exata do template usado em uma execução
+type Stamp struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Source  Source `json:"source"`
+	Hash    string `json:"hash"` // sha256 do corpo do template
+}
+
+// String formata o stamp como "name@version (source, hash)"
+func (s Stamp) String() string {
+	return fmt.Sprintf("%s@%s (%s, %s)", s.Name, s.Version, s.Source, s.Hash)
+}
+
+// Rendered representa um prompt renderizado
+type Rendered struct {
+	Stamp Stamp  `json:"stamp"`
+	Text  string `json:"text"`
+}
+
+// MergeStamps adiciona stamps à lista sem duplicar templates
+func MergeStamps(stamps []Stamp, added ...Stamp) []Stamp {
+	for _, stamp := range added {
+		exists := false
+		for _, current := range stamps {
+			if current == stamp {
+				exists = true
+				break
+			}
+		}
+		if !exists {
+			stamps = append(stamps, stamp)
+		}
+	}
+	return stamps
+}
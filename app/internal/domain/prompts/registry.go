@@ -0,0 +1,262 @@
+package prompts
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/PHRaulino/phengineer/internal/infrastructure/config"
+	"gopkg.in/yaml.v3"
+)
+
+// Extension extensão dos arquivos de template
+const Extension = ".md"
+
+// DirName nome do diretório de overrides no projeto e no usuário
+const DirName = "prompts"
+
+//go:embed templates/*.md
+var embedded embed.FS
+
+// layer diretório de overrides de uma origem
+type layer struct {
+	source Source
+	dir    string
+}
+
+// Registry resolve templates na ordem projeto > usuário > embutido
+type Registry struct {
+	layers []layer // Da menor para a maior precedência
+}
+
+// NewRegistry cria um registro com os diretórios de override do usuário e do projeto.
+// Diretórios vazios são ignorados.
+func NewRegistry(userDir, projectDir string) *Registry {
+	layers := make([]layer, 0, 2)
+	if userDir != "" {
+		layers = append(layers, layer{source: SourceUser, dir: userDir})
+	}
+	if projectDir != "" {
+		layers = append(layers, layer{source: SourceProject, dir: projectDir})
+	}
+	return &Registry{layers: layers}
+}
+
+// Embedded cria um registro apenas com os templates embutidos
+func Embedded() *Registry {
+	return NewRegistry("", "")
+}
+
+// FromContext cria o registro com os overrides do usuário e de .phengineer/prompts
+func FromContext(ctx context.Context) (*Registry, error) {
+	cfg := config.FromContext(ctx)
+
+	userDir, err := config.UserConfigDir()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewRegistry(filepath.Join(userDir, DirName), filepath.Join(cfg.ConfigPath, DirName)), nil
+}
+
+// Get resolve um template pelo nome aplicando os overrides
+func (r *Registry) Get(name string) (*Template, error) {
+	var resolved *Template
+
+	data, err := embedded.ReadFile("templates/" + name + Extension)
+	if err == nil {
+		resolved, err = parseTemplate(name, data)
+		if err != nil {
+			return nil, err
+		}
+		resolved.Source = SourceEmbedded
+	}
+
+	for _, l := range r.layers {
+		path := filepath.Join(l.dir, name+Extension)
+		data, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read prompt %s: %w", path, err)
+		}
+
+		override, err := parseTemplate(name, data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid prompt %s: %w", path, err)
+		}
+		override.Source = l.source
+		override.Path = path
+		override.inherit(resolved)
+		resolved = override
+	}
+
+	if resolved == nil {
+		return nil, fmt.Errorf("prompt '%s' not found", name)
+	}
+	return resolved, nil
+}
+
+// List retorna todos os templates conhecidos, já resolvidos
+func (r *Registry) List() ([]*Template, error) {
+	names := make(map[string]bool)
+
+	entries, err := fs.ReadDir(embedded, "templates")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read embedded prompts: %w", err)
+	}
+	for _, entry := range entries {
+		names[strings.TrimSuffix(entry.Name(), Extension)] = true
+	}
+
+	for _, l := range r.layers {
+		entries, err := os.ReadDir(l.dir)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read prompts directory %s: %w", l.dir, err)
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() && filepath.Ext(entry.Name()) == Extension {
+				names[strings.TrimSuffix(entry.Name(), Extension)] = true
+			}
+		}
+	}
+
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	templates := make([]*Template, 0, len(sorted))
+	for _, name := range sorted {
+		tmpl, err := r.Get(name)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, tmpl)
+	}
+	return templates, nil
+}
+
+// Render resolve e renderiza um template
+func (r *Registry) Render(name string, vars map[string]any) (*Rendered, error) {
+	tmpl, err := r.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	return tmpl.Render(vars)
+}
+
+// Stamp retorna a identificação da versão do template
+func (t *Template) Stamp() Stamp {
+	sum := sha256.Sum256([]byte(t.Body))
+	return Stamp{
+		Name:    t.Name,
+		Version: t.Version,
+		Source:  t.Source,
+		Hash:    hex.EncodeToString(sum[:])[:12],
+	}
+}
+
+// Render valida as variáveis obrigatórias e renderiza o template
+func (t *Template) Render(vars map[string]any) (*Rendered, error) {
+	if missing := t.Missing(vars); len(missing) > 0 {
+		return nil, fmt.Errorf("prompt '%s' missing required variables: %s", t.Name, strings.Join(missing, ", "))
+	}
+
+	data := make(map[string]any, len(vars)+len(t.Optional))
+	for _, name := range t.Optional {
+		data[name] = nil
+	}
+	for name, value := range vars {
+		data[name] = value
+	}
+
+	parsed, err := template.New(t.Name).Option("missingkey=error").Parse(t.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse prompt '%s': %w", t.Name, err)
+	}
+
+	var out bytes.Buffer
+	if err := parsed.Execute(&out, data); err != nil {
+		return nil, fmt.Errorf("failed to render prompt '%s': %w", t.Name, err)
+	}
+
+	return &Rendered{
+		Stamp: t.Stamp(),
+		Text:  strings.TrimSpace(out.String()),
+	}, nil
+}
+
+// Missing retorna as variáveis obrigatórias ausentes ou vazias
+func (t *Template) Missing(vars map[string]any) []string {
+	missing := make([]string, 0)
+	for _, name := range t.Variables {
+		value, ok := vars[name]
+		if !ok || value == nil {
+			missing = append(missing, name)
+			continue
+		}
+		if text, isString := value.(string); isString && strings.TrimSpace(text) == "" {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
+
+// inherit herda do template base os metadados não declarados no override
+func (t *Template) inherit(base *Template) {
+	if base == nil {
+		if t.Version == "" {
+			t.Version = "custom"
+		}
+		return
+	}
+
+	if t.Version == "" {
+		t.Version = base.Version + "+" + string(t.Source)
+	}
+	if t.Description == "" {
+		t.Description = base.Description
+	}
+	if t.Variables == nil {
+		t.Variables = base.Variables
+	}
+	if t.Optional == nil {
+		t.Optional = base.Optional
+	}
+}
+
+// parseTemplate separa o front matter YAML do corpo do template
+func parseTemplate(name string, data []byte) (*Template, error) {
+	content := strings.ReplaceAll(string(data), "\r\n", "\n")
+	tmpl := &Template{}
+
+	if rest, ok := strings.CutPrefix(content, "---\n"); ok {
+		header, body, found := strings.Cut(rest, "\n---\n")
+		if !found {
+			return nil, fmt.Errorf("unterminated front matter")
+		}
+		if err := yaml.Unmarshal([]byte(header), tmpl); err != nil {
+			return nil, fmt.Errorf("failed to parse front matter: %w", err)
+		}
+		content = body
+	}
+
+	tmpl.Name = name
+	tmpl.Body = content
+	return tmpl, nil
+}
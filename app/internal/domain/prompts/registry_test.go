@@ -0,0 +1,154 @@
+package prompts
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestEmbeddedTemplates testa se todos os templates embutidos são válidos
+func TestEmbeddedTemplates(t *testing.T) {
+	templates, err := Embedded().List()
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(templates) == 0 {
+		t.Fatal("Expected embedded templates")
+	}
+
+	for _, tmpl := range templates {
+		if tmpl.Version == "" {
+			t.Errorf("Template %s has no version", tmpl.Name)
+		}
+		if tmpl.Source != SourceEmbedded {
+			t.Errorf("Template %s source = %s, expected %s", tmpl.Name, tmpl.Source, SourceEmbedded)
+		}
+	}
+}
+
+// TestRegistryOverrides testa a precedência projeto > usuário > embutido
+func TestRegistryOverrides(t *testing.T) {
+	userDir := t.TempDir()
+	projectDir := t.TempDir()
+
+	writePrompt(t, userDir, "context-selection-system", "Instruções do usuário")
+	writePrompt(t, projectDir, "context-selection-system", "---\nversion: 2.0.0\n---\nInstruções do projeto")
+	writePrompt(t, userDir, "context-selection", "{{.user_request}} (usuário)")
+	writePrompt(t, projectDir, "team-review", "---\nvariables: [diff]\n---\nRevise: {{.diff}}")
+
+	registry := NewRegistry(userDir, projectDir)
+
+	tests := []struct {
+		name            string
+		template        string
+		expectedSource  Source
+		expectedVersion string
+	}{
+		{
+			name:            "Project overrides user and embedded",
+			template:        "context-selection-system",
+			expectedSource:  SourceProject,
+			expectedVersion: "2.0.0",
+		},
+		{
+			name:            "User override inherits embedded version",
+			template:        "context-selection",
+			expectedSource:  SourceUser,
+			expectedVersion: "1.0.0+user",
+		},
+		{
+			name:            "Embedded when no override",
+			template:        "requirements",
+			expectedSource:  SourceEmbedded,
+			expectedVersion: "1.0.0",
+		},
+		{
+			name:            "Project-only template",
+			template:        "team-review",
+			expectedSource:  SourceProject,
+			expectedVersion: "custom",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := registry.Get(tt.template)
+			if err != nil {
+				t.Fatalf("Get(%s) failed: %v", tt.template, err)
+			}
+			if tmpl.Source != tt.expectedSource {
+				t.Errorf("Source = %s, expected %s", tmpl.Source, tt.expectedSource)
+			}
+			if tmpl.Version != tt.expectedVersion {
+				t.Errorf("Version = %s, expected %s", tmpl.Version, tt.expectedVersion)
+			}
+		})
+	}
+
+	// O override do usuário herda as variáveis obrigatórias do template embutido
+	if _, err := registry.Render("context-selection", map[string]any{"user_request": "x"}); err == nil {
+		t.Error("Expected error for missing inherited variable 'summary'")
+	}
+}
+
+// TestRender testa a validação de variáveis e o stamp de versão
+func TestRender(t *testing.T) {
+	registry := Embedded()
+
+	tests := []struct {
+		name     string
+		vars     map[string]any
+		wantErr  string
+		contains string
+	}{
+		{
+			name:    "Missing required variables",
+			vars:    map[string]any{"user_request": "Criar endpoint"},
+			wantErr: "project_context, project_structure",
+		},
+		{
+			name:    "Empty string counts as missing",
+			vars:    map[string]any{"project_context": "{}", "project_structure": "internal/", "user_request": "  "},
+			wantErr: "user_request",
+		},
+		{
+			name:     "Optional variable defaults to null",
+			vars:     map[string]any{"project_context": "{}", "project_structure": "internal/", "user_request": "Criar endpoint"},
+			contains: "**Correções/Alterações (se houver):**\nnull",
+		},
+		{
+			name:     "Optional variable provided",
+			vars:     map[string]any{"project_context": "{}", "project_structure": "internal/", "user_request": "Criar endpoint", "user_corrections": "Usar Gin"},
+			contains: "**Correções/Alterações (se houver):**\nUsar Gin",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rendered, err := registry.Render("requirements", tt.vars)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Render failed: %v", err)
+			}
+			if !strings.Contains(rendered.Text, tt.contains) {
+				t.Errorf("Expected rendered text to contain %q, got:\n%s", tt.contains, rendered.Text)
+			}
+			if rendered.Stamp.Name != "requirements" || rendered.Stamp.Version != "1.0.0" || len(rendered.Stamp.Hash) != 12 {
+				t.Errorf("Unexpected stamp %s", rendered.Stamp)
+			}
+		})
+	}
+}
+
+func writePrompt(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name+Extension), []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write prompt: %v", err)
+	}
+}
@@ -0,0 +1,116 @@
+package spec
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// GenerationType define o tipo de geração solicitada
+type GenerationType string
+
+const (
+	GenerationFeature  GenerationType = "feature"
+	GenerationTest     GenerationType = "test"
+	GenerationFix      GenerationType = "fix"
+	GenerationDoc      GenerationType = "doc"
+	GenerationRefactor GenerationType = "refactor"
+)
+
+// ChangeType define o tipo de alteração em um arquivo
+type ChangeType string
+
+const (
+	ChangeNewFile ChangeType = "new_file"
+	ChangeModify  ChangeType = "modify"
+	ChangeDelete  ChangeType = "delete"
+)
+
+// TestType define o tipo de teste previsto na especificação
+type TestType string
+
+const (
+	TestUnit        TestType = "unit"
+	TestIntegration TestType = "integration"
+	TestE2E         TestType = "e2e"
+)
+
+// Spec representa a especificação gerada pelo Requirements Interpreter
+type Spec struct {
+	GenerationType GenerationType `json:"generation_type"`
+	Summary        string         `json:"summary"`
+	Architecture   Architecture   `json:"architecture"`
+	FilesChanges   []FileChange   `json:"files_changes"`
+	Tests          []Test         `json:"tests"`
+	Complexity     string         `json:"complexity"`
+	DOR            []string       `json:"dor"`
+	DOD            []string       `json:"dod"`
+	AgentFeedback  AgentFeedback  `json:"agent_feedback"`
+}
+
+// Architecture representa as decisões arquiteturais da especificação
+type Architecture struct {
+	Pattern        string   `json:"pattern"`
+	Stack          []string `json:"stack"`
+	Principles     []string `json:"principles"`
+	DesignPatterns []string `json:"design_patterns"`
+}
+
+// FileChange representa um arquivo criado, alterado ou removido
+type FileChange struct {
+	FilePath      string     `json:"file_path"`
+	Change        string     `json:"change"`
+	Type          ChangeType `json:"type"`
+	RelevantFiles []string   `json:"relevant_files"`
+}
+
+// Test representa um teste previsto na especificação
+type Test struct {
+	Type        TestType `json:"type"`
+	Description string   `json:"description"`
+}
+
+// AgentFeedback representa a comunicação do agente com o usuário
+type AgentFeedback struct {
+	Suggestions []string `json:"suggestions"`
+	Warnings    []string `json:"warnings"`
+	MissingInfo []string `json:"missing_info"`
+}
+
+// LoadFromFile carrega uma especificação de um arquivo JSON
+func LoadFromFile(filePath string) (*Spec, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read spec file: %w", err)
+	}
+
+	var s Spec
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse spec: %w", err)
+	}
+
+	return &s, nil
+}
+
+// TestsOfType retorna os testes da especificação de um tipo
+func (s *Spec) TestsOfType(testType TestType) []Test {
+	tests := make([]Test, 0)
+	for _, t := range s.Tests {
+		if t.Type == testType {
+			tests = append(tests, t)
+		}
+	}
+	return tests
+}
+
+// ChangedFiles retorna os caminhos de arquivos criados ou alterados
+func (s *Spec) ChangedFiles() []string {
+	files := make([]string, 0, len(s.FilesChanges))
+	for _, change := range s.FilesChanges {
+		if change.Type == ChangeDelete {
+			continue
+		}
+		files = append(files, change.FilePath)
+	}
+	return files
+}
@@ -0,0 +1,71 @@
+package testgen
+
+import (
+	"github.com/PHRaulino/phengineer/internal/domain/prompts"
+	"github.com/PHRaulino/phengineer/internal/domain/spec"
+	"github.com/PHRaulino/phengineer/internal/infrastructure/symbols"
+)
+
+// BaselinePolicy define o que fazer com testes que falham no baseline
+type BaselinePolicy string
+
+const (
+	PolicyDiscard BaselinePolicy = "discard" // Remove o teste do resultado
+	PolicyFlag    BaselinePolicy = "flag"    // Mantém o teste marcado como falho
+)
+
+// TestStatus representa o estado de um teste gerado
+type TestStatus string
+
+const (
+	StatusPending   TestStatus = "pending"
+	StatusPassed    TestStatus = "passed"
+	StatusFlagged   TestStatus = "flagged"
+	StatusDiscarded TestStatus = "discarded"
+)
+
+// Target representa uma função tocada pela especificação
+type Target struct {
+	Symbol symbols.Symbol  `json:"symbol"`
+	Change spec.FileChange `json:"change"`
+}
+
+// Case representa um caso da tabela de testes.
+// Os valores são expressões Go válidas no pacote do arquivo testado.
+type Case struct {
+	Name     string            `json:"name"`
+	Receiver string            `json:"receiver,omitempty"` // Expressão para o receiver de métodos
+	Args     map[string]string `json:"args"`               // key: nome do parâmetro
+	Expected []string          `json:"expected"`           // Um valor por retorno, exceto error
+	WantErr  bool              `json:"want_err"`
+}
+
+// GeneratedTest representa uma função de teste gerada para um alvo
+type GeneratedTest struct {
+	TestName string     `json:"test_name"`
+	Target   Target     `json:"target"`
+	Cases    []Case     `json:"cases"`
+	Imports  []string   `json:"imports,omitempty"`
+	Status   TestStatus `json:"status"`
+	Output   string     `json:"output,omitempty"` // Saída do go test quando falha
+}
+
+// GeneratedFile representa um arquivo _test.go gerado
+type GeneratedFile struct {
+	Path    string           `json:"path"` // Caminho relativo à raiz do projeto
+	Package string           `json:"package"`
+	Tests   []*GeneratedTest `json:"tests"`
+	Content []byte           `json:"-"`
+}
+
+// Result contém o resultado da geração de testes
+type Result struct {
+	BaselineRef string           `json:"baseline_ref"`
+	Files       []*GeneratedFile `json:"files"`
+	Flagged     []*GeneratedTest `json:"flagged"`
+	Discarded   []*GeneratedTest `json:"discarded"`
+	Prompts     []prompts.Stamp  `json:"prompts"` // Versões dos prompts usados
+
+	root      string // Raiz do projeto onde WriteFiles grava os arquivos
+	overwrite bool
+}
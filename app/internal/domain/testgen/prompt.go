@@ -0,0 +1,100 @@
+package testgen
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/PHRaulino/phengineer/internal/domain/agent"
+	"github.com/PHRaulino/phengineer/internal/domain/prompts"
+	"github.com/PHRaulino/phengineer/internal/domain/spec"
+)
+
+// casesSchema é o JSON Schema da resposta esperada do modelo
+var casesSchema = json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "imports": { "type": "array", "items": { "type": "string" } },
+    "cases": {
+      "type": "array",
+      "items": {
+        "type": "object",
+        "properties": {
+          "name": { "type": "string" },
+          "receiver": { "type": "string" },
+          "args": { "type": "object", "additionalProperties": { "type": "string" } },
+          "expected": { "type": "array", "items": { "type": "string" } },
+          "want_err": { "type": "boolean" }
+        },
+        "required": ["name", "args", "expected", "want_err"]
+      }
+    }
+  },
+  "required": ["imports", "cases"]
+}`)
+
+// casesResponse representa a resposta do modelo
+type casesResponse struct {
+	Imports []string `json:"imports"`
+	Cases   []Case   `json:"cases"`
+}
+
+// requestCases pede ao modelo os casos de teste para um alvo
+func requestCases(ctx context.Context, model agent.Model, registry *prompts.Registry, root string, s *spec.Spec, target Target, knowledgeSection string) (*casesResponse, []prompts.Stamp, error) {
+	source, err := readSymbolSource(root, target)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	system, err := registry.Render("testgen-system", nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	prompt, err := registry.Render("testgen-cases", map[string]any{
+		"summary":     s.Summary,
+		"file_path":   target.Change.FilePath,
+		"change_type": string(target.Change.Type),
+		"change":      target.Change.Change,
+		"tests":       s.Tests,
+		"knowledge":   knowledgeSection,
+		"package":     target.Symbol.Package,
+		"source":      source,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	stamps := []prompts.Stamp{system.Stamp, prompt.Stamp}
+
+	var resp casesResponse
+	if _, err := agent.CompleteJSON(ctx, model, agent.Request{
+		System: system.Text,
+		Prompt: prompt.Text,
+		Schema: casesSchema,
+	}, &resp); err != nil {
+		return nil, stamps, fmt.Errorf("failed to generate cases for %s: %w", target.Symbol.QualifiedName(), err)
+	}
+
+	return &resp, stamps, nil
+}
+
+// readSymbolSource lê o código-fonte da função no projeto
+func readSymbolSource(root string, target Target) (string, error) {
+	data, err := os.ReadFile(filepath.Join(root, target.Symbol.File))
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", target.Symbol.File, err)
+	}
+
+	lines := strings.Split(string(data), "\n")
+	start := target.Symbol.StartLine - 1
+	end := target.Symbol.EndLine
+	if start < 0 || end > len(lines) || start >= end {
+		return "", fmt.Errorf("invalid line range for %s", target.Symbol.QualifiedName())
+	}
+
+	return strings.Join(lines[start:end], "\n"), nil
+}
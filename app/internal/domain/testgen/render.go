@@ -0,0 +1,252 @@
+package testgen
+
+import (
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/PHRaulino/phengineer/internal/infrastructure/symbols"
+)
+
+// comparableTypes são os tipos comparados com != em vez de reflect.DeepEqual
+var comparableTypes = map[string]bool{
+	"string": true, "bool": true, "byte": true, "rune": true,
+	"int": true, "int8": true, "int16": true, "int32": true, "int64": true,
+	"uint": true, "uint8": true, "uint16": true, "uint32": true, "uint64": true,
+	"float32": true, "float64": true,
+}
+
+// reservedFields são os campos fixos da tabela de testes
+var reservedFields = map[string]bool{
+	"name": true, "receiver": true, "wantErr": true, "tt": true, "t": true, "tests": true,
+}
+
+// field representa um campo da struct da tabela
+type field struct {
+	Name string
+	Type string
+	Expr string // Expressão usada na chamada ou no retorno
+}
+
+// TestName gera o nome da função de teste para um símbolo
+func TestName(symbol symbols.Symbol) string {
+	name := upperFirst(symbol.Name)
+	if symbol.Receiver != "" {
+		name = upperFirst(strings.TrimPrefix(symbol.Receiver, "*")) + name
+	}
+	return "Test" + name
+}
+
+// RenderFile gera o conteúdo formatado de um arquivo de teste
+func RenderFile(file *GeneratedFile) ([]byte, error) {
+	var body strings.Builder
+	imports := map[string]bool{"testing": true}
+
+	for _, test := range file.Tests {
+		usesReflect := renderTest(&body, test)
+		if usesReflect {
+			imports["reflect"] = true
+		}
+		for _, imp := range test.Imports {
+			imports[imp] = true
+		}
+	}
+
+	importList := make([]string, 0, len(imports))
+	for imp := range imports {
+		importList = append(importList, imp)
+	}
+	sort.Strings(importList)
+
+	var src strings.Builder
+	fmt.Fprintf(&src, "package %s\n\nimport (\n", file.Package)
+	for _, imp := range importList {
+		fmt.Fprintf(&src, "\t%q\n", imp)
+	}
+	src.WriteString(")\n")
+	src.WriteString(body.String())
+
+	formatted, err := format.Source([]byte(src.String()))
+	if err != nil {
+		return nil, fmt.Errorf("generated test for %s is not valid Go: %w", file.Path, err)
+	}
+
+	return formatted, nil
+}
+
+// renderTest escreve uma função de teste table-driven e informa se usa reflect
+func renderTest(w *strings.Builder, test *GeneratedTest) bool {
+	symbol := test.Target.Symbol
+	params := paramFields(symbol)
+	expected := expectedFields(symbol)
+	usesReflect := false
+
+	fmt.Fprintf(w, "\n// %s testa %s\n", test.TestName, symbol.QualifiedName())
+	fmt.Fprintf(w, "func %s(t *testing.T) {\n", test.TestName)
+	w.WriteString("tests := []struct {\nname string\n")
+	if symbol.Receiver != "" {
+		fmt.Fprintf(w, "receiver %s\n", symbol.Receiver)
+	}
+	for _, p := range params {
+		fmt.Fprintf(w, "%s %s\n", p.Name, p.Type)
+	}
+	for _, e := range expected {
+		fmt.Fprintf(w, "%s %s\n", e.Name, e.Type)
+	}
+	if symbol.ReturnsError() {
+		w.WriteString("wantErr bool\n")
+	}
+	w.WriteString("}{\n")
+
+	for _, c := range test.Cases {
+		w.WriteString("{\n")
+		fmt.Fprintf(w, "name: %q,\n", c.Name)
+		if symbol.Receiver != "" {
+			fmt.Fprintf(w, "receiver: %s,\n", receiverExpr(symbol, c))
+		}
+		for _, p := range params {
+			if value, ok := c.Args[p.Expr]; ok && value != "" {
+				fmt.Fprintf(w, "%s: %s,\n", p.Name, value)
+			}
+		}
+		for i, e := range expected {
+			if i < len(c.Expected) && c.Expected[i] != "" {
+				fmt.Fprintf(w, "%s: %s,\n", e.Name, c.Expected[i])
+			}
+		}
+		if c.WantErr {
+			w.WriteString("wantErr: true,\n")
+		}
+		w.WriteString("},\n")
+	}
+	w.WriteString("}\n\n")
+
+	// Chamada da função
+	results := make([]string, 0, len(expected)+1)
+	for _, e := range expected {
+		results = append(results, e.Expr)
+	}
+	if symbol.ReturnsError() {
+		results = append(results, "err")
+	}
+
+	args := make([]string, 0, len(params))
+	for _, p := range params {
+		arg := "tt." + p.Name
+		if strings.HasPrefix(p.Type, "[]") && isVariadic(symbol, p.Expr) {
+			arg += "..."
+		}
+		args = append(args, arg)
+	}
+
+	call := symbol.Name
+	if symbol.Receiver != "" {
+		call = "tt.receiver." + symbol.Name
+	}
+
+	w.WriteString("for _, tt := range tests {\nt.Run(tt.name, func(t *testing.T) {\n")
+	fmt.Fprintf(w, "%s := %s(%s)\n", strings.Join(results, ", "), call, strings.Join(args, ", "))
+
+	if symbol.ReturnsError() {
+		w.WriteString("if (err != nil) != tt.wantErr {\n")
+		fmt.Fprintf(w, "t.Errorf(\"%s() error = %%v, wantErr %%v\", err, tt.wantErr)\nreturn\n}\n", symbol.Name)
+		if len(expected) > 0 {
+			w.WriteString("if tt.wantErr {\nreturn\n}\n")
+		}
+	}
+
+	for _, e := range expected {
+		if comparableTypes[e.Type] {
+			fmt.Fprintf(w, "if %s != tt.%s {\n", e.Expr, e.Name)
+		} else {
+			usesReflect = true
+			fmt.Fprintf(w, "if !reflect.DeepEqual(%s, tt.%s) {\n", e.Expr, e.Name)
+		}
+		fmt.Fprintf(w, "t.Errorf(\"%s() = %%v, expected %%v\", %s, tt.%s)\n}\n", symbol.Name, e.Expr, e.Name)
+	}
+
+	w.WriteString("})\n}\n}\n")
+	return usesReflect
+}
+
+// paramFields mapeia os parâmetros da função para campos da tabela.
+// Expr guarda o nome original usado como chave em Case.Args.
+func paramFields(symbol symbols.Symbol) []field {
+	fields := make([]field, 0, len(symbol.Params))
+	for i, p := range symbol.Params {
+		original := p.Name
+		if original == "" || original == "_" {
+			original = fmt.Sprintf("arg%d", i)
+		}
+
+		name := original
+		if reservedFields[name] || strings.HasPrefix(name, "expected") || strings.HasPrefix(name, "result") {
+			name = "arg" + upperFirst(name)
+		}
+
+		fieldType := p.Type
+		if strings.HasPrefix(fieldType, "...") {
+			fieldType = "[]" + strings.TrimPrefix(fieldType, "...")
+		}
+
+		fields = append(fields, field{Name: name, Type: fieldType, Expr: original})
+	}
+	return fields
+}
+
+// expectedFields mapeia os retornos (exceto o error final) para campos da tabela
+func expectedFields(symbol symbols.Symbol) []field {
+	results := symbol.Results
+	if symbol.ReturnsError() {
+		results = results[:len(results)-1]
+	}
+
+	fields := make([]field, 0, len(results))
+	for i, r := range results {
+		if len(results) == 1 {
+			fields = append(fields, field{Name: "expected", Type: r.Type, Expr: "result"})
+			continue
+		}
+		fields = append(fields, field{
+			Name: fmt.Sprintf("expected%d", i+1),
+			Type: r.Type,
+			Expr: fmt.Sprintf("result%d", i+1),
+		})
+	}
+	return fields
+}
+
+// receiverExpr retorna a expressão do receiver do caso ou um valor zero
+func receiverExpr(symbol symbols.Symbol, c Case) string {
+	if c.Receiver != "" {
+		return c.Receiver
+	}
+	if strings.HasPrefix(symbol.Receiver, "*") {
+		return "&" + strings.TrimPrefix(symbol.Receiver, "*") + "{}"
+	}
+	return symbol.Receiver + "{}"
+}
+
+// isVariadic verifica se o parâmetro é o variádico da função
+func isVariadic(symbol symbols.Symbol, paramName string) bool {
+	if len(symbol.Params) == 0 {
+		return false
+	}
+	last := symbol.Params[len(symbol.Params)-1]
+	name := last.Name
+	if name == "" || name == "_" {
+		name = fmt.Sprintf("arg%d", len(symbol.Params)-1)
+	}
+	return name == paramName && strings.HasPrefix(last.Type, "...")
+}
+
+func upperFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	runes := []rune(s)
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
@@ -0,0 +1,106 @@
+package testgen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PHRaulino/phengineer/internal/infrastructure/symbols"
+)
+
+// TestTestName testa a geração do nome da função de teste
+func TestTestName(t *testing.T) {
+	tests := []struct {
+		name     string
+		symbol   symbols.Symbol
+		expected string
+	}{
+		{
+			name:     "Unexported function",
+			symbol:   symbols.Symbol{Name: "extractRepoNameFromURL"},
+			expected: "TestExtractRepoNameFromURL",
+		},
+		{
+			name:     "Pointer receiver method",
+			symbol:   symbols.Symbol{Name: "parseFileSize", Receiver: "*Service"},
+			expected: "TestServiceParseFileSize",
+		},
+		{
+			name:     "Value receiver method",
+			symbol:   symbols.Symbol{Name: "QualifiedName", Receiver: "Symbol"},
+			expected: "TestSymbolQualifiedName",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := TestName(tt.symbol)
+			if result != tt.expected {
+				t.Errorf("TestName(%q) = %q, expected %q", tt.symbol.Name, result, tt.expected)
+			}
+		})
+	}
+}
+
+// TestRenderFile testa a renderização de um arquivo de teste table-driven
+func TestRenderFile(t *testing.T) {
+	parseSize := symbols.Symbol{
+		Name:     "parseFileSize",
+		Receiver: "*Service",
+		Package:  "discovery",
+		Params:   []symbols.Param{{Name: "sizeStr", Type: "string"}},
+		Results:  []symbols.Param{{Type: "int64"}, {Type: "error"}},
+	}
+	split := symbols.Symbol{
+		Name:    "splitAll",
+		Package: "discovery",
+		Params:  []symbols.Param{{Name: "parts", Type: "...string"}},
+		Results: []symbols.Param{{Type: "[]string"}},
+	}
+
+	file := &GeneratedFile{
+		Path:    "internal/domain/discovery/service_test.go",
+		Package: "discovery",
+		Tests: []*GeneratedTest{
+			{
+				TestName: TestName(parseSize),
+				Target:   Target{Symbol: parseSize},
+				Cases: []Case{
+					{Name: "Megabytes", Args: map[string]string{"sizeStr": `"10MB"`}, Expected: []string{"10 << 20"}},
+					{Name: "Invalid", Args: map[string]string{"sizeStr": `"abc"`}, WantErr: true},
+				},
+			},
+			{
+				TestName: TestName(split),
+				Target:   Target{Symbol: split},
+				Cases: []Case{
+					{Name: "Two parts", Args: map[string]string{"parts": `[]string{"a", "b"}`}, Expected: []string{`[]string{"a", "b"}`}},
+				},
+			},
+		},
+	}
+
+	content, err := RenderFile(file)
+	if err != nil {
+		t.Fatalf("Failed to render file: %v", err)
+	}
+
+	source := string(content)
+	expectedStrings := []string{
+		"package discovery",
+		"\"reflect\"",
+		"func TestServiceParseFileSize(t *testing.T) {",
+		"receiver: &Service{},",
+		"sizeStr:  \"10MB\",",
+		"wantErr:  true,",
+		"result, err := tt.receiver.parseFileSize(tt.sizeStr)",
+		"if result != tt.expected {",
+		"result := splitAll(tt.parts...)",
+		"if !reflect.DeepEqual(result, tt.expected) {",
+	}
+
+	for _, expected := range expectedStrings {
+		if !strings.Contains(source, expected) {
+			t.Errorf("Expected rendered test to contain '%s', got:\n%s", expected, source)
+		}
+	}
+}
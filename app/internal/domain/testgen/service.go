@@ -0,0 +1,307 @@
+package testgen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/PHRaulino/phengineer/internal/domain/agent"
+	"github.com/PHRaulino/phengineer/internal/domain/knowledge"
+	"github.com/PHRaulino/phengineer/internal/domain/prompts"
+	"github.com/PHRaulino/phengineer/internal/domain/spec"
+	"github.com/PHRaulino/phengineer/internal/infrastructure/config"
+	"github.com/PHRaulino/phengineer/internal/infrastructure/symbols"
+	"github.com/PHRaulino/phengineer/internal/infrastructure/worktree"
+	"go.uber.org/zap"
+)
+
+// ErrTestFileExists o arquivo de teste gerado já existe e a sobrescrita não foi permitida
+var ErrTestFileExists = errors.New("generated test file already exists")
+
+// Service agente Test Generator
+type Service struct {
+	model     agent.Model
+	policy    BaselinePolicy
+	knowledge string // Seção de padrões da empresa incluída no prompt
+	prompts   *prompts.Registry
+	overwrite bool
+}
+
+// NewService cria uma nova instância do Test Generator
+func NewService(model agent.Model, policy BaselinePolicy) *Service {
+	if policy == "" {
+		policy = PolicyFlag
+	}
+	return &Service{
+		model:   model,
+		policy:  policy,
+		prompts: prompts.Embedded(),
+	}
+}
+
+// WithKnowledge inclui os documentos de conhecimento no prompt do agente
+func (s *Service) WithKnowledge(documents []knowledge.Document) *Service {
+	s.knowledge = knowledge.FormatSection(documents)
+	return s
+}
+
+// WithPrompts usa o registro de prompts com os overrides do projeto e do usuário
+func (s *Service) WithPrompts(registry *prompts.Registry) *Service {
+	s.prompts = registry
+	return s
+}
+
+// WithOverwrite permite substituir um <arquivo>_generated_test.go existente (--force)
+func (s *Service) WithOverwrite(overwrite bool) *Service {
+	s.overwrite = overwrite
+	return s
+}
+
+// Generate gera testes table-driven para as funções Go tocadas pela especificação.
+// Os caminhos são relativos à raiz do projeto (RootAppPath da configuração no context);
+// baselineRef é o commit sem as mudanças, onde os testes são verificados em um worktree isolado.
+func (s *Service) Generate(ctx context.Context, sp *spec.Spec, baselineRef string) (*Result, error) {
+	root := config.GetAutoConfig(ctx).RootAppPath
+	if len(sp.TestsOfType(spec.TestUnit)) == 0 && sp.GenerationType != spec.GenerationTest {
+		zap.L().Info("spec has no unit tests, skipping test generation")
+		return &Result{BaselineRef: baselineRef, root: root}, nil
+	}
+
+	index, err := symbols.BuildGoIndex(root, sp.ChangedFiles())
+	if err != nil {
+		return nil, fmt.Errorf("failed to build symbol index: %w", err)
+	}
+
+	targets := FindTargets(sp, index)
+	if len(targets) == 0 {
+		zap.L().Info("no Go functions touched by spec, skipping test generation")
+		return &Result{BaselineRef: baselineRef, root: root}, nil
+	}
+
+	files, stamps, err := s.buildFiles(ctx, sp, root, targets)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.verify(ctx, root, baselineRef, files)
+	if err != nil {
+		return nil, err
+	}
+	result.Prompts = stamps
+	result.root = root
+	result.overwrite = s.overwrite
+
+	return result, nil
+}
+
+// buildFiles gera os casos via modelo e agrupa os testes por arquivo de origem
+func (s *Service) buildFiles(ctx context.Context, sp *spec.Spec, root string, targets []Target) ([]*GeneratedFile, []prompts.Stamp, error) {
+	filesByPath := make(map[string]*GeneratedFile)
+	stamps := make([]prompts.Stamp, 0)
+	order := make([]string, 0)
+	existingByDir := make(map[string]map[string]bool)
+
+	for _, target := range targets {
+		// Confere o destino antes de chamar o modelo
+		testPath, err := testFilePath(root, target.Symbol.File, s.overwrite)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		cases, used, err := requestCases(ctx, s.model, s.prompts, root, sp, target, s.knowledge)
+		if err != nil {
+			return nil, nil, err
+		}
+		stamps = prompts.MergeStamps(stamps, used...)
+		if len(cases.Cases) == 0 {
+			zap.L().Warn("model returned no cases", zap.String("function", target.Symbol.QualifiedName()))
+			continue
+		}
+
+		dir := filepath.Dir(target.Symbol.File)
+		if _, ok := existingByDir[dir]; !ok {
+			existingByDir[dir] = existingTestNames(filepath.Join(root, dir))
+		}
+
+		file, ok := filesByPath[testPath]
+		if !ok {
+			file = &GeneratedFile{Path: testPath, Package: target.Symbol.Package}
+			filesByPath[testPath] = file
+			order = append(order, testPath)
+		}
+
+		name := TestName(target.Symbol)
+		if existingByDir[dir][name] {
+			name += "Generated"
+		}
+		existingByDir[dir][name] = true
+
+		file.Tests = append(file.Tests, &GeneratedTest{
+			TestName: name,
+			Target:   target,
+			Cases:    cases.Cases,
+			Imports:  cases.Imports,
+			Status:   StatusPending,
+		})
+	}
+
+	files := make([]*GeneratedFile, 0, len(order))
+	for _, path := range order {
+		file := filesByPath[path]
+		content, err := RenderFile(file)
+		if err != nil {
+			return nil, nil, err
+		}
+		file.Content = content
+		files = append(files, file)
+	}
+
+	return files, stamps, nil
+}
+
+// verify roda os testes gerados no baseline e aplica a política de falhas
+func (s *Service) verify(ctx context.Context, root, baselineRef string, files []*GeneratedFile) (*Result, error) {
+	result := &Result{
+		BaselineRef: baselineRef,
+		Files:       make([]*GeneratedFile, 0, len(files)),
+		Flagged:     make([]*GeneratedTest, 0),
+		Discarded:   make([]*GeneratedTest, 0),
+	}
+
+	if len(files) == 0 {
+		return result, nil
+	}
+
+	wt, err := worktree.Create(root, baselineRef)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create verification worktree: %w", err)
+	}
+	defer func() {
+		if err := wt.Remove(); err != nil {
+			zap.L().Warn("failed to remove verification worktree", zap.Error(err))
+		}
+	}()
+
+	// O worktree reproduz o repositório inteiro; o projeto pode ser uma subpasta dele
+	projectDir, err := wt.Resolve(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to map project into verification worktree: %w", err)
+	}
+
+	for _, file := range files {
+		failures, err := verifyFile(ctx, wt.Path, projectDir, file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to verify %s: %w", file.Path, err)
+		}
+
+		kept := make([]*GeneratedTest, 0, len(file.Tests))
+		for _, test := range file.Tests {
+			output, failed := failures[test.TestName]
+			if !failed {
+				test.Status = StatusPassed
+				kept = append(kept, test)
+				continue
+			}
+
+			test.Output = output
+			zap.L().Info("generated test failed on baseline",
+				zap.String("test", test.TestName),
+				zap.String("file", file.Path),
+				zap.String("policy", string(s.policy)),
+			)
+
+			if s.policy == PolicyDiscard {
+				test.Status = StatusDiscarded
+				result.Discarded = append(result.Discarded, test)
+				continue
+			}
+
+			test.Status = StatusFlagged
+			result.Flagged = append(result.Flagged, test)
+			kept = append(kept, test)
+		}
+
+		if len(kept) == 0 {
+			continue
+		}
+
+		if len(kept) != len(file.Tests) {
+			file.Tests = kept
+			content, err := RenderFile(file)
+			if err != nil {
+				return nil, err
+			}
+			file.Content = content
+		}
+
+		result.Files = append(result.Files, file)
+	}
+
+	return result, nil
+}
+
+// WriteFiles grava os arquivos de teste gerados na raiz do projeto. Sem WithOverwrite,
+// nenhum arquivo é gravado se algum deles já existir.
+func (r *Result) WriteFiles() error {
+	if !r.overwrite {
+		for _, file := range r.Files {
+			if _, err := os.Stat(filepath.Join(r.root, file.Path)); err == nil {
+				return fmt.Errorf("%w: %s", ErrTestFileExists, file.Path)
+			}
+		}
+	}
+
+	for _, file := range r.Files {
+		fullPath := filepath.Join(r.root, file.Path)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0o755); err != nil {
+			return fmt.Errorf("failed to create test directory: %w", err)
+		}
+		if err := os.WriteFile(fullPath, file.Content, 0o644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", file.Path, err)
+		}
+	}
+	return nil
+}
+
+// testFilePath define o caminho do arquivo de teste, relativo à raiz do projeto. Testes
+// escritos à mão nunca são substituídos; um _generated_test.go anterior só com overwrite.
+func testFilePath(root, sourceFile string, overwrite bool) (string, error) {
+	base := strings.TrimSuffix(sourceFile, ".go")
+	candidate := base + "_test.go"
+	if _, err := os.Stat(filepath.Join(root, candidate)); os.IsNotExist(err) {
+		return candidate, nil
+	}
+
+	generated := base + "_generated_test.go"
+	if _, err := os.Stat(filepath.Join(root, generated)); err == nil && !overwrite {
+		return "", fmt.Errorf("%w: %s", ErrTestFileExists, generated)
+	}
+	return generated, nil
+}
+
+// existingTestNames lista as funções de teste já declaradas no pacote
+func existingTestNames(dir string) map[string]bool {
+	names := make(map[string]bool)
+
+	matches, _ := filepath.Glob(filepath.Join(dir, "*_test.go"))
+	fset := token.NewFileSet()
+	for _, match := range matches {
+		file, err := parser.ParseFile(fset, match, nil, parser.SkipObjectResolution)
+		if err != nil {
+			continue
+		}
+		for _, decl := range file.Decls {
+			if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv == nil && strings.HasPrefix(fn.Name.Name, "Test") {
+				names[fn.Name.Name] = true
+			}
+		}
+	}
+
+	return names
+}
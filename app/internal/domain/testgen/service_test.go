@@ -0,0 +1,100 @@
+package testgen
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestTestFilePath testa a escolha do arquivo de teste sem sobrescrever testes existentes
+func TestTestFilePath(t *testing.T) {
+	tests := []struct {
+		name      string
+		existing  []string
+		overwrite bool
+		want      string
+		wantErr   bool
+	}{
+		{
+			name: "No test file",
+			want: "pkg/util_test.go",
+		},
+		{
+			name:     "Handwritten test file",
+			existing: []string{"pkg/util_test.go"},
+			want:     "pkg/util_generated_test.go",
+		},
+		{
+			name:     "Generated file from a previous run",
+			existing: []string{"pkg/util_test.go", "pkg/util_generated_test.go"},
+			wantErr:  true,
+		},
+		{
+			name:      "Generated file with overwrite",
+			existing:  []string{"pkg/util_test.go", "pkg/util_generated_test.go"},
+			overwrite: true,
+			want:      "pkg/util_generated_test.go",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			for _, name := range tt.existing {
+				writeTestFile(t, filepath.Join(root, name), "package pkg\n")
+			}
+
+			got, err := testFilePath(root, "pkg/util.go", tt.overwrite)
+			if tt.wantErr {
+				if !errors.Is(err, ErrTestFileExists) {
+					t.Errorf("testFilePath() error = %v, want ErrTestFileExists", err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("testFilePath() = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+// TestWriteFiles testa que arquivos existentes só são substituídos com overwrite
+func TestWriteFiles(t *testing.T) {
+	root := t.TempDir()
+	existing := filepath.Join(root, "pkg", "util_generated_test.go")
+	writeTestFile(t, existing, "package pkg // anterior\n")
+
+	result := &Result{
+		root: root,
+		Files: []*GeneratedFile{
+			{Path: "pkg/new_test.go", Content: []byte("package pkg\n")},
+			{Path: "pkg/util_generated_test.go", Content: []byte("package pkg\n")},
+		},
+	}
+
+	if err := result.WriteFiles(); !errors.Is(err, ErrTestFileExists) {
+		t.Fatalf("WriteFiles() error = %v, want ErrTestFileExists", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "pkg", "new_test.go")); !os.IsNotExist(err) {
+		t.Error("WriteFiles() wrote files before refusing to overwrite")
+	}
+
+	result.overwrite = true
+	if err := result.WriteFiles(); err != nil {
+		t.Fatalf("WriteFiles() with overwrite error = %v", err)
+	}
+	if data, _ := os.ReadFile(existing); string(data) != "package pkg\n" {
+		t.Errorf("%s = %q, want replaced content", existing, data)
+	}
+}
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,78 @@
+package testgen
+
+import (
+	"strings"
+
+	"github.com/PHRaulino/phengineer/internal/domain/spec"
+	"github.com/PHRaulino/phengineer/internal/infrastructure/symbols"
+)
+
+// FindTargets encontra as funções tocadas pelos files_changes da especificação.
+// Quando a descrição da mudança cita funções pelo nome, apenas elas são
+// selecionadas; caso contrário todas as funções testáveis do arquivo entram.
+func FindTargets(s *spec.Spec, index *symbols.Index) []Target {
+	targets := make([]Target, 0)
+
+	for _, change := range s.FilesChanges {
+		if change.Type == spec.ChangeDelete || !strings.HasSuffix(change.FilePath, ".go") {
+			continue
+		}
+
+		candidates := make([]symbols.Symbol, 0)
+		for _, symbol := range index.ForFile(change.FilePath) {
+			if isTestable(symbol) {
+				candidates = append(candidates, symbol)
+			}
+		}
+
+		mentioned := make([]symbols.Symbol, 0)
+		for _, symbol := range candidates {
+			if mentionsSymbol(change.Change, symbol) {
+				mentioned = append(mentioned, symbol)
+			}
+		}
+		if len(mentioned) > 0 {
+			candidates = mentioned
+		}
+
+		for _, symbol := range candidates {
+			targets = append(targets, Target{Symbol: symbol, Change: change})
+		}
+	}
+
+	return targets
+}
+
+// isTestable verifica se a função pode ser coberta por um teste de tabela
+func isTestable(symbol symbols.Symbol) bool {
+	if symbol.Name == "main" || symbol.Name == "init" || symbol.Name == "_" {
+		return false
+	}
+
+	// Sem retornos não há o que comparar na tabela
+	if len(symbol.Results) == 0 {
+		return false
+	}
+
+	// Receivers genéricos exigem instanciação que o template não cobre
+	if strings.Contains(symbol.Receiver, "[") {
+		return false
+	}
+
+	return true
+}
+
+// mentionsSymbol verifica se o texto da mudança cita a função
+func mentionsSymbol(text string, symbol symbols.Symbol) bool {
+	for _, word := range strings.FieldsFunc(text, isNotIdentifierRune) {
+		word = strings.Trim(word, ".")
+		if word == symbol.Name || word == symbol.QualifiedName() {
+			return true
+		}
+	}
+	return false
+}
+
+func isNotIdentifierRune(r rune) bool {
+	return !(r == '_' || r == '.' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z')
+}
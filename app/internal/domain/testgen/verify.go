@@ -0,0 +1,137 @@
+package testgen
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// testEvent representa um evento da saída de go test -json
+type testEvent struct {
+	Action string `json:"Action"`
+	Test   string `json:"Test"`
+	Output string `json:"Output"`
+}
+
+// verifyFile executa os testes de um arquivo no worktree de verificação; projectDir é a
+// raiz do projeto dentro do worktree. Retorna os testes que falharam (ou não rodaram)
+// com a saída do go test.
+func verifyFile(ctx context.Context, worktreePath, projectDir string, file *GeneratedFile) (map[string]string, error) {
+	testPath := filepath.Join(projectDir, file.Path)
+	if err := os.MkdirAll(filepath.Dir(testPath), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create test directory: %w", err)
+	}
+	if err := os.WriteFile(testPath, file.Content, 0o644); err != nil {
+		return nil, fmt.Errorf("failed to write test file: %w", err)
+	}
+	defer os.Remove(testPath)
+
+	names := make([]string, 0, len(file.Tests))
+	for _, test := range file.Tests {
+		names = append(names, test.TestName)
+	}
+
+	moduleDir := findModuleRoot(worktreePath, filepath.Dir(testPath))
+	pkgDir, err := filepath.Rel(moduleDir, filepath.Dir(testPath))
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve package path: %w", err)
+	}
+
+	cmd := exec.CommandContext(ctx, "go", "test", "-json", "-count=1",
+		"-run", fmt.Sprintf("^(%s)$", strings.Join(names, "|")), "./"+filepath.ToSlash(pkgDir))
+	cmd.Dir = moduleDir
+
+	// Falha de teste retorna exit code != 0; a saída JSON é o que importa
+	output, runErr := cmd.Output()
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	passed, outputs := parseTestEvents(output)
+
+	failures := make(map[string]string)
+	for _, name := range names {
+		if passed[name] {
+			continue
+		}
+		out := outputs[name]
+		if out == "" {
+			// Sem eventos do teste: erro de compilação ou pacote inexistente no baseline
+			out = buildFailureOutput(output, runErr)
+		}
+		failures[name] = out
+	}
+
+	return failures, nil
+}
+
+// parseTestEvents lê a saída de go test -json e agrupa por teste de nível superior
+func parseTestEvents(output []byte) (map[string]bool, map[string]string) {
+	passed := make(map[string]bool)
+	outputs := make(map[string]string)
+
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var event testEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil || event.Test == "" {
+			continue
+		}
+
+		topLevel := strings.SplitN(event.Test, "/", 2)[0]
+		switch event.Action {
+		case "output":
+			outputs[topLevel] += event.Output
+		case "pass":
+			if event.Test == topLevel {
+				passed[topLevel] = true
+			}
+		}
+	}
+
+	return passed, outputs
+}
+
+// buildFailureOutput monta a mensagem de falha quando não há eventos de teste
+func buildFailureOutput(output []byte, runErr error) string {
+	var lines []string
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		var event testEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err == nil {
+			if event.Output != "" {
+				lines = append(lines, strings.TrimRight(event.Output, "\n"))
+			}
+			continue
+		}
+		lines = append(lines, scanner.Text())
+	}
+
+	if exitErr, ok := runErr.(*exec.ExitError); ok && len(exitErr.Stderr) > 0 {
+		lines = append(lines, strings.TrimSpace(string(exitErr.Stderr)))
+	}
+
+	if len(lines) == 0 && runErr != nil {
+		return runErr.Error()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// findModuleRoot sobe a partir de dir até encontrar um go.mod, sem passar de root
+func findModuleRoot(root, dir string) string {
+	for {
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return dir
+		}
+		if dir == root || dir == filepath.Dir(dir) {
+			return root
+		}
+		dir = filepath.Dir(dir)
+	}
+}
@@ -1,33 +1,45 @@
 package auth
 
 import (
+	"context"
+	"fmt"
+
 	"github.com/PHRaulino/phengineer/internal/infrastructure/auth/providers"
-	"github.com/PHRaulino/phengineer/internal/infrastructure/auth/storage"
 	"github.com/PHRaulino/phengineer/internal/infrastructure/auth/token"
+	"github.com/PHRaulino/phengineer/internal/infrastructure/config"
+	"github.com/PHRaulino/phengineer/internal/infrastructure/httpx"
 	"github.com/spf13/viper"
+	"go.uber.org/zap"
 )
 
-func SetupGenerators() {
+// SetupGenerators registra os geradores de token com as credenciais do perfil ativo e a
+// configuração do repositório do context
+func SetupGenerators(ctx context.Context) {
 	tokenService := token.GetService()
-	
-	// Inicializar storage (keyring por padrão)
-	authStorage := storage.NewKeyringAdapter()
+
+	// Inicializar storage (keyring do perfil ativo por padrão)
+	authStorage := profileStorage()
+	settings := projectAuthSettings(ctx)
+	if viper.GetString("auth.mode") != string(AuthModeService) {
+		// Tokens em cache também são separados por perfil
+		tokenService.SetStorage(authStorage)
+	}
 
 	// Provider para HashiCorp Vault + AWS
-	vaultProvider := providers.NewVaultProvider(authStorage)
+	vaultProvider := providers.NewVaultProvider(authStorage).WithIdM(settings.StackSpot)
 	tokenService.RegisterGenerator(token.TokenGenHC, func(scope token.TokenScope) (token.TokenResponse, error) {
-		return vaultProvider.GetToken(scope)
+		return vaultProvider.WithSettings(projectVaultSettings(ctx)).GetToken(scope)
 	})
 
 	// Provider para StackSpot
-	stackspotProvider := providers.NewStackSpotProvider(authStorage)
+	stackspotProvider := providers.NewStackSpotProvider(authStorage).WithSettings(settings.StackSpot)
 	tokenService.RegisterGenerator(token.TokenGenSTK, func(scope token.TokenScope) (token.TokenResponse, error) {
 		authMode := viper.GetString("auth.mode")
 		
 		switch authMode {
 		case "stackspot_service":
 			// Usar Vault para buscar credenciais
-			return vaultProvider.GetToken(scope)
+			return vaultProvider.WithSettings(projectVaultSettings(ctx)).GetToken(scope)
 		case "stackspot_user":
 			fallthrough
 		default:
@@ -35,28 +47,73 @@ func SetupGenerators() {
 			return stackspotProvider.GetToken(scope)
 		}
 	})
+	// Tokens do login pelo navegador são renovados com o refresh token antes de um novo login
+	tokenService.RegisterRefresher(token.TokenGenSTK, stackspotProvider.Refresh)
 
 	// Provider para GitHub
-	githubProvider := providers.NewGitHubProvider(authStorage)
+	githubProvider := withRepository(ctx, providers.NewGitHubProvider(authStorage).WithSettings(settings.GitHub))
 	tokenService.RegisterGenerator(token.TokenGenGH, func(scope token.TokenScope) (token.TokenResponse, error) {
+		// GitHub App: tokens de instalação curtos, com commits e comentários atribuídos ao bot
+		if githubProvider.HasApp() {
+			return githubProvider.AppToken(scope)
+		}
 		return githubProvider.GetToken(scope)
 	})
+	tokenService.RegisterRefresher(token.TokenGenGH, githubProvider.Refresh)
+}
+
+// ConfigureHTTP aplica a seção http do settings.yml (proxy, certificados, retentativas)
+// ao cliente compartilhado pelos provedores
+func ConfigureHTTP(ctx context.Context) error {
+	if err := httpx.Configure(httpx.FromSettings(projectSettings(ctx).HTTP)); err != nil {
+		return fmt.Errorf("configuração http inválida: %w", err)
+	}
+	return nil
+}
+
+// projectSettings resolve a configuração do repositório do context (usuário, settings.yml, PHENGINEER_* e flags)
+func projectSettings(ctx context.Context) config.Settings {
+	layered, err := config.LoadLayered(ctx, ".phengineer")
+	if err != nil {
+		zap.L().Warn("failed to load project settings, using defaults", zap.Error(err))
+		return config.Settings{}
+	}
+	return *layered.Settings
+}
+
+// projectAuthSettings lê a seção auth do settings.yml do repositório do context, se existir
+func projectAuthSettings(ctx context.Context) config.Auth {
+	return projectSettings(ctx).Auth
+}
+
+// projectVaultSettings lê a seção auth.vault do settings.yml do repositório do context, se existir
+func projectVaultSettings(ctx context.Context) config.VaultSettings {
+	return projectAuthSettings(ctx).Vault
 }
 
 // GetStackSpotProvider retorna uma instância do provider StackSpot
-func GetStackSpotProvider() *providers.StackSpotProvider {
-	authStorage := storage.NewKeyringAdapter()
-	return providers.NewStackSpotProvider(authStorage)
+func GetStackSpotProvider(ctx context.Context) *providers.StackSpotProvider {
+	return providers.NewStackSpotProvider(profileStorage()).WithSettings(projectAuthSettings(ctx).StackSpot)
 }
 
 // GetVaultProvider retorna uma instância do provider Vault
-func GetVaultProvider() *providers.VaultProvider {
-	authStorage := storage.NewKeyringAdapter()
-	return providers.NewVaultProvider(authStorage)
+func GetVaultProvider(ctx context.Context) *providers.VaultProvider {
+	return providers.NewVaultProvider(profileStorage()).WithIdM(projectAuthSettings(ctx).StackSpot)
 }
 
 // GetGitHubProvider retorna uma instância do provider GitHub
-func GetGitHubProvider() *providers.GitHubProvider {
-	authStorage := storage.NewKeyringAdapter()
-	return providers.NewGitHubProvider(authStorage)
+func GetGitHubProvider(ctx context.Context) *providers.GitHubProvider {
+	return withRepository(ctx, providers.NewGitHubProvider(profileStorage()).WithSettings(projectAuthSettings(ctx).GitHub))
+}
+
+// withRepository aplica o remote origin do repositório do context, em que a instalação do
+// GitHub App é descoberta e as permissões do token são verificadas. Sem remote, o provider
+// guarda o erro do requirement para devolvê-lo quando a instalação for necessária.
+func withRepository(ctx context.Context, provider *providers.GitHubProvider) *providers.GitHubProvider {
+	remoteURL, err := config.RemoteURL(ctx)
+	if err != nil {
+		zap.L().Debug("remote origin not found", zap.Error(err))
+		return provider.WithRepositoryError(err)
+	}
+	return provider.WithRepository(remoteURL)
 }
@@ -0,0 +1,95 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// expiryWindow antecedência com que credenciais temporárias são renovadas
+const expiryWindow = 5 * time.Minute
+
+// ErrNoCredentials indica que a fonte não está configurada no ambiente
+var ErrNoCredentials = errors.New("credenciais AWS não encontradas")
+
+// Credentials credenciais AWS usadas na assinatura SigV4
+type Credentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	Expires         time.Time // Zero para credenciais estáticas
+	Source          string
+}
+
+// Expired indica se as credenciais expiram dentro da janela de renovação
+func (c Credentials) Expired(now time.Time) bool {
+	return !c.Expires.IsZero() && now.Add(expiryWindow).After(c.Expires)
+}
+
+// Provider fonte de credenciais AWS
+type Provider interface {
+	Name() string
+	Retrieve(ctx context.Context) (Credentials, error)
+}
+
+// Chain percorre as fontes em ordem e usa a primeira configurada
+type Chain struct {
+	providers []Provider
+	mu        sync.Mutex
+	cached    *Credentials
+}
+
+// NewChain cria uma cadeia com as fontes informadas
+func NewChain(providers ...Provider) *Chain {
+	return &Chain{providers: providers}
+}
+
+// NewDefaultChain cria a cadeia padrão da AWS:
+// variáveis de ambiente, web identity, arquivos compartilhados, ECS e EC2
+func NewDefaultChain() *Chain {
+	return NewChain(
+		&EnvProvider{},
+		&WebIdentityProvider{},
+		&SharedConfigProvider{},
+		&ECSProvider{},
+		&EC2Provider{},
+	)
+}
+
+// Name implementa Provider
+func (c *Chain) Name() string {
+	return "chain"
+}
+
+// Retrieve retorna credenciais em cache ou busca na primeira fonte configurada
+func (c *Chain) Retrieve(ctx context.Context) (Credentials, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.cached != nil && !c.cached.Expired(time.Now()) {
+		return *c.cached, nil
+	}
+
+	tried := make([]string, 0, len(c.providers))
+	for _, provider := range c.providers {
+		creds, err := provider.Retrieve(ctx)
+		if errors.Is(err, ErrNoCredentials) {
+			tried = append(tried, provider.Name())
+			continue
+		}
+		if err != nil {
+			return Credentials{}, fmt.Errorf("erro ao obter credenciais AWS (%s): %w", provider.Name(), err)
+		}
+
+		if creds.Source == "" {
+			creds.Source = provider.Name()
+		}
+		c.cached = &creds
+		return creds, nil
+	}
+
+	return Credentials{}, fmt.Errorf("%w (fontes verificadas: %s)", ErrNoCredentials, strings.Join(tried, ", "))
+}
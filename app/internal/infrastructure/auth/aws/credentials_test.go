@@ -0,0 +1,323 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// clearAWSEnv remove as variáveis AWS do ambiente do teste
+func clearAWSEnv(t *testing.T) {
+	t.Helper()
+	for _, name := range []string{
+		"AWS_ACCESS_KEY_ID", "AWS_ACCESS_KEY", "AWS_SECRET_ACCESS_KEY", "AWS_SECRET_KEY", "AWS_SESSION_TOKEN",
+		"AWS_PROFILE", "AWS_DEFAULT_PROFILE", "AWS_REGION", "AWS_DEFAULT_REGION",
+		"AWS_WEB_IDENTITY_TOKEN_FILE", "AWS_ROLE_ARN", "AWS_ROLE_SESSION_NAME",
+		"AWS_CONTAINER_CREDENTIALS_RELATIVE_URI", "AWS_CONTAINER_CREDENTIALS_FULL_URI",
+		"AWS_CONTAINER_AUTHORIZATION_TOKEN", "AWS_CONTAINER_AUTHORIZATION_TOKEN_FILE",
+		"AWS_EC2_METADATA_DISABLED", "AWS_EC2_METADATA_SERVICE_ENDPOINT",
+	} {
+		t.Setenv(name, "")
+	}
+
+	dir := t.TempDir()
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(dir, "credentials"))
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(dir, "config"))
+}
+
+// TestEnvProvider testa a leitura das variáveis de ambiente
+func TestEnvProvider(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		wantErr error
+		partial bool
+	}{
+		{
+			name: "Static credentials with session token",
+			env:  map[string]string{"AWS_ACCESS_KEY_ID": "AKID", "AWS_SECRET_ACCESS_KEY": "secret", "AWS_SESSION_TOKEN": "session"},
+		},
+		{
+			name:    "Not configured",
+			env:     map[string]string{},
+			wantErr: ErrNoCredentials,
+		},
+		{
+			name:    "Missing secret",
+			env:     map[string]string{"AWS_ACCESS_KEY_ID": "AKID"},
+			partial: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearAWSEnv(t)
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			creds, err := (&EnvProvider{}).Retrieve(context.Background())
+			switch {
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("Expected %v, got %v", tt.wantErr, err)
+				}
+			case tt.partial:
+				if err == nil || errors.Is(err, ErrNoCredentials) {
+					t.Errorf("Expected configuration error, got %v", err)
+				}
+			default:
+				if err != nil {
+					t.Fatalf("Retrieve failed: %v", err)
+				}
+				if creds.AccessKeyID != "AKID" || creds.SessionToken != "session" {
+					t.Errorf("Unexpected credentials %+v", creds)
+				}
+			}
+		})
+	}
+}
+
+// TestSharedConfigProvider testa perfis nos arquivos credentials e config
+func TestSharedConfigProvider(t *testing.T) {
+	clearAWSEnv(t)
+
+	credentials := `[default]
+aws_access_key_id = AKIDDEFAULT
+aws_secret_access_key = secretdefault
+
+[work]
+aws_access_key_id = AKIDWORK
+aws_secret_access_key = secretwork
+aws_session_token = sessionwork
+`
+	config := `[default]
+region = us-west-2
+
+[profile work]
+region = sa-east-1
+`
+	writeFile(t, os.Getenv("AWS_SHARED_CREDENTIALS_FILE"), credentials)
+	writeFile(t, os.Getenv("AWS_CONFIG_FILE"), config)
+
+	tests := []struct {
+		name           string
+		profile        string
+		expectedKey    string
+		expectedRegion string
+		wantErr        error
+	}{
+		{name: "Default profile", expectedKey: "AKIDDEFAULT", expectedRegion: "us-west-2"},
+		{name: "Named profile", profile: "work", expectedKey: "AKIDWORK", expectedRegion: "sa-east-1"},
+		{name: "Unknown profile", profile: "missing", wantErr: ErrNoCredentials},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("AWS_PROFILE", tt.profile)
+			provider := &SharedConfigProvider{}
+
+			creds, err := provider.Retrieve(context.Background())
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("Expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Retrieve failed: %v", err)
+			}
+			if creds.AccessKeyID != tt.expectedKey {
+				t.Errorf("AccessKeyID = %s, expected %s", creds.AccessKeyID, tt.expectedKey)
+			}
+			if region := ResolveRegion(); region != tt.expectedRegion {
+				t.Errorf("ResolveRegion() = %s, expected %s", region, tt.expectedRegion)
+			}
+		})
+	}
+}
+
+// TestEC2Provider testa o IMDSv2 e o fallback para IMDSv1 em um endpoint de metadata falso
+func TestEC2Provider(t *testing.T) {
+	expiration := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+
+	tests := []struct {
+		name     string
+		imdsV2   bool
+		disabled bool
+		wantErr  error
+	}{
+		{name: "IMDSv2", imdsV2: true},
+		{name: "IMDSv1 fallback", imdsV2: false},
+		{name: "Disabled", disabled: true, wantErr: ErrNoCredentials},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearAWSEnv(t)
+			if tt.disabled {
+				t.Setenv("AWS_EC2_METADATA_DISABLED", "true")
+			}
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/latest/api/token" {
+					if r.Method != http.MethodPut || !tt.imdsV2 {
+						w.WriteHeader(http.StatusForbidden)
+						return
+					}
+					fmt.Fprint(w, "imds-token")
+					return
+				}
+
+				if tt.imdsV2 && r.Header.Get("X-aws-ec2-metadata-token") != "imds-token" {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+
+				switch r.URL.Path {
+				case "/latest/meta-data/iam/security-credentials/":
+					fmt.Fprint(w, "instance-role\n")
+				case "/latest/meta-data/iam/security-credentials/instance-role":
+					fmt.Fprintf(w, `{"Code":"Success","AccessKeyId":"AKIDEC2","SecretAccessKey":"secret","Token":"token","Expiration":"%s"}`, expiration.Format(time.RFC3339))
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer server.Close()
+
+			creds, err := (&EC2Provider{Endpoint: server.URL}).Retrieve(context.Background())
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("Expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Retrieve failed: %v", err)
+			}
+			if creds.AccessKeyID != "AKIDEC2" || creds.SessionToken != "token" || !creds.Expires.Equal(expiration) {
+				t.Errorf("Unexpected credentials %+v", creds)
+			}
+		})
+	}
+}
+
+// TestECSProvider testa o endpoint de credenciais de containers com token de autorização
+func TestECSProvider(t *testing.T) {
+	clearAWSEnv(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "container-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, `{"AccessKeyId":"AKIDECS","SecretAccessKey":"secret","Token":"token","Expiration":"2099-01-01T00:00:00Z"}`)
+	}))
+	defer server.Close()
+
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	writeFile(t, tokenFile, "container-token\n")
+
+	t.Setenv("AWS_CONTAINER_CREDENTIALS_FULL_URI", server.URL+"/v2/credentials")
+	t.Setenv("AWS_CONTAINER_AUTHORIZATION_TOKEN_FILE", tokenFile)
+
+	creds, err := (&ECSProvider{}).Retrieve(context.Background())
+	if err != nil {
+		t.Fatalf("Retrieve failed: %v", err)
+	}
+	if creds.AccessKeyID != "AKIDECS" {
+		t.Errorf("Unexpected credentials %+v", creds)
+	}
+}
+
+// TestWebIdentityProvider testa a troca do token OIDC em um STS falso
+func TestWebIdentityProvider(t *testing.T) {
+	clearAWSEnv(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if r.Form.Get("Action") != "AssumeRoleWithWebIdentity" || r.Form.Get("WebIdentityToken") != "oidc-token" ||
+			r.Form.Get("RoleArn") != "arn:aws:iam::123456789012:role/ci" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		fmt.Fprint(w, `<AssumeRoleWithWebIdentityResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/">
+  <AssumeRoleWithWebIdentityResult>
+    <Credentials>
+      <AccessKeyId>AKIDWEB</AccessKeyId>
+      <SecretAccessKey>secret</SecretAccessKey>
+      <SessionToken>session</SessionToken>
+      <Expiration>2099-01-01T00:00:00Z</Expiration>
+    </Credentials>
+  </AssumeRoleWithWebIdentityResult>
+</AssumeRoleWithWebIdentityResponse>`)
+	}))
+	defer server.Close()
+
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	writeFile(t, tokenFile, "oidc-token")
+	t.Setenv("AWS_WEB_IDENTITY_TOKEN_FILE", tokenFile)
+	t.Setenv("AWS_ROLE_ARN", "arn:aws:iam::123456789012:role/ci")
+
+	creds, err := (&WebIdentityProvider{Endpoint: server.URL}).Retrieve(context.Background())
+	if err != nil {
+		t.Fatalf("Retrieve failed: %v", err)
+	}
+	if creds.AccessKeyID != "AKIDWEB" || creds.SessionToken != "session" || creds.Expires.IsZero() {
+		t.Errorf("Unexpected credentials %+v", creds)
+	}
+}
+
+// TestChain testa a ordem das fontes e o cache das credenciais
+func TestChain(t *testing.T) {
+	clearAWSEnv(t)
+
+	calls := 0
+	counting := providerFunc(func(ctx context.Context) (Credentials, error) {
+		calls++
+		return Credentials{AccessKeyID: "AKIDSTATIC", SecretAccessKey: "secret"}, nil
+	})
+	chain := NewChain(&EnvProvider{}, counting)
+
+	for i := 0; i < 2; i++ {
+		creds, err := chain.Retrieve(context.Background())
+		if err != nil {
+			t.Fatalf("Retrieve failed: %v", err)
+		}
+		if creds.AccessKeyID != "AKIDSTATIC" {
+			t.Errorf("Unexpected credentials %+v", creds)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("Expected cached credentials, provider called %d times", calls)
+	}
+
+	_, err := NewChain(&EnvProvider{}).Retrieve(context.Background())
+	if !errors.Is(err, ErrNoCredentials) || !strings.Contains(err.Error(), "env") {
+		t.Errorf("Expected ErrNoCredentials listing sources, got %v", err)
+	}
+}
+
+// providerFunc permite usar uma função como Provider nos testes
+type providerFunc func(ctx context.Context) (Credentials, error)
+
+func (f providerFunc) Name() string { return "func" }
+
+func (f providerFunc) Retrieve(ctx context.Context) (Credentials, error) { return f(ctx) }
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+}
@@ -0,0 +1,45 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"os"
+)
+
+// EnvProvider lê AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY e AWS_SESSION_TOKEN
+type EnvProvider struct{}
+
+// Name implementa Provider
+func (p *EnvProvider) Name() string {
+	return "env"
+}
+
+// Retrieve implementa Provider
+func (p *EnvProvider) Retrieve(ctx context.Context) (Credentials, error) {
+	accessKey := firstEnv("AWS_ACCESS_KEY_ID", "AWS_ACCESS_KEY")
+	secretKey := firstEnv("AWS_SECRET_ACCESS_KEY", "AWS_SECRET_KEY")
+
+	if accessKey == "" && secretKey == "" {
+		return Credentials{}, ErrNoCredentials
+	}
+	if accessKey == "" || secretKey == "" {
+		return Credentials{}, fmt.Errorf("AWS_ACCESS_KEY_ID e AWS_SECRET_ACCESS_KEY devem ser definidas juntas")
+	}
+
+	return Credentials{
+		AccessKeyID:     accessKey,
+		SecretAccessKey: secretKey,
+		SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		Source:          p.Name(),
+	}, nil
+}
+
+// firstEnv retorna a primeira variável de ambiente definida
+func firstEnv(names ...string) string {
+	for _, name := range names {
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+	}
+	return ""
+}
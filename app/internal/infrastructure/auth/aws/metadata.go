@@ -0,0 +1,230 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	// ecsMetadataHost host do endpoint de credenciais de containers ECS
+	ecsMetadataHost = "http://169.254.170.2"
+	// ec2MetadataEndpoint endpoint padrão do IMDS
+	ec2MetadataEndpoint = "http://169.254.169.254"
+	// ec2TokenTTL validade do token de sessão do IMDSv2
+	ec2TokenTTL = "21600"
+)
+
+// metadataCredentials resposta dos endpoints de credenciais do ECS e do EC2
+type metadataCredentials struct {
+	Code            string    `json:"Code,omitempty"`
+	Message         string    `json:"Message,omitempty"`
+	AccessKeyID     string    `json:"AccessKeyId"`
+	SecretAccessKey string    `json:"SecretAccessKey"`
+	Token           string    `json:"Token"`
+	Expiration      time.Time `json:"Expiration"`
+}
+
+// toCredentials valida a resposta e converte para Credentials
+func (m metadataCredentials) toCredentials(source string) (Credentials, error) {
+	if m.Code != "" && m.Code != "Success" {
+		return Credentials{}, fmt.Errorf("metadata retornou %s: %s", m.Code, m.Message)
+	}
+	if m.AccessKeyID == "" || m.SecretAccessKey == "" {
+		return Credentials{}, fmt.Errorf("metadata retornou credenciais vazias")
+	}
+	return Credentials{
+		AccessKeyID:     m.AccessKeyID,
+		SecretAccessKey: m.SecretAccessKey,
+		SessionToken:    m.Token,
+		Expires:         m.Expiration,
+		Source:          source,
+	}, nil
+}
+
+// ECSProvider lê credenciais do endpoint de containers (ECS, Fargate, EKS Pod Identity)
+type ECSProvider struct {
+	Host   string // Padrão: http://169.254.170.2, usado com a URI relativa
+	Client *http.Client
+}
+
+// Name implementa Provider
+func (p *ECSProvider) Name() string {
+	return "ecs"
+}
+
+// Retrieve implementa Provider
+func (p *ECSProvider) Retrieve(ctx context.Context) (Credentials, error) {
+	endpoint := os.Getenv("AWS_CONTAINER_CREDENTIALS_FULL_URI")
+	if relative := os.Getenv("AWS_CONTAINER_CREDENTIALS_RELATIVE_URI"); relative != "" {
+		host := p.Host
+		if host == "" {
+			host = ecsMetadataHost
+		}
+		endpoint = strings.TrimSuffix(host, "/") + relative
+	}
+	if endpoint == "" {
+		return Credentials{}, ErrNoCredentials
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("erro ao criar requisição de metadata: %w", err)
+	}
+
+	authToken := os.Getenv("AWS_CONTAINER_AUTHORIZATION_TOKEN")
+	if tokenFile := os.Getenv("AWS_CONTAINER_AUTHORIZATION_TOKEN_FILE"); tokenFile != "" {
+		data, err := os.ReadFile(tokenFile)
+		if err != nil {
+			return Credentials{}, fmt.Errorf("erro ao ler token de autorização do container: %w", err)
+		}
+		authToken = strings.TrimSpace(string(data))
+	}
+	if authToken != "" {
+		req.Header.Set("Authorization", authToken)
+	}
+
+	body, err := doMetadata(p.client(), req)
+	if err != nil {
+		return Credentials{}, err
+	}
+
+	var creds metadataCredentials
+	if err := json.Unmarshal(body, &creds); err != nil {
+		return Credentials{}, fmt.Errorf("erro ao decodificar credenciais do container: %w", err)
+	}
+	return creds.toCredentials(p.Name())
+}
+
+func (p *ECSProvider) client() *http.Client {
+	if p.Client != nil {
+		return p.Client
+	}
+	return &http.Client{Timeout: 5 * time.Second}
+}
+
+// EC2Provider lê credenciais do perfil de instância via IMDSv2, com fallback para IMDSv1
+type EC2Provider struct {
+	Endpoint string // Padrão: AWS_EC2_METADATA_SERVICE_ENDPOINT ou http://169.254.169.254
+	Client   *http.Client
+}
+
+// Name implementa Provider
+func (p *EC2Provider) Name() string {
+	return "ec2"
+}
+
+// Retrieve implementa Provider
+func (p *EC2Provider) Retrieve(ctx context.Context) (Credentials, error) {
+	if strings.EqualFold(os.Getenv("AWS_EC2_METADATA_DISABLED"), "true") {
+		return Credentials{}, ErrNoCredentials
+	}
+
+	endpoint := p.Endpoint
+	if endpoint == "" {
+		endpoint = firstEnv("AWS_EC2_METADATA_SERVICE_ENDPOINT")
+	}
+	if endpoint == "" {
+		endpoint = ec2MetadataEndpoint
+	}
+	endpoint = strings.TrimSuffix(endpoint, "/")
+
+	client := p.client()
+
+	// Fora do EC2 o IMDS não responde: a fonte não se aplica
+	sessionToken, err := p.sessionToken(ctx, client, endpoint)
+	if err != nil {
+		return Credentials{}, ErrNoCredentials
+	}
+
+	rolesURL := endpoint + "/latest/meta-data/iam/security-credentials/"
+	roles, err := p.get(ctx, client, rolesURL, sessionToken)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("erro ao listar perfil de instância: %w", err)
+	}
+
+	role := strings.TrimSpace(strings.SplitN(string(roles), "\n", 2)[0])
+	if role == "" {
+		return Credentials{}, ErrNoCredentials
+	}
+
+	body, err := p.get(ctx, client, rolesURL+role, sessionToken)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("erro ao buscar credenciais do perfil %s: %w", role, err)
+	}
+
+	var creds metadataCredentials
+	if err := json.Unmarshal(body, &creds); err != nil {
+		return Credentials{}, fmt.Errorf("erro ao decodificar credenciais do EC2: %w", err)
+	}
+	return creds.toCredentials(p.Name())
+}
+
+// sessionToken obtém o token do IMDSv2. Retorna vazio se o IMDS só aceitar IMDSv1.
+func (p *EC2Provider) sessionToken(ctx context.Context, client *http.Client, endpoint string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, endpoint+"/latest/api/token", nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-aws-ec2-metadata-token-ttl-seconds", ec2TokenTTL)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(string(body)), nil
+	case http.StatusForbidden, http.StatusNotFound, http.StatusMethodNotAllowed:
+		return "", nil
+	default:
+		return "", fmt.Errorf("IMDS retornou status %d", resp.StatusCode)
+	}
+}
+
+func (p *EC2Provider) get(ctx context.Context, client *http.Client, url, sessionToken string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if sessionToken != "" {
+		req.Header.Set("X-aws-ec2-metadata-token", sessionToken)
+	}
+	return doMetadata(client, req)
+}
+
+func (p *EC2Provider) client() *http.Client {
+	if p.Client != nil {
+		return p.Client
+	}
+	return &http.Client{Timeout: 2 * time.Second}
+}
+
+// doMetadata executa a requisição e exige status 200
+func doMetadata(client *http.Client, req *http.Request) ([]byte, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("erro na requisição de metadata: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler resposta de metadata: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("metadata retornou status %d", resp.StatusCode)
+	}
+	return body, nil
+}
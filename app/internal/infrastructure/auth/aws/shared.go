@@ -0,0 +1,206 @@
+package aws
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultRegion região usada quando nenhuma é configurada
+const DefaultRegion = "us-east-1"
+
+// SharedConfigProvider lê ~/.aws/credentials e ~/.aws/config do perfil ativo
+type SharedConfigProvider struct {
+	CredentialsFile string // Padrão: AWS_SHARED_CREDENTIALS_FILE ou ~/.aws/credentials
+	ConfigFile      string // Padrão: AWS_CONFIG_FILE ou ~/.aws/config
+	Profile         string // Padrão: AWS_PROFILE ou "default"
+}
+
+// Name implementa Provider
+func (p *SharedConfigProvider) Name() string {
+	return "shared-config"
+}
+
+// Retrieve implementa Provider
+func (p *SharedConfigProvider) Retrieve(ctx context.Context) (Credentials, error) {
+	profile := p.profile()
+	values, err := p.profileValues(profile)
+	if err != nil {
+		return Credentials{}, err
+	}
+	if values == nil {
+		return Credentials{}, ErrNoCredentials
+	}
+
+	if values["aws_access_key_id"] != "" || values["aws_secret_access_key"] != "" {
+		if values["aws_access_key_id"] == "" || values["aws_secret_access_key"] == "" {
+			return Credentials{}, fmt.Errorf("perfil '%s' incompleto: aws_access_key_id e aws_secret_access_key são obrigatórios", profile)
+		}
+		return Credentials{
+			AccessKeyID:     values["aws_access_key_id"],
+			SecretAccessKey: values["aws_secret_access_key"],
+			SessionToken:    values["aws_session_token"],
+			Source:          p.Name() + ":" + profile,
+		}, nil
+	}
+
+	// Perfis com web identity delegam para o WebIdentityProvider
+	if values["web_identity_token_file"] != "" && values["role_arn"] != "" {
+		webIdentity := &WebIdentityProvider{
+			TokenFile:   expandHome(values["web_identity_token_file"]),
+			RoleARN:     values["role_arn"],
+			SessionName: values["role_session_name"],
+			Region:      values["region"],
+		}
+		return webIdentity.Retrieve(ctx)
+	}
+
+	if values["role_arn"] != "" {
+		return Credentials{}, fmt.Errorf("perfil '%s' usa role_arn com source_profile, que não é suportado; use credenciais estáticas ou web identity", profile)
+	}
+
+	return Credentials{}, ErrNoCredentials
+}
+
+// Region retorna a região configurada no perfil ativo
+func (p *SharedConfigProvider) Region() string {
+	values, err := p.profileValues(p.profile())
+	if err != nil || values == nil {
+		return ""
+	}
+	return values["region"]
+}
+
+func (p *SharedConfigProvider) profile() string {
+	if p.Profile != "" {
+		return p.Profile
+	}
+	if profile := firstEnv("AWS_PROFILE", "AWS_DEFAULT_PROFILE"); profile != "" {
+		return profile
+	}
+	return "default"
+}
+
+// profileValues mescla o perfil do arquivo de credenciais com o do arquivo de config.
+// Retorna nil se o perfil não existir em nenhum dos arquivos.
+func (p *SharedConfigProvider) profileValues(profile string) (map[string]string, error) {
+	credentialsFile := p.CredentialsFile
+	if credentialsFile == "" {
+		credentialsFile = firstEnv("AWS_SHARED_CREDENTIALS_FILE")
+	}
+	if credentialsFile == "" {
+		credentialsFile = filepath.Join("~", ".aws", "credentials")
+	}
+
+	configFile := p.ConfigFile
+	if configFile == "" {
+		configFile = firstEnv("AWS_CONFIG_FILE")
+	}
+	if configFile == "" {
+		configFile = filepath.Join("~", ".aws", "config")
+	}
+
+	var values map[string]string
+
+	// No arquivo de config perfis não padrão usam o prefixo "profile "
+	configSection := profile
+	if profile != "default" {
+		configSection = "profile " + profile
+	}
+
+	sources := []struct {
+		path    string
+		section string
+	}{
+		{expandHome(configFile), configSection},
+		{expandHome(credentialsFile), profile},
+	}
+
+	for _, source := range sources {
+		sections, err := parseINI(source.path)
+		if err != nil {
+			return nil, err
+		}
+		section, ok := sections[source.section]
+		if !ok {
+			continue
+		}
+		if values == nil {
+			values = make(map[string]string)
+		}
+		// O arquivo de credenciais tem precedência sobre o de config
+		for key, value := range section {
+			values[key] = value
+		}
+	}
+
+	return values, nil
+}
+
+// ResolveRegion retorna a região de AWS_REGION, AWS_DEFAULT_REGION, do perfil ativo ou o padrão
+func ResolveRegion() string {
+	if region := firstEnv("AWS_REGION", "AWS_DEFAULT_REGION"); region != "" {
+		return region
+	}
+	if region := (&SharedConfigProvider{}).Region(); region != "" {
+		return region
+	}
+	return DefaultRegion
+}
+
+// parseINI lê um arquivo INI no formato dos arquivos compartilhados da AWS
+func parseINI(path string) (map[string]map[string]string, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return map[string]map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao abrir %s: %w", path, err)
+	}
+	defer file.Close()
+
+	sections := make(map[string]map[string]string)
+	var current map[string]string
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			name := strings.Join(strings.Fields(line[1:len(line)-1]), " ")
+			current = make(map[string]string)
+			sections[name] = current
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok || current == nil {
+			continue
+		}
+		current[strings.ToLower(strings.TrimSpace(key))] = strings.TrimSpace(value)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao ler %s: %w", path, err)
+	}
+	return sections, nil
+}
+
+// expandHome expande o prefixo ~ para o diretório do usuário
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") && !strings.HasPrefix(path, "~"+string(filepath.Separator)) {
+		return path
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[1:])
+}
@@ -0,0 +1,113 @@
+package aws
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestSign testa a assinatura com os vetores da suíte oficial de testes do SigV4
+func TestSign(t *testing.T) {
+	creds := Credentials{
+		AccessKeyID:     "AKIDEXAMPLE",
+		SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+	}
+	now := time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+
+	tests := []struct {
+		name              string
+		method            string
+		url               string
+		expectedSignature string
+	}{
+		{
+			name:              "get-vanilla",
+			method:            http.MethodGet,
+			url:               "https://example.amazonaws.com/",
+			expectedSignature: "5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+		},
+		{
+			name:              "post-vanilla",
+			method:            http.MethodPost,
+			url:               "https://example.amazonaws.com/",
+			expectedSignature: "5da7c1a2acd57cee7505fc6676e4e544621c30862966e37dddb68e92efbe5d6b",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, tt.url, nil)
+			if err != nil {
+				t.Fatalf("Failed to create request: %v", err)
+			}
+
+			if err := Sign(req, nil, creds, "us-east-1", "service", now); err != nil {
+				t.Fatalf("Sign failed: %v", err)
+			}
+
+			expected := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, " +
+				"SignedHeaders=host;x-amz-date, Signature=" + tt.expectedSignature
+			if got := req.Header.Get("Authorization"); got != expected {
+				t.Errorf("Authorization = %q, expected %q", got, expected)
+			}
+		})
+	}
+}
+
+// TestGetCallerIdentityRequest testa a assinatura com session token e server ID
+func TestGetCallerIdentityRequest(t *testing.T) {
+	creds := Credentials{AccessKeyID: "AKID", SecretAccessKey: "secret", SessionToken: "session"}
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		region        string
+		headers       map[string]string
+		expectedURL   string
+		expectedScope string
+		signed        string
+	}{
+		{
+			name:          "Global endpoint signs in us-east-1",
+			expectedURL:   "https://sts.amazonaws.com/",
+			expectedScope: "AKID/20240102/us-east-1/sts/aws4_request",
+			signed:        "SignedHeaders=content-type;host;x-amz-date;x-amz-security-token,",
+		},
+		{
+			name:          "Regional endpoint with server ID",
+			region:        "sa-east-1",
+			headers:       map[string]string{"X-Vault-AWS-IAM-Server-ID": "vault.example.com"},
+			expectedURL:   "https://sts.sa-east-1.amazonaws.com/",
+			expectedScope: "AKID/20240102/sa-east-1/sts/aws4_request",
+			signed:        "SignedHeaders=content-type;host;x-amz-date;x-amz-security-token;x-vault-aws-iam-server-id,",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, body, err := GetCallerIdentityRequest(creds, tt.region, tt.headers, now)
+			if err != nil {
+				t.Fatalf("GetCallerIdentityRequest failed: %v", err)
+			}
+
+			if req.URL.String() != tt.expectedURL {
+				t.Errorf("URL = %s, expected %s", req.URL, tt.expectedURL)
+			}
+			if string(body) != "Action=GetCallerIdentity&Version=2011-06-15" {
+				t.Errorf("Unexpected body %q", body)
+			}
+			if req.Header.Get("X-Amz-Security-Token") != "session" {
+				t.Error("Expected X-Amz-Security-Token header")
+			}
+
+			authorization := req.Header.Get("Authorization")
+			if !strings.Contains(authorization, "Credential="+tt.expectedScope) {
+				t.Errorf("Expected scope %s in %q", tt.expectedScope, authorization)
+			}
+			if !strings.Contains(authorization, tt.signed) {
+				t.Errorf("Expected %s in %q", tt.signed, authorization)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package aws
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// getCallerIdentityBody corpo da chamada sts:GetCallerIdentity
+const getCallerIdentityBody = "Action=GetCallerIdentity&Version=" + stsAPIVersion
+
+// GetCallerIdentityRequest cria e assina uma requisição sts:GetCallerIdentity sem enviá-la.
+// Com região vazia usa o endpoint global assinado em us-east-1. Os cabeçalhos
+// extras (como X-Vault-AWS-IAM-Server-ID) entram na assinatura.
+func GetCallerIdentityRequest(creds Credentials, region string, headers map[string]string, now time.Time) (*http.Request, []byte, error) {
+	body := []byte(getCallerIdentityBody)
+
+	req, err := http.NewRequest(http.MethodPost, STSEndpoint(region), bytes.NewReader(body))
+	if err != nil {
+		return nil, nil, fmt.Errorf("erro ao criar requisição GetCallerIdentity: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+	for name, value := range headers {
+		if value != "" {
+			req.Header.Set(name, value)
+		}
+	}
+
+	signingRegion := region
+	if signingRegion == "" {
+		signingRegion = DefaultRegion
+	}
+	if err := Sign(req, body, creds, signingRegion, "sts", now); err != nil {
+		return nil, nil, err
+	}
+
+	return req, body, nil
+}
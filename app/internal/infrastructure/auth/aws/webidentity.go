@@ -0,0 +1,140 @@
+package aws
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/PHRaulino/phengineer/internal/infrastructure/httpx"
+)
+
+// stsAPIVersion versão da API do STS
+const stsAPIVersion = "2011-06-15"
+
+// WebIdentityProvider troca um token OIDC por credenciais via sts:AssumeRoleWithWebIdentity
+type WebIdentityProvider struct {
+	TokenFile   string // Padrão: AWS_WEB_IDENTITY_TOKEN_FILE
+	RoleARN     string // Padrão: AWS_ROLE_ARN
+	SessionName string // Padrão: AWS_ROLE_SESSION_NAME
+	Region      string // Padrão: ResolveRegion()
+	Endpoint    string // Padrão: endpoint regional do STS
+	Client      *http.Client
+}
+
+// assumeRoleWithWebIdentityResponse resposta XML do STS
+type assumeRoleWithWebIdentityResponse struct {
+	Result struct {
+		Credentials struct {
+			AccessKeyID     string    `xml:"AccessKeyId"`
+			SecretAccessKey string    `xml:"SecretAccessKey"`
+			SessionToken    string    `xml:"SessionToken"`
+			Expiration      time.Time `xml:"Expiration"`
+		} `xml:"Credentials"`
+	} `xml:"AssumeRoleWithWebIdentityResult"`
+}
+
+// Name implementa Provider
+func (p *WebIdentityProvider) Name() string {
+	return "web-identity"
+}
+
+// Retrieve implementa Provider
+func (p *WebIdentityProvider) Retrieve(ctx context.Context) (Credentials, error) {
+	tokenFile := p.TokenFile
+	if tokenFile == "" {
+		tokenFile = os.Getenv("AWS_WEB_IDENTITY_TOKEN_FILE")
+	}
+	roleARN := p.RoleARN
+	if roleARN == "" {
+		roleARN = os.Getenv("AWS_ROLE_ARN")
+	}
+	if tokenFile == "" || roleARN == "" {
+		return Credentials{}, ErrNoCredentials
+	}
+
+	webToken, err := os.ReadFile(tokenFile)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("erro ao ler token de web identity: %w", err)
+	}
+
+	sessionName := p.SessionName
+	if sessionName == "" {
+		sessionName = os.Getenv("AWS_ROLE_SESSION_NAME")
+	}
+	if sessionName == "" {
+		sessionName = fmt.Sprintf("phengineer-%d", time.Now().Unix())
+	}
+
+	endpoint := p.Endpoint
+	if endpoint == "" {
+		region := p.Region
+		if region == "" {
+			region = ResolveRegion()
+		}
+		endpoint = STSEndpoint(region)
+	}
+
+	form := url.Values{}
+	form.Set("Action", "AssumeRoleWithWebIdentity")
+	form.Set("Version", stsAPIVersion)
+	form.Set("RoleArn", roleARN)
+	form.Set("RoleSessionName", sessionName)
+	form.Set("WebIdentityToken", strings.TrimSpace(string(webToken)))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return Credentials{}, fmt.Errorf("erro ao criar requisição STS: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+
+	client := p.Client
+	if client == nil {
+		client = httpx.Default()
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("erro na requisição STS: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("erro ao ler resposta STS: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return Credentials{}, fmt.Errorf("AssumeRoleWithWebIdentity falhou: status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var result assumeRoleWithWebIdentityResponse
+	if err := xml.Unmarshal(body, &result); err != nil {
+		return Credentials{}, fmt.Errorf("erro ao decodificar resposta STS: %w", err)
+	}
+
+	creds := result.Result.Credentials
+	if creds.AccessKeyID == "" || creds.SecretAccessKey == "" {
+		return Credentials{}, fmt.Errorf("resposta STS sem credenciais")
+	}
+
+	return Credentials{
+		AccessKeyID:     creds.AccessKeyID,
+		SecretAccessKey: creds.SecretAccessKey,
+		SessionToken:    creds.SessionToken,
+		Expires:         creds.Expiration,
+		Source:          p.Name(),
+	}, nil
+}
+
+// STSEndpoint retorna o endpoint regional do STS
+func STSEndpoint(region string) string {
+	if region == "" {
+		return "https://sts.amazonaws.com/"
+	}
+	return fmt.Sprintf("https://sts.%s.amazonaws.com/", region)
+}
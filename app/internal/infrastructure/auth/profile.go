@@ -0,0 +1,113 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"os"
+
+	"github.com/PHRaulino/phengineer/internal/infrastructure/auth/storage"
+	"github.com/PHRaulino/phengineer/internal/infrastructure/config"
+	"go.uber.org/zap"
+)
+
+// ProfileEnv variável de ambiente que seleciona o perfil de credenciais
+const ProfileEnv = "PHENGINEER_PROFILE"
+
+// Origens possíveis do perfil ativo
+const (
+	ProfileFromFlag     = "flag --profile"
+	ProfileFromEnv      = ProfileEnv
+	ProfileFromSettings = "settings.yml (auth.profile)"
+	ProfileFromUser     = "auth use"
+	ProfileFromDefault  = "padrão"
+)
+
+var (
+	activeProfile       = storage.DefaultProfile
+	activeProfileSource = ProfileFromDefault
+)
+
+// ResolveProfile escolhe o perfil ativo: flag, variável de ambiente, vínculo do
+// repositório no settings.yml e, por fim, o padrão definido com `auth use`
+func ResolveProfile(ctx context.Context, flag string) (profile string, source string) {
+	if flag != "" {
+		return flag, ProfileFromFlag
+	}
+	if env := os.Getenv(ProfileEnv); env != "" {
+		return env, ProfileFromEnv
+	}
+	if bound, source := projectProfile(ctx); bound != "" {
+		return bound, source
+	}
+	if profile := Profiles().Default(); profile != storage.DefaultProfile {
+		return profile, ProfileFromUser
+	}
+	return storage.DefaultProfile, ProfileFromDefault
+}
+
+// UseProfile define o perfil usado pelos providers e pelo cache de tokens e a origem da escolha
+func UseProfile(profile, source string) error {
+	if err := storage.ValidateProfileName(profile); err != nil {
+		return err
+	}
+	activeProfile = profile
+	activeProfileSource = source
+	return nil
+}
+
+// ActiveProfile retorna o perfil em uso
+func ActiveProfile() string {
+	return activeProfile
+}
+
+// ActiveProfileSource retorna de onde veio a escolha do perfil em uso
+func ActiveProfileSource() string {
+	return activeProfileSource
+}
+
+// Profiles retorna o registro de perfis salvo no storage do processo
+func Profiles() *storage.ProfileRegistry {
+	base, _, _ := storage.Default()
+	return storage.NewProfileRegistry(base)
+}
+
+// StorageBackend retorna o backend de credenciais selecionado e o erro de seleção, se houver
+func StorageBackend() (storage.Backend, error) {
+	_, backend, err := storage.Default()
+	return backend, err
+}
+
+// ErrStorageNotPersistent o backend em memória perde as credenciais ao final do comando
+var ErrStorageNotPersistent = errors.New("armazenamento em memória: as credenciais não seriam salvas")
+
+// RequirePersistentStorage falha quando as credenciais não persistem entre execuções
+func RequirePersistentStorage() error {
+	backend, err := StorageBackend()
+	if err != nil {
+		return err
+	}
+	if backend == storage.BackendMemory {
+		return ErrStorageNotPersistent
+	}
+	return nil
+}
+
+// profileStorage storage isolado no perfil ativo
+func profileStorage() storage.StorageAdapter {
+	return Profiles().Adapter(activeProfile)
+}
+
+// projectProfile lê o perfil vinculado ao repositório do context e a camada que o definiu
+func projectProfile(ctx context.Context) (string, string) {
+	layered, err := config.LoadLayered(ctx, ".phengineer")
+	if err != nil {
+		zap.L().Debug("failed to load project settings, ignoring auth.profile", zap.Error(err))
+		return "", ""
+	}
+
+	origin := layered.Origins["auth.profile"]
+	if origin.Layer == config.LayerRepo {
+		return layered.Settings.Auth.Profile, ProfileFromSettings
+	}
+	return layered.Settings.Auth.Profile, "auth.profile (" + origin.String() + ")"
+}
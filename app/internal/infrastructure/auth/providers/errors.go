@@ -0,0 +1,140 @@
+package providers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/PHRaulino/phengineer/internal/infrastructure/httpx"
+)
+
+// Categorias de falha dos provedores; use errors.Is para identificar a causa
+var (
+	ErrInvalidCredentials = errors.New("credenciais inválidas")
+	ErrNetwork            = errors.New("falha de rede")
+	ErrRateLimited        = errors.New("limite de requisições excedido")
+	ErrPermission         = errors.New("permissão insuficiente")
+	ErrNotConfigured      = errors.New("credenciais não configuradas")
+)
+
+// Nomes dos provedores em ProviderError
+const (
+	ProviderStackSpot = "stackspot"
+	ProviderVault     = "vault"
+	ProviderGitHub    = "github"
+)
+
+// bodyExcerptLimit tamanho máximo do trecho da resposta guardado no erro
+const bodyExcerptLimit = 300
+
+// ProviderError falha de um provedor com a categoria, o status HTTP e um trecho sanitizado da resposta
+type ProviderError struct {
+	Provider string
+	Op       string // o que estava sendo feito, ex.: "autenticação"
+	Kind     error  // uma das categorias Err*; nil quando a falha não se encaixa em nenhuma
+	Status   int
+	Body     string
+	Err      error
+}
+
+func (e *ProviderError) Error() string {
+	msg := e.Provider + ": " + e.Op
+	if e.Kind != nil {
+		msg += ": " + e.Kind.Error()
+	}
+	if e.Status != 0 {
+		msg += fmt.Sprintf(" (status %d)", e.Status)
+	}
+	switch {
+	case e.Err != nil:
+		msg += ": " + e.Err.Error()
+	case e.Body != "":
+		msg += ": " + e.Body
+	}
+	return msg
+}
+
+func (e *ProviderError) Unwrap() []error {
+	var errs []error
+	if e.Kind != nil {
+		errs = append(errs, e.Kind)
+	}
+	if e.Err != nil {
+		errs = append(errs, e.Err)
+	}
+	return errs
+}
+
+// responseError classifica uma resposta de erro pelo status e guarda um trecho do corpo
+func responseError(provider, op string, resp *http.Response) *ProviderError {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+
+	kind := statusKind(resp)
+	// Servidores OAuth respondem 400 com o motivo no campo "error"
+	var oauthErr struct {
+		Error string `json:"error"`
+	}
+	if json.Unmarshal(body, &oauthErr) == nil {
+		if oauthKind := oauthErrorKind(oauthErr.Error); oauthKind != nil {
+			kind = oauthKind
+		}
+	}
+
+	return &ProviderError{
+		Provider: provider,
+		Op:       op,
+		Kind:     kind,
+		Status:   resp.StatusCode,
+		Body:     httpx.SanitizeBody(body, bodyExcerptLimit),
+	}
+}
+
+// networkError falha antes de haver resposta: DNS, conexão, TLS, proxy ou timeout
+func networkError(provider, op string, err error) *ProviderError {
+	return &ProviderError{Provider: provider, Op: op, Kind: ErrNetwork, Err: err}
+}
+
+// notConfiguredError credencial ou configuração ausente
+func notConfiguredError(provider, detail string) *ProviderError {
+	return &ProviderError{Provider: provider, Op: detail, Kind: ErrNotConfigured}
+}
+
+// statusKind mapeia o status HTTP para a categoria da falha
+func statusKind(resp *http.Response) error {
+	switch code := resp.StatusCode; {
+	case code == http.StatusUnauthorized:
+		return ErrInvalidCredentials
+	case code == http.StatusTooManyRequests,
+		code == http.StatusForbidden && resp.Header.Get("X-RateLimit-Remaining") == "0":
+		return ErrRateLimited
+	case code == http.StatusForbidden:
+		return ErrPermission
+	case code >= 500:
+		// O serviço não respondeu, mesmo após as retentativas do cliente HTTP
+		return ErrNetwork
+	}
+	return nil
+}
+
+// oauthErrorKind mapeia os códigos de erro OAuth (RFC 6749, seção 5.2) para a categoria da falha
+func oauthErrorKind(code string) error {
+	switch code {
+	case "invalid_client", "invalid_grant", "unauthorized_client":
+		return ErrInvalidCredentials
+	case "access_denied", "invalid_scope":
+		return ErrPermission
+	case "slow_down":
+		return ErrRateLimited
+	}
+	return nil
+}
+
+// vaultStatusKind o Vault recusa logins com 400 ou 403; fora do login, 403 é falta de policy
+func vaultStatusKind(status int, login bool) error {
+	if login && (status == http.StatusBadRequest || status == http.StatusForbidden) {
+		return ErrInvalidCredentials
+	}
+	return statusKind(&http.Response{StatusCode: status, Header: http.Header{}})
+}
@@ -0,0 +1,124 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/PHRaulino/phengineer/internal/infrastructure/auth/storage"
+	"github.com/PHRaulino/phengineer/internal/infrastructure/auth/token"
+)
+
+// TestProviderErrorKinds testa a classificação das respostas de erro e o trecho sanitizado do corpo
+func TestProviderErrorKinds(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		header   map[string]string
+		body     string
+		wantKind error
+		wantBody string
+		leak     string
+	}{
+		{
+			name:     "Invalid client",
+			status:   http.StatusUnauthorized,
+			body:     `{"error":"invalid_client","error_description":"Invalid client or Invalid client credentials"}`,
+			wantKind: ErrInvalidCredentials,
+			wantBody: "Invalid client credentials",
+		},
+		{
+			name:     "OAuth error on bad request",
+			status:   http.StatusBadRequest,
+			body:     `{"error":"unauthorized_client"}`,
+			wantKind: ErrInvalidCredentials,
+		},
+		{
+			name:     "Forbidden",
+			status:   http.StatusForbidden,
+			body:     `{"message":"Resource not accessible by integration"}`,
+			wantKind: ErrPermission,
+			wantBody: "not accessible",
+		},
+		{
+			name:     "Rate limited",
+			status:   http.StatusTooManyRequests,
+			header:   map[string]string{"Retry-After": "3600"},
+			wantKind: ErrRateLimited,
+		},
+		{
+			name:     "GitHub quota exhausted",
+			status:   http.StatusForbidden,
+			header:   map[string]string{"X-RateLimit-Remaining": "0"},
+			wantKind: ErrRateLimited,
+		},
+		{
+			name:     "Service unavailable",
+			status:   http.StatusBadGateway,
+			body:     "<html>bad gateway</html>",
+			wantKind: ErrNetwork,
+			wantBody: "bad gateway",
+		},
+		{
+			name:     "Secrets in body are redacted",
+			status:   http.StatusUnauthorized,
+			body:     `{"error":"invalid_grant","refresh_token":"rt-secret-value"}`,
+			wantKind: ErrInvalidCredentials,
+			leak:     "rt-secret-value",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				for key, value := range tt.header {
+					w.Header().Set(key, value)
+				}
+				w.WriteHeader(tt.status)
+				fmt.Fprint(w, tt.body)
+			}))
+			defer server.Close()
+
+			_, err := ExchangeStackSpotToken(context.Background(), server.Client(), server.URL, StackSpotCredentials{ClientID: "id", ClientSecret: "secret"}, token.ScopeExecution)
+
+			var providerErr *ProviderError
+			if !errors.As(err, &providerErr) {
+				t.Fatalf("error = %v, want *ProviderError", err)
+			}
+			if !errors.Is(err, tt.wantKind) {
+				t.Errorf("error = %v, want kind %v", err, tt.wantKind)
+			}
+			if providerErr.Provider != ProviderStackSpot || providerErr.Status != tt.status {
+				t.Errorf("provider/status = %s/%d, want %s/%d", providerErr.Provider, providerErr.Status, ProviderStackSpot, tt.status)
+			}
+			if tt.wantBody != "" && !strings.Contains(providerErr.Body, tt.wantBody) {
+				t.Errorf("Body = %q, want containing %q", providerErr.Body, tt.wantBody)
+			}
+			if tt.leak != "" && strings.Contains(err.Error(), tt.leak) {
+				t.Errorf("error %q leaks %q", err, tt.leak)
+			}
+		})
+	}
+}
+
+// TestProviderErrorNetwork testa falhas sem resposta e credenciais ausentes
+func TestProviderErrorNetwork(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL
+	server.Close()
+
+	_, err := ExchangeStackSpotToken(context.Background(), &http.Client{}, url, StackSpotCredentials{}, token.ScopeExecution)
+	if !errors.Is(err, ErrNetwork) {
+		t.Errorf("closed server error = %v, want ErrNetwork", err)
+	}
+
+	t.Setenv("GITHUB_TOKEN", "")
+	_, err = NewGitHubProvider(storage.NewMemoryAdapter()).StoredToken()
+	if !errors.Is(err, ErrNotConfigured) {
+		t.Errorf("StoredToken() error = %v, want ErrNotConfigured", err)
+	}
+}
@@ -1,24 +1,98 @@
 package providers
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"os"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/PHRaulino/phengineer/internal/infrastructure/auth/storage"
 	"github.com/PHRaulino/phengineer/internal/infrastructure/auth/token"
+	"github.com/PHRaulino/phengineer/internal/infrastructure/config"
+	"github.com/PHRaulino/phengineer/internal/infrastructure/httpx"
+)
+
+const (
+	GitHubURL    = "https://github.com"
+	GitHubAPIURL = "https://api.github.com"
+
+	// Escopos pedidos no login pelo navegador de um OAuth App
+	gitHubDeviceScope = "repo read:org"
 )
 
 type GitHubProvider struct {
-	storage storage.StorageAdapter
+	storage       storage.StorageAdapter
+	client        *http.Client
+	endpoints     oauthEndpoints
+	apiURL        string
+	oauthClientID string // OAuth App ou GitHub App do login pelo navegador
+	wait          func(ctx context.Context, d time.Duration) error
+
+	// GitHub App
+	app            config.GitHubAppSettings
+	repositoryURL  string // remote origin usado para descobrir a instalação
+	repositoryErr  error  // por que o remote não foi encontrado
+	mu             sync.Mutex
+	installationID int64 // instalação descoberta pelo repositório
+	now            func() time.Time
 }
 
 func NewGitHubProvider(storage storage.StorageAdapter) *GitHubProvider {
-	return &GitHubProvider{
+	return (&GitHubProvider{
 		storage: storage,
+		client:  httpx.Default(),
+		now:     time.Now,
+	}).WithSettings(config.GitHubSettings{})
+}
+
+// WithRepository define o remote do repositório em que a instalação do GitHub App é procurada
+func (p *GitHubProvider) WithRepository(remoteURL string) *GitHubProvider {
+	p.repositoryURL = remoteURL
+	return p
+}
+
+// WithRepositoryError registra por que o remote não foi encontrado; o erro é devolvido
+// quando a instalação do GitHub App precisa ser descoberta
+func (p *GitHubProvider) WithRepositoryError(err error) *GitHubProvider {
+	p.repositoryErr = err
+	return p
+}
+
+// WithSettings aplica os endereços do GitHub (ou GHES) e o app do login pelo navegador
+func (p *GitHubProvider) WithSettings(settings config.GitHubSettings) *GitHubProvider {
+	baseURL := strings.TrimSuffix(settings.URL, "/")
+	if baseURL == "" {
+		baseURL = GitHubURL
+	}
+
+	p.apiURL = strings.TrimSuffix(settings.APIURL, "/")
+	if p.apiURL == "" {
+		p.apiURL = GitHubAPIURL
+		if baseURL != GitHubURL {
+			// GitHub Enterprise Server expõe a API em /api/v3
+			p.apiURL = baseURL + "/api/v3"
+		}
+	}
+
+	p.endpoints = oauthEndpoints{
+		DeviceAuthorizationURL: baseURL + "/login/device/code",
+		TokenURL:               baseURL + "/login/oauth/access_token",
+	}
+	p.oauthClientID = settings.ClientID
+	p.app = settings.App
+	return p
+}
+
+// WithClientID sobrescreve o app do login pelo navegador; vazio mantém o configurado
+func (p *GitHubProvider) WithClientID(clientID string) *GitHubProvider {
+	if clientID != "" {
+		p.oauthClientID = clientID
 	}
+	return p
 }
 
 type GitHubUser struct {
@@ -29,19 +103,26 @@ type GitHubUser struct {
 }
 
 func (p *GitHubProvider) GetToken(scope token.TokenScope) (token.TokenResponse, error) {
+	// GitHub App com tokens que expiram: renovar com o refresh token do login pelo navegador
+	if refreshToken, err := p.storage.Get("github_refresh_token"); err == nil && refreshToken != "" {
+		tokenResponse, err := p.Refresh(scope, refreshToken)
+		if err == nil {
+			return tokenResponse, nil
+		}
+		if IsInvalidGrant(err) {
+			deleteKeys(p.storage, "github_refresh_token")
+		}
+	}
+
 	// Buscar token GitHub armazenado
-	githubToken, err := p.storage.Get("github_token")
+	githubToken, err := p.StoredToken()
 	if err != nil {
-		// Tentar variável de ambiente como fallback
-		githubToken = os.Getenv("GITHUB_TOKEN")
-		if githubToken == "" {
-			return token.TokenResponse{}, fmt.Errorf("github_token não encontrado: %w", err)
-		}
+		return token.TokenResponse{}, err
 	}
 
 	// Validar token fazendo uma requisição de teste
 	if err := p.validateToken(githubToken); err != nil {
-		return token.TokenResponse{}, fmt.Errorf("token GitHub inválido: %w", err)
+		return token.TokenResponse{}, err
 	}
 
 	// GitHub tokens geralmente não expiram (Personal Access Tokens)
@@ -53,9 +134,9 @@ func (p *GitHubProvider) GetToken(scope token.TokenScope) (token.TokenResponse,
 }
 
 func (p *GitHubProvider) validateToken(token string) error {
-	client := &http.Client{Timeout: 30 * time.Second}
-	
-	req, err := http.NewRequest("GET", "https://api.github.com/user", nil)
+	client := p.client
+
+	req, err := http.NewRequest("GET", p.apiURL+"/user", nil)
 	if err != nil {
 		return fmt.Errorf("erro ao criar requisição de validação: %w", err)
 	}
@@ -65,29 +146,38 @@ func (p *GitHubProvider) validateToken(token string) error {
 
 	resp, err := client.Do(req)
 	if err != nil {
-		return fmt.Errorf("erro na requisição de validação: %w", err)
+		return networkError(ProviderGitHub, "validação do token", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("token inválido: status %d", resp.StatusCode)
+		return responseError(ProviderGitHub, "validação do token", resp)
 	}
 
 	return nil
 }
 
-func (p *GitHubProvider) GetUser() (*GitHubUser, error) {
+// StoredToken retorna o token salvo no perfil ou, como fallback, GITHUB_TOKEN
+func (p *GitHubProvider) StoredToken() (string, error) {
 	githubToken, err := p.storage.Get("github_token")
 	if err != nil {
 		githubToken = os.Getenv("GITHUB_TOKEN")
 		if githubToken == "" {
-			return nil, fmt.Errorf("github_token não encontrado: %w", err)
+			return "", notConfiguredError(ProviderGitHub, "github_token não encontrado")
 		}
 	}
+	return githubToken, nil
+}
+
+func (p *GitHubProvider) GetUser() (*GitHubUser, error) {
+	githubToken, err := p.StoredToken()
+	if err != nil {
+		return nil, err
+	}
 
-	client := &http.Client{Timeout: 30 * time.Second}
-	
-	req, err := http.NewRequest("GET", "https://api.github.com/user", nil)
+	client := p.client
+
+	req, err := http.NewRequest("GET", p.apiURL+"/user", nil)
 	if err != nil {
 		return nil, fmt.Errorf("erro ao criar requisição: %w", err)
 	}
@@ -97,12 +187,12 @@ func (p *GitHubProvider) GetUser() (*GitHubUser, error) {
 
 	resp, err := client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("erro na requisição: %w", err)
+		return nil, networkError(ProviderGitHub, "busca do usuário", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("erro ao buscar usuário: status %d", resp.StatusCode)
+		return nil, responseError(ProviderGitHub, "busca do usuário", resp)
 	}
 
 	var user GitHubUser
@@ -116,7 +206,7 @@ func (p *GitHubProvider) GetUser() (*GitHubUser, error) {
 func (p *GitHubProvider) SaveToken(githubToken string) error {
 	// Validar token antes de salvar
 	if err := p.validateToken(githubToken); err != nil {
-		return fmt.Errorf("token inválido: %w", err)
+		return err
 	}
 
 	if err := p.storage.Set("github_token", githubToken); err != nil {
@@ -126,19 +216,86 @@ func (p *GitHubProvider) SaveToken(githubToken string) error {
 	return nil
 }
 
+// StartDeviceLogin inicia o login pelo navegador e retorna o código a ser exibido ao usuário
+func (p *GitHubProvider) StartDeviceLogin(ctx context.Context) (DeviceAuthorization, error) {
+	if p.oauthClientID == "" {
+		return DeviceAuthorization{}, notConfiguredError(ProviderGitHub, "app do login pelo navegador não configurado: defina auth.github.client_id ou use --client-id")
+	}
+	return p.oauth().requestDeviceCode(ctx, gitHubDeviceScope)
+}
+
+// CompleteDeviceLogin aguarda a autorização do usuário e salva o token (e o refresh token, em GitHub Apps)
+func (p *GitHubProvider) CompleteDeviceLogin(ctx context.Context, authorization DeviceAuthorization) error {
+	tokenResp, err := p.oauth().pollDeviceToken(ctx, authorization)
+	if err != nil {
+		return err
+	}
+	return p.saveOAuthToken(tokenResp, p.oauthClientID)
+}
+
+// Refresh troca o refresh token por um novo token de usuário
+func (p *GitHubProvider) Refresh(scope token.TokenScope, refreshToken string) (token.TokenResponse, error) {
+	client := p.oauth()
+	if stored, err := p.storage.Get("github_oauth_client_id"); err == nil && stored != "" {
+		client.clientID = stored
+	}
+
+	tokenResp, err := client.refresh(context.Background(), refreshToken)
+	if err != nil {
+		return token.TokenResponse{}, err
+	}
+	if err := p.saveOAuthToken(tokenResp, client.clientID); err != nil {
+		return token.TokenResponse{}, err
+	}
+	return tokenResp.tokenResponse(), nil
+}
+
+// saveOAuthToken salva o token emitido pelo GitHub; OAuth Apps não retornam refresh token
+func (p *GitHubProvider) saveOAuthToken(tokenResp oauthToken, clientID string) error {
+	if err := p.storage.Set("github_token", tokenResp.AccessToken); err != nil {
+		return fmt.Errorf("erro ao salvar github_token: %w", err)
+	}
+	if tokenResp.RefreshToken == "" {
+		return deleteKeys(p.storage, "github_refresh_token", "github_oauth_client_id")
+	}
+
+	if err := p.storage.Set("github_oauth_client_id", clientID); err != nil {
+		return fmt.Errorf("erro ao salvar client_id: %w", err)
+	}
+	if err := p.storage.Set("github_refresh_token", tokenResp.RefreshToken); err != nil {
+		return fmt.Errorf("erro ao salvar refresh token: %w", err)
+	}
+	return nil
+}
+
+func (p *GitHubProvider) oauth() *oauthClient {
+	client := newOAuthClient(ProviderGitHub, p.client, p.endpoints, p.oauthClientID)
+	if p.wait != nil {
+		client.wait = p.wait
+	}
+	return client
+}
+
+// DeleteToken remove o token salvo
+func (p *GitHubProvider) DeleteToken() error {
+	return deleteKeys(p.storage, "github_token", "github_refresh_token", "github_oauth_client_id")
+}
+
+// HasToken verifica se há token salvo ou em GITHUB_TOKEN
+func (p *GitHubProvider) HasToken() bool {
+	return p.storage.Exists("github_token") || os.Getenv("GITHUB_TOKEN") != ""
+}
+
 // ListRepositories lista repositórios do usuário
 func (p *GitHubProvider) ListRepositories() ([]map[string]interface{}, error) {
-	githubToken, err := p.storage.Get("github_token")
+	githubToken, err := p.StoredToken()
 	if err != nil {
-		githubToken = os.Getenv("GITHUB_TOKEN")
-		if githubToken == "" {
-			return nil, fmt.Errorf("github_token não encontrado: %w", err)
-		}
+		return nil, err
 	}
 
-	client := &http.Client{Timeout: 30 * time.Second}
-	
-	req, err := http.NewRequest("GET", "https://api.github.com/user/repos?sort=updated&per_page=100", nil)
+	client := p.client
+
+	req, err := http.NewRequest("GET", p.apiURL+"/user/repos?sort=updated&per_page=100", nil)
 	if err != nil {
 		return nil, fmt.Errorf("erro ao criar requisição: %w", err)
 	}
@@ -148,12 +305,12 @@ func (p *GitHubProvider) ListRepositories() ([]map[string]interface{}, error) {
 
 	resp, err := client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("erro na requisição: %w", err)
+		return nil, networkError(ProviderGitHub, "listagem de repositórios", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("erro ao listar repositórios: status %d", resp.StatusCode)
+		return nil, responseError(ProviderGitHub, "listagem de repositórios", resp)
 	}
 
 	var repos []map[string]interface{}
@@ -0,0 +1,302 @@
+package providers
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PHRaulino/phengineer/internal/infrastructure/auth/token"
+)
+
+const (
+	gitHubAPIVersion = "2022-11-28"
+
+	// O GitHub aceita JWTs de app com no máximo 10 minutos; iat recuado absorve diferença de relógio
+	gitHubAppJWTLifetime = 9 * time.Minute
+	gitHubAppClockSkew   = 60 * time.Second
+)
+
+// GitHubAppConfig credenciais de um GitHub App
+type GitHubAppConfig struct {
+	AppID          int64
+	InstallationID int64  // 0: descobre pela instalação no repositório do remote origin
+	PrivateKey     string // PEM salvo no storage ou em GITHUB_APP_PRIVATE_KEY
+	PrivateKeyFile string // usado quando PrivateKey está vazio
+}
+
+// ResolveAppConfig combina settings.yml, storage e variáveis de ambiente, nessa ordem de precedência
+func (p *GitHubProvider) ResolveAppConfig() GitHubAppConfig {
+	cfg := GitHubAppConfig{
+		AppID:          storedInt64(p.stored("github_app_id")),
+		InstallationID: storedInt64(p.stored("github_app_installation_id")),
+		PrivateKey:     p.stored("github_app_private_key"),
+		PrivateKeyFile: p.stored("github_app_private_key_file"),
+	}
+
+	settings := p.app
+	if settings.AppID != 0 {
+		cfg.AppID = settings.AppID
+	}
+	if settings.InstallationID != 0 {
+		cfg.InstallationID = settings.InstallationID
+	}
+	if settings.PrivateKeyFile != "" {
+		cfg.PrivateKeyFile = settings.PrivateKeyFile
+		cfg.PrivateKey = ""
+	}
+
+	if cfg.AppID == 0 {
+		cfg.AppID = storedInt64(os.Getenv("GITHUB_APP_ID"))
+	}
+	if cfg.InstallationID == 0 {
+		cfg.InstallationID = storedInt64(os.Getenv("GITHUB_APP_INSTALLATION_ID"))
+	}
+	if cfg.PrivateKey == "" && cfg.PrivateKeyFile == "" {
+		cfg.PrivateKey = os.Getenv("GITHUB_APP_PRIVATE_KEY")
+		cfg.PrivateKeyFile = os.Getenv("GITHUB_APP_PRIVATE_KEY_FILE")
+	}
+	return cfg
+}
+
+// HasApp verifica se há um GitHub App configurado
+func (p *GitHubProvider) HasApp() bool {
+	return p.ResolveAppConfig().AppID != 0
+}
+
+// SaveAppConfig valida a chave privada e salva a configuração do GitHub App.
+// Com privateKeyFile apenas o caminho é salvo; a chave continua no arquivo.
+func (p *GitHubProvider) SaveAppConfig(cfg GitHubAppConfig) error {
+	if cfg.AppID == 0 {
+		return fmt.Errorf("app_id do GitHub App é obrigatório")
+	}
+	if _, err := cfg.privateKey(); err != nil {
+		return err
+	}
+
+	values := map[string]string{
+		"github_app_id":               strconv.FormatInt(cfg.AppID, 10),
+		"github_app_installation_id":  "",
+		"github_app_private_key":      cfg.PrivateKey,
+		"github_app_private_key_file": "",
+	}
+	if cfg.InstallationID != 0 {
+		values["github_app_installation_id"] = strconv.FormatInt(cfg.InstallationID, 10)
+	}
+	if cfg.PrivateKey == "" {
+		values["github_app_private_key_file"] = cfg.PrivateKeyFile
+	}
+
+	for key, value := range values {
+		if value == "" {
+			if err := deleteKeys(p.storage, key); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := p.storage.Set(key, value); err != nil {
+			return fmt.Errorf("erro ao salvar %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// DeleteAppConfig remove a configuração do GitHub App
+func (p *GitHubProvider) DeleteAppConfig() error {
+	return deleteKeys(p.storage, "github_app_id", "github_app_installation_id",
+		"github_app_private_key", "github_app_private_key_file")
+}
+
+// AppToken emite um token de instalação do GitHub App com a expiração real informada pelo GitHub
+func (p *GitHubProvider) AppToken(scope token.TokenScope) (token.TokenResponse, error) {
+	ctx := context.Background()
+
+	cfg := p.ResolveAppConfig()
+	if cfg.AppID == 0 {
+		return token.TokenResponse{}, notConfiguredError(ProviderGitHub, "GitHub App não configurado: defina auth.github.app.app_id ou GITHUB_APP_ID")
+	}
+	key, err := cfg.privateKey()
+	if err != nil {
+		return token.TokenResponse{}, err
+	}
+
+	jwt, err := signAppJWT(cfg.AppID, key, p.now())
+	if err != nil {
+		return token.TokenResponse{}, err
+	}
+
+	installationID := cfg.InstallationID
+	if installationID == 0 {
+		if installationID, err = p.discoverInstallation(ctx, jwt); err != nil {
+			return token.TokenResponse{}, err
+		}
+	}
+
+	var resp struct {
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+	path := fmt.Sprintf("/app/installations/%d/access_tokens", installationID)
+	if err := p.appRequest(ctx, http.MethodPost, path, jwt, http.StatusCreated, &resp); err != nil {
+		return token.TokenResponse{}, fmt.Errorf("erro ao emitir token de instalação %d: %w", installationID, err)
+	}
+
+	return token.TokenResponse{
+		AccessToken: resp.Token,
+		ExpiresIn:   int(resp.ExpiresAt.Sub(p.now()).Seconds()),
+	}, nil
+}
+
+// discoverInstallation busca a instalação do app no repositório do remote origin. O ID
+// fica em memória: a instalação não muda durante a execução.
+func (p *GitHubProvider) discoverInstallation(ctx context.Context, jwt string) (int64, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.installationID != 0 {
+		return p.installationID, nil
+	}
+	if p.repositoryURL == "" && p.repositoryErr != nil {
+		return 0, fmt.Errorf("installation_id do GitHub App não configurado: %w", p.repositoryErr)
+	}
+	if p.repositoryURL == "" {
+		return 0, notConfiguredError(ProviderGitHub, "installation_id do GitHub App não configurado e remote origin não encontrado")
+	}
+
+	repository, err := parseGitHubRepository(p.repositoryURL)
+	if err != nil {
+		return 0, err
+	}
+
+	var installation struct {
+		ID int64 `json:"id"`
+	}
+	if err := p.appRequest(ctx, http.MethodGet, "/repos/"+repository+"/installation", jwt, http.StatusOK, &installation); err != nil {
+		return 0, fmt.Errorf("GitHub App não está instalado em %s: %w", repository, err)
+	}
+
+	p.installationID = installation.ID
+	return p.installationID, nil
+}
+
+// appRequest chama a API do GitHub autenticado como o app (JWT)
+func (p *GitHubProvider) appRequest(ctx context.Context, method, path, jwt string, expected int, out any) error {
+	req, err := http.NewRequestWithContext(ctx, method, p.apiURL+path, nil)
+	if err != nil {
+		return fmt.Errorf("erro ao criar requisição: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+jwt)
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", gitHubAPIVersion)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return networkError(ProviderGitHub, "GitHub App", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != expected {
+		return responseError(ProviderGitHub, "GitHub App", resp)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("erro ao decodificar resposta: %w", err)
+	}
+	return nil
+}
+
+// privateKey carrega a chave RSA do app em PKCS#1 (formato gerado pelo GitHub) ou PKCS#8
+func (cfg GitHubAppConfig) privateKey() (*rsa.PrivateKey, error) {
+	data := []byte(cfg.PrivateKey)
+	if len(data) == 0 {
+		if cfg.PrivateKeyFile == "" {
+			return nil, fmt.Errorf("chave privada do GitHub App não configurada")
+		}
+		var err error
+		if data, err = os.ReadFile(cfg.PrivateKeyFile); err != nil {
+			return nil, fmt.Errorf("erro ao ler chave privada do GitHub App: %w", err)
+		}
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("chave privada do GitHub App não está em PEM")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("chave privada do GitHub App inválida: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("chave privada do GitHub App deve ser RSA")
+	}
+	return key, nil
+}
+
+// signAppJWT assina o JWT RS256 que autentica o GitHub App
+func signAppJWT(appID int64, key *rsa.PrivateKey, now time.Time) (string, error) {
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	claims, _ := json.Marshal(map[string]any{
+		"iat": now.Add(-gitHubAppClockSkew).Unix(),
+		"exp": now.Add(gitHubAppJWTLifetime).Unix(),
+		"iss": strconv.FormatInt(appID, 10),
+	})
+
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("erro ao assinar JWT do GitHub App: %w", err)
+	}
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// parseGitHubRepository extrai owner/repo de URLs https, ssh ou scp (git@host:owner/repo)
+func parseGitHubRepository(remoteURL string) (string, error) {
+	path := strings.TrimSuffix(strings.TrimSpace(remoteURL), ".git")
+
+	if parsed, err := url.Parse(path); err == nil && parsed.Scheme != "" && parsed.Host != "" {
+		path = parsed.Path
+	} else if at := strings.Index(path, "@"); at >= 0 {
+		if colon := strings.Index(path[at:], ":"); colon >= 0 {
+			path = path[at+colon+1:]
+		}
+	}
+
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) < 2 || parts[len(parts)-2] == "" || parts[len(parts)-1] == "" {
+		return "", fmt.Errorf("não foi possível identificar owner/repo em %q", remoteURL)
+	}
+	return parts[len(parts)-2] + "/" + parts[len(parts)-1], nil
+}
+
+// stored retorna o valor salvo no storage ou vazio
+func (p *GitHubProvider) stored(key string) string {
+	value, _ := p.storage.Get(key)
+	return value
+}
+
+func storedInt64(value string) int64 {
+	number, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return number
+}
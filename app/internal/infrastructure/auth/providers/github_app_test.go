@@ -0,0 +1,220 @@
+package providers
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PHRaulino/phengineer/internal/infrastructure/auth/storage"
+	"github.com/PHRaulino/phengineer/internal/infrastructure/auth/token"
+	"github.com/PHRaulino/phengineer/internal/infrastructure/config"
+)
+
+// TestGitHubAppToken testa a emissão de tokens de instalação contra uma API falsa
+func TestGitHubAppToken(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey failed: %v", err)
+	}
+	pkcs1 := string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}))
+	pkcs8Bytes, _ := x509.MarshalPKCS8PrivateKey(key)
+	pkcs8 := string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8Bytes}))
+
+	keyFile := filepath.Join(t.TempDir(), "app.pem")
+	if err := os.WriteFile(keyFile, []byte(pkcs8), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name             string
+		stored           map[string]string
+		settings         config.GitHubAppSettings
+		remote           string
+		remoteErr        error
+		wantInstallation string
+		wantDiscovery    bool
+		wantErr          string
+	}{
+		{
+			name:             "Installation ID from storage",
+			stored:           map[string]string{"github_app_id": "123", "github_app_installation_id": "42", "github_app_private_key": pkcs1},
+			wantInstallation: "42",
+		},
+		{
+			name:             "Discover installation from https remote",
+			stored:           map[string]string{"github_app_id": "123", "github_app_private_key": pkcs1},
+			remote:           "https://github.com/empresa/servico",
+			wantInstallation: "77",
+			wantDiscovery:    true,
+		},
+		{
+			name:             "Discover installation from ssh remote with key file",
+			settings:         config.GitHubAppSettings{AppID: 123, PrivateKeyFile: keyFile},
+			remote:           "git@github.com:empresa/servico.git",
+			wantInstallation: "77",
+			wantDiscovery:    true,
+		},
+		{
+			name:    "App not installed in repository",
+			stored:  map[string]string{"github_app_id": "123", "github_app_private_key": pkcs1},
+			remote:  "https://github.com/empresa/outro",
+			wantErr: "não está instalado em empresa/outro",
+		},
+		{
+			name:    "Missing remote and installation ID",
+			stored:  map[string]string{"github_app_id": "123", "github_app_private_key": pkcs1},
+			wantErr: "installation_id",
+		},
+		{
+			name:      "Missing remote reports the requirement",
+			stored:    map[string]string{"github_app_id": "123", "github_app_private_key": pkcs1},
+			remoteErr: config.ValidationError{Requirement: "Git Remote", Message: "No Git remotes configured"},
+			wantErr:   "Requirement 'Git Remote' failed",
+		},
+		{
+			name:    "Invalid private key",
+			stored:  map[string]string{"github_app_id": "123", "github_app_installation_id": "42", "github_app_private_key": "not a key"},
+			wantErr: "não está em PEM",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var discovered bool
+			api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				verifyAppJWT(t, r, &key.PublicKey, now)
+
+				switch {
+				case r.Method == http.MethodGet && r.URL.Path == "/repos/empresa/servico/installation":
+					discovered = true
+					fmt.Fprint(w, `{"id":77}`)
+				case r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, "/app/installations/"):
+					id := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/app/installations/"), "/access_tokens")
+					if id != tt.wantInstallation {
+						t.Errorf("installation = %s, want %s", id, tt.wantInstallation)
+					}
+					w.WriteHeader(http.StatusCreated)
+					fmt.Fprintf(w, `{"token":"ghs_%s","expires_at":%q}`, id, now.Add(time.Hour).Format(time.RFC3339))
+				default:
+					w.WriteHeader(http.StatusNotFound)
+					fmt.Fprint(w, `{"message":"Not Found"}`)
+				}
+			}))
+			defer api.Close()
+
+			memory := storage.NewMemoryAdapter()
+			for key, value := range tt.stored {
+				memory.Set(key, value)
+			}
+
+			provider := NewGitHubProvider(memory).
+				WithSettings(config.GitHubSettings{APIURL: api.URL, App: tt.settings}).
+				WithRepository(tt.remote).
+				WithRepositoryError(tt.remoteErr)
+			provider.now = func() time.Time { return now }
+
+			resp, err := provider.AppToken(token.ScopeExecution)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("AppToken() error = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("AppToken() error = %v", err)
+			}
+
+			if resp.AccessToken != "ghs_"+tt.wantInstallation {
+				t.Errorf("AccessToken = %q, want ghs_%s", resp.AccessToken, tt.wantInstallation)
+			}
+			if resp.ExpiresIn != 3600 {
+				t.Errorf("ExpiresIn = %d, want 3600 from expires_at", resp.ExpiresIn)
+			}
+			if discovered != tt.wantDiscovery {
+				t.Errorf("discovery = %v, want %v", discovered, tt.wantDiscovery)
+			}
+		})
+	}
+}
+
+// verifyAppJWT confere a assinatura RS256 e as claims do JWT do app
+func verifyAppJWT(t *testing.T, r *http.Request, key *rsa.PublicKey, now time.Time) {
+	t.Helper()
+
+	jwt, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		t.Errorf("Authorization = %q, want Bearer JWT", r.Header.Get("Authorization"))
+		return
+	}
+	parts := strings.Split(jwt, ".")
+	if len(parts) != 3 {
+		t.Errorf("JWT has %d parts, want 3", len(parts))
+		return
+	}
+
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	signature, _ := base64.RawURLEncoding.DecodeString(parts[2])
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature); err != nil {
+		t.Errorf("JWT signature invalid: %v", err)
+	}
+
+	payload, _ := base64.RawURLEncoding.DecodeString(parts[1])
+	var claims struct {
+		Iat int64  `json:"iat"`
+		Exp int64  `json:"exp"`
+		Iss string `json:"iss"`
+	}
+	json.Unmarshal(payload, &claims)
+	if claims.Iss != "123" {
+		t.Errorf("iss = %q, want 123", claims.Iss)
+	}
+	if claims.Iat > now.Unix() || claims.Exp > now.Add(10*time.Minute).Unix() {
+		t.Errorf("iat/exp = %d/%d outside GitHub limits for now %d", claims.Iat, claims.Exp, now.Unix())
+	}
+	if r.Header.Get("X-GitHub-Api-Version") == "" {
+		t.Error("X-GitHub-Api-Version header missing")
+	}
+}
+
+// TestParseGitHubRepository testa a extração de owner/repo das URLs de remote
+func TestParseGitHubRepository(t *testing.T) {
+	tests := []struct {
+		remote  string
+		want    string
+		wantErr bool
+	}{
+		{remote: "https://github.com/empresa/servico", want: "empresa/servico"},
+		{remote: "https://github.com/empresa/servico.git", want: "empresa/servico"},
+		{remote: "git@github.com:empresa/servico.git", want: "empresa/servico"},
+		{remote: "ssh://git@github.empresa.com/empresa/servico.git", want: "empresa/servico"},
+		{remote: "https://github.com/empresa", wantErr: true},
+		{remote: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.remote, func(t *testing.T) {
+			got, err := parseGitHubRepository(tt.remote)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseGitHubRepository() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseGitHubRepository() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,246 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// GitHubPermission permissão de repositório no formato dos fine-grained PATs (recurso:nível)
+type GitHubPermission string
+
+const (
+	PermContentsRead      GitHubPermission = "contents:read"
+	PermContentsWrite     GitHubPermission = "contents:write"
+	PermIssuesWrite       GitHubPermission = "issues:write"
+	PermPullRequestsWrite GitHubPermission = "pull_requests:write"
+)
+
+// GitHubFeature funcionalidade da CLI e as permissões de que ela precisa no repositório
+type GitHubFeature struct {
+	Name        string
+	Description string
+	Permissions []GitHubPermission
+}
+
+// GitHubFeatures permissões exigidas por funcionalidade
+var GitHubFeatures = []GitHubFeature{
+	{Name: "knowledge", Description: "ler fontes de conhecimento em repositórios privados", Permissions: []GitHubPermission{PermContentsRead}},
+	{Name: "issues", Description: "abrir e comentar issues no pipeline", Permissions: []GitHubPermission{PermIssuesWrite}},
+	{Name: "pull_requests", Description: "publicar branches e abrir pull requests", Permissions: []GitHubPermission{PermContentsWrite, PermPullRequestsWrite}},
+}
+
+// Tipos de token do GitHub
+const (
+	GitHubTokenClassic     = "classic"      // PAT clássico ou OAuth App: escopos em X-OAuth-Scopes
+	GitHubTokenFineGrained = "fine-grained" // fine-grained PAT ou token de usuário de GitHub App
+	GitHubTokenApp         = "app"          // token de instalação de GitHub App
+)
+
+// classicScopePermissions permissões concedidas por escopo de PAT clássico
+var classicScopePermissions = map[string][]GitHubPermission{
+	"repo":        {PermContentsRead, PermContentsWrite, PermIssuesWrite, PermPullRequestsWrite},
+	"public_repo": {PermContentsRead, PermContentsWrite, PermIssuesWrite, PermPullRequestsWrite},
+}
+
+// MissingPermission permissão ausente e a funcionalidade que depende dela
+type MissingPermission struct {
+	Feature    string           `json:"feature"`
+	Permission GitHubPermission `json:"permission"`
+}
+
+func (m MissingPermission) String() string {
+	return fmt.Sprintf("%s (%s)", m.Permission, m.Feature)
+}
+
+// GitHubTokenReport resultado da verificação de um token
+type GitHubTokenReport struct {
+	Login      string              `json:"login,omitempty"`
+	Kind       string              `json:"kind"`
+	Scopes     []string            `json:"scopes,omitempty"`
+	Repository string              `json:"repository,omitempty"`
+	Granted    []GitHubPermission  `json:"granted"`
+	Missing    []MissingPermission `json:"missing,omitempty"`
+	Verified   bool                `json:"verified"` // falso quando não há repositório para sondar
+}
+
+// OK indica que o token atende a todas as funcionalidades
+func (r *GitHubTokenReport) OK() bool {
+	return r.Verified && len(r.Missing) == 0
+}
+
+// Warnings descreve, em linhas legíveis, as permissões ausentes ou por que não foram verificadas
+func (r *GitHubTokenReport) Warnings() []string {
+	if !r.Verified {
+		return []string{"permissões não verificadas: repositório GitHub não identificado pelo remote origin"}
+	}
+	warnings := make([]string, 0, len(r.Missing))
+	for _, missing := range r.Missing {
+		warnings = append(warnings, "permissão ausente: "+missing.String())
+	}
+	return warnings
+}
+
+// VerifyToken valida o token e compara as permissões com as exigidas por cada funcionalidade.
+// PATs clássicos são avaliados pelos escopos; fine-grained PATs e tokens de app, sondando o
+// repositório configurado (WithRepository).
+func (p *GitHubProvider) VerifyToken(ctx context.Context, githubToken string) (*GitHubTokenReport, error) {
+	report := &GitHubTokenReport{Kind: gitHubTokenKind(githubToken)}
+
+	if report.Kind == GitHubTokenApp {
+		// Tokens de instalação não acessam /user
+		report.Login = "app"
+	} else {
+		resp, err := p.githubRequest(ctx, http.MethodGet, "/user", githubToken, nil)
+		if err != nil {
+			return nil, networkError(ProviderGitHub, "validação do token", err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, responseError(ProviderGitHub, "validação do token", resp)
+		}
+
+		var user GitHubUser
+		if err := json.NewDecoder(resp.Body).Decode(&user); err == nil {
+			report.Login = user.Login
+		}
+
+		// O header existe (mesmo vazio) apenas em tokens com escopos clássicos
+		if header, ok := resp.Header[http.CanonicalHeaderKey("X-OAuth-Scopes")]; ok {
+			report.Kind = GitHubTokenClassic
+			report.Scopes = parseScopes(strings.Join(header, ","))
+		}
+	}
+
+	if p.repositoryURL != "" {
+		repository, err := parseGitHubRepository(p.repositoryURL)
+		if err != nil {
+			return nil, err
+		}
+		report.Repository = repository
+	}
+
+	switch {
+	case report.Kind == GitHubTokenClassic:
+		for _, scope := range report.Scopes {
+			report.Granted = append(report.Granted, classicScopePermissions[scope]...)
+		}
+		report.Verified = true
+	case report.Repository != "":
+		granted, err := p.probePermissions(ctx, githubToken, report.Repository)
+		if err != nil {
+			return nil, err
+		}
+		report.Granted = granted
+		report.Verified = true
+	}
+
+	slices.Sort(report.Granted)
+	report.Granted = slices.Compact(report.Granted)
+	if report.Verified {
+		report.Missing = missingPermissions(report.Granted)
+	}
+	return report, nil
+}
+
+// probePermissions sonda o repositório sem efeitos colaterais: leituras com GET e escritas
+// com payloads inválidos, em que 422 indica permissão (o corpo é validado depois da
+// autorização) e 403/404 indica permissão ausente.
+func (p *GitHubProvider) probePermissions(ctx context.Context, githubToken, repository string) ([]GitHubPermission, error) {
+	base := "/repos/" + repository
+
+	resp, err := p.githubRequest(ctx, http.MethodGet, base, githubToken, nil)
+	if err != nil {
+		return nil, networkError(ProviderGitHub, "consulta de "+repository, err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		// Sem acesso ao repositório nenhuma permissão se aplica
+		return nil, nil
+	}
+
+	probes := []struct {
+		permission GitHubPermission
+		method     string
+		path       string
+		granted    []int
+	}{
+		// 409: repositório vazio, mas legível
+		{PermContentsRead, http.MethodGet, base + "/commits?per_page=1", []int{http.StatusOK, http.StatusConflict}},
+		{PermContentsWrite, http.MethodPut, base + "/contents/.phengineer-permission-check", []int{http.StatusUnprocessableEntity}},
+		{PermIssuesWrite, http.MethodPost, base + "/issues", []int{http.StatusUnprocessableEntity}},
+		{PermPullRequestsWrite, http.MethodPost, base + "/pulls", []int{http.StatusUnprocessableEntity}},
+	}
+
+	var granted []GitHubPermission
+	for _, probe := range probes {
+		var body io.Reader
+		if probe.method != http.MethodGet {
+			body = strings.NewReader("{}")
+		}
+
+		resp, err := p.githubRequest(ctx, probe.method, probe.path, githubToken, body)
+		if err != nil {
+			return nil, networkError(ProviderGitHub, "verificação de "+string(probe.permission), err)
+		}
+		resp.Body.Close()
+
+		if slices.Contains(probe.granted, resp.StatusCode) {
+			granted = append(granted, probe.permission)
+		}
+	}
+	return granted, nil
+}
+
+// githubRequest chama a API do GitHub autenticado com o token
+func (p *GitHubProvider) githubRequest(ctx context.Context, method, path, githubToken string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, p.apiURL+path, body)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao criar requisição: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+githubToken)
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", gitHubAPIVersion)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return p.client.Do(req)
+}
+
+// missingPermissions lista, por funcionalidade, as permissões que não foram concedidas
+func missingPermissions(granted []GitHubPermission) []MissingPermission {
+	var missing []MissingPermission
+	for _, feature := range GitHubFeatures {
+		for _, permission := range feature.Permissions {
+			if !slices.Contains(granted, permission) {
+				missing = append(missing, MissingPermission{Feature: feature.Name, Permission: permission})
+			}
+		}
+	}
+	return missing
+}
+
+// gitHubTokenKind identifica o tipo do token pelo prefixo documentado pelo GitHub
+func gitHubTokenKind(githubToken string) string {
+	switch {
+	case strings.HasPrefix(githubToken, "ghs_"):
+		return GitHubTokenApp
+	default:
+		// ghp_/gho_ são clássicos, mas a confirmação vem do header X-OAuth-Scopes
+		return GitHubTokenFineGrained
+	}
+}
+
+func parseScopes(header string) []string {
+	var scopes []string
+	for _, scope := range strings.Split(header, ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes
+}
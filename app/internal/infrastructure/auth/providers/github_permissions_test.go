@@ -0,0 +1,165 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/PHRaulino/phengineer/internal/infrastructure/auth/storage"
+	"github.com/PHRaulino/phengineer/internal/infrastructure/config"
+)
+
+// TestGitHubVerifyToken testa a detecção de escopos clássicos e a sondagem de permissões no repositório
+func TestGitHubVerifyToken(t *testing.T) {
+	tests := []struct {
+		name        string
+		token       string
+		scopes      *string // nil: sem header X-OAuth-Scopes (fine-grained)
+		granted     []GitHubPermission
+		remote      string
+		wantKind    string
+		wantMissing []string
+		wantVerify  bool
+		wantErr     string
+	}{
+		{
+			name:       "Classic token with repo scope",
+			token:      "ghp_classic",
+			scopes:     ptr("repo, read:org"),
+			wantKind:   GitHubTokenClassic,
+			wantVerify: true,
+		},
+		{
+			name:        "Classic token without repo scope",
+			token:       "ghp_classic",
+			scopes:      ptr("read:org, gist"),
+			wantKind:    GitHubTokenClassic,
+			wantMissing: []string{"contents:read", "issues:write", "contents:write", "pull_requests:write"},
+			wantVerify:  true,
+		},
+		{
+			name:        "Fine-grained token with read-only contents",
+			token:       "github_pat_fine",
+			granted:     []GitHubPermission{PermContentsRead},
+			remote:      "git@github.com:empresa/servico.git",
+			wantKind:    GitHubTokenFineGrained,
+			wantMissing: []string{"issues:write", "contents:write", "pull_requests:write"},
+			wantVerify:  true,
+		},
+		{
+			name:       "App installation token with all permissions",
+			token:      "ghs_app",
+			granted:    []GitHubPermission{PermContentsRead, PermContentsWrite, PermIssuesWrite, PermPullRequestsWrite},
+			remote:     "https://github.com/empresa/servico",
+			wantKind:   GitHubTokenApp,
+			wantVerify: true,
+		},
+		{
+			name:     "Fine-grained token without remote",
+			token:    "github_pat_fine",
+			wantKind: GitHubTokenFineGrained,
+		},
+		{
+			name:    "Revoked token",
+			token:   "ghp_revoked",
+			wantErr: "status 401",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var writes []string
+			api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("Authorization") != "Bearer "+tt.token {
+					t.Errorf("Authorization = %q, want Bearer token", r.Header.Get("Authorization"))
+				}
+				if tt.token == "ghp_revoked" {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+
+				allow := func(permission GitHubPermission, status int) {
+					if slices.Contains(tt.granted, permission) {
+						w.WriteHeader(status)
+						return
+					}
+					w.WriteHeader(http.StatusForbidden)
+				}
+
+				if r.Method != http.MethodGet {
+					writes = append(writes, r.Method+" "+r.URL.Path)
+				}
+
+				switch r.Method + " " + r.URL.Path {
+				case "GET /user":
+					if tt.token == "ghs_app" {
+						t.Error("installation token must not call /user")
+					}
+					if tt.scopes != nil {
+						w.Header().Set("X-OAuth-Scopes", *tt.scopes)
+					}
+					fmt.Fprint(w, `{"login":"octocat","id":1}`)
+				case "GET /repos/empresa/servico":
+					fmt.Fprint(w, `{"full_name":"empresa/servico"}`)
+				case "GET /repos/empresa/servico/commits":
+					allow(PermContentsRead, http.StatusOK)
+				case "PUT /repos/empresa/servico/contents/.phengineer-permission-check":
+					allow(PermContentsWrite, http.StatusUnprocessableEntity)
+				case "POST /repos/empresa/servico/issues":
+					allow(PermIssuesWrite, http.StatusUnprocessableEntity)
+				case "POST /repos/empresa/servico/pulls":
+					allow(PermPullRequestsWrite, http.StatusUnprocessableEntity)
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer api.Close()
+
+			provider := NewGitHubProvider(storage.NewMemoryAdapter()).
+				WithSettings(config.GitHubSettings{APIURL: api.URL}).
+				WithRepository(tt.remote)
+
+			report, err := provider.VerifyToken(context.Background(), tt.token)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("VerifyToken() error = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("VerifyToken() error = %v", err)
+			}
+
+			if report.Kind != tt.wantKind {
+				t.Errorf("Kind = %q, want %q", report.Kind, tt.wantKind)
+			}
+			if report.Verified != tt.wantVerify {
+				t.Errorf("Verified = %v, want %v", report.Verified, tt.wantVerify)
+			}
+
+			var missing []string
+			for _, m := range report.Missing {
+				missing = append(missing, string(m.Permission))
+			}
+			if !slices.Equal(missing, tt.wantMissing) {
+				t.Errorf("Missing = %v, want %v", missing, tt.wantMissing)
+			}
+			if len(tt.wantMissing) > 0 && len(report.Warnings()) != len(tt.wantMissing) {
+				t.Errorf("Warnings() = %v, want one per missing permission", report.Warnings())
+			}
+
+			// Classic tokens are evaluated by scope only: probing would be redundant
+			if tt.wantKind == GitHubTokenClassic && len(writes) > 0 {
+				t.Errorf("classic token probed the repository: %v", writes)
+			}
+		})
+	}
+}
+
+func ptr(value string) *string {
+	return &value
+}
@@ -0,0 +1,240 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/PHRaulino/phengineer/internal/infrastructure/auth/token"
+	"github.com/PHRaulino/phengineer/internal/infrastructure/httpx"
+)
+
+const (
+	grantTypeDeviceCode   = "urn:ietf:params:oauth:grant-type:device_code"
+	grantTypeRefreshToken = "refresh_token"
+
+	// Intervalo de polling quando o servidor não informa (RFC 8628, seção 3.2)
+	defaultDeviceInterval = 5 * time.Second
+)
+
+var (
+	// ErrAuthorizationDenied o usuário recusou o acesso na página de verificação
+	ErrAuthorizationDenied = errors.New("autorização negada pelo usuário")
+	// ErrDeviceCodeExpired o código expirou antes de o usuário concluir a autorização
+	ErrDeviceCodeExpired = errors.New("código de autorização expirou; inicie o login novamente")
+)
+
+// OAuthError erro retornado pelo servidor de autorização (RFC 6749, seção 5.2)
+type OAuthError struct {
+	StatusCode  int
+	Code        string
+	Description string
+}
+
+func (e *OAuthError) Error() string {
+	if e.Description != "" {
+		return fmt.Sprintf("erro OAuth %s: %s", e.Code, e.Description)
+	}
+	return fmt.Sprintf("erro OAuth %s (status %d)", e.Code, e.StatusCode)
+}
+
+// IsInvalidGrant indica refresh token revogado ou expirado: só um novo login resolve
+func IsInvalidGrant(err error) bool {
+	var oauthErr *OAuthError
+	return errors.As(err, &oauthErr) && oauthErr.Code == "invalid_grant"
+}
+
+// DeviceAuthorization código que o usuário informa na página de verificação
+type DeviceAuthorization struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete,omitempty"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval"`
+}
+
+// oauthToken resposta do endpoint de token para os grants device_code e refresh_token
+type oauthToken struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	Scope        string `json:"scope,omitempty"`
+}
+
+func (t oauthToken) tokenResponse() token.TokenResponse {
+	return token.TokenResponse{
+		AccessToken:  t.AccessToken,
+		ExpiresIn:    t.ExpiresIn,
+		RefreshToken: t.RefreshToken,
+	}
+}
+
+// oauthEndpoints endpoints do servidor de autorização
+type oauthEndpoints struct {
+	DeviceAuthorizationURL string
+	TokenURL               string
+}
+
+// oauthClient cliente público (sem secret) dos grants device_code e refresh_token
+type oauthClient struct {
+	provider  string
+	http      *http.Client
+	endpoints oauthEndpoints
+	clientID  string
+	wait      func(ctx context.Context, d time.Duration) error
+}
+
+func newOAuthClient(provider string, client *http.Client, endpoints oauthEndpoints, clientID string) *oauthClient {
+	return &oauthClient{provider: provider, http: client, endpoints: endpoints, clientID: clientID, wait: sleepContext}
+}
+
+// requestDeviceCode inicia o device authorization grant (RFC 8628)
+func (c *oauthClient) requestDeviceCode(ctx context.Context, scope string) (DeviceAuthorization, error) {
+	form := url.Values{}
+	form.Set("client_id", c.clientID)
+	if scope != "" {
+		form.Set("scope", scope)
+	}
+
+	var authorization DeviceAuthorization
+	if err := c.post(ctx, c.endpoints.DeviceAuthorizationURL, form, &authorization); err != nil {
+		return DeviceAuthorization{}, fmt.Errorf("erro ao solicitar código de dispositivo: %w", err)
+	}
+	if authorization.DeviceCode == "" || authorization.UserCode == "" {
+		return DeviceAuthorization{}, fmt.Errorf("resposta sem device_code ou user_code")
+	}
+	return authorization, nil
+}
+
+// pollDeviceToken consulta o endpoint de token até o usuário autorizar, negar ou o código expirar
+func (c *oauthClient) pollDeviceToken(ctx context.Context, authorization DeviceAuthorization) (oauthToken, error) {
+	interval := time.Duration(authorization.Interval) * time.Second
+	if interval <= 0 {
+		interval = defaultDeviceInterval
+	}
+	if authorization.ExpiresIn > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(authorization.ExpiresIn)*time.Second)
+		defer cancel()
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", grantTypeDeviceCode)
+	form.Set("device_code", authorization.DeviceCode)
+	form.Set("client_id", c.clientID)
+
+	for {
+		if err := c.wait(ctx, interval); err != nil {
+			if errors.Is(err, context.DeadlineExceeded) {
+				return oauthToken{}, ErrDeviceCodeExpired
+			}
+			return oauthToken{}, err
+		}
+
+		var tokenResp oauthToken
+		err := c.post(ctx, c.endpoints.TokenURL, form, &tokenResp)
+		if err == nil {
+			return tokenResp, nil
+		}
+
+		var oauthErr *OAuthError
+		if !errors.As(err, &oauthErr) {
+			return oauthToken{}, err
+		}
+		switch oauthErr.Code {
+		case "authorization_pending":
+		case "slow_down":
+			interval += 5 * time.Second
+		case "access_denied":
+			return oauthToken{}, ErrAuthorizationDenied
+		case "expired_token":
+			return oauthToken{}, ErrDeviceCodeExpired
+		default:
+			return oauthToken{}, err
+		}
+	}
+}
+
+// refresh troca o refresh token por um novo access token
+func (c *oauthClient) refresh(ctx context.Context, refreshToken string) (oauthToken, error) {
+	form := url.Values{}
+	form.Set("grant_type", grantTypeRefreshToken)
+	form.Set("refresh_token", refreshToken)
+	form.Set("client_id", c.clientID)
+
+	var tokenResp oauthToken
+	if err := c.post(ctx, c.endpoints.TokenURL, form, &tokenResp); err != nil {
+		return oauthToken{}, fmt.Errorf("erro ao renovar token: %w", err)
+	}
+	return tokenResp, nil
+}
+
+// post envia o formulário e decodifica a resposta. O GitHub responde erros OAuth com
+// status 200, por isso o campo "error" é verificado em qualquer status.
+func (c *oauthClient) post(ctx context.Context, endpoint string, form url.Values, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewBufferString(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("erro ao criar requisição: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return networkError(c.provider, "login", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("erro ao ler resposta: %w", err)
+	}
+
+	var oauthErr struct {
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if json.Unmarshal(body, &oauthErr) == nil && oauthErr.Error != "" {
+		return &ProviderError{
+			Provider: c.provider,
+			Op:       "login",
+			Kind:     oauthErrorKind(oauthErr.Error),
+			Status:   resp.StatusCode,
+			Err:      &OAuthError{StatusCode: resp.StatusCode, Code: oauthErr.Error, Description: oauthErr.ErrorDescription},
+		}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return &ProviderError{
+			Provider: c.provider,
+			Op:       "login",
+			Kind:     statusKind(resp),
+			Status:   resp.StatusCode,
+			Body:     httpx.SanitizeBody(body, bodyExcerptLimit),
+		}
+	}
+
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("erro ao decodificar resposta: %w", err)
+	}
+	return nil
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,336 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/PHRaulino/phengineer/internal/infrastructure/auth/storage"
+	"github.com/PHRaulino/phengineer/internal/infrastructure/auth/token"
+	"github.com/PHRaulino/phengineer/internal/infrastructure/config"
+)
+
+// fakeIdP servidor de autorização falso com device authorization e refresh token
+type fakeIdP struct {
+	t *testing.T
+
+	pending   []string // erros retornados pelas consultas antes da autorização
+	final     string   // erro terminal; vazio autoriza
+	status    int      // status das respostas de erro (o GitHub usa 200)
+	rotate    bool
+	noRefresh bool
+
+	mu       sync.Mutex
+	polls    int
+	grants   []string
+	clientID string
+	refresh  string // refresh token válido
+}
+
+func (f *fakeIdP) handler(devicePath, tokenPath string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		f.mu.Lock()
+		defer f.mu.Unlock()
+
+		if r.Header.Get("Accept") != "application/json" {
+			f.t.Errorf("Accept = %q, want application/json", r.Header.Get("Accept"))
+		}
+		f.clientID = r.PostForm.Get("client_id")
+
+		switch r.URL.Path {
+		case devicePath:
+			fmt.Fprint(w, `{"device_code":"dev-123","user_code":"ABCD-EFGH","verification_uri":"https://idm.test/device","expires_in":600,"interval":5}`)
+
+		case tokenPath:
+			grant := r.PostForm.Get("grant_type")
+			f.grants = append(f.grants, grant)
+
+			switch grant {
+			case grantTypeDeviceCode:
+				if r.PostForm.Get("device_code") != "dev-123" {
+					f.t.Errorf("device_code = %q, want dev-123", r.PostForm.Get("device_code"))
+				}
+				f.polls++
+				if f.polls <= len(f.pending) {
+					f.writeError(w, f.pending[f.polls-1])
+					return
+				}
+				if f.final != "" {
+					f.writeError(w, f.final)
+					return
+				}
+				f.refresh = "rt-1"
+				if f.noRefresh {
+					f.refresh = ""
+				}
+				fmt.Fprintf(w, `{"access_token":"at-device","token_type":"bearer","expires_in":300,"refresh_token":%q}`, f.refresh)
+
+			case grantTypeRefreshToken:
+				if r.PostForm.Get("refresh_token") != f.refresh || f.refresh == "" {
+					f.writeError(w, "invalid_grant")
+					return
+				}
+				if f.rotate {
+					f.refresh = "rt-rotated"
+				}
+				fmt.Fprintf(w, `{"access_token":"at-refreshed","token_type":"bearer","expires_in":300,"refresh_token":%q}`, f.refresh)
+
+			case "client_credentials":
+				fmt.Fprint(w, `{"access_token":"at-client","expires_in":300}`)
+
+			default:
+				f.t.Errorf("Unexpected grant_type %q", grant)
+			}
+
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+}
+
+func (f *fakeIdP) writeError(w http.ResponseWriter, code string) {
+	status := f.status
+	if status == 0 {
+		status = http.StatusBadRequest
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, `{"error":%q,"error_description":"%s from fake idp"}`, code, code)
+}
+
+// TestOAuthDevicePolling testa o polling do device authorization grant
+func TestOAuthDevicePolling(t *testing.T) {
+	tests := []struct {
+		name          string
+		pending       []string
+		final         string
+		status        int
+		wantErr       error
+		wantIntervals []time.Duration
+	}{
+		{
+			name:          "Authorized after pending",
+			pending:       []string{"authorization_pending", "authorization_pending"},
+			wantIntervals: []time.Duration{5 * time.Second, 5 * time.Second, 5 * time.Second},
+		},
+		{
+			name:          "Slow down increases interval",
+			pending:       []string{"slow_down", "authorization_pending"},
+			wantIntervals: []time.Duration{5 * time.Second, 10 * time.Second, 10 * time.Second},
+		},
+		{
+			name:          "GitHub style errors with status 200",
+			pending:       []string{"authorization_pending"},
+			status:        http.StatusOK,
+			wantIntervals: []time.Duration{5 * time.Second, 5 * time.Second},
+		},
+		{name: "Access denied", final: "access_denied", wantErr: ErrAuthorizationDenied},
+		{name: "Expired device code", pending: []string{"authorization_pending"}, final: "expired_token", wantErr: ErrDeviceCodeExpired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idp := &fakeIdP{t: t, pending: tt.pending, final: tt.final, status: tt.status}
+			server := httptest.NewServer(idp.handler("/device", "/token"))
+			defer server.Close()
+
+			client := newOAuthClient(ProviderStackSpot, server.Client(), oauthEndpoints{
+				DeviceAuthorizationURL: server.URL + "/device",
+				TokenURL:               server.URL + "/token",
+			}, "cli-client")
+
+			var intervals []time.Duration
+			client.wait = func(ctx context.Context, d time.Duration) error {
+				intervals = append(intervals, d)
+				return nil
+			}
+
+			authorization, err := client.requestDeviceCode(context.Background(), "openid")
+			if err != nil {
+				t.Fatalf("requestDeviceCode() error = %v", err)
+			}
+			if authorization.UserCode != "ABCD-EFGH" || authorization.VerificationURI != "https://idm.test/device" {
+				t.Errorf("authorization = %+v", authorization)
+			}
+
+			tokenResp, err := client.pollDeviceToken(context.Background(), authorization)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("pollDeviceToken() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("pollDeviceToken() error = %v", err)
+			}
+			if tokenResp.AccessToken != "at-device" || tokenResp.RefreshToken != "rt-1" {
+				t.Errorf("token = %+v", tokenResp)
+			}
+			if fmt.Sprint(intervals) != fmt.Sprint(tt.wantIntervals) {
+				t.Errorf("intervals = %v, want %v", intervals, tt.wantIntervals)
+			}
+		})
+	}
+}
+
+// TestOAuthDevicePollingCancel testa a interrupção do polling pelo contexto
+func TestOAuthDevicePollingCancel(t *testing.T) {
+	idp := &fakeIdP{t: t, pending: []string{"authorization_pending"}}
+	server := httptest.NewServer(idp.handler("/device", "/token"))
+	defer server.Close()
+
+	client := newOAuthClient(ProviderStackSpot, server.Client(), oauthEndpoints{TokenURL: server.URL + "/token"}, "cli-client")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := client.pollDeviceToken(ctx, DeviceAuthorization{DeviceCode: "dev-123", Interval: 1})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("pollDeviceToken() error = %v, want context.Canceled", err)
+	}
+}
+
+// TestStackSpotDeviceLogin testa o login pelo navegador e a renovação com refresh token
+func TestStackSpotDeviceLogin(t *testing.T) {
+	tests := []struct {
+		name          string
+		rotate        bool
+		revoke        bool
+		clientSecret  bool
+		wantToken     string
+		wantRefresh   string
+		wantErr       string
+		wantLastGrant string
+	}{
+		{name: "Refresh grant replaces client secret", wantToken: "at-refreshed", wantRefresh: "rt-1", wantLastGrant: grantTypeRefreshToken},
+		{name: "Rotated refresh token is persisted", rotate: true, wantToken: "at-refreshed", wantRefresh: "rt-rotated", wantLastGrant: grantTypeRefreshToken},
+		{name: "Revoked session falls back to client credentials", revoke: true, clientSecret: true, wantToken: "at-client", wantLastGrant: "client_credentials"},
+		{name: "Revoked session without client secret", revoke: true, wantErr: "auth login stackspot --device"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idp := &fakeIdP{t: t, pending: []string{"authorization_pending"}, rotate: tt.rotate}
+			realm := "/realms/test/protocol/openid-connect"
+			server := httptest.NewServer(idp.handler(realm+"/auth/device", realm+"/token"))
+			defer server.Close()
+
+			memory := storage.NewMemoryAdapter()
+			provider := NewStackSpotProvider(memory).WithSettings(config.StackSpotSettings{
+				IdMURL:   server.URL + "/realms/test/",
+				ClientID: "phengineer-cli",
+			})
+			provider.wait = func(ctx context.Context, d time.Duration) error { return nil }
+
+			authorization, err := provider.StartDeviceLogin(context.Background())
+			if err != nil {
+				t.Fatalf("StartDeviceLogin() error = %v", err)
+			}
+			if err := provider.CompleteDeviceLogin(context.Background(), authorization); err != nil {
+				t.Fatalf("CompleteDeviceLogin() error = %v", err)
+			}
+			if !provider.HasDeviceLogin() || provider.ClientID() != "phengineer-cli" {
+				t.Fatalf("device login not stored: HasDeviceLogin=%v ClientID=%q", provider.HasDeviceLogin(), provider.ClientID())
+			}
+
+			if tt.revoke {
+				idp.mu.Lock()
+				idp.refresh = ""
+				idp.mu.Unlock()
+			}
+			if tt.clientSecret {
+				provider.SaveCredentials("user-id", "user-secret")
+			}
+
+			// Nova instância, como em outro processo: o client vem do storage
+			resp, err := NewStackSpotProvider(memory).WithSettings(config.StackSpotSettings{IdMURL: server.URL + "/realms/test"}).GetToken(token.ScopeExecution)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("GetToken() error = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetToken() error = %v", err)
+			}
+			if resp.AccessToken != tt.wantToken {
+				t.Errorf("AccessToken = %q, want %q", resp.AccessToken, tt.wantToken)
+			}
+			if grant := idp.grants[len(idp.grants)-1]; grant != tt.wantLastGrant {
+				t.Errorf("last grant = %q, want %q", grant, tt.wantLastGrant)
+			}
+			if grant := idp.grants[len(idp.grants)-1]; grant == grantTypeRefreshToken && idp.clientID != "phengineer-cli" {
+				t.Errorf("refresh client_id = %q, want phengineer-cli", idp.clientID)
+			}
+
+			stored, _ := memory.Get("stackspot_refresh_token")
+			if stored != tt.wantRefresh {
+				t.Errorf("stored refresh token = %q, want %q", stored, tt.wantRefresh)
+			}
+		})
+	}
+}
+
+// TestGitHubDeviceLogin testa o login pelo navegador em OAuth Apps e GitHub Apps
+func TestGitHubDeviceLogin(t *testing.T) {
+	tests := []struct {
+		name        string
+		noRefresh   bool
+		wantRefresh string
+	}{
+		{name: "OAuth App token without refresh", noRefresh: true},
+		{name: "GitHub App token with refresh", wantRefresh: "rt-1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idp := &fakeIdP{t: t, status: http.StatusOK, pending: []string{"authorization_pending"}, noRefresh: tt.noRefresh}
+			mux := http.NewServeMux()
+			mux.Handle("/login/", idp.handler("/login/device/code", "/login/oauth/access_token"))
+			mux.HandleFunc("/api/v3/user", func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("Authorization") == "" {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				fmt.Fprint(w, `{"login":"octocat","id":1}`)
+			})
+			server := httptest.NewServer(mux)
+			defer server.Close()
+
+			memory := storage.NewMemoryAdapter()
+			provider := NewGitHubProvider(memory).WithSettings(config.GitHubSettings{URL: server.URL}).WithClientID("Iv1.test")
+			provider.wait = func(ctx context.Context, d time.Duration) error { return nil }
+
+			authorization, err := provider.StartDeviceLogin(context.Background())
+			if err != nil {
+				t.Fatalf("StartDeviceLogin() error = %v", err)
+			}
+			if err := provider.CompleteDeviceLogin(context.Background(), authorization); err != nil {
+				t.Fatalf("CompleteDeviceLogin() error = %v", err)
+			}
+
+			if stored, _ := memory.Get("github_token"); stored != "at-device" {
+				t.Errorf("github_token = %q, want at-device", stored)
+			}
+			if stored, _ := memory.Get("github_refresh_token"); stored != tt.wantRefresh {
+				t.Errorf("github_refresh_token = %q, want %q", stored, tt.wantRefresh)
+			}
+
+			// GHES: a API fica em <url>/api/v3
+			user, err := provider.GetUser()
+			if err != nil {
+				t.Fatalf("GetUser() error = %v", err)
+			}
+			if user.Login != "octocat" {
+				t.Errorf("Login = %q, want octocat", user.Login)
+			}
+		})
+	}
+}
@@ -2,27 +2,72 @@ package providers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/url"
+	"strings"
 	"time"
 
 	"github.com/PHRaulino/phengineer/internal/infrastructure/auth/storage"
 	"github.com/PHRaulino/phengineer/internal/infrastructure/auth/token"
+	"github.com/PHRaulino/phengineer/internal/infrastructure/config"
+	"github.com/PHRaulino/phengineer/internal/infrastructure/httpx"
 )
 
 const (
-	StackSpotTokenURL = "https://idm.stackspot.com/realms/stackspot/protocol/openid-connect/token"
+	StackSpotIdMURL   = "https://idm.stackspot.com/realms/stackspot"
+	StackSpotTokenURL = StackSpotIdMURL + "/protocol/openid-connect/token"
+
+	// Escopo pedido no login pelo navegador; offline_access mantém o refresh token entre sessões
+	stackSpotDeviceScope = "openid offline_access"
 )
 
 type StackSpotProvider struct {
-	storage storage.StorageAdapter
+	storage       storage.StorageAdapter
+	client        *http.Client
+	endpoints     oauthEndpoints
+	tokenURL      string
+	oauthClientID string // client público do login pelo navegador
+	wait          func(ctx context.Context, d time.Duration) error
 }
 
 func NewStackSpotProvider(storage storage.StorageAdapter) *StackSpotProvider {
+	endpoints := stackSpotEndpoints(config.StackSpotSettings{})
 	return &StackSpotProvider{
-		storage: storage,
+		storage:   storage,
+		client:    httpx.Default(),
+		endpoints: endpoints,
+		tokenURL:  endpoints.TokenURL,
+	}
+}
+
+// WithSettings aplica o IdM e o client do login pelo navegador configurados no settings.yml
+func (p *StackSpotProvider) WithSettings(settings config.StackSpotSettings) *StackSpotProvider {
+	p.endpoints = stackSpotEndpoints(settings)
+	p.tokenURL = p.endpoints.TokenURL
+	p.oauthClientID = settings.ClientID
+	return p
+}
+
+// WithClientID sobrescreve o client do login pelo navegador; vazio mantém o configurado
+func (p *StackSpotProvider) WithClientID(clientID string) *StackSpotProvider {
+	if clientID != "" {
+		p.oauthClientID = clientID
+	}
+	return p
+}
+
+// stackSpotEndpoints monta os endpoints OpenID Connect do realm do IdM
+func stackSpotEndpoints(settings config.StackSpotSettings) oauthEndpoints {
+	realm := strings.TrimSuffix(settings.IdMURL, "/")
+	if realm == "" {
+		realm = StackSpotIdMURL
+	}
+	return oauthEndpoints{
+		DeviceAuthorizationURL: realm + "/protocol/openid-connect/auth/device",
+		TokenURL:               realm + "/protocol/openid-connect/token",
 	}
 }
 
@@ -41,31 +86,63 @@ type StackSpotTokenResponse struct {
 	Scope        string `json:"scope,omitempty"`
 }
 
+// StackSpotCredentials credenciais client_credentials da StackSpot
+type StackSpotCredentials struct {
+	ClientID     string
+	ClientSecret string
+}
+
 func (p *StackSpotProvider) GetToken(scope token.TokenScope) (token.TokenResponse, error) {
+	// Login pelo navegador: o refresh token salvo substitui o client secret
+	if refreshToken, err := p.storage.Get("stackspot_refresh_token"); err == nil && refreshToken != "" {
+		tokenResponse, err := p.Refresh(scope, refreshToken)
+		if err == nil {
+			return tokenResponse, nil
+		}
+		if !IsInvalidGrant(err) {
+			return token.TokenResponse{}, err
+		}
+
+		// Sessão revogada ou expirada: descartar e usar client_credentials, se houver
+		deleteKeys(p.storage, "stackspot_refresh_token")
+		if !p.storage.Exists("stackspot_client_secret") {
+			return token.TokenResponse{}, fmt.Errorf("sessão StackSpot expirada, execute 'phengineer auth login stackspot --device': %w", err)
+		}
+	}
+
 	// Buscar credenciais armazenadas
 	clientID, err := p.storage.Get("stackspot_client_id")
 	if err != nil {
-		return token.TokenResponse{}, fmt.Errorf("client_id não encontrado: %w", err)
+		return token.TokenResponse{}, notConfiguredError(ProviderStackSpot, "client_id não encontrado")
 	}
 
 	clientSecret, err := p.storage.Get("stackspot_client_secret")
 	if err != nil {
-		return token.TokenResponse{}, fmt.Errorf("client_secret não encontrado: %w", err)
+		return token.TokenResponse{}, notConfiguredError(ProviderStackSpot, "client_secret não encontrado")
 	}
 
+	return ExchangeStackSpotToken(context.Background(), p.client, p.tokenURL, StackSpotCredentials{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+	}, scope)
+}
+
+// ExchangeStackSpotToken troca as credenciais por um access token no IdM da StackSpot.
+// Não lê nem grava nada no storage: quem chama decide de onde vêm as credenciais.
+func ExchangeStackSpotToken(ctx context.Context, client *http.Client, tokenURL string, creds StackSpotCredentials, scope token.TokenScope) (token.TokenResponse, error) {
 	// Preparar dados para requisição
 	data := url.Values{}
 	data.Set("grant_type", "client_credentials")
-	data.Set("client_id", clientID)
-	data.Set("client_secret", clientSecret)
-	
+	data.Set("client_id", creds.ClientID)
+	data.Set("client_secret", creds.ClientSecret)
+
 	// Mapear escopo interno para escopo StackSpot
-	if stackSpotScope := p.mapScope(scope); stackSpotScope != "" {
+	if stackSpotScope := mapStackSpotScope(scope); stackSpotScope != "" {
 		data.Set("scope", stackSpotScope)
 	}
 
 	// Fazer requisição HTTP
-	req, err := http.NewRequest("POST", StackSpotTokenURL, bytes.NewBufferString(data.Encode()))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, bytes.NewBufferString(data.Encode()))
 	if err != nil {
 		return token.TokenResponse{}, fmt.Errorf("erro ao criar requisição: %w", err)
 	}
@@ -73,15 +150,14 @@ func (p *StackSpotProvider) GetToken(scope token.TokenScope) (token.TokenRespons
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 	req.Header.Set("Accept", "application/json")
 
-	client := &http.Client{Timeout: 30 * time.Second}
 	resp, err := client.Do(req)
 	if err != nil {
-		return token.TokenResponse{}, fmt.Errorf("erro na requisição HTTP: %w", err)
+		return token.TokenResponse{}, networkError(ProviderStackSpot, "autenticação", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return token.TokenResponse{}, fmt.Errorf("erro na autenticação StackSpot: status %d", resp.StatusCode)
+		return token.TokenResponse{}, responseError(ProviderStackSpot, "autenticação", resp)
 	}
 
 	// Decodificar resposta
@@ -96,6 +172,60 @@ func (p *StackSpotProvider) GetToken(scope token.TokenScope) (token.TokenRespons
 	}, nil
 }
 
+// StartDeviceLogin inicia o login pelo navegador e retorna o código a ser exibido ao usuário
+func (p *StackSpotProvider) StartDeviceLogin(ctx context.Context) (DeviceAuthorization, error) {
+	if p.oauthClientID == "" {
+		return DeviceAuthorization{}, notConfiguredError(ProviderStackSpot, "client do login pelo navegador não configurado: defina auth.stackspot.client_id ou use --client-id")
+	}
+	return p.oauth().requestDeviceCode(ctx, stackSpotDeviceScope)
+}
+
+// CompleteDeviceLogin aguarda a autorização do usuário e salva o refresh token
+func (p *StackSpotProvider) CompleteDeviceLogin(ctx context.Context, authorization DeviceAuthorization) error {
+	tokenResp, err := p.oauth().pollDeviceToken(ctx, authorization)
+	if err != nil {
+		return err
+	}
+	if tokenResp.RefreshToken == "" {
+		return fmt.Errorf("o IdM não retornou refresh token; verifique se o client permite offline_access")
+	}
+
+	if err := p.storage.Set("stackspot_oauth_client_id", p.oauthClientID); err != nil {
+		return fmt.Errorf("erro ao salvar client_id: %w", err)
+	}
+	if err := p.storage.Set("stackspot_refresh_token", tokenResp.RefreshToken); err != nil {
+		return fmt.Errorf("erro ao salvar refresh token: %w", err)
+	}
+	return nil
+}
+
+// Refresh troca o refresh token por um access token e guarda o refresh token rotacionado
+func (p *StackSpotProvider) Refresh(scope token.TokenScope, refreshToken string) (token.TokenResponse, error) {
+	client := p.oauth()
+	if stored, err := p.storage.Get("stackspot_oauth_client_id"); err == nil && stored != "" {
+		client.clientID = stored
+	}
+
+	tokenResp, err := client.refresh(context.Background(), refreshToken)
+	if err != nil {
+		return token.TokenResponse{}, err
+	}
+	if tokenResp.RefreshToken != "" && tokenResp.RefreshToken != refreshToken {
+		if err := p.storage.Set("stackspot_refresh_token", tokenResp.RefreshToken); err != nil {
+			return token.TokenResponse{}, fmt.Errorf("erro ao salvar refresh token: %w", err)
+		}
+	}
+	return tokenResp.tokenResponse(), nil
+}
+
+func (p *StackSpotProvider) oauth() *oauthClient {
+	client := newOAuthClient(ProviderStackSpot, p.client, p.endpoints, p.oauthClientID)
+	if p.wait != nil {
+		client.wait = p.wait
+	}
+	return client
+}
+
 func (p *StackSpotProvider) SaveCredentials(clientID, clientSecret string) error {
 	if err := p.storage.Set("stackspot_client_id", clientID); err != nil {
 		return fmt.Errorf("erro ao salvar client_id: %w", err)
@@ -108,7 +238,33 @@ func (p *StackSpotProvider) SaveCredentials(clientID, clientSecret string) error
 	return nil
 }
 
-func (p *StackSpotProvider) mapScope(scope token.TokenScope) string {
+// DeleteCredentials remove as credenciais de usuário do storage
+func (p *StackSpotProvider) DeleteCredentials() error {
+	return deleteKeys(p.storage, "stackspot_client_id", "stackspot_client_secret",
+		"stackspot_refresh_token", "stackspot_oauth_client_id")
+}
+
+// HasCredentials verifica se há credenciais de usuário ou login pelo navegador salvos
+func (p *StackSpotProvider) HasCredentials() bool {
+	return p.HasDeviceLogin() ||
+		(p.storage.Exists("stackspot_client_id") && p.storage.Exists("stackspot_client_secret"))
+}
+
+// HasDeviceLogin verifica se há refresh token de um login pelo navegador
+func (p *StackSpotProvider) HasDeviceLogin() bool {
+	return p.storage.Exists("stackspot_refresh_token")
+}
+
+// ClientID retorna o client_id salvo
+func (p *StackSpotProvider) ClientID() string {
+	if clientID, err := p.storage.Get("stackspot_client_id"); err == nil {
+		return clientID
+	}
+	clientID, _ := p.storage.Get("stackspot_oauth_client_id")
+	return clientID
+}
+
+func mapStackSpotScope(scope token.TokenScope) string {
 	switch scope {
 	case token.ScopeExecution:
 		return "execution"
@@ -121,4 +277,4 @@ func (p *StackSpotProvider) mapScope(scope token.TokenScope) string {
 	default:
 		return ""
 	}
-}
\ No newline at end of file
+}
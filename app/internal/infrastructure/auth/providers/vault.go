@@ -1,180 +1,385 @@
 package providers
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/PHRaulino/phengineer/internal/infrastructure/auth/aws"
 	"github.com/PHRaulino/phengineer/internal/infrastructure/auth/storage"
 	"github.com/PHRaulino/phengineer/internal/infrastructure/auth/token"
+	"github.com/PHRaulino/phengineer/internal/infrastructure/config"
+	"github.com/PHRaulino/phengineer/internal/infrastructure/httpx"
 )
 
+// DefaultVaultSecretPath caminho padrão das credenciais StackSpot no Vault
+const DefaultVaultSecretPath = "secret/data/stackspot"
+
 type VaultProvider struct {
-	storage storage.StorageAdapter
+	storage     storage.StorageAdapter
+	settings    config.VaultSettings // auth.vault do settings.yml
+	credentials aws.Provider
+	client      *http.Client
+	session     *vaultSession
+	now         func() time.Time
+	tokenURL    string // IdM da StackSpot
 }
 
 func NewVaultProvider(storage storage.StorageAdapter) *VaultProvider {
 	return &VaultProvider{
-		storage: storage,
+		storage:     storage,
+		credentials: aws.NewDefaultChain(),
+		client:      httpx.Default(),
+		session:     &vaultSession{},
+		now:         time.Now,
+		tokenURL:    StackSpotTokenURL,
 	}
 }
 
-type VaultAWSAuthRequest struct {
-	Role string `json:"role"`
+// WithSettings aplica a seção auth.vault do settings.yml sobre a configuração salva no keyring
+func (p *VaultProvider) WithSettings(settings config.VaultSettings) *VaultProvider {
+	p.settings = settings
+	return p
 }
 
-type VaultAWSAuthResponse struct {
-	Auth struct {
-		ClientToken string `json:"client_token"`
-		LeaseDuration int  `json:"lease_duration"`
-		Renewable     bool `json:"renewable"`
-	} `json:"auth"`
+// WithIdM usa o IdM da StackSpot configurado para trocar as credenciais lidas do Vault
+func (p *VaultProvider) WithIdM(settings config.StackSpotSettings) *VaultProvider {
+	p.tokenURL = stackSpotEndpoints(settings).TokenURL
+	return p
 }
 
-type VaultSecretResponse struct {
-	Data struct {
-		Data map[string]interface{} `json:"data"`
-	} `json:"data"`
+// WithAWSCredentials define a fonte das credenciais AWS usadas no login IAM
+func (p *VaultProvider) WithAWSCredentials(credentials aws.Provider) *VaultProvider {
+	p.credentials = credentials
+	return p
 }
 
 func (p *VaultProvider) GetToken(scope token.TokenScope) (token.TokenResponse, error) {
-	// Buscar configurações do Vault
-	vaultURL, err := p.storage.Get("vault_url")
+	ctx := context.Background()
+
+	creds, err := p.stackSpotCredentials(ctx)
 	if err != nil {
-		return token.TokenResponse{}, fmt.Errorf("vault_url não encontrado: %w", err)
+		return token.TokenResponse{}, err
 	}
 
-	vaultRole, err := p.storage.Get("vault_aws_role")
-	if err != nil {
-		return token.TokenResponse{}, fmt.Errorf("vault_aws_role não encontrado: %w", err)
+	// As credenciais do Vault ficam só em memória: gravá-las no storage sobrescreveria
+	// as credenciais pessoais do modo user no keyring
+	return ExchangeStackSpotToken(ctx, p.client, p.tokenURL, creds, scope)
+}
+
+// stackSpotCredentials autentica no Vault (reaproveitando o token em cache) e lê
+// client_id e client_secret do secret configurado
+func (p *VaultProvider) stackSpotCredentials(ctx context.Context) (StackSpotCredentials, error) {
+	cfg := p.ResolveConfig()
+
+	if cfg.Address == "" {
+		return StackSpotCredentials{}, notConfiguredError(ProviderVault, "endereço do Vault não configurado (vault_url, auth.vault.address ou VAULT_ADDR)")
 	}
 
-	// Autenticar com Vault usando AWS IAM
-	vaultToken, err := p.authenticateWithAWS(vaultURL, vaultRole)
+	method, err := p.authMethod(cfg)
 	if err != nil {
-		return token.TokenResponse{}, fmt.Errorf("erro na autenticação AWS/Vault: %w", err)
+		return StackSpotCredentials{}, err
 	}
 
-	// Buscar credenciais StackSpot no Vault
-	stackSpotCreds, err := p.getStackSpotCredentials(vaultURL, vaultToken)
+	client := p.vaultClient(cfg)
+	key := strings.Join([]string{cfg.Address, cfg.Namespace, cfg.Method, cfg.Mount, cfg.Role, cfg.AppRole.RoleID}, "|")
+
+	data, err := p.readSecret(ctx, client, method, key, cfg)
+	var apiErr *VaultAPIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusForbidden {
+		// Token revogado ou expirado antes do previsto: descartar o cache e tentar com novo login
+		p.session.invalidate()
+		data, err = p.readSecret(ctx, client, method, key, cfg)
+	}
 	if err != nil {
-		return token.TokenResponse{}, fmt.Errorf("erro ao buscar credenciais StackSpot: %w", err)
+		return StackSpotCredentials{}, fmt.Errorf("erro ao buscar credenciais StackSpot: %w", err)
 	}
 
-	// Usar provider StackSpot para gerar token
-	stackSpotProvider := NewStackSpotProvider(p.storage)
-	
-	// Salvar temporariamente as credenciais
-	if err := stackSpotProvider.SaveCredentials(
-		stackSpotCreds["client_id"].(string),
-		stackSpotCreds["client_secret"].(string),
-	); err != nil {
-		return token.TokenResponse{}, fmt.Errorf("erro ao salvar credenciais temporárias: %w", err)
+	clientID, err := stringField(data, cfg.SecretPath, cfg.Fields.ClientID)
+	if err != nil {
+		return StackSpotCredentials{}, err
 	}
-
-	return stackSpotProvider.GetToken(scope)
+	clientSecret, err := stringField(data, cfg.SecretPath, cfg.Fields.ClientSecret)
+	if err != nil {
+		return StackSpotCredentials{}, err
+	}
+	return StackSpotCredentials{ClientID: clientID, ClientSecret: clientSecret}, nil
 }
 
-func (p *VaultProvider) authenticateWithAWS(vaultURL, role string) (string, error) {
-	// Verificar se estamos em ambiente AWS (EC2, ECS, Lambda, etc.)
-	awsRegion := os.Getenv("AWS_REGION")
-	if awsRegion == "" {
-		awsRegion = os.Getenv("AWS_DEFAULT_REGION")
-	}
-	if awsRegion == "" {
-		awsRegion = "us-east-1" // fallback
+// readSecret obtém o token do Vault e lê o secret detectando a versão do KV
+func (p *VaultProvider) readSecret(ctx context.Context, client *vaultClient, method vaultAuthMethod, key string, cfg config.VaultSettings) (map[string]any, error) {
+	vaultToken, err := p.session.token(ctx, key, client, method, p.now())
+	if err != nil {
+		return nil, fmt.Errorf("erro na autenticação %s/Vault: %w", method.Type(), err)
 	}
 
-	// Para simplificar, vamos usar token IAM role-based
-	// Em produção, seria necessário implementar assinatura AWS SigV4
-	client := &http.Client{Timeout: 30 * time.Second}
-	
-	// Endpoint para auth AWS no Vault
-	authURL := fmt.Sprintf("%s/v1/auth/aws/login", strings.TrimSuffix(vaultURL, "/"))
-	
-	authReq := VaultAWSAuthRequest{
-		Role: role,
+	mount, err := p.kvMount(ctx, client, vaultToken, cfg)
+	if err != nil {
+		return nil, err
 	}
 
-	reqBody, _ := json.Marshal(authReq)
-	req, err := http.NewRequest("POST", authURL, strings.NewReader(string(reqBody)))
-	if err != nil {
-		return "", fmt.Errorf("erro ao criar requisição de auth: %w", err)
+	return readKVSecret(ctx, client, vaultToken, mount, cfg.SecretPath)
+}
+
+// kvMount usa a versão do KV configurada ou detecta o mount via sys/mounts
+func (p *VaultProvider) kvMount(ctx context.Context, client *vaultClient, vaultToken string, cfg config.VaultSettings) (kvMount, error) {
+	if cfg.KVVersion != 0 {
+		// Sem detecção o mount é o primeiro segmento do caminho
+		first, _, _ := strings.Cut(strings.Trim(cfg.SecretPath, "/"), "/")
+		return kvMount{Path: first + "/", Version: cfg.KVVersion}, nil
 	}
 
-	req.Header.Set("Content-Type", "application/json")
+	if mount, ok := p.session.mount(cfg.SecretPath); ok {
+		return mount, nil
+	}
 
-	resp, err := client.Do(req)
+	mount, err := detectKVMount(ctx, client, vaultToken, cfg.SecretPath)
 	if err != nil {
-		return "", fmt.Errorf("erro na requisição de auth: %w", err)
+		return kvMount{}, err
 	}
-	defer resp.Body.Close()
+	p.session.setMount(cfg.SecretPath, mount)
+	return mount, nil
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("falha na autenticação AWS: status %d", resp.StatusCode)
+// ResolveConfig combina a configuração do keyring, do settings.yml e das variáveis VAULT_*.
+// Campos do settings.yml têm precedência sobre o keyring; as variáveis de ambiente só preenchem lacunas.
+func (p *VaultProvider) ResolveConfig() config.VaultSettings {
+	cfg := config.VaultSettings{
+		Address:    p.stored("vault_url"),
+		Namespace:  p.stored("vault_namespace"),
+		Method:     p.stored("vault_auth_method"),
+		Mount:      p.stored("vault_auth_mount", "vault_aws_mount"),
+		Role:       p.stored("vault_role", "vault_aws_role"),
+		SecretPath: p.stored("vault_stackspot_path"),
+		KVVersion:  storedInt(p.stored("vault_kv_version")),
+		Fields: config.VaultFields{
+			ClientID:     p.stored("vault_client_id_field"),
+			ClientSecret: p.stored("vault_client_secret_field"),
+		},
+		AWS: config.VaultAWS{
+			ServerID:  p.stored("vault_aws_server_id"),
+			STSRegion: p.stored("vault_aws_sts_region"),
+		},
+		AppRole: config.VaultAppRole{
+			RoleID: p.stored("vault_approle_role_id"),
+		},
+		JWT: config.VaultJWT{
+			TokenFile:      p.stored("vault_jwt_token_file"),
+			GitHubAudience: p.stored("vault_jwt_github_audience"),
+		},
+		Kubernetes: config.VaultKubernetes{
+			TokenFile: p.stored("vault_kubernetes_token_file"),
+		},
 	}
 
-	var authResp VaultAWSAuthResponse
-	if err := json.NewDecoder(resp.Body).Decode(&authResp); err != nil {
-		return "", fmt.Errorf("erro ao decodificar resposta de auth: %w", err)
+	overrides := p.settings
+	override(&cfg.Address, overrides.Address)
+	override(&cfg.Namespace, overrides.Namespace)
+	override(&cfg.Method, overrides.Method)
+	override(&cfg.Mount, overrides.Mount)
+	override(&cfg.Role, overrides.Role)
+	override(&cfg.SecretPath, overrides.SecretPath)
+	override(&cfg.Fields.ClientID, overrides.Fields.ClientID)
+	override(&cfg.Fields.ClientSecret, overrides.Fields.ClientSecret)
+	if overrides.KVVersion != 0 {
+		cfg.KVVersion = overrides.KVVersion
 	}
+	override(&cfg.AWS.ServerID, overrides.AWS.ServerID)
+	override(&cfg.AWS.STSRegion, overrides.AWS.STSRegion)
+	override(&cfg.AppRole.RoleID, overrides.AppRole.RoleID)
+	override(&cfg.JWT.TokenFile, overrides.JWT.TokenFile)
+	override(&cfg.JWT.GitHubAudience, overrides.JWT.GitHubAudience)
+	override(&cfg.Kubernetes.TokenFile, overrides.Kubernetes.TokenFile)
+
+	fallback(&cfg.Address, os.Getenv("VAULT_ADDR"))
+	fallback(&cfg.Namespace, os.Getenv("VAULT_NAMESPACE"))
+	fallback(&cfg.AppRole.RoleID, os.Getenv("VAULT_ROLE_ID"))
+
+	fallback(&cfg.Method, config.VaultMethodAWS)
+	fallback(&cfg.Mount, cfg.Method)
+	fallback(&cfg.SecretPath, DefaultVaultSecretPath)
+	fallback(&cfg.Fields.ClientID, "client_id")
+	fallback(&cfg.Fields.ClientSecret, "client_secret")
+	fallback(&cfg.Kubernetes.TokenFile, DefaultKubernetesTokenFile)
+
+	return cfg
+}
 
-	return authResp.Auth.ClientToken, nil
+// authMethod cria o método de login configurado
+func (p *VaultProvider) authMethod(cfg config.VaultSettings) (vaultAuthMethod, error) {
+	switch cfg.Method {
+	case config.VaultMethodAWS:
+		return &awsAuth{
+			mount:       cfg.Mount,
+			role:        cfg.Role,
+			serverID:    cfg.AWS.ServerID,
+			stsRegion:   cfg.AWS.STSRegion,
+			credentials: p.credentials,
+		}, nil
+	case config.VaultMethodAppRole:
+		return &appRoleAuth{
+			mount:    cfg.Mount,
+			roleID:   cfg.AppRole.RoleID,
+			secretID: p.secret("vault_approle_secret_id", "VAULT_SECRET_ID"),
+		}, nil
+	case config.VaultMethodJWT:
+		return &jwtAuth{
+			mount:          cfg.Mount,
+			role:           cfg.Role,
+			tokenFile:      cfg.JWT.TokenFile,
+			githubAudience: cfg.JWT.GitHubAudience,
+			http:           p.client,
+		}, nil
+	case config.VaultMethodKubernetes:
+		return &kubernetesAuth{
+			mount:     cfg.Mount,
+			role:      cfg.Role,
+			tokenFile: cfg.Kubernetes.TokenFile,
+		}, nil
+	case config.VaultMethodToken:
+		return &tokenAuth{token: p.secret("vault_token", "VAULT_TOKEN")}, nil
+	default:
+		return nil, fmt.Errorf("método de autenticação do Vault desconhecido: %s", cfg.Method)
+	}
 }
 
-func (p *VaultProvider) getStackSpotCredentials(vaultURL, vaultToken string) (map[string]interface{}, error) {
-	secretPath, err := p.storage.Get("vault_stackspot_path")
-	if err != nil {
-		// Usar path padrão se não configurado
-		secretPath = "secret/data/stackspot"
+func (p *VaultProvider) vaultClient(cfg config.VaultSettings) *vaultClient {
+	return &vaultClient{
+		address:   cfg.Address,
+		namespace: cfg.Namespace,
+		http:      p.client,
 	}
+}
 
-	client := &http.Client{Timeout: 30 * time.Second}
-	
-	secretURL := fmt.Sprintf("%s/v1/%s", strings.TrimSuffix(vaultURL, "/"), secretPath)
-	
-	req, err := http.NewRequest("GET", secretURL, nil)
-	if err != nil {
-		return nil, fmt.Errorf("erro ao criar requisição de secret: %w", err)
+// SaveConfig salva a configuração do Vault no keyring. secret é o secret_id do
+// AppRole ou o token do método token; é ignorado nos demais métodos.
+func (p *VaultProvider) SaveConfig(cfg config.VaultSettings, secret string) error {
+	if err := cfg.Validate(); err != nil {
+		return err
 	}
 
-	req.Header.Set("X-Vault-Token", vaultToken)
+	values := vaultConfigValues(cfg)
 
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("erro na requisição de secret: %w", err)
+	switch cfg.Method {
+	case config.VaultMethodAppRole:
+		values["vault_approle_secret_id"] = secret
+	case config.VaultMethodToken:
+		values["vault_token"] = secret
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("erro ao buscar secret: status %d", resp.StatusCode)
+	for key, value := range values {
+		if value == "" {
+			if err := deleteKeys(p.storage, key); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := p.storage.Set(key, value); err != nil {
+			return fmt.Errorf("erro ao salvar %s: %w", key, err)
+		}
 	}
 
-	var secretResp VaultSecretResponse
-	if err := json.NewDecoder(resp.Body).Decode(&secretResp); err != nil {
-		return nil, fmt.Errorf("erro ao decodificar secret: %w", err)
+	return nil
+}
+
+// DeleteConfig remove toda a configuração do Vault do storage
+func (p *VaultProvider) DeleteConfig() error {
+	keys := make([]string, 0)
+	for key := range vaultConfigValues(config.VaultSettings{}) {
+		keys = append(keys, key)
 	}
+	return deleteKeys(p.storage, keys...)
+}
 
-	return secretResp.Data.Data, nil
+// HasConfig verifica se há endereço do Vault salvo
+func (p *VaultProvider) HasConfig() bool {
+	return p.storage.Exists("vault_url")
 }
 
-func (p *VaultProvider) SaveConfig(vaultURL, awsRole, stackspotPath string) error {
-	if err := p.storage.Set("vault_url", vaultURL); err != nil {
-		return fmt.Errorf("erro ao salvar vault_url: %w", err)
+// vaultConfigValues mapeia a configuração para as chaves do storage; segredos e chaves antigas ficam vazios
+func vaultConfigValues(cfg config.VaultSettings) map[string]string {
+	values := map[string]string{
+		"vault_url":                   cfg.Address,
+		"vault_namespace":             cfg.Namespace,
+		"vault_auth_method":           cfg.Method,
+		"vault_auth_mount":            cfg.Mount,
+		"vault_role":                  cfg.Role,
+		"vault_stackspot_path":        cfg.SecretPath,
+		"vault_kv_version":            "",
+		"vault_client_id_field":       cfg.Fields.ClientID,
+		"vault_client_secret_field":   cfg.Fields.ClientSecret,
+		"vault_aws_server_id":         cfg.AWS.ServerID,
+		"vault_aws_sts_region":        cfg.AWS.STSRegion,
+		"vault_approle_role_id":       cfg.AppRole.RoleID,
+		"vault_jwt_token_file":        cfg.JWT.TokenFile,
+		"vault_jwt_github_audience":   cfg.JWT.GitHubAudience,
+		"vault_kubernetes_token_file": cfg.Kubernetes.TokenFile,
+		"vault_approle_secret_id":     "",
+		"vault_token":                 "",
+		// Chaves antigas substituídas por vault_role e vault_auth_mount
+		"vault_aws_role":  "",
+		"vault_aws_mount": "",
 	}
 
-	if err := p.storage.Set("vault_aws_role", awsRole); err != nil {
-		return fmt.Errorf("erro ao salvar vault_aws_role: %w", err)
+	if cfg.KVVersion != 0 {
+		values["vault_kv_version"] = strconv.Itoa(cfg.KVVersion)
 	}
+	return values
+}
 
-	if stackspotPath != "" {
-		if err := p.storage.Set("vault_stackspot_path", stackspotPath); err != nil {
-			return fmt.Errorf("erro ao salvar vault_stackspot_path: %w", err)
+// deleteKeys remove as chaves existentes do storage
+func deleteKeys(storage storage.StorageAdapter, keys ...string) error {
+	for _, key := range keys {
+		if !storage.Exists(key) {
+			continue
+		}
+		if err := storage.Delete(key); err != nil {
+			return fmt.Errorf("erro ao remover %s: %w", key, err)
 		}
 	}
-
 	return nil
-}
\ No newline at end of file
+}
+
+// stored retorna o primeiro valor encontrado no storage
+func (p *VaultProvider) stored(keys ...string) string {
+	for _, key := range keys {
+		if value, err := p.storage.Get(key); err == nil && value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// secret lê um segredo do storage ou da variável de ambiente
+func (p *VaultProvider) secret(key, env string) string {
+	if value := p.stored(key); value != "" {
+		return value
+	}
+	return os.Getenv(env)
+}
+
+// storedInt converte um valor numérico do storage; valores inválidos viram zero
+func storedInt(value string) int {
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		return 0
+	}
+	return number
+}
+
+func override(target *string, value string) {
+	if value != "" {
+		*target = value
+	}
+}
+
+func fallback(target *string, value string) {
+	if *target == "" {
+		*target = value
+	}
+}
@@ -0,0 +1,380 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/PHRaulino/phengineer/internal/infrastructure/auth/aws"
+	"github.com/PHRaulino/phengineer/internal/infrastructure/config"
+)
+
+const (
+	// VaultAWSServerIDHeader cabeçalho validado pelo iam_server_id_header_value do Vault
+	VaultAWSServerIDHeader = "X-Vault-AWS-IAM-Server-ID"
+	// VaultNamespaceHeader cabeçalho de namespace do Vault Enterprise
+	VaultNamespaceHeader = "X-Vault-Namespace"
+	// DefaultKubernetesTokenFile token da service account montado no pod
+	DefaultKubernetesTokenFile = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+)
+
+// VaultAuthResponse resposta dos endpoints de login do Vault
+type VaultAuthResponse struct {
+	Auth struct {
+		ClientToken   string `json:"client_token"`
+		LeaseDuration int    `json:"lease_duration"`
+		Renewable     bool   `json:"renewable"`
+	} `json:"auth"`
+}
+
+// VaultAWSAuthRequest payload do login IAM: a requisição sts:GetCallerIdentity
+// assinada é enviada ao Vault, que a repassa ao STS para validar a identidade
+type VaultAWSAuthRequest struct {
+	Role                 string `json:"role"`
+	IAMHTTPRequestMethod string `json:"iam_http_request_method"`
+	IAMRequestURL        string `json:"iam_request_url"`     // base64
+	IAMRequestBody       string `json:"iam_request_body"`    // base64
+	IAMRequestHeaders    string `json:"iam_request_headers"` // base64 do JSON dos cabeçalhos
+}
+
+type vaultErrorResponse struct {
+	Errors []string `json:"errors"`
+}
+
+// VaultAPIError resposta de erro da API do Vault
+type VaultAPIError struct {
+	StatusCode int
+	Errors     []string
+}
+
+func (e *VaultAPIError) Error() string {
+	if len(e.Errors) > 0 {
+		return fmt.Sprintf("status %d: %s", e.StatusCode, strings.Join(e.Errors, "; "))
+	}
+	return fmt.Sprintf("status %d", e.StatusCode)
+}
+
+// vaultLease token do Vault com a duração do lease informada no login
+type vaultLease struct {
+	Token     string
+	Duration  time.Duration // zero: token sem expiração
+	Renewable bool
+}
+
+func newVaultLease(resp VaultAuthResponse) vaultLease {
+	return vaultLease{
+		Token:     resp.Auth.ClientToken,
+		Duration:  time.Duration(resp.Auth.LeaseDuration) * time.Second,
+		Renewable: resp.Auth.Renewable,
+	}
+}
+
+// vaultClient cliente HTTP mínimo da API do Vault
+type vaultClient struct {
+	address   string
+	namespace string
+	http      *http.Client
+}
+
+// do executa uma chamada na API do Vault e decodifica a resposta em out
+func (c *vaultClient) do(ctx context.Context, method, path, vaultToken string, payload, out any) error {
+	var body io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("erro ao serializar requisição: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+
+	endpoint := fmt.Sprintf("%s/v1/%s", strings.TrimSuffix(c.address, "/"), strings.TrimPrefix(path, "/"))
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		return fmt.Errorf("erro ao criar requisição: %w", err)
+	}
+
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if vaultToken != "" {
+		req.Header.Set("X-Vault-Token", vaultToken)
+	}
+	if c.namespace != "" {
+		req.Header.Set(VaultNamespaceHeader, c.namespace)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return networkError(ProviderVault, path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		var vaultErr vaultErrorResponse
+		_ = json.NewDecoder(resp.Body).Decode(&vaultErr)
+		login := strings.HasPrefix(path, "auth/") && strings.HasSuffix(path, "/login")
+		return &ProviderError{
+			Provider: ProviderVault,
+			Op:       path,
+			Kind:     vaultStatusKind(resp.StatusCode, login),
+			Status:   resp.StatusCode,
+			Err:      &VaultAPIError{StatusCode: resp.StatusCode, Errors: vaultErr.Errors},
+		}
+	}
+
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("erro ao decodificar resposta: %w", err)
+	}
+	return nil
+}
+
+// login executa o login em auth/<mount>/login e retorna o client token com seu lease
+func (c *vaultClient) login(ctx context.Context, mount string, payload any) (vaultLease, error) {
+	var authResp VaultAuthResponse
+	if err := c.do(ctx, http.MethodPost, fmt.Sprintf("auth/%s/login", strings.Trim(mount, "/")), "", payload, &authResp); err != nil {
+		return vaultLease{}, err
+	}
+
+	if authResp.Auth.ClientToken == "" {
+		return vaultLease{}, fmt.Errorf("resposta de auth sem client_token")
+	}
+	return newVaultLease(authResp), nil
+}
+
+// renewSelf renova o lease do token em auth/token/renew-self
+func (c *vaultClient) renewSelf(ctx context.Context, vaultToken string) (vaultLease, error) {
+	var authResp VaultAuthResponse
+	if err := c.do(ctx, http.MethodPost, "auth/token/renew-self", vaultToken, map[string]string{}, &authResp); err != nil {
+		return vaultLease{}, err
+	}
+
+	lease := newVaultLease(authResp)
+	if lease.Token == "" {
+		lease.Token = vaultToken
+	}
+	return lease, nil
+}
+
+// lookupSelf consulta o TTL e se o token é renovável em auth/token/lookup-self
+func (c *vaultClient) lookupSelf(ctx context.Context, vaultToken string) (vaultLease, error) {
+	var lookup struct {
+		Data struct {
+			TTL       int  `json:"ttl"`
+			Renewable bool `json:"renewable"`
+		} `json:"data"`
+	}
+	if err := c.do(ctx, http.MethodGet, "auth/token/lookup-self", vaultToken, nil, &lookup); err != nil {
+		return vaultLease{}, err
+	}
+
+	return vaultLease{
+		Token:     vaultToken,
+		Duration:  time.Duration(lookup.Data.TTL) * time.Second,
+		Renewable: lookup.Data.Renewable,
+	}, nil
+}
+
+// vaultAuthMethod método de login no Vault
+type vaultAuthMethod interface {
+	Type() string
+	Login(ctx context.Context, client *vaultClient) (vaultLease, error)
+}
+
+// awsAuth login IAM assinando sts:GetCallerIdentity
+type awsAuth struct {
+	mount       string
+	role        string
+	serverID    string
+	stsRegion   string
+	credentials aws.Provider
+}
+
+func (a *awsAuth) Type() string { return config.VaultMethodAWS }
+
+func (a *awsAuth) Login(ctx context.Context, client *vaultClient) (vaultLease, error) {
+	creds, err := a.credentials.Retrieve(ctx)
+	if err != nil {
+		return vaultLease{}, err
+	}
+
+	authReq, err := buildAWSLoginRequest(creds, a.role, a.stsRegion, a.serverID, time.Now())
+	if err != nil {
+		return vaultLease{}, err
+	}
+
+	return client.login(ctx, a.mount, authReq)
+}
+
+// buildAWSLoginRequest assina sts:GetCallerIdentity e codifica os campos em base64 como o Vault espera
+func buildAWSLoginRequest(creds aws.Credentials, role, stsRegion, serverID string, now time.Time) (VaultAWSAuthRequest, error) {
+	headers := map[string]string{}
+	if serverID != "" {
+		headers[VaultAWSServerIDHeader] = serverID
+	}
+
+	stsReq, body, err := aws.GetCallerIdentityRequest(creds, stsRegion, headers, now)
+	if err != nil {
+		return VaultAWSAuthRequest{}, err
+	}
+
+	headersJSON, err := json.Marshal(stsReq.Header)
+	if err != nil {
+		return VaultAWSAuthRequest{}, fmt.Errorf("erro ao serializar cabeçalhos STS: %w", err)
+	}
+
+	return VaultAWSAuthRequest{
+		Role:                 role,
+		IAMHTTPRequestMethod: stsReq.Method,
+		IAMRequestURL:        base64.StdEncoding.EncodeToString([]byte(stsReq.URL.String())),
+		IAMRequestBody:       base64.StdEncoding.EncodeToString(body),
+		IAMRequestHeaders:    base64.StdEncoding.EncodeToString(headersJSON),
+	}, nil
+}
+
+// appRoleAuth login com role_id e secret_id
+type appRoleAuth struct {
+	mount    string
+	roleID   string
+	secretID string
+}
+
+func (a *appRoleAuth) Type() string { return config.VaultMethodAppRole }
+
+func (a *appRoleAuth) Login(ctx context.Context, client *vaultClient) (vaultLease, error) {
+	if a.roleID == "" {
+		return vaultLease{}, notConfiguredError(ProviderVault, "role_id do AppRole não configurado")
+	}
+
+	payload := map[string]string{"role_id": a.roleID}
+	if a.secretID != "" {
+		payload["secret_id"] = a.secretID
+	}
+	return client.login(ctx, a.mount, payload)
+}
+
+// jwtAuth login JWT/OIDC com token de arquivo ou ID token do GitHub Actions
+type jwtAuth struct {
+	mount          string
+	role           string
+	tokenFile      string
+	githubAudience string
+	http           *http.Client
+}
+
+func (a *jwtAuth) Type() string { return config.VaultMethodJWT }
+
+func (a *jwtAuth) Login(ctx context.Context, client *vaultClient) (vaultLease, error) {
+	jwt, err := a.token(ctx)
+	if err != nil {
+		return vaultLease{}, err
+	}
+	return client.login(ctx, a.mount, map[string]string{"role": a.role, "jwt": jwt})
+}
+
+// token lê o JWT do arquivo configurado ou solicita o ID token ao GitHub Actions
+func (a *jwtAuth) token(ctx context.Context) (string, error) {
+	if a.tokenFile != "" {
+		return readTokenFile(a.tokenFile)
+	}
+
+	requestURL := os.Getenv("ACTIONS_ID_TOKEN_REQUEST_URL")
+	requestToken := os.Getenv("ACTIONS_ID_TOKEN_REQUEST_TOKEN")
+	if requestURL == "" || requestToken == "" {
+		return "", fmt.Errorf("JWT não encontrado: configure jwt.token_file ou execute no GitHub Actions com permissions id-token: write")
+	}
+
+	tokenURL, err := url.Parse(requestURL)
+	if err != nil {
+		return "", fmt.Errorf("ACTIONS_ID_TOKEN_REQUEST_URL inválida: %w", err)
+	}
+	if a.githubAudience != "" {
+		query := tokenURL.Query()
+		query.Set("audience", a.githubAudience)
+		tokenURL.RawQuery = query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, tokenURL.String(), nil)
+	if err != nil {
+		return "", fmt.Errorf("erro ao criar requisição do ID token: %w", err)
+	}
+	req.Header.Set("Authorization", "bearer "+requestToken)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := a.http.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("erro ao solicitar ID token do GitHub Actions: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("erro ao solicitar ID token do GitHub Actions: status %d", resp.StatusCode)
+	}
+
+	var idToken struct {
+		Value string `json:"value"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&idToken); err != nil {
+		return "", fmt.Errorf("erro ao decodificar ID token: %w", err)
+	}
+	if idToken.Value == "" {
+		return "", fmt.Errorf("GitHub Actions retornou ID token vazio")
+	}
+	return idToken.Value, nil
+}
+
+// kubernetesAuth login com o token da service account
+type kubernetesAuth struct {
+	mount     string
+	role      string
+	tokenFile string
+}
+
+func (a *kubernetesAuth) Type() string { return config.VaultMethodKubernetes }
+
+func (a *kubernetesAuth) Login(ctx context.Context, client *vaultClient) (vaultLease, error) {
+	jwt, err := readTokenFile(a.tokenFile)
+	if err != nil {
+		return vaultLease{}, err
+	}
+	return client.login(ctx, a.mount, map[string]string{"role": a.role, "jwt": jwt})
+}
+
+// tokenAuth usa um token do Vault já emitido
+type tokenAuth struct {
+	token string
+}
+
+func (a *tokenAuth) Type() string { return config.VaultMethodToken }
+
+func (a *tokenAuth) Login(ctx context.Context, client *vaultClient) (vaultLease, error) {
+	if a.token == "" {
+		return vaultLease{}, notConfiguredError(ProviderVault, "token do Vault não configurado (keyring vault_token ou VAULT_TOKEN)")
+	}
+	// Sem login: o TTL vem do próprio token para permitir a renovação
+	return client.lookupSelf(ctx, a.token)
+}
+
+// readTokenFile lê um token de arquivo removendo espaços
+func readTokenFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("erro ao ler token de %s: %w", path, err)
+	}
+
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", fmt.Errorf("token vazio em %s", path)
+	}
+	return token, nil
+}
@@ -0,0 +1,167 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ErrVaultSecretNotFound o caminho configurado não contém um secret com dados
+var ErrVaultSecretNotFound = errors.New("secret não encontrado no Vault")
+
+// VaultFieldError campo de credencial ausente ou com tipo inválido no secret
+type VaultFieldError struct {
+	Path   string
+	Field  string
+	Reason string
+}
+
+func (e *VaultFieldError) Error() string {
+	return fmt.Sprintf("campo '%s' do secret %s %s", e.Field, e.Path, e.Reason)
+}
+
+// kvMount mount do secrets engine KV que contém o secret
+type kvMount struct {
+	Path    string // com barra final, ex.: "secret/"
+	Version int
+}
+
+// readPath monta o caminho da API de leitura: no KV v2 os dados ficam em <mount>data/<secret>
+func (m kvMount) readPath(secretPath string) string {
+	secretPath = strings.Trim(secretPath, "/")
+	if m.Version != 2 {
+		return secretPath
+	}
+
+	relative := strings.TrimPrefix(secretPath, strings.TrimSuffix(m.Path, "/"))
+	relative = strings.TrimPrefix(relative, "/")
+	if strings.HasPrefix(relative, "data/") {
+		return secretPath
+	}
+	return m.Path + "data/" + relative
+}
+
+type vaultMountInfo struct {
+	Type    string            `json:"type"`
+	Options map[string]string `json:"options"`
+}
+
+// detectKVMount identifica o mount e a versão do KV do caminho via sys/mounts.
+// Sem permissão de leitura em sys/mounts, assume v2 quando o caminho contém /data/.
+func detectKVMount(ctx context.Context, client *vaultClient, vaultToken, secretPath string) (kvMount, error) {
+	secretPath = strings.Trim(secretPath, "/") + "/"
+
+	var raw map[string]json.RawMessage
+	if err := client.do(ctx, http.MethodGet, "sys/mounts", vaultToken, nil, &raw); err != nil {
+		var apiErr *VaultAPIError
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusForbidden {
+			return guessKVMount(secretPath), nil
+		}
+		return kvMount{}, fmt.Errorf("erro ao consultar sys/mounts: %w", err)
+	}
+
+	mounts := parseMounts(raw)
+
+	var found kvMount
+	for path, info := range mounts {
+		if !strings.HasPrefix(secretPath, path) || len(path) <= len(found.Path) {
+			continue
+		}
+		if info.Type != "kv" && info.Type != "generic" {
+			return kvMount{}, fmt.Errorf("mount %s é do tipo %s, esperado kv", path, info.Type)
+		}
+		found = kvMount{Path: path, Version: 1}
+		if info.Options["version"] == "2" {
+			found.Version = 2
+		}
+	}
+
+	if found.Path == "" {
+		return kvMount{}, fmt.Errorf("nenhum mount KV encontrado para %s", strings.TrimSuffix(secretPath, "/"))
+	}
+	return found, nil
+}
+
+// parseMounts aceita as respostas com os mounts no topo ou dentro de "data"
+func parseMounts(raw map[string]json.RawMessage) map[string]vaultMountInfo {
+	if data, ok := raw["data"]; ok {
+		var nested map[string]vaultMountInfo
+		if err := json.Unmarshal(data, &nested); err == nil {
+			return nested
+		}
+	}
+
+	mounts := make(map[string]vaultMountInfo)
+	for path, value := range raw {
+		if !strings.HasSuffix(path, "/") {
+			continue
+		}
+		var info vaultMountInfo
+		if err := json.Unmarshal(value, &info); err == nil {
+			mounts[path] = info
+		}
+	}
+	return mounts
+}
+
+func guessKVMount(secretPath string) kvMount {
+	if index := strings.Index(secretPath, "/data/"); index > 0 {
+		return kvMount{Path: secretPath[:index+1], Version: 2}
+	}
+	return kvMount{Version: 1}
+}
+
+// readKVSecret lê o secret e retorna os dados conforme a versão do KV
+func readKVSecret(ctx context.Context, client *vaultClient, vaultToken string, mount kvMount, secretPath string) (map[string]any, error) {
+	path := mount.readPath(secretPath)
+
+	var resp struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := client.do(ctx, http.MethodGet, path, vaultToken, nil, &resp); err != nil {
+		var apiErr *VaultAPIError
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+			return nil, fmt.Errorf("%w: %s", ErrVaultSecretNotFound, path)
+		}
+		return nil, err
+	}
+
+	var data map[string]any
+	if mount.Version == 2 {
+		var v2 struct {
+			Data map[string]any `json:"data"`
+		}
+		if err := json.Unmarshal(resp.Data, &v2); err != nil {
+			return nil, fmt.Errorf("erro ao decodificar secret KV v2: %w", err)
+		}
+		data = v2.Data
+	} else if err := json.Unmarshal(resp.Data, &data); err != nil {
+		return nil, fmt.Errorf("erro ao decodificar secret KV v1: %w", err)
+	}
+
+	// KV v2 retorna data nulo para versões removidas
+	if len(data) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrVaultSecretNotFound, path)
+	}
+	return data, nil
+}
+
+// stringField extrai um campo texto obrigatório do secret
+func stringField(data map[string]any, path, field string) (string, error) {
+	value, ok := data[field]
+	if !ok {
+		return "", &VaultFieldError{Path: path, Field: field, Reason: "não existe"}
+	}
+
+	text, ok := value.(string)
+	if !ok {
+		return "", &VaultFieldError{Path: path, Field: field, Reason: fmt.Sprintf("deveria ser texto, encontrado %T", value)}
+	}
+	if text == "" {
+		return "", &VaultFieldError{Path: path, Field: field, Reason: "está vazio"}
+	}
+	return text, nil
+}
@@ -0,0 +1,98 @@
+package providers
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// vaultSession mantém em memória o token do Vault e renova o lease antes de expirar.
+// O token nunca é gravado no storage.
+type vaultSession struct {
+	mu        sync.Mutex
+	key       string // configuração que emitiu o token; outra configuração força novo login
+	lease     vaultLease
+	expiresAt time.Time // zero: token sem expiração
+	kvMounts  map[string]kvMount
+}
+
+// token retorna o token em cache, renovando-o via renew-self quando resta menos de
+// um terço do lease, ou faz um novo login se não houver token ou a renovação falhar
+func (s *vaultSession) token(ctx context.Context, key string, client *vaultClient, method vaultAuthMethod, now time.Time) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.key != key {
+		s.reset(key)
+	}
+
+	if s.lease.Token != "" {
+		if s.fresh(now) {
+			return s.lease.Token, nil
+		}
+
+		if s.lease.Renewable && now.Before(s.expiresAt) {
+			remaining := s.expiresAt.Sub(now)
+			renewed, err := client.renewSelf(ctx, s.lease.Token)
+			// Lease que não passa do restante indica max_ttl atingido: só um novo login resolve
+			if err == nil && renewed.Duration > remaining {
+				s.store(renewed, now)
+				return s.lease.Token, nil
+			}
+		}
+	}
+
+	lease, err := method.Login(ctx, client)
+	if err != nil {
+		return "", err
+	}
+	s.store(lease, now)
+	return s.lease.Token, nil
+}
+
+// invalidate descarta o token em cache, por exemplo após um 403 do Vault
+func (s *vaultSession) invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lease = vaultLease{}
+	s.expiresAt = time.Time{}
+}
+
+// mount retorna o mount KV já detectado para o caminho
+func (s *vaultSession) mount(path string) (kvMount, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	mount, ok := s.kvMounts[path]
+	return mount, ok
+}
+
+func (s *vaultSession) setMount(path string, mount kvMount) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.kvMounts == nil {
+		s.kvMounts = make(map[string]kvMount)
+	}
+	s.kvMounts[path] = mount
+}
+
+func (s *vaultSession) fresh(now time.Time) bool {
+	if s.expiresAt.IsZero() {
+		return true
+	}
+	return now.Before(s.expiresAt.Add(-s.lease.Duration / 3))
+}
+
+func (s *vaultSession) store(lease vaultLease, now time.Time) {
+	s.lease = lease
+	s.expiresAt = time.Time{}
+	if lease.Duration > 0 {
+		s.expiresAt = now.Add(lease.Duration)
+	}
+}
+
+func (s *vaultSession) reset(key string) {
+	s.key = key
+	s.lease = vaultLease{}
+	s.expiresAt = time.Time{}
+	s.kvMounts = nil
+}
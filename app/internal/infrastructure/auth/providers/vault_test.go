@@ -0,0 +1,567 @@
+package providers
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PHRaulino/phengineer/internal/infrastructure/auth/aws"
+	"github.com/PHRaulino/phengineer/internal/infrastructure/auth/storage"
+	"github.com/PHRaulino/phengineer/internal/infrastructure/auth/token"
+	"github.com/PHRaulino/phengineer/internal/infrastructure/config"
+)
+
+// staticCredentials fonte de credenciais AWS fixa para os testes
+type staticCredentials struct{}
+
+func (staticCredentials) Name() string { return "static" }
+
+func (staticCredentials) Retrieve(ctx context.Context) (aws.Credentials, error) {
+	return aws.Credentials{AccessKeyID: "AKIDTEST", SecretAccessKey: "secret", SessionToken: "session"}, nil
+}
+
+// TestVaultAWSLogin testa o login IAM contra um Vault falso
+func TestVaultAWSLogin(t *testing.T) {
+	tests := []struct {
+		name        string
+		options     map[string]string
+		expectedURL string
+		loginPath   string
+		wantErr     string
+	}{
+		{
+			name:        "Global STS endpoint with server ID",
+			options:     map[string]string{"vault_aws_server_id": "vault.example.com"},
+			expectedURL: "https://sts.amazonaws.com/",
+			loginPath:   "/v1/auth/aws/login",
+		},
+		{
+			name:        "Regional STS endpoint and custom mount",
+			options:     map[string]string{"vault_aws_sts_region": "sa-east-1", "vault_aws_mount": "aws-prod"},
+			expectedURL: "https://sts.sa-east-1.amazonaws.com/",
+			loginPath:   "/v1/auth/aws-prod/login",
+		},
+		{
+			name:      "Vault rejects role",
+			options:   map[string]string{},
+			loginPath: "/v1/auth/aws/login",
+			wantErr:   "entry for role stackspot-role not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != tt.loginPath || r.Method != http.MethodPost {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+
+				if tt.wantErr != "" {
+					w.WriteHeader(http.StatusBadRequest)
+					w.Write([]byte(`{"errors":["entry for role stackspot-role not found"]}`))
+					return
+				}
+
+				var login VaultAWSAuthRequest
+				if err := json.NewDecoder(r.Body).Decode(&login); err != nil {
+					t.Errorf("Failed to decode login: %v", err)
+				}
+
+				if login.Role != "stackspot-role" || login.IAMHTTPRequestMethod != http.MethodPost {
+					t.Errorf("Unexpected login %+v", login)
+				}
+				if got := decodeBase64(t, login.IAMRequestURL); got != tt.expectedURL {
+					t.Errorf("iam_request_url = %s, expected %s", got, tt.expectedURL)
+				}
+				if got := decodeBase64(t, login.IAMRequestBody); got != "Action=GetCallerIdentity&Version=2011-06-15" {
+					t.Errorf("Unexpected iam_request_body %q", got)
+				}
+
+				var headers http.Header
+				if err := json.Unmarshal([]byte(decodeBase64(t, login.IAMRequestHeaders)), &headers); err != nil {
+					t.Errorf("Failed to decode headers: %v", err)
+				}
+
+				authorization := headers.Get("Authorization")
+				if !strings.HasPrefix(authorization, "AWS4-HMAC-SHA256 Credential=AKIDTEST/") {
+					t.Errorf("Unexpected Authorization %q", authorization)
+				}
+				if headers.Get("X-Amz-Security-Token") != "session" {
+					t.Error("Expected X-Amz-Security-Token header")
+				}
+
+				serverID := tt.options["vault_aws_server_id"]
+				if headers.Get(VaultAWSServerIDHeader) != serverID {
+					t.Errorf("%s = %q, expected %q", VaultAWSServerIDHeader, headers.Get(VaultAWSServerIDHeader), serverID)
+				}
+				if serverID != "" && !strings.Contains(authorization, "x-vault-aws-iam-server-id") {
+					t.Errorf("Server ID header must be signed: %q", authorization)
+				}
+
+				w.Write([]byte(`{"auth":{"client_token":"hvs.test","lease_duration":3600,"renewable":true}}`))
+			}))
+			defer vault.Close()
+
+			memory := storage.NewMemoryAdapter()
+			for key, value := range tt.options {
+				memory.Set(key, value)
+			}
+
+			memory.Set("vault_url", vault.URL)
+			memory.Set("vault_aws_role", "stackspot-role")
+
+			vaultToken, err := loginWithStoredConfig(NewVaultProvider(memory).WithAWSCredentials(staticCredentials{}))
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("AWS login failed: %v", err)
+			}
+			if vaultToken != "hvs.test" {
+				t.Errorf("Expected client token hvs.test, got %q", vaultToken)
+			}
+		})
+	}
+}
+
+// TestVaultAuthMethods testa os métodos AppRole, JWT, Kubernetes e token com namespace
+func TestVaultAuthMethods(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte("file-jwt\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write token file: %v", err)
+	}
+
+	github := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "bearer request-token" || r.URL.Query().Get("audience") != "https://github.com/empresa" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Write([]byte(`{"value":"github-jwt"}`))
+	}))
+	defer github.Close()
+
+	tests := []struct {
+		name            string
+		stored          map[string]string
+		env             map[string]string
+		expectedPath    string
+		expectedPayload map[string]string
+		expectedToken   string
+	}{
+		{
+			name: "AppRole with secret from keyring",
+			stored: map[string]string{
+				"vault_auth_method":       "approle",
+				"vault_approle_role_id":   "role-id",
+				"vault_approle_secret_id": "secret-id",
+			},
+			expectedPath:    "/v1/auth/approle/login",
+			expectedPayload: map[string]string{"role_id": "role-id", "secret_id": "secret-id"},
+			expectedToken:   "hvs.login",
+		},
+		{
+			name: "JWT from token file with custom mount",
+			stored: map[string]string{
+				"vault_auth_method":    "jwt",
+				"vault_auth_mount":     "jwt-ci",
+				"vault_role":           "ci",
+				"vault_jwt_token_file": tokenFile,
+			},
+			expectedPath:    "/v1/auth/jwt-ci/login",
+			expectedPayload: map[string]string{"role": "ci", "jwt": "file-jwt"},
+			expectedToken:   "hvs.login",
+		},
+		{
+			name: "JWT from GitHub Actions ID token",
+			stored: map[string]string{
+				"vault_auth_method":         "jwt",
+				"vault_role":                "ci",
+				"vault_jwt_github_audience": "https://github.com/empresa",
+			},
+			env: map[string]string{
+				"ACTIONS_ID_TOKEN_REQUEST_URL":   github.URL + "/token?api-version=2.0",
+				"ACTIONS_ID_TOKEN_REQUEST_TOKEN": "request-token",
+			},
+			expectedPath:    "/v1/auth/jwt/login",
+			expectedPayload: map[string]string{"role": "ci", "jwt": "github-jwt"},
+			expectedToken:   "hvs.login",
+		},
+		{
+			name: "Kubernetes service account",
+			stored: map[string]string{
+				"vault_auth_method":           "kubernetes",
+				"vault_role":                  "stackspot",
+				"vault_kubernetes_token_file": tokenFile,
+			},
+			expectedPath:    "/v1/auth/kubernetes/login",
+			expectedPayload: map[string]string{"role": "stackspot", "jwt": "file-jwt"},
+			expectedToken:   "hvs.login",
+		},
+		{
+			name:          "Token from environment is looked up",
+			stored:        map[string]string{"vault_auth_method": "token"},
+			env:           map[string]string{"VAULT_TOKEN": "hvs.env"},
+			expectedPath:  "/v1/auth/token/lookup-self",
+			expectedToken: "hvs.env",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("VAULT_TOKEN", "")
+			t.Setenv("ACTIONS_ID_TOKEN_REQUEST_URL", "")
+			t.Setenv("ACTIONS_ID_TOKEN_REQUEST_TOKEN", "")
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get(VaultNamespaceHeader) != "team/a" {
+					t.Errorf("Expected namespace header, got %q", r.Header.Get(VaultNamespaceHeader))
+				}
+				if tt.expectedPath == "" || r.URL.Path != tt.expectedPath {
+					t.Errorf("Unexpected login path %s", r.URL.Path)
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+
+				var payload map[string]string
+				json.NewDecoder(r.Body).Decode(&payload)
+				for key, expected := range tt.expectedPayload {
+					if payload[key] != expected {
+						t.Errorf("payload[%s] = %q, expected %q", key, payload[key], expected)
+					}
+				}
+
+				w.Write([]byte(`{"auth":{"client_token":"hvs.login"}}`))
+			}))
+			defer vault.Close()
+
+			memory := storage.NewMemoryAdapter()
+			for key, value := range tt.stored {
+				memory.Set(key, value)
+			}
+			memory.Set("vault_url", vault.URL)
+			memory.Set("vault_namespace", "team/a")
+
+			vaultToken, err := loginWithStoredConfig(NewVaultProvider(memory))
+			if err != nil {
+				t.Fatalf("Login failed: %v", err)
+			}
+			if vaultToken != tt.expectedToken {
+				t.Errorf("Expected token %q, got %q", tt.expectedToken, vaultToken)
+			}
+		})
+	}
+}
+
+// TestVaultResolveConfig testa a precedência settings.yml > keyring > VAULT_*
+func TestVaultResolveConfig(t *testing.T) {
+	t.Setenv("VAULT_ADDR", "https://env.vault")
+	t.Setenv("VAULT_NAMESPACE", "env-namespace")
+
+	memory := storage.NewMemoryAdapter()
+	memory.Set("vault_url", "https://keyring.vault")
+	memory.Set("vault_aws_role", "legacy-role")
+
+	cfg := NewVaultProvider(memory).WithSettings(config.VaultSettings{
+		Method:  "approle",
+		AppRole: config.VaultAppRole{RoleID: "settings-role-id"},
+		Fields:  config.VaultFields{ClientSecret: "secret"},
+	}).ResolveConfig()
+
+	expected := config.VaultSettings{
+		Address:    "https://keyring.vault",
+		Namespace:  "env-namespace",
+		Method:     "approle",
+		Mount:      "approle",
+		Role:       "legacy-role",
+		SecretPath: DefaultVaultSecretPath,
+		Fields:     config.VaultFields{ClientID: "client_id", ClientSecret: "secret"},
+		AppRole:    config.VaultAppRole{RoleID: "settings-role-id"},
+		Kubernetes: config.VaultKubernetes{TokenFile: DefaultKubernetesTokenFile},
+	}
+	if cfg != expected {
+		t.Errorf("ResolveConfig() = %+v, expected %+v", cfg, expected)
+	}
+}
+
+// TestVaultKVSecrets testa a leitura de secrets KV v1/v2 e os erros de campos
+func TestVaultKVSecrets(t *testing.T) {
+	tests := []struct {
+		name      string
+		settings  config.VaultSettings
+		mounts    string // resposta de sys/mounts; vazio responde 403
+		secrets   map[string]string
+		wantID    string
+		wantErr   error
+		wantField string
+	}{
+		{
+			name:     "KV v2 detected with API path",
+			settings: config.VaultSettings{SecretPath: "secret/data/stackspot"},
+			mounts:   `{"data":{"secret/":{"type":"kv","options":{"version":"2"}}}}`,
+			secrets:  map[string]string{"/v1/secret/data/stackspot": `{"data":{"data":{"client_id":"id-v2","client_secret":"s"}}}`},
+			wantID:   "id-v2",
+		},
+		{
+			name:     "KV v2 detected with logical path",
+			settings: config.VaultSettings{SecretPath: "kv/team/stackspot"},
+			mounts:   `{"kv/":{"type":"kv","options":{"version":"2"}},"kv/team/":{"type":"kv","options":{"version":"1"}},"sys/":{"type":"system"}}`,
+			secrets:  map[string]string{"/v1/kv/team/stackspot": `{"data":{"client_id":"id-nested-v1","client_secret":"s"}}`},
+			wantID:   "id-nested-v1",
+		},
+		{
+			name:     "KV v1 with custom field names",
+			settings: config.VaultSettings{SecretPath: "legacy/stackspot", Fields: config.VaultFields{ClientID: "id", ClientSecret: "key"}},
+			mounts:   `{"legacy/":{"type":"kv","options":null}}`,
+			secrets:  map[string]string{"/v1/legacy/stackspot": `{"data":{"id":"id-v1","key":"s"}}`},
+			wantID:   "id-v1",
+		},
+		{
+			name:     "Explicit KV version skips detection",
+			settings: config.VaultSettings{SecretPath: "secret/stackspot", KVVersion: 2},
+			secrets:  map[string]string{"/v1/secret/data/stackspot": `{"data":{"data":{"client_id":"id-explicit","client_secret":"s"}}}`},
+			wantID:   "id-explicit",
+		},
+		{
+			name:     "Forbidden sys/mounts falls back to path",
+			settings: config.VaultSettings{SecretPath: "secret/data/stackspot"},
+			secrets:  map[string]string{"/v1/secret/data/stackspot": `{"data":{"data":{"client_id":"id-guess","client_secret":"s"}}}`},
+			wantID:   "id-guess",
+		},
+		{
+			name:      "Missing client_secret",
+			settings:  config.VaultSettings{SecretPath: "secret/data/stackspot", KVVersion: 2},
+			secrets:   map[string]string{"/v1/secret/data/stackspot": `{"data":{"data":{"client_id":"id"}}}`},
+			wantField: "client_secret",
+		},
+		{
+			name:      "Non string client_id",
+			settings:  config.VaultSettings{SecretPath: "secret/data/stackspot", KVVersion: 2},
+			secrets:   map[string]string{"/v1/secret/data/stackspot": `{"data":{"data":{"client_id":42,"client_secret":"s"}}}`},
+			wantField: "client_id",
+		},
+		{
+			name:     "Deleted KV v2 version",
+			settings: config.VaultSettings{SecretPath: "secret/data/stackspot", KVVersion: 2},
+			secrets:  map[string]string{"/v1/secret/data/stackspot": `{"data":{"data":null}}`},
+			wantErr:  ErrVaultSecretNotFound,
+		},
+		{
+			name:     "Secret not found",
+			settings: config.VaultSettings{SecretPath: "secret/data/other", KVVersion: 2},
+			wantErr:  ErrVaultSecretNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case r.URL.Path == "/v1/auth/token/lookup-self":
+					w.Write([]byte(`{"data":{"ttl":0}}`))
+				case r.URL.Path == "/v1/sys/mounts":
+					if tt.mounts == "" {
+						w.WriteHeader(http.StatusForbidden)
+						w.Write([]byte(`{"errors":["permission denied"]}`))
+						return
+					}
+					w.Write([]byte(tt.mounts))
+				case tt.secrets[r.URL.Path] != "":
+					w.Write([]byte(tt.secrets[r.URL.Path]))
+				default:
+					w.WriteHeader(http.StatusNotFound)
+					w.Write([]byte(`{"errors":[]}`))
+				}
+			}))
+			defer vault.Close()
+
+			memory := storage.NewMemoryAdapter()
+			memory.Set("vault_url", vault.URL)
+			memory.Set("vault_auth_method", "token")
+			memory.Set("vault_token", "hvs.root")
+
+			creds, err := NewVaultProvider(memory).WithSettings(tt.settings).stackSpotCredentials(context.Background())
+
+			if tt.wantField != "" {
+				var fieldErr *VaultFieldError
+				if !errors.As(err, &fieldErr) || fieldErr.Field != tt.wantField {
+					t.Fatalf("Expected VaultFieldError for %s, got %v", tt.wantField, err)
+				}
+				return
+			}
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to read credentials: %v", err)
+			}
+			if creds.ClientID != tt.wantID {
+				t.Errorf("client_id = %q, expected %q", creds.ClientID, tt.wantID)
+			}
+		})
+	}
+}
+
+// TestVaultTokenCache testa o reaproveitamento, a renovação e o novo login do token do Vault
+func TestVaultTokenCache(t *testing.T) {
+	var logins, renewals int
+	renewLease := 3600
+	revokedToken := ""
+
+	vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/auth/approle/login":
+			logins++
+			fmt.Fprintf(w, `{"auth":{"client_token":"hvs.login-%d","lease_duration":3600,"renewable":true}}`, logins)
+		case "/v1/auth/token/renew-self":
+			renewals++
+			fmt.Fprintf(w, `{"auth":{"client_token":%q,"lease_duration":%d,"renewable":true}}`, r.Header.Get("X-Vault-Token"), renewLease)
+		case "/v1/secret/data/stackspot":
+			if r.Header.Get("X-Vault-Token") == revokedToken {
+				w.WriteHeader(http.StatusForbidden)
+				w.Write([]byte(`{"errors":["permission denied"]}`))
+				return
+			}
+			w.Write([]byte(`{"data":{"data":{"client_id":"id","client_secret":"s"}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer vault.Close()
+
+	memory := storage.NewMemoryAdapter()
+	memory.Set("vault_url", vault.URL)
+	memory.Set("vault_auth_method", "approle")
+	memory.Set("vault_approle_role_id", "role-id")
+	memory.Set("vault_kv_version", "2")
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	provider := NewVaultProvider(memory)
+	provider.now = func() time.Time { return now }
+
+	steps := []struct {
+		name         string
+		advance      time.Duration
+		renewLease   int
+		revoke       bool
+		wantLogins   int
+		wantRenewals int
+	}{
+		{name: "First call logs in", wantLogins: 1},
+		{name: "Fresh token is reused", advance: 30 * time.Minute, wantLogins: 1},
+		{name: "Token near expiry is renewed", advance: 15 * time.Minute, renewLease: 3600, wantLogins: 1, wantRenewals: 1},
+		{name: "Renewal capped by max TTL logs in again", advance: 45 * time.Minute, renewLease: 60, wantLogins: 2, wantRenewals: 2},
+		{name: "Expired token logs in again", advance: 2 * time.Hour, wantLogins: 3, wantRenewals: 2},
+		{name: "Revoked token logs in again", revoke: true, wantLogins: 4, wantRenewals: 2},
+	}
+
+	for _, step := range steps {
+		now = now.Add(step.advance)
+		renewLease = step.renewLease
+		if step.revoke {
+			revokedToken = fmt.Sprintf("hvs.login-%d", logins)
+		}
+
+		if _, err := provider.stackSpotCredentials(context.Background()); err != nil {
+			t.Fatalf("%s: %v", step.name, err)
+		}
+		if logins != step.wantLogins || renewals != step.wantRenewals {
+			t.Errorf("%s: logins = %d, renewals = %d, expected %d and %d", step.name, logins, renewals, step.wantLogins, step.wantRenewals)
+		}
+	}
+}
+
+// TestVaultGetTokenKeepsUserCredentials testa que as credenciais vindas do Vault
+// não sobrescrevem as credenciais pessoais salvas no storage
+func TestVaultGetTokenKeepsUserCredentials(t *testing.T) {
+	vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/auth/token/lookup-self":
+			w.Write([]byte(`{"data":{"ttl":0}}`))
+		case "/v1/secret/data/stackspot":
+			w.Write([]byte(`{"data":{"data":{"client_id":"service-id","client_secret":"service-secret"}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer vault.Close()
+
+	idm := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.PostForm.Get("client_id") != "service-id" || r.PostForm.Get("client_secret") != "service-secret" {
+			t.Errorf("Unexpected credentials %q/%q", r.PostForm.Get("client_id"), r.PostForm.Get("client_secret"))
+		}
+		if r.PostForm.Get("scope") != "execution" {
+			t.Errorf("Unexpected scope %q", r.PostForm.Get("scope"))
+		}
+		w.Write([]byte(`{"access_token":"service-access","expires_in":300}`))
+	}))
+	defer idm.Close()
+
+	memory := storage.NewMemoryAdapter()
+	memory.Set("stackspot_client_id", "user-id")
+	memory.Set("stackspot_client_secret", "user-secret")
+	memory.Set("vault_url", vault.URL)
+	memory.Set("vault_auth_method", "token")
+	memory.Set("vault_token", "hvs.root")
+	memory.Set("vault_kv_version", "2")
+
+	provider := NewVaultProvider(memory)
+	provider.tokenURL = idm.URL
+
+	resp, err := provider.GetToken(token.ScopeExecution)
+	if err != nil {
+		t.Fatalf("GetToken failed: %v", err)
+	}
+	if resp.AccessToken != "service-access" {
+		t.Errorf("Expected service-access, got %q", resp.AccessToken)
+	}
+
+	for key, expected := range map[string]string{
+		"stackspot_client_id":     "user-id",
+		"stackspot_client_secret": "user-secret",
+	} {
+		if value, _ := memory.Get(key); value != expected {
+			t.Errorf("%s = %q, expected user value %q to be untouched", key, value, expected)
+		}
+	}
+}
+
+// loginWithStoredConfig executa o login com o método resolvido da configuração
+func loginWithStoredConfig(provider *VaultProvider) (string, error) {
+	cfg := provider.ResolveConfig()
+	method, err := provider.authMethod(cfg)
+	if err != nil {
+		return "", err
+	}
+	lease, err := method.Login(context.Background(), provider.vaultClient(cfg))
+	return lease.Token, err
+}
+
+func decodeBase64(t *testing.T, value string) string {
+	t.Helper()
+	decoded, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		t.Fatalf("Invalid base64 %q: %v", value, err)
+	}
+	return string(decoded)
+}
@@ -0,0 +1,76 @@
+package auth
+
+import (
+	"errors"
+
+	"github.com/PHRaulino/phengineer/internal/infrastructure/auth/providers"
+)
+
+// remediations orientação por categoria de falha e provedor; a chave "" vale para qualquer provedor
+var remediations = map[error]map[string]string{
+	providers.ErrNotConfigured: {
+		providers.ProviderStackSpot: "Configure as credenciais com 'phengineer auth login stackspot' (ou --device para entrar pelo navegador).",
+		providers.ProviderVault:     "Configure o Vault com 'phengineer auth login vault' ou pela seção auth.vault do settings.yml.",
+		providers.ProviderGitHub:    "Salve um token com 'phengineer auth login github --token-stdin' ou configure um GitHub App.",
+		"":                          "Configure as credenciais com 'phengineer auth login'.",
+	},
+	providers.ErrInvalidCredentials: {
+		providers.ProviderStackSpot: "Confira client_id e client_secret no portal da StackSpot e salve-os novamente com 'phengineer auth login stackspot'.",
+		providers.ProviderVault:     "O Vault recusou o login: confira método, role e mount em 'phengineer auth status' e as credenciais do ambiente.",
+		providers.ProviderGitHub:    "O token foi revogado ou expirou: gere um novo e salve com 'phengineer auth login github --token-stdin'.",
+		"":                          "Refaça o login com 'phengineer auth login'.",
+	},
+	providers.ErrPermission: {
+		providers.ProviderVault:  "A policy do token do Vault não permite ler o secret configurado: peça acesso ao caminho em auth.vault.secret_path.",
+		providers.ProviderGitHub: "O token não tem as permissões necessárias: 'phengineer auth status' lista as que faltam.",
+		"":                       "A conta não tem acesso ao recurso: verifique as permissões concedidas a ela.",
+	},
+	providers.ErrRateLimited: {
+		providers.ProviderGitHub: "Limite da API do GitHub atingido: aguarde a renovação da cota ou use um GitHub App, que tem limite maior.",
+		"":                       "Limite de requisições atingido: aguarde alguns minutos e tente novamente.",
+	},
+	providers.ErrNetwork: {
+		"": "Não foi possível falar com o serviço: verifique a conexão, o proxy (http.proxy ou HTTPS_PROXY) e os certificados (http.ca_cert_files).",
+	},
+	providers.ErrVaultSecretNotFound: {
+		"": "Confira auth.vault.secret_path e a versão do KV (auth.vault.kv_version).",
+	},
+	ErrStorageNotPersistent: {
+		"": "Habilite o keyring do sistema ou defina PHENGINEER_STORAGE_PASSPHRASE (ou PHENGINEER_STORAGE_KEY) para salvar as credenciais em arquivo cifrado.",
+	},
+}
+
+// remediationOrder ordem de verificação: a primeira categoria encontrada no erro define a orientação
+var remediationOrder = []error{
+	providers.ErrNotConfigured,
+	providers.ErrVaultSecretNotFound,
+	providers.ErrInvalidCredentials,
+	providers.ErrPermission,
+	providers.ErrRateLimited,
+	providers.ErrNetwork,
+	ErrStorageNotPersistent,
+}
+
+// Remediation sugere como resolver uma falha de autenticação; vazio quando não há orientação específica
+func Remediation(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	var provider string
+	var providerErr *providers.ProviderError
+	if errors.As(err, &providerErr) {
+		provider = providerErr.Provider
+	}
+
+	for _, kind := range remediationOrder {
+		if !errors.Is(err, kind) {
+			continue
+		}
+		if message, ok := remediations[kind][provider]; ok {
+			return message
+		}
+		return remediations[kind][""]
+	}
+	return ""
+}
@@ -0,0 +1,287 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/PHRaulino/phengineer/internal/infrastructure/auth/token"
+	"github.com/spf13/viper"
+)
+
+// ProviderStatus situação das credenciais de um provedor no perfil ativo
+type ProviderStatus struct {
+	Provider   string            `json:"provider"`
+	Configured bool              `json:"configured"`
+	Details    map[string]string `json:"details,omitempty"`
+	Warnings   []string          `json:"warnings,omitempty"`
+}
+
+// Identity quem o token representa
+type Identity struct {
+	Subject string         `json:"subject,omitempty"`
+	Login   string         `json:"login,omitempty"`
+	Email   string         `json:"email,omitempty"`
+	Issuer  string         `json:"issuer,omitempty"`
+	Claims  map[string]any `json:"claims,omitempty"`
+	Error   string         `json:"error,omitempty"`
+}
+
+// TokenStatus situação do token em cache para um alias e escopo
+type TokenStatus struct {
+	Alias            token.TokenGeneratorAlias `json:"alias"`
+	Scope            token.TokenScope          `json:"scope"`
+	Cached           bool                      `json:"cached"`
+	Valid            bool                      `json:"valid"`
+	Refreshable      bool                      `json:"refreshable"` // renovável com refresh token, sem novo login
+	ExpiresAt        *time.Time                `json:"expires_at,omitempty"`
+	RemainingSeconds int64                     `json:"remaining_seconds"`
+	Storage          string                    `json:"storage"`
+	Identity         *Identity                 `json:"identity,omitempty"`
+}
+
+// Remaining tempo de vida restante do token
+func (t TokenStatus) Remaining() time.Duration {
+	return time.Duration(t.RemainingSeconds) * time.Second
+}
+
+// Status visão geral da autenticação no perfil ativo. Não expõe segredos.
+type Status struct {
+	GeneratedAt   time.Time        `json:"generated_at"`
+	Profile       string           `json:"profile"`
+	ProfileSource string           `json:"profile_source"`
+	Backend       string           `json:"backend"`
+	Mode          string           `json:"mode"`
+	Providers     []ProviderStatus `json:"providers"`
+	Tokens        []TokenStatus    `json:"tokens"`
+}
+
+// StatusOptions opções da coleta de status
+type StatusOptions struct {
+	// Offline não consulta APIs externas; a identidade e as permissões do GitHub dependem da API
+	Offline bool
+}
+
+// GetStatus coleta o estado das credenciais e de cada token em cache dos geradores registrados
+func GetStatus(ctx context.Context, opts StatusOptions) Status {
+	backend, _ := StorageBackend()
+	mode := viper.GetString("auth.mode")
+	if mode == "" {
+		mode = string(AuthModeUser)
+	}
+
+	// No modo service os tokens ficam só em memória
+	tokenStorage := string(backend)
+	if mode == string(AuthModeService) {
+		tokenStorage = "memory"
+	}
+
+	status := Status{
+		GeneratedAt:   time.Now(),
+		Profile:       ActiveProfile(),
+		ProfileSource: ActiveProfileSource(),
+		Backend:       string(backend),
+		Mode:          mode,
+		Providers:     []ProviderStatus{stackSpotStatus(ctx), vaultStatus(ctx), gitHubStatus(ctx, opts.Offline)},
+		Tokens:        []TokenStatus{},
+	}
+
+	service := token.GetService()
+	aliases := service.Aliases()
+	if len(aliases) == 0 {
+		aliases = token.Aliases
+	}
+
+	var github *Identity
+	for _, alias := range aliases {
+		for _, scope := range token.Scopes {
+			entry := TokenStatus{Alias: alias, Scope: scope, Storage: tokenStorage}
+
+			data, err := service.Cached(scope, alias)
+			if err == nil && data != nil {
+				expiresAt := data.ExpiresAt
+				entry.Cached = true
+				entry.Refreshable = data.RefreshToken != ""
+				entry.ExpiresAt = &expiresAt
+				entry.Valid = data.Token != "" && status.GeneratedAt.Before(expiresAt)
+				if entry.Valid {
+					entry.RemainingSeconds = int64(expiresAt.Sub(status.GeneratedAt).Seconds())
+				}
+
+				if alias == token.TokenGenGH {
+					if github == nil && !opts.Offline {
+						github = gitHubIdentity(ctx)
+					}
+					entry.Identity = github
+				} else {
+					entry.Identity = jwtIdentity(data.Token)
+				}
+			}
+
+			status.Tokens = append(status.Tokens, entry)
+		}
+	}
+
+	return status
+}
+
+// jwtIdentity extrai a identidade das claims de um token StackSpot
+func jwtIdentity(accessToken string) *Identity {
+	claims, err := token.DecodeClaims(accessToken)
+	if err != nil {
+		return &Identity{Error: err.Error()}
+	}
+
+	identity := &Identity{
+		Subject: claimString(claims, "sub"),
+		Login:   claimString(claims, "preferred_username", "client_id", "azp"),
+		Email:   claimString(claims, "email"),
+		Issuer:  claimString(claims, "iss"),
+		Claims:  make(map[string]any),
+	}
+	// Apenas claims úteis para identificar a conta; o restante do payload fica de fora
+	for _, key := range []string{"azp", "client_id", "realm", "tenant", "account_id", "account_name", "scope", "iat", "exp"} {
+		if value, ok := claims[key]; ok {
+			identity.Claims[key] = value
+		}
+	}
+	return identity
+}
+
+func gitHubIdentity(ctx context.Context) *Identity {
+	provider := GetGitHubProvider(ctx)
+	// Tokens de instalação não acessam /user: a identidade é o próprio app
+	if cfg := provider.ResolveAppConfig(); cfg.AppID != 0 {
+		return &Identity{Subject: fmt.Sprintf("app/%d", cfg.AppID)}
+	}
+
+	user, err := provider.GetUser()
+	if err != nil {
+		return &Identity{Error: err.Error()}
+	}
+	return &Identity{Subject: fmt.Sprint(user.ID), Login: user.Login, Email: user.Email}
+}
+
+func claimString(claims map[string]any, keys ...string) string {
+	for _, key := range keys {
+		if value, ok := claims[key].(string); ok && value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+func stackSpotStatus(ctx context.Context) ProviderStatus {
+	provider := GetStackSpotProvider(ctx)
+	status := ProviderStatus{Provider: "stackspot", Configured: provider.HasCredentials()}
+	if status.Configured {
+		status.Details = map[string]string{"client_id": provider.ClientID(), "login": "client_credentials"}
+		if provider.HasDeviceLogin() {
+			status.Details["login"] = "device"
+		}
+	}
+	return status
+}
+
+func vaultStatus(ctx context.Context) ProviderStatus {
+	provider := GetVaultProvider(ctx).WithSettings(projectVaultSettings(ctx))
+	cfg := provider.ResolveConfig()
+	status := ProviderStatus{Provider: "vault", Configured: cfg.Address != ""}
+	if status.Configured {
+		status.Details = map[string]string{
+			"address":     cfg.Address,
+			"method":      cfg.Method,
+			"mount":       cfg.Mount,
+			"secret_path": cfg.SecretPath,
+		}
+		if cfg.Namespace != "" {
+			status.Details["namespace"] = cfg.Namespace
+		}
+		if cfg.Role != "" {
+			status.Details["role"] = cfg.Role
+		}
+	}
+	return status
+}
+
+func gitHubStatus(ctx context.Context, offline bool) ProviderStatus {
+	provider := GetGitHubProvider(ctx)
+	status := ProviderStatus{Provider: "github", Configured: provider.HasToken()}
+
+	var githubToken string
+	if cfg := provider.ResolveAppConfig(); cfg.AppID != 0 {
+		status.Configured = true
+		status.Details = map[string]string{"login": "app", "app_id": fmt.Sprint(cfg.AppID), "installation_id": "auto"}
+		if cfg.InstallationID != 0 {
+			status.Details["installation_id"] = fmt.Sprint(cfg.InstallationID)
+		}
+		// Só o token de instalação em cache é verificado; status não emite tokens novos
+		for _, scope := range token.Scopes {
+			if data, err := token.GetService().Cached(scope, token.TokenGenGH); err == nil && data != nil && time.Now().Before(data.ExpiresAt) {
+				githubToken = data.Token
+				break
+			}
+		}
+	} else if status.Configured {
+		githubToken, _ = provider.StoredToken()
+	}
+
+	if offline || githubToken == "" {
+		return status
+	}
+
+	report, err := provider.VerifyToken(ctx, githubToken)
+	if err != nil {
+		status.Warnings = []string{"verificação de permissões falhou: " + err.Error()}
+		return status
+	}
+	if status.Details == nil {
+		status.Details = map[string]string{}
+	}
+	status.Details["token_kind"] = report.Kind
+	if report.Repository != "" {
+		status.Details["repository"] = report.Repository
+	}
+	status.Warnings = report.Warnings()
+	return status
+}
+
+// Providers lista os provedores com credenciais gerenciadas pela CLI
+var Providers = []string{"stackspot", "vault", "github"}
+
+// Logout remove as credenciais do provedor no perfil ativo e os tokens em cache gerados com elas
+func Logout(ctx context.Context, provider string) error {
+	var aliases []token.TokenGeneratorAlias
+	var err error
+
+	switch provider {
+	case "stackspot":
+		err = GetStackSpotProvider(ctx).DeleteCredentials()
+		aliases = []token.TokenGeneratorAlias{token.TokenGenSTK}
+	case "vault":
+		err = GetVaultProvider(ctx).DeleteConfig()
+		aliases = []token.TokenGeneratorAlias{token.TokenGenHC, token.TokenGenSTK}
+	case "github":
+		github := GetGitHubProvider(ctx)
+		err = errors.Join(github.DeleteToken(), github.DeleteAppConfig())
+		aliases = []token.TokenGeneratorAlias{token.TokenGenGH}
+	default:
+		return fmt.Errorf("provedor desconhecido %q (use stackspot, vault ou github)", provider)
+	}
+	if err != nil {
+		return err
+	}
+
+	service := token.GetService()
+	for _, alias := range aliases {
+		for _, scope := range token.Scopes {
+			if service.Exists(scope, alias) {
+				if err := service.Delete(scope, alias); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/zalando/go-keyring"
+	"go.uber.org/zap"
+)
+
+// StorageBackendEnv força o backend de credenciais: keyring, file ou memory
+const StorageBackendEnv = "PHENGINEER_STORAGE"
+
+// Backend tipo de armazenamento das credenciais
+type Backend string
+
+const (
+	BackendKeyring Backend = "keyring"
+	BackendFile    Backend = "file"
+	BackendMemory  Backend = "memory"
+)
+
+// keyringProbeTimeout limite para detectar um secret service que não responde
+const keyringProbeTimeout = 3 * time.Second
+
+var (
+	defaultOnce    sync.Once
+	defaultAdapter StorageAdapter
+	defaultBackend Backend
+	defaultErr     error
+)
+
+// Default retorna o storage do processo. Sem PHENGINEER_STORAGE, usa o keyring do
+// sistema quando disponível, senão o arquivo cifrado (se houver chave ou passphrase)
+// e, por último, a memória. Em caso de erro o storage retornado é a memória.
+func Default() (StorageAdapter, Backend, error) {
+	defaultOnce.Do(func() {
+		defaultAdapter, defaultBackend, defaultErr = selectBackend(os.Getenv(StorageBackendEnv), keyringAvailable)
+		if defaultErr != nil {
+			defaultAdapter, defaultBackend = NewMemoryAdapter(), BackendMemory
+		}
+	})
+	return defaultAdapter, defaultBackend, defaultErr
+}
+
+func selectBackend(forced string, keyringOK func() bool) (StorageAdapter, Backend, error) {
+	switch Backend(forced) {
+	case BackendKeyring:
+		return NewKeyringAdapter(), BackendKeyring, nil
+	case BackendFile:
+		adapter, err := newDefaultFileAdapter()
+		if err != nil {
+			return nil, "", err
+		}
+		return adapter, BackendFile, nil
+	case BackendMemory:
+		return NewMemoryAdapter(), BackendMemory, nil
+	case "":
+	default:
+		return nil, "", fmt.Errorf("%s inválido '%s': use keyring, file ou memory", StorageBackendEnv, forced)
+	}
+
+	if keyringOK() {
+		return NewKeyringAdapter(), BackendKeyring, nil
+	}
+
+	adapter, err := newDefaultFileAdapter()
+	if err == nil {
+		zap.L().Debug("keyring unavailable, using encrypted file storage", zap.String("path", adapter.Path()))
+		return adapter, BackendFile, nil
+	}
+	if !errors.Is(err, ErrNoStorageKey) {
+		return nil, "", err
+	}
+
+	zap.L().Debug("keyring unavailable and no storage key configured, credentials will not persist",
+		zap.String("hint", ErrNoStorageKey.Error()))
+	return NewMemoryAdapter(), BackendMemory, nil
+}
+
+func newDefaultFileAdapter() (*FileAdapter, error) {
+	dir, err := DefaultFileDir()
+	if err != nil {
+		return nil, err
+	}
+	return NewFileAdapter(dir)
+}
+
+// keyringAvailable verifica se o secret service responde
+func keyringAvailable() bool {
+	result := make(chan bool, 1)
+	go func() {
+		_, err := keyring.Get(ServiceName, "__probe__")
+		result <- err == nil || errors.Is(err, keyring.ErrNotFound)
+	}()
+
+	select {
+	case ok := <-result:
+		return ok
+	case <-time.After(keyringProbeTimeout):
+		return false
+	}
+}
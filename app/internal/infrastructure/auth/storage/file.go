@@ -0,0 +1,341 @@
+package storage
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	// StorageKeyEnv chave de 32 bytes em base64 usada diretamente na cifra
+	StorageKeyEnv = "PHENGINEER_STORAGE_KEY"
+	// StoragePassphraseEnv passphrase da qual a chave é derivada com scrypt
+	StoragePassphraseEnv = "PHENGINEER_STORAGE_PASSPHRASE"
+
+	credentialsFile = "credentials.enc"
+	lockFileName    = "credentials.lock"
+	fileFormat      = 1
+
+	// Parâmetros do scrypt recomendados para uso interativo
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+)
+
+// ErrNoStorageKey nenhuma chave ou passphrase configurada para o armazenamento em arquivo
+var ErrNoStorageKey = fmt.Errorf("defina %s ou %s para usar o armazenamento em arquivo", StorageKeyEnv, StoragePassphraseEnv)
+
+// ErrDecrypt a chave não abre o arquivo de credenciais
+var ErrDecrypt = errors.New("não foi possível decifrar as credenciais: chave ou passphrase incorreta")
+
+// encryptedFile formato do arquivo de credenciais
+type encryptedFile struct {
+	Version int    `json:"version"`
+	KDF     string `json:"kdf"`            // scrypt ou raw
+	Salt    string `json:"salt,omitempty"` // base64
+	Nonce   string `json:"nonce"`          // base64
+	Data    string `json:"data"`           // base64 do JSON das chaves cifrado com secretbox
+}
+
+// FileAdapter armazena as credenciais cifradas com NaCl secretbox em um único arquivo.
+// Gravações são atômicas (arquivo temporário + rename) e protegidas por flock,
+// permitindo vários processos usando o mesmo diretório.
+type FileAdapter struct {
+	dir        string
+	key        []byte // chave fornecida diretamente
+	passphrase []byte
+
+	mu      sync.Mutex
+	derived map[string][32]byte // chaves derivadas por salt
+}
+
+// NewFileAdapter cria o armazenamento em arquivo no diretório informado usando a
+// chave ou a passphrase das variáveis de ambiente
+func NewFileAdapter(dir string) (*FileAdapter, error) {
+	if encoded := os.Getenv(StorageKeyEnv); encoded != "" {
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil || len(key) != 32 {
+			return nil, fmt.Errorf("%s deve conter 32 bytes em base64", StorageKeyEnv)
+		}
+		return &FileAdapter{dir: dir, key: key}, nil
+	}
+
+	if passphrase := os.Getenv(StoragePassphraseEnv); passphrase != "" {
+		return NewFileAdapterWithPassphrase(dir, passphrase), nil
+	}
+
+	return nil, ErrNoStorageKey
+}
+
+// NewFileAdapterWithPassphrase cria o armazenamento em arquivo com a chave derivada da passphrase
+func NewFileAdapterWithPassphrase(dir, passphrase string) *FileAdapter {
+	return &FileAdapter{dir: dir, passphrase: []byte(passphrase)}
+}
+
+// DefaultFileDir retorna $XDG_DATA_HOME/phengineer (padrão ~/.local/share/phengineer)
+func DefaultFileDir() (string, error) {
+	if dataHome := os.Getenv("XDG_DATA_HOME"); dataHome != "" {
+		return filepath.Join(dataHome, ServiceName), nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("erro ao localizar o diretório home: %w", err)
+	}
+	return filepath.Join(home, ".local", "share", ServiceName), nil
+}
+
+// Path retorna o caminho do arquivo de credenciais
+func (f *FileAdapter) Path() string {
+	return filepath.Join(f.dir, credentialsFile)
+}
+
+func (f *FileAdapter) Set(key, value string) error {
+	return f.update(func(data map[string]string) {
+		data[key] = value
+	})
+}
+
+func (f *FileAdapter) Get(key string) (string, error) {
+	var value string
+	var found bool
+	err := f.view(func(data map[string]string) {
+		value, found = data[key]
+	})
+	if err != nil {
+		return "", err
+	}
+	if !found {
+		return "", errors.New("key not found")
+	}
+	return value, nil
+}
+
+func (f *FileAdapter) Delete(key string) error {
+	return f.update(func(data map[string]string) {
+		delete(data, key)
+	})
+}
+
+func (f *FileAdapter) Exists(key string) bool {
+	_, err := f.Get(key)
+	return err == nil
+}
+
+// view lê as credenciais com lock compartilhado
+func (f *FileAdapter) view(read func(map[string]string)) error {
+	unlock, err := f.lock(false)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	data, _, err := f.load()
+	if err != nil {
+		return err
+	}
+	read(data)
+	return nil
+}
+
+// update lê, altera e regrava as credenciais com lock exclusivo
+func (f *FileAdapter) update(change func(map[string]string)) error {
+	unlock, err := f.lock(true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	data, salt, err := f.load()
+	if err != nil {
+		return err
+	}
+	change(data)
+	return f.save(data, salt)
+}
+
+// lock abre o arquivo de lock e aplica flock; retorna a função que libera o lock
+func (f *FileAdapter) lock(exclusive bool) (func(), error) {
+	if err := os.MkdirAll(f.dir, 0o700); err != nil {
+		return nil, fmt.Errorf("erro ao criar diretório de credenciais: %w", err)
+	}
+
+	file, err := os.OpenFile(filepath.Join(f.dir, lockFileName), os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao abrir lock de credenciais: %w", err)
+	}
+	if err := lockFile(file, exclusive); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("erro ao obter lock de credenciais: %w", err)
+	}
+
+	return func() {
+		unlockFile(file)
+		file.Close()
+	}, nil
+}
+
+// load decifra as credenciais e retorna o salt do arquivo para reaproveitar a chave derivada
+func (f *FileAdapter) load() (map[string]string, []byte, error) {
+	raw, err := os.ReadFile(f.Path())
+	if errors.Is(err, os.ErrNotExist) {
+		return make(map[string]string), nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("erro ao ler credenciais: %w", err)
+	}
+
+	var file encryptedFile
+	if err := json.Unmarshal(raw, &file); err != nil {
+		return nil, nil, fmt.Errorf("arquivo de credenciais corrompido: %w", err)
+	}
+	if file.Version != fileFormat {
+		return nil, nil, fmt.Errorf("versão %d do arquivo de credenciais não suportada", file.Version)
+	}
+
+	salt, err := base64.StdEncoding.DecodeString(file.Salt)
+	if err != nil {
+		return nil, nil, fmt.Errorf("arquivo de credenciais corrompido: %w", err)
+	}
+	nonceBytes, err := base64.StdEncoding.DecodeString(file.Nonce)
+	if err != nil || len(nonceBytes) != 24 {
+		return nil, nil, fmt.Errorf("arquivo de credenciais corrompido: nonce inválido")
+	}
+	sealed, err := base64.StdEncoding.DecodeString(file.Data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("arquivo de credenciais corrompido: %w", err)
+	}
+
+	key, err := f.keyFor(file.KDF, salt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var nonce [24]byte
+	copy(nonce[:], nonceBytes)
+	plain, ok := secretbox.Open(nil, sealed, &nonce, &key)
+	if !ok {
+		return nil, nil, ErrDecrypt
+	}
+
+	data := make(map[string]string)
+	if err := json.Unmarshal(plain, &data); err != nil {
+		return nil, nil, fmt.Errorf("arquivo de credenciais corrompido: %w", err)
+	}
+	return data, salt, nil
+}
+
+// save cifra e grava as credenciais de forma atômica
+func (f *FileAdapter) save(data map[string]string, salt []byte) error {
+	plain, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("erro ao serializar credenciais: %w", err)
+	}
+
+	file := encryptedFile{Version: fileFormat, KDF: "raw"}
+	if f.key == nil {
+		// O salt é mantido entre gravações para não derivar a chave de novo; o nonce é sempre novo
+		if len(salt) == 0 {
+			salt = make([]byte, 16)
+			if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+				return fmt.Errorf("erro ao gerar salt: %w", err)
+			}
+		}
+		file.KDF = "scrypt"
+		file.Salt = base64.StdEncoding.EncodeToString(salt)
+	} else {
+		salt = nil
+	}
+
+	key, err := f.keyFor(file.KDF, salt)
+	if err != nil {
+		return err
+	}
+
+	var nonce [24]byte
+	if _, err := io.ReadFull(rand.Reader, nonce[:]); err != nil {
+		return fmt.Errorf("erro ao gerar nonce: %w", err)
+	}
+	file.Nonce = base64.StdEncoding.EncodeToString(nonce[:])
+	file.Data = base64.StdEncoding.EncodeToString(secretbox.Seal(nil, plain, &nonce, &key))
+
+	raw, err := json.Marshal(file)
+	if err != nil {
+		return fmt.Errorf("erro ao serializar credenciais: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(f.dir, credentialsFile+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("erro ao criar arquivo temporário: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := tmp.Chmod(0o600); err != nil {
+		tmp.Close()
+		return fmt.Errorf("erro ao restringir permissões: %w", err)
+	}
+	if _, err := tmp.Write(raw); err != nil {
+		tmp.Close()
+		return fmt.Errorf("erro ao gravar credenciais: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("erro ao gravar credenciais: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("erro ao gravar credenciais: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), f.Path()); err != nil {
+		return fmt.Errorf("erro ao substituir arquivo de credenciais: %w", err)
+	}
+	return nil
+}
+
+// keyFor retorna a chave da cifra conforme o KDF do arquivo
+func (f *FileAdapter) keyFor(kdf string, salt []byte) ([32]byte, error) {
+	var key [32]byte
+
+	switch kdf {
+	case "raw":
+		if f.key == nil {
+			return key, fmt.Errorf("arquivo cifrado com chave direta: defina %s", StorageKeyEnv)
+		}
+		copy(key[:], f.key)
+		return key, nil
+	case "scrypt":
+		if f.passphrase == nil {
+			return key, fmt.Errorf("arquivo cifrado com passphrase: defina %s", StoragePassphraseEnv)
+		}
+	default:
+		return key, fmt.Errorf("KDF %s não suportado", kdf)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if cached, ok := f.derived[string(salt)]; ok {
+		return cached, nil
+	}
+
+	derived, err := scrypt.Key(f.passphrase, salt, scryptN, scryptR, scryptP, 32)
+	if err != nil {
+		return key, fmt.Errorf("erro ao derivar chave: %w", err)
+	}
+	copy(key[:], derived)
+
+	if f.derived == nil {
+		f.derived = make(map[string][32]byte)
+	}
+	f.derived[string(salt)] = key
+	return key, nil
+}
@@ -0,0 +1,174 @@
+package storage
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// TestFileAdapter testa a cifra, as permissões e as chaves do armazenamento em arquivo
+func TestFileAdapter(t *testing.T) {
+	rawKey := base64.StdEncoding.EncodeToString([]byte("0123456789abcdef0123456789abcdef"))
+
+	tests := []struct {
+		name string
+		env  map[string]string
+	}{
+		{name: "Passphrase derived key", env: map[string]string{StoragePassphraseEnv: "correct horse"}},
+		{name: "Raw key from environment", env: map[string]string{StorageKeyEnv: rawKey}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(StorageKeyEnv, "")
+			t.Setenv(StoragePassphraseEnv, "")
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			dir := filepath.Join(t.TempDir(), "phengineer")
+			adapter, err := NewFileAdapter(dir)
+			if err != nil {
+				t.Fatalf("NewFileAdapter failed: %v", err)
+			}
+
+			if err := adapter.Set("github_token", "ghp_secret"); err != nil {
+				t.Fatalf("Set failed: %v", err)
+			}
+			if err := adapter.Set("stackspot_client_id", "client"); err != nil {
+				t.Fatalf("Set failed: %v", err)
+			}
+			if err := adapter.Delete("stackspot_client_id"); err != nil {
+				t.Fatalf("Delete failed: %v", err)
+			}
+
+			// Uma nova instância lê o que a anterior gravou
+			reopened, _ := NewFileAdapter(dir)
+			if value, err := reopened.Get("github_token"); err != nil || value != "ghp_secret" {
+				t.Errorf("Expected ghp_secret, got %q (%v)", value, err)
+			}
+			if reopened.Exists("stackspot_client_id") {
+				t.Error("Deleted key must not exist")
+			}
+
+			raw, err := os.ReadFile(adapter.Path())
+			if err != nil {
+				t.Fatalf("Failed to read credentials file: %v", err)
+			}
+			if strings.Contains(string(raw), "ghp_secret") || strings.Contains(string(raw), "github_token") {
+				t.Error("Credentials file must not contain plaintext")
+			}
+
+			for path, mode := range map[string]os.FileMode{dir: 0o700, adapter.Path(): 0o600} {
+				info, err := os.Stat(path)
+				if err != nil {
+					t.Fatalf("Stat failed: %v", err)
+				}
+				if info.Mode().Perm() != mode {
+					t.Errorf("%s mode = %o, expected %o", path, info.Mode().Perm(), mode)
+				}
+			}
+
+			leftovers, _ := filepath.Glob(filepath.Join(dir, "*.tmp"))
+			if len(leftovers) > 0 {
+				t.Errorf("Temporary files left behind: %v", leftovers)
+			}
+		})
+	}
+}
+
+// TestFileAdapterWrongKey testa a recusa de uma passphrase incorreta
+func TestFileAdapterWrongKey(t *testing.T) {
+	dir := t.TempDir()
+	if err := NewFileAdapterWithPassphrase(dir, "right").Set("key", "value"); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+
+	wrong := NewFileAdapterWithPassphrase(dir, "wrong")
+	if _, err := wrong.Get("key"); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("Expected ErrDecrypt, got %v", err)
+	}
+	if err := wrong.Set("other", "value"); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("Set with wrong passphrase must not overwrite the file, got %v", err)
+	}
+}
+
+// TestFileAdapterConcurrentWriters testa gravações simultâneas de instâncias diferentes
+func TestFileAdapterConcurrentWriters(t *testing.T) {
+	dir := t.TempDir()
+	const writers = 4
+	const keysPerWriter = 5
+
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			// Instâncias separadas simulam processos distintos: cada uma abre o lock por conta própria
+			adapter := NewFileAdapterWithPassphrase(dir, "shared")
+			for k := 0; k < keysPerWriter; k++ {
+				if err := adapter.Set(fmt.Sprintf("w%d_k%d", w, k), "value"); err != nil {
+					t.Errorf("Set failed: %v", err)
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	adapter := NewFileAdapterWithPassphrase(dir, "shared")
+	for w := 0; w < writers; w++ {
+		for k := 0; k < keysPerWriter; k++ {
+			key := fmt.Sprintf("w%d_k%d", w, k)
+			if !adapter.Exists(key) {
+				t.Errorf("Key %s lost in concurrent writes", key)
+			}
+		}
+	}
+}
+
+// TestSelectBackend testa a escolha do backend e o fallback sem keyring
+func TestSelectBackend(t *testing.T) {
+	tests := []struct {
+		name       string
+		forced     string
+		keyringOK  bool
+		passphrase string
+		expected   Backend
+		wantErr    bool
+	}{
+		{name: "Keyring available", keyringOK: true, expected: BackendKeyring},
+		{name: "Fallback to file with passphrase", passphrase: "secret", expected: BackendFile},
+		{name: "Fallback to memory without key", expected: BackendMemory},
+		{name: "Forced file", forced: "file", keyringOK: true, passphrase: "secret", expected: BackendFile},
+		{name: "Forced file without key", forced: "file", wantErr: true},
+		{name: "Forced memory", forced: "memory", keyringOK: true, expected: BackendMemory},
+		{name: "Invalid backend", forced: "vault", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("XDG_DATA_HOME", t.TempDir())
+			t.Setenv(StorageKeyEnv, "")
+			t.Setenv(StoragePassphraseEnv, tt.passphrase)
+
+			_, backend, err := selectBackend(tt.forced, func() bool { return tt.keyringOK })
+			if tt.wantErr {
+				if err == nil {
+					t.Error("Expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("selectBackend failed: %v", err)
+			}
+			if backend != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, backend)
+			}
+		})
+	}
+}
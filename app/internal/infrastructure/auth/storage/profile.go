@@ -0,0 +1,216 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+)
+
+// DefaultProfile perfil usado quando nenhum outro é selecionado. Suas chaves não
+// têm prefixo, mantendo as credenciais salvas antes da existência de perfis.
+const DefaultProfile = "default"
+
+const (
+	profilesKey      = "profiles"
+	activeProfileKey = "active_profile"
+)
+
+var profileNamePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_-]*$`)
+
+// ValidateProfileName valida o nome de um perfil
+func ValidateProfileName(name string) error {
+	if !profileNamePattern.MatchString(name) {
+		return fmt.Errorf("nome de perfil inválido '%s': use letras, números, '-' ou '_'", name)
+	}
+	return nil
+}
+
+// ProfileRegistry perfis de autenticação conhecidos e o perfil padrão do usuário.
+// O keyring não lista chaves, então os perfis e as chaves de cada um ficam indexados no próprio storage.
+type ProfileRegistry struct {
+	base StorageAdapter
+}
+
+func NewProfileRegistry(base StorageAdapter) *ProfileRegistry {
+	return &ProfileRegistry{base: base}
+}
+
+// Adapter retorna o storage isolado do perfil
+func (r *ProfileRegistry) Adapter(profile string) *ProfileAdapter {
+	if profile == "" {
+		profile = DefaultProfile
+	}
+	return &ProfileAdapter{registry: r, profile: profile}
+}
+
+// List retorna os perfis que possuem credenciais salvas
+func (r *ProfileRegistry) List() ([]string, error) {
+	return r.readList(profilesKey)
+}
+
+// Exists verifica se o perfil possui credenciais salvas
+func (r *ProfileRegistry) Exists(profile string) bool {
+	profiles, err := r.List()
+	return err == nil && slices.Contains(profiles, profile)
+}
+
+// Default retorna o perfil escolhido com `auth use`
+func (r *ProfileRegistry) Default() string {
+	if profile, err := r.base.Get(activeProfileKey); err == nil && profile != "" {
+		return profile
+	}
+	return DefaultProfile
+}
+
+// SetDefault define o perfil usado quando nenhum outro é selecionado
+func (r *ProfileRegistry) SetDefault(profile string) error {
+	if err := ValidateProfileName(profile); err != nil {
+		return err
+	}
+	if profile == DefaultProfile {
+		if r.base.Exists(activeProfileKey) {
+			return r.base.Delete(activeProfileKey)
+		}
+		return nil
+	}
+	return r.base.Set(activeProfileKey, profile)
+}
+
+// Remove apaga todas as chaves do perfil e o retira da lista
+func (r *ProfileRegistry) Remove(profile string) error {
+	adapter := r.Adapter(profile)
+	keys, err := adapter.Keys()
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		if adapter.Exists(key) {
+			if err := r.base.Delete(adapter.key(key)); err != nil {
+				return fmt.Errorf("erro ao remover %s do perfil %s: %w", key, profile, err)
+			}
+		}
+	}
+	if r.base.Exists(adapter.indexKey()) {
+		if err := r.base.Delete(adapter.indexKey()); err != nil {
+			return err
+		}
+	}
+
+	if err := r.updateList(profilesKey, func(items []string) []string {
+		return slices.DeleteFunc(items, func(item string) bool { return item == profile })
+	}); err != nil {
+		return err
+	}
+
+	if r.Default() == profile {
+		return r.SetDefault(DefaultProfile)
+	}
+	return nil
+}
+
+func (r *ProfileRegistry) readList(key string) ([]string, error) {
+	if !r.base.Exists(key) {
+		return nil, nil
+	}
+
+	data, err := r.base.Get(key)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []string
+	if err := json.Unmarshal([]byte(data), &items); err != nil {
+		return nil, fmt.Errorf("índice %s corrompido: %w", key, err)
+	}
+	return items, nil
+}
+
+func (r *ProfileRegistry) updateList(key string, update func([]string) []string) error {
+	items, err := r.readList(key)
+	if err != nil {
+		return err
+	}
+
+	updated := update(slices.Clone(items))
+	if slices.Equal(items, updated) {
+		return nil
+	}
+	if len(updated) == 0 {
+		return r.base.Delete(key)
+	}
+
+	slices.Sort(updated)
+	data, err := json.Marshal(updated)
+	if err != nil {
+		return err
+	}
+	return r.base.Set(key, string(data))
+}
+
+func (r *ProfileRegistry) track(listKey, item string) error {
+	return r.updateList(listKey, func(items []string) []string {
+		if slices.Contains(items, item) {
+			return items
+		}
+		return append(items, item)
+	})
+}
+
+// ProfileAdapter storage de um perfil: as chaves recebem o prefixo profile/<nome>/
+type ProfileAdapter struct {
+	registry *ProfileRegistry
+	profile  string
+}
+
+// Profile retorna o nome do perfil
+func (p *ProfileAdapter) Profile() string {
+	return p.profile
+}
+
+func (p *ProfileAdapter) Set(key, value string) error {
+	if err := ValidateProfileName(p.profile); err != nil {
+		return err
+	}
+	if err := p.registry.base.Set(p.key(key), value); err != nil {
+		return err
+	}
+	if err := p.registry.track(p.indexKey(), key); err != nil {
+		return err
+	}
+	return p.registry.track(profilesKey, p.profile)
+}
+
+func (p *ProfileAdapter) Get(key string) (string, error) {
+	return p.registry.base.Get(p.key(key))
+}
+
+func (p *ProfileAdapter) Delete(key string) error {
+	if err := p.registry.base.Delete(p.key(key)); err != nil {
+		return err
+	}
+	return p.registry.updateList(p.indexKey(), func(items []string) []string {
+		return slices.DeleteFunc(items, func(item string) bool { return item == key })
+	})
+}
+
+func (p *ProfileAdapter) Exists(key string) bool {
+	return p.registry.base.Exists(p.key(key))
+}
+
+// Keys retorna as chaves gravadas no perfil
+func (p *ProfileAdapter) Keys() ([]string, error) {
+	return p.registry.readList(p.indexKey())
+}
+
+func (p *ProfileAdapter) key(key string) string {
+	if p.profile == DefaultProfile {
+		return key
+	}
+	return "profile/" + p.profile + "/" + key
+}
+
+func (p *ProfileAdapter) indexKey() string {
+	return "profile_keys/" + p.profile
+}
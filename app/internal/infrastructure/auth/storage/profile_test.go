@@ -0,0 +1,98 @@
+package storage
+
+import (
+	"slices"
+	"testing"
+)
+
+// TestProfileAdapter testa o isolamento das chaves por perfil
+func TestProfileAdapter(t *testing.T) {
+	base := NewMemoryAdapter()
+	base.Set("github_token", "legacy-token")
+
+	registry := NewProfileRegistry(base)
+	work := registry.Adapter("work")
+	personal := registry.Adapter(DefaultProfile)
+
+	if err := work.Set("github_token", "work-token"); err != nil {
+		t.Fatalf("Failed to set work token: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		adapter  *ProfileAdapter
+		expected string
+	}{
+		{name: "Default profile keeps legacy keys", adapter: personal, expected: "legacy-token"},
+		{name: "Named profile is namespaced", adapter: work, expected: "work-token"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, err := tt.adapter.Get("github_token")
+			if err != nil {
+				t.Fatalf("Get failed: %v", err)
+			}
+			if value != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, value)
+			}
+		})
+	}
+
+	if value, _ := base.Get("profile/work/github_token"); value != "work-token" {
+		t.Errorf("Expected namespaced key in base storage, got %q", value)
+	}
+	if registry.Adapter("other").Exists("github_token") {
+		t.Error("Profile other must not see work credentials")
+	}
+	if err := registry.Adapter("bad name").Set("key", "value"); err == nil {
+		t.Error("Expected error for invalid profile name")
+	}
+}
+
+// TestProfileRegistry testa a listagem, o perfil padrão e a remoção de perfis
+func TestProfileRegistry(t *testing.T) {
+	base := NewMemoryAdapter()
+	registry := NewProfileRegistry(base)
+
+	registry.Adapter("work").Set("stackspot_client_id", "id")
+	registry.Adapter("work").Set("stackspot_client_secret", "secret")
+	registry.Adapter("oss").Set("github_token", "token")
+
+	profiles, err := registry.List()
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if !slices.Equal(profiles, []string{"oss", "work"}) {
+		t.Errorf("Expected [oss work], got %v", profiles)
+	}
+
+	if registry.Default() != DefaultProfile {
+		t.Errorf("Expected default profile, got %s", registry.Default())
+	}
+	if err := registry.SetDefault("work"); err != nil {
+		t.Fatalf("SetDefault failed: %v", err)
+	}
+	if registry.Default() != "work" {
+		t.Errorf("Expected work as default, got %s", registry.Default())
+	}
+
+	if err := registry.Remove("work"); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+
+	for _, key := range []string{"profile/work/stackspot_client_id", "profile/work/stackspot_client_secret", "profile_keys/work"} {
+		if base.Exists(key) {
+			t.Errorf("Key %s must be removed", key)
+		}
+	}
+	if !registry.Adapter("oss").Exists("github_token") {
+		t.Error("Removing work must keep oss credentials")
+	}
+	if registry.Exists("work") {
+		t.Error("Profile work must not be listed after removal")
+	}
+	if registry.Default() != DefaultProfile {
+		t.Errorf("Removing the default profile must reset it, got %s", registry.Default())
+	}
+}
@@ -0,0 +1,39 @@
+package token
+
+import (
+	"encoding/base64"
+	"testing"
+)
+
+// TestDecodeClaims testa a leitura do payload de um JWT sem validação de assinatura
+func TestDecodeClaims(t *testing.T) {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"user-1","iss":"https://idm.stackspot.com/realm","exp":1700000000}`))
+
+	tests := []struct {
+		name    string
+		jwt     string
+		wantSub string
+		wantErr bool
+	}{
+		{name: "Valid JWT", jwt: "header." + payload + ".signature", wantSub: "user-1"},
+		{name: "Padded payload", jwt: "header." + payload + "==.signature", wantSub: "user-1"},
+		{name: "Opaque token", jwt: "ghp_abcdef", wantErr: true},
+		{name: "Invalid base64", jwt: "header.%%%.signature", wantErr: true},
+		{name: "Payload is not JSON", jwt: "header." + base64.RawURLEncoding.EncodeToString([]byte("plain")) + ".signature", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := DecodeClaims(tt.jwt)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DecodeClaims() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if claims["sub"] != tt.wantSub {
+				t.Errorf("sub = %v, want %v", claims["sub"], tt.wantSub)
+			}
+		})
+	}
+}
@@ -20,15 +20,18 @@ func GetService() *Service {
 		authMode := viper.GetString("auth.mode")
 		switch authMode {
 		case "stackspot_user":
-			adapter = storage.NewKeyringAdapter()
+			adapter, _, _ = storage.Default()
 		case "stackspot_service":
 			adapter = storage.NewMemoryAdapter()
 		default:
 			// Default para user
-			adapter = storage.NewKeyringAdapter()
+			adapter, _, _ = storage.Default()
 		}
 
 		globalService = NewService(adapter)
+		if viper.IsSet("auth.token_refresh_skew") {
+			globalService.SetRefreshSkew(viper.GetDuration("auth.token_refresh_skew"))
+		}
 	})
 	return globalService
 }
@@ -1,6 +1,9 @@
 package token
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
 
 type TokenGeneratorAlias string
 
@@ -10,6 +13,22 @@ const (
 	TokenGenGH  TokenGeneratorAlias = "github-api"
 )
 
+// Aliases lista os geradores de token conhecidos
+var Aliases = []TokenGeneratorAlias{TokenGenSTK, TokenGenHC, TokenGenGH}
+
+// ParseAlias aceita o alias completo ou o nome curto do provedor (stackspot, vault, github)
+func ParseAlias(name string) (TokenGeneratorAlias, error) {
+	switch name {
+	case "stackspot", string(TokenGenSTK):
+		return TokenGenSTK, nil
+	case "vault", string(TokenGenHC):
+		return TokenGenHC, nil
+	case "github", string(TokenGenGH):
+		return TokenGenGH, nil
+	}
+	return "", fmt.Errorf("unknown token alias %q (expected stackspot, vault or github)", name)
+}
+
 // TokenScope define os escopos disponíveis
 type TokenScope string
 
@@ -20,19 +39,37 @@ const (
 	ScopeWrite     TokenScope = "write"
 )
 
+// Scopes lista os escopos disponíveis
+var Scopes = []TokenScope{ScopeExecution, ScopeCreation, ScopeRead, ScopeWrite}
+
+// ParseScope valida o nome de um escopo
+func ParseScope(name string) (TokenScope, error) {
+	for _, scope := range Scopes {
+		if string(scope) == name {
+			return scope, nil
+		}
+	}
+	return "", fmt.Errorf("unknown token scope %q", name)
+}
+
 // TokenData representa os dados do token armazenados
 type TokenData struct {
-	Token     string              `json:"token"`
-	ExpiresAt time.Time           `json:"expires_at"`
-	Scope     TokenScope          `json:"scope"`
-	Alias     TokenGeneratorAlias `json:"alias"`
+	Token        string              `json:"token"`
+	RefreshToken string              `json:"refresh_token,omitempty"`
+	ExpiresAt    time.Time           `json:"expires_at"`
+	Scope        TokenScope          `json:"scope"`
+	Alias        TokenGeneratorAlias `json:"alias"`
 }
 
 // TokenResponse representa a resposta da API de token
 type TokenResponse struct {
-	AccessToken string `json:"access_token"`
-	ExpiresIn   int    `json:"expires_in"` // segundos
+	AccessToken  string `json:"access_token"`
+	ExpiresIn    int    `json:"expires_in"`              // segundos
+	RefreshToken string `json:"refresh_token,omitempty"` // presente apenas em logins OAuth interativos
 }
 
 // TokenGenerator função para gerar tokens
 type TokenGenerator func(scope TokenScope) (TokenResponse, error)
+
+// TokenRefresher função que troca um refresh token por um novo access token
+type TokenRefresher func(scope TokenScope, refreshToken string) (TokenResponse, error)
@@ -3,28 +3,86 @@ package token
 import (
 	"encoding/json"
 	"fmt"
+	"slices"
+	"sync"
 	"time"
 
 	"github.com/PHRaulino/phengineer/internal/infrastructure/auth/storage"
+	"golang.org/x/sync/singleflight"
 )
 
+// DefaultRefreshSkew antecedência com que um token é renovado antes de expirar
+const DefaultRefreshSkew = 60 * time.Second
+
+// Service gera e armazena tokens por escopo e alias. É seguro para uso concorrente:
+// chamadas simultâneas para a mesma chave compartilham uma única geração.
 type Service struct {
+	mu         sync.RWMutex
 	storage    storage.StorageAdapter
 	generators map[TokenGeneratorAlias]TokenGenerator // key: alias, value: generator function
+	refreshers map[TokenGeneratorAlias]TokenRefresher
+	cache      map[string]TokenData // camada em memória na frente do storage
+	skew       time.Duration
+
+	flight singleflight.Group
+	now    func() time.Time
 }
 
 func NewService(storage storage.StorageAdapter) *Service {
 	return &Service{
 		storage:    storage,
 		generators: make(map[TokenGeneratorAlias]TokenGenerator),
+		refreshers: make(map[TokenGeneratorAlias]TokenRefresher),
+		cache:      make(map[string]TokenData),
+		skew:       DefaultRefreshSkew,
+		now:        time.Now,
 	}
 }
 
+// SetStorage troca o storage dos tokens, por exemplo ao selecionar um perfil.
+// Os tokens em memória pertencem ao storage anterior e são descartados.
+func (s *Service) SetStorage(storage storage.StorageAdapter) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.storage = storage
+	s.cache = make(map[string]TokenData)
+}
+
+// SetRefreshSkew define a antecedência da renovação; valores negativos equivalem a zero
+func (s *Service) SetRefreshSkew(skew time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.skew = max(skew, 0)
+}
+
 // RegisterGenerator registra uma função geradora de token para um alias
 func (s *Service) RegisterGenerator(alias TokenGeneratorAlias, generator TokenGenerator) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	s.generators[alias] = generator
 }
 
+// RegisterRefresher registra a renovação via refresh token para um alias.
+// Sem refresher, ou se a renovação falhar, o token é gerado de novo pelo generator.
+func (s *Service) RegisterRefresher(alias TokenGeneratorAlias, refresher TokenRefresher) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.refreshers[alias] = refresher
+}
+
+// Aliases retorna os aliases com gerador registrado, em ordem alfabética
+func (s *Service) Aliases() []TokenGeneratorAlias {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	aliases := make([]TokenGeneratorAlias, 0, len(s.generators))
+	for alias := range s.generators {
+		aliases = append(aliases, alias)
+	}
+	slices.Sort(aliases)
+	return aliases
+}
+
 // getTokenKey gera a chave para armazenamento baseado no escopo e alias
 func (s *Service) getTokenKey(scope TokenScope, alias TokenGeneratorAlias) string {
 	return fmt.Sprintf("token_%s_%s", string(scope), alias)
@@ -34,24 +92,80 @@ func (s *Service) getTokenKey(scope TokenScope, alias TokenGeneratorAlias) strin
 func (s *Service) Get(scope TokenScope, alias TokenGeneratorAlias) (string, error) {
 	key := s.getTokenKey(scope, alias)
 
-	// 1. Buscar token existente
-	if s.storage.Exists(key) {
-		tokenData, err := s.getStoredToken(key)
-		if err == nil {
-			// 2. Validar se ainda é válido
-			if s.isValid(tokenData) {
-				return tokenData.Token, nil
+	// 1. Buscar token existente (memória e depois storage)
+	if tokenData, ok := s.lookup(key); ok && s.isValid(&tokenData) {
+		return tokenData.Token, nil
+	}
+
+	// 2. Se não existe ou vai expirar, renovar ou criar novo; chamadas simultâneas esperam a mesma geração
+	result, err, _ := s.flight.Do(key, func() (any, error) {
+		// Outra chamada pode ter gerado o token enquanto esta aguardava
+		tokenData, ok := s.lookup(key)
+		if ok && s.isValid(&tokenData) {
+			return tokenData.Token, nil
+		}
+
+		// 3. Com refresh token, tentar grant_type=refresh_token antes de um login completo
+		if ok && tokenData.RefreshToken != "" {
+			if accessToken, err := s.refresh(tokenData); err == nil {
+				return accessToken, nil
 			}
 		}
+		return s.create(scope, alias)
+	})
+	if err != nil {
+		return "", err
 	}
+	return result.(string), nil
+}
 
-	// 3. Se não existe ou inválido, criar novo
-	return s.create(scope, alias)
+// Cached retorna o token armazenado para o escopo e alias, ou nil se não houver
+func (s *Service) Cached(scope TokenScope, alias TokenGeneratorAlias) (*TokenData, error) {
+	key := s.getTokenKey(scope, alias)
+
+	s.mu.RLock()
+	tokenData, ok := s.cache[key]
+	adapter := s.storage
+	s.mu.RUnlock()
+	if ok {
+		return &tokenData, nil
+	}
+
+	if !adapter.Exists(key) {
+		return nil, nil
+	}
+	return s.getStoredToken(adapter, key)
+}
+
+// lookup busca o token na memória e, se ausente, no storage, guardando-o em memória
+func (s *Service) lookup(key string) (TokenData, bool) {
+	s.mu.RLock()
+	tokenData, ok := s.cache[key]
+	adapter := s.storage
+	s.mu.RUnlock()
+	if ok {
+		return tokenData, true
+	}
+
+	if !adapter.Exists(key) {
+		return TokenData{}, false
+	}
+	stored, err := s.getStoredToken(adapter, key)
+	if err != nil {
+		return TokenData{}, false
+	}
+
+	s.mu.Lock()
+	if s.storage == adapter {
+		s.cache[key] = *stored
+	}
+	s.mu.Unlock()
+	return *stored, true
 }
 
 // getStoredToken recupera e deserializa token do storage
-func (s *Service) getStoredToken(key string) (*TokenData, error) {
-	data, err := s.storage.Get(key)
+func (s *Service) getStoredToken(adapter storage.StorageAdapter, key string) (*TokenData, error) {
+	data, err := adapter.Get(key)
 	if err != nil {
 		return nil, err
 	}
@@ -64,17 +178,24 @@ func (s *Service) getStoredToken(key string) (*TokenData, error) {
 	return &tokenData, nil
 }
 
-// isValid verifica se o token não expirou
+// isValid verifica se o token não expira dentro da margem de renovação
 func (s *Service) isValid(tokenData *TokenData) bool {
 	if tokenData == nil || tokenData.Token == "" {
 		return false
 	}
-	return time.Now().Before(tokenData.ExpiresAt)
+
+	s.mu.RLock()
+	skew := s.skew
+	s.mu.RUnlock()
+
+	return s.now().Before(tokenData.ExpiresAt.Add(-skew))
 }
 
 // create gera um novo token usando o generator registrado
 func (s *Service) create(scope TokenScope, alias TokenGeneratorAlias) (string, error) {
+	s.mu.RLock()
 	generator, exists := s.generators[alias]
+	s.mu.RUnlock()
 	if !exists {
 		return "", fmt.Errorf("no generator registered for alias: %s", alias)
 	}
@@ -85,12 +206,38 @@ func (s *Service) create(scope TokenScope, alias TokenGeneratorAlias) (string, e
 		return "", fmt.Errorf("failed to generate token: %w", err)
 	}
 
-	// Salvar no storage
+	return s.store(scope, alias, tokenResponse)
+}
+
+// refresh renova o token com o refresh token armazenado usando o refresher registrado
+func (s *Service) refresh(previous TokenData) (string, error) {
+	s.mu.RLock()
+	refresher, exists := s.refreshers[previous.Alias]
+	s.mu.RUnlock()
+	if !exists {
+		return "", fmt.Errorf("no refresher registered for alias: %s", previous.Alias)
+	}
+
+	tokenResponse, err := refresher(previous.Scope, previous.RefreshToken)
+	if err != nil {
+		return "", fmt.Errorf("failed to refresh token: %w", err)
+	}
+
+	// Servidores sem rotação não devolvem um novo refresh token
+	if tokenResponse.RefreshToken == "" {
+		tokenResponse.RefreshToken = previous.RefreshToken
+	}
+	return s.store(previous.Scope, previous.Alias, tokenResponse)
+}
+
+// store converte a resposta em TokenData e salva no storage
+func (s *Service) store(scope TokenScope, alias TokenGeneratorAlias, tokenResponse TokenResponse) (string, error) {
 	tokenData := TokenData{
-		Token:     tokenResponse.AccessToken,
-		ExpiresAt: time.Now().Add(time.Duration(tokenResponse.ExpiresIn) * time.Second),
-		Scope:     scope,
-		Alias:     alias,
+		Token:        tokenResponse.AccessToken,
+		RefreshToken: tokenResponse.RefreshToken,
+		ExpiresAt:    s.now().Add(time.Duration(tokenResponse.ExpiresIn) * time.Second),
+		Scope:        scope,
+		Alias:        alias,
 	}
 
 	if err := s.save(tokenData); err != nil {
@@ -100,7 +247,7 @@ func (s *Service) create(scope TokenScope, alias TokenGeneratorAlias) (string, e
 	return tokenData.Token, nil
 }
 
-// save serializa e armazena o token
+// save serializa e armazena o token no storage e na memória
 func (s *Service) save(tokenData TokenData) error {
 	data, err := json.Marshal(tokenData)
 	if err != nil {
@@ -108,17 +255,42 @@ func (s *Service) save(tokenData TokenData) error {
 	}
 
 	key := s.getTokenKey(tokenData.Scope, tokenData.Alias)
-	return s.storage.Set(key, string(data))
+
+	s.mu.RLock()
+	adapter := s.storage
+	s.mu.RUnlock()
+
+	// O keyring pode ser lento: a gravação acontece fora do lock
+	if err := adapter.Set(key, string(data)); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	if s.storage == adapter {
+		s.cache[key] = tokenData
+	}
+	s.mu.Unlock()
+	return nil
 }
 
 // Delete remove um token específico
 func (s *Service) Delete(scope TokenScope, alias TokenGeneratorAlias) error {
 	key := s.getTokenKey(scope, alias)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.cache, key)
 	return s.storage.Delete(key)
 }
 
 // Exists verifica se existe token para o escopo e alias
 func (s *Service) Exists(scope TokenScope, alias TokenGeneratorAlias) bool {
 	key := s.getTokenKey(scope, alias)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if _, ok := s.cache[key]; ok {
+		return true
+	}
 	return s.storage.Exists(key)
 }
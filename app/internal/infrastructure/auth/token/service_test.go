@@ -0,0 +1,281 @@
+package token
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/PHRaulino/phengineer/internal/infrastructure/auth/storage"
+)
+
+// fakeGenerator gerador que conta as chamadas e pode demorar para simular o IdM
+type fakeGenerator struct {
+	calls     atomic.Int32
+	delay     time.Duration
+	expiresIn int
+	err       error
+}
+
+func (g *fakeGenerator) generate(scope TokenScope) (TokenResponse, error) {
+	n := g.calls.Add(1)
+	time.Sleep(g.delay)
+	if g.err != nil {
+		return TokenResponse{}, g.err
+	}
+	return TokenResponse{AccessToken: fmt.Sprintf("%s-%d", scope, n), ExpiresIn: g.expiresIn}, nil
+}
+
+// countingStorage storage em memória que conta as leituras
+type countingStorage struct {
+	*storage.MemoryAdapter
+	gets atomic.Int32
+}
+
+func (c *countingStorage) Get(key string) (string, error) {
+	c.gets.Add(1)
+	return c.MemoryAdapter.Get(key)
+}
+
+// TestServiceSingleFlight testa que chamadas simultâneas para a mesma chave geram um único token
+func TestServiceSingleFlight(t *testing.T) {
+	tests := []struct {
+		name      string
+		scopes    []TokenScope
+		callers   int
+		wantCalls int32
+	}{
+		{name: "Same scope shares one generation", scopes: []TokenScope{ScopeExecution}, callers: 50, wantCalls: 1},
+		{name: "Different scopes generate separately", scopes: []TokenScope{ScopeExecution, ScopeRead, ScopeWrite}, callers: 30, wantCalls: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			generator := &fakeGenerator{delay: 20 * time.Millisecond, expiresIn: 3600}
+			service := NewService(storage.NewMemoryAdapter())
+			service.RegisterGenerator(TokenGenSTK, generator.generate)
+
+			var wg sync.WaitGroup
+			errs := make(chan error, tt.callers)
+			for i := 0; i < tt.callers; i++ {
+				wg.Add(1)
+				go func(scope TokenScope) {
+					defer wg.Done()
+					if _, err := service.Get(scope, TokenGenSTK); err != nil {
+						errs <- err
+					}
+				}(tt.scopes[i%len(tt.scopes)])
+			}
+			wg.Wait()
+			close(errs)
+
+			for err := range errs {
+				t.Errorf("Get() error = %v", err)
+			}
+			if got := generator.calls.Load(); got != tt.wantCalls {
+				t.Errorf("generator calls = %d, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+// TestServiceRefreshSkew testa a renovação antecipada de tokens próximos de expirar
+func TestServiceRefreshSkew(t *testing.T) {
+	tests := []struct {
+		name      string
+		skew      time.Duration
+		elapsed   time.Duration
+		wantCalls int32
+	}{
+		{name: "Token far from expiry is reused", skew: time.Minute, elapsed: 30 * time.Minute, wantCalls: 1},
+		{name: "Token inside skew is refreshed", skew: time.Minute, elapsed: 59*time.Minute + 30*time.Second, wantCalls: 2},
+		{name: "Zero skew uses token until expiry", skew: 0, elapsed: 59*time.Minute + 30*time.Second, wantCalls: 1},
+		{name: "Expired token is refreshed", skew: 0, elapsed: 2 * time.Hour, wantCalls: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+			generator := &fakeGenerator{expiresIn: 3600}
+			service := NewService(storage.NewMemoryAdapter())
+			service.now = func() time.Time { return now }
+			service.SetRefreshSkew(tt.skew)
+			service.RegisterGenerator(TokenGenSTK, generator.generate)
+
+			if _, err := service.Get(ScopeExecution, TokenGenSTK); err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+			now = now.Add(tt.elapsed)
+			if _, err := service.Get(ScopeExecution, TokenGenSTK); err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+
+			if got := generator.calls.Load(); got != tt.wantCalls {
+				t.Errorf("generator calls = %d, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+// TestServiceMemoryLayer testa que a camada em memória evita leituras repetidas do storage
+func TestServiceMemoryLayer(t *testing.T) {
+	backing := &countingStorage{MemoryAdapter: storage.NewMemoryAdapter()}
+	generator := &fakeGenerator{expiresIn: 3600}
+
+	// Token gravado por outro processo: lido do storage uma única vez
+	writer := NewService(backing)
+	writer.RegisterGenerator(TokenGenSTK, generator.generate)
+	want, err := writer.Get(ScopeRead, TokenGenSTK)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+
+	reader := NewService(backing)
+	reader.RegisterGenerator(TokenGenSTK, generator.generate)
+	for i := 0; i < 10; i++ {
+		got, err := reader.Get(ScopeRead, TokenGenSTK)
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		if got != want {
+			t.Errorf("Get() = %q, want %q", got, want)
+		}
+	}
+
+	if got := backing.gets.Load(); got != 1 {
+		t.Errorf("storage reads = %d, want 1", got)
+	}
+	if got := generator.calls.Load(); got != 1 {
+		t.Errorf("generator calls = %d, want 1", got)
+	}
+
+	// Trocar o storage descarta os tokens em memória do perfil anterior
+	reader.SetStorage(storage.NewMemoryAdapter())
+	if reader.Exists(ScopeRead, TokenGenSTK) {
+		t.Error("Exists() = true after SetStorage, want false")
+	}
+
+	if err := writer.Delete(ScopeRead, TokenGenSTK); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if cached, _ := writer.Cached(ScopeRead, TokenGenSTK); cached != nil {
+		t.Errorf("Cached() = %+v after Delete, want nil", cached)
+	}
+}
+
+// TestServiceGeneratorError testa que falhas do gerador não são armazenadas
+func TestServiceGeneratorError(t *testing.T) {
+	generator := &fakeGenerator{err: errors.New("idm unavailable")}
+	service := NewService(storage.NewMemoryAdapter())
+	service.RegisterGenerator(TokenGenSTK, generator.generate)
+
+	for i := 0; i < 2; i++ {
+		if _, err := service.Get(ScopeExecution, TokenGenSTK); err == nil {
+			t.Fatal("Get() error = nil, want error")
+		}
+	}
+	if got := generator.calls.Load(); got != 2 {
+		t.Errorf("generator calls = %d, want 2", got)
+	}
+	if service.Exists(ScopeExecution, TokenGenSTK) {
+		t.Error("Exists() = true after failed generation, want false")
+	}
+
+	if _, err := service.Get(ScopeExecution, TokenGenGH); err == nil {
+		t.Error("Get() for unregistered alias error = nil, want error")
+	}
+}
+
+// TestServiceConcurrentRegistry testa o registro de geradores concorrente com leituras
+func TestServiceConcurrentRegistry(t *testing.T) {
+	service := NewService(storage.NewMemoryAdapter())
+
+	var wg sync.WaitGroup
+	for _, alias := range Aliases {
+		generator := &fakeGenerator{expiresIn: 3600}
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			service.RegisterGenerator(alias, generator.generate)
+		}()
+		go func() {
+			defer wg.Done()
+			service.Aliases()
+			service.Exists(ScopeExecution, alias)
+		}()
+	}
+	wg.Wait()
+
+	if got := len(service.Aliases()); got != len(Aliases) {
+		t.Errorf("len(Aliases()) = %d, want %d", got, len(Aliases))
+	}
+}
+
+// TestServiceRefreshToken testa a renovação via refresh token antes de um novo login
+func TestServiceRefreshToken(t *testing.T) {
+	tests := []struct {
+		name             string
+		refreshErr       error
+		rotate           bool
+		wantGenerator    int32
+		wantRefresher    int32
+		wantRefreshToken string
+	}{
+		{name: "Refresh replaces full login", wantGenerator: 1, wantRefresher: 1, wantRefreshToken: "rt-1"},
+		{name: "Rotated refresh token is stored", rotate: true, wantGenerator: 1, wantRefresher: 1, wantRefreshToken: "rt-rotated"},
+		{name: "Failed refresh falls back to generator", refreshErr: errors.New("invalid_grant"), wantGenerator: 2, wantRefresher: 1, wantRefreshToken: "rt-2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+			var generatorCalls, refresherCalls atomic.Int32
+
+			service := NewService(storage.NewMemoryAdapter())
+			service.now = func() time.Time { return now }
+			service.RegisterGenerator(TokenGenSTK, func(scope TokenScope) (TokenResponse, error) {
+				n := generatorCalls.Add(1)
+				return TokenResponse{AccessToken: fmt.Sprintf("at-%d", n), ExpiresIn: 300, RefreshToken: fmt.Sprintf("rt-%d", n)}, nil
+			})
+			service.RegisterRefresher(TokenGenSTK, func(scope TokenScope, refreshToken string) (TokenResponse, error) {
+				refresherCalls.Add(1)
+				if refreshToken != "rt-1" {
+					t.Errorf("refresh token = %q, want rt-1", refreshToken)
+				}
+				if tt.refreshErr != nil {
+					return TokenResponse{}, tt.refreshErr
+				}
+				response := TokenResponse{AccessToken: "at-refreshed", ExpiresIn: 300}
+				if tt.rotate {
+					response.RefreshToken = "rt-rotated"
+				}
+				return response, nil
+			})
+
+			if _, err := service.Get(ScopeExecution, TokenGenSTK); err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+			now = now.Add(10 * time.Minute)
+			if _, err := service.Get(ScopeExecution, TokenGenSTK); err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+
+			if got := generatorCalls.Load(); got != tt.wantGenerator {
+				t.Errorf("generator calls = %d, want %d", got, tt.wantGenerator)
+			}
+			if got := refresherCalls.Load(); got != tt.wantRefresher {
+				t.Errorf("refresher calls = %d, want %d", got, tt.wantRefresher)
+			}
+
+			cached, err := service.Cached(ScopeExecution, TokenGenSTK)
+			if err != nil || cached == nil {
+				t.Fatalf("Cached() = %v, %v", cached, err)
+			}
+			if cached.RefreshToken != tt.wantRefreshToken {
+				t.Errorf("stored refresh token = %q, want %q", cached.RefreshToken, tt.wantRefreshToken)
+			}
+		})
+	}
+}
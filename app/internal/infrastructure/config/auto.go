@@ -1,108 +1,149 @@
 package config
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"os/exec"
+	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/PHRaulino/phengineer/internal/infrastructure/gitrepo"
 )
 
-// collectAutoConfig coleta configurações automáticas do ambiente
-func collectAutoConfig(configFolderName string) (*AutoConfig, error) {
+// collectAutoConfig coleta configurações automáticas do repositório. Fora do git (repo nil),
+// ou sem remote origin, usa o diretório do projeto e deixa RemoteURL vazio.
+func collectAutoConfig(repo gitrepo.Repository, configFolderName string) (*AutoConfig, error) {
 	auto := &AutoConfig{}
 
-	// Coleta nome do app (nome do repositório)
-	appName, err := getRepositoryName()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get repository name: %w", err)
-	}
-	auto.AppName = appName
-
-	// Coleta caminho da pasta de config
-	configDirPath, err := getConfigDirPath(configFolderName)
+	rootAppPath, err := getRootPath(repo, configFolderName)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get config dir path: %w", err)
+		return nil, fmt.Errorf("failed to get project root: %w", err)
 	}
-	auto.ConfigDirPath = configDirPath
+	auto.RootAppPath = rootAppPath
+	auto.ConfigDirPath = filepath.Join(rootAppPath, configFolderName)
 
-	// Coleta caminho da pasta de config
-	rootAppPath, err := getRootPath(configFolderName)
+	// Nome do app: repositório do remote, raiz do git ou, sem git, a pasta do projeto
+	appName, err := getRepositoryName(repo)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get config dir path: %w", err)
+		appName = filepath.Base(rootAppPath)
 	}
-	auto.RootAppPath = rootAppPath
+	auto.AppName = appName
 
-	// Coleta URL do remote
-	remoteURL, err := getRemoteURL()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get remote URL: %w", err)
+	// Sem remote, as funcionalidades que dependem dele falham só quando usadas
+	if remoteURL, err := getRemoteURL(repo); err == nil {
+		auto.RemoteURL = remoteURL
 	}
-	auto.RemoteURL = remoteURL
 
 	return auto, nil
 }
 
-// getRepositoryName obtém o nome do repositório atual
-func getRepositoryName() (string, error) {
-	// Primeiro tenta pegar do remote
-	cmd := exec.Command("git", "remote", "get-url", "origin")
-	output, err := cmd.CombinedOutput()
-	if err == nil {
-		remoteURL := strings.TrimSpace(string(output))
+// contextRepository retorna o repositório do context (ou do diretório atual); nil fora do git
+func contextRepository(ctx context.Context) (gitrepo.Repository, error) {
+	repo, err := gitrepo.FromContext(ctx)
+	if errors.Is(err, gitrepo.ErrNotRepository) {
+		return nil, nil
+	}
+	return repo, err
+}
+
+// getRepositoryName obtém o nome do repositório: o do remote origin ou o da pasta raiz
+func getRepositoryName(repo gitrepo.Repository) (string, error) {
+	if repo == nil {
+		return "", gitrepo.ErrNotRepository
+	}
+
+	if remoteURL, err := gitrepo.RemoteURL(repo, "origin"); err == nil {
 		if name := extractRepoNameFromURL(remoteURL); name != "" {
 			return name, nil
 		}
 	}
+	return filepath.Base(repo.Root()), nil
+}
 
-	// Se não conseguir do remote, pega o nome da pasta
-	cmd = exec.Command("git", "rev-parse", "--show-toplevel")
-	output, err = cmd.CombinedOutput()
-	if err != nil {
-		return "", fmt.Errorf("failed to get git root: %v", err)
+// extractRepoNameFromURL extrai o nome do repositório de uma URL
+func extractRepoNameFromURL(url string) string {
+	// Remove .git no final se existir
+	url = strings.TrimSuffix(url, ".git")
+
+	// Divide por / e pega o último elemento
+	parts := strings.Split(url, "/")
+	if len(parts) > 0 {
+		return parts[len(parts)-1]
 	}
 
-	gitRoot := strings.TrimSpace(string(output))
-	return filepath.Base(gitRoot), nil
+	return ""
 }
 
-
 // getConfigDirPath obtém o caminho completo da pasta de configuração
-func getConfigDirPath(configFolderName string) (string, error) {
-	cmd := exec.Command("git", "rev-parse", "--show-toplevel")
-	output, err := cmd.CombinedOutput()
+func getConfigDirPath(ctx context.Context, configFolderName string) (string, error) {
+	repo, err := contextRepository(ctx)
 	if err != nil {
-		return "", fmt.Errorf("failed to get git root: %v", err)
+		return "", err
 	}
-
-	gitRoot := strings.TrimSpace(string(output))
-	return filepath.Join(gitRoot, configFolderName), nil
+	root, err := getRootPath(repo, configFolderName)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(root, configFolderName), nil
 }
 
-// getConfigDirPath obtém o caminho completo da pasta de configuração
-func getRootPath(configFolderName string) (string, error) {
-	cmd := exec.Command("git", "rev-parse", "--show-toplevel")
-	output, err := cmd.CombinedOutput()
+// getRootPath obtém a raiz do projeto: a raiz do repositório git ou, fora do git, o
+// diretório mais próximo que contém a pasta de configuração (o diretório atual se nenhum tiver)
+func getRootPath(repo gitrepo.Repository, configFolderName string) (string, error) {
+	if repo != nil {
+		return repo.Root(), nil
+	}
+
+	cwd, err := os.Getwd()
 	if err != nil {
-		return "", fmt.Errorf("failed to get git root: %v", err)
+		return "", fmt.Errorf("failed to get working directory: %w", err)
 	}
+	for dir := cwd; ; dir = filepath.Dir(dir) {
+		if info, err := os.Stat(filepath.Join(dir, configFolderName)); err == nil && info.IsDir() {
+			return dir, nil
+		}
+		if filepath.Dir(dir) == dir {
+			return cwd, nil
+		}
+	}
+}
 
-	gitRoot := strings.TrimSpace(string(output))
-	fullPath := filepath.Join(gitRoot, configFolderName)
-	return filepath.Dir(fullPath), nil
+// RemoteURL retorna a URL do remote origin: a de AutoConfig.RemoteURL quando a configuração
+// está no context, senão a do repositório do context. Sem remote, retorna o ValidationError
+// do requirement de FeatureRemote.
+func RemoteURL(ctx context.Context) (string, error) {
+	if cfg, ok := ctx.Value(configKey{}).(*Config); ok && cfg.Auto != nil && cfg.Auto.RemoteURL != "" {
+		return cfg.Auto.RemoteURL, nil
+	}
+
+	validator := NewRequirementsValidator("").WithContext(ctx)
+	if err := validator.Require(FeatureRemote); err != nil {
+		return "", err
+	}
+
+	repo, _ := validator.repo()
+	remoteURL, err := getRemoteURL(repo)
+	if err != nil {
+		return "", ValidationError{
+			Requirement: "Git Remote",
+			Message:     fmt.Sprintf("Remote 'origin' not found: %v", err),
+		}
+	}
+	return remoteURL, nil
 }
 
 // getRemoteURL obtém a URL do remote origin sem .git
-func getRemoteURL() (string, error) {
-	cmd := exec.Command("git", "remote", "get-url", "origin")
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return "", fmt.Errorf("failed to get remote URL: %v", err)
+func getRemoteURL(repo gitrepo.Repository) (string, error) {
+	if repo == nil {
+		return "", gitrepo.ErrNotRepository
 	}
 
-	remoteURL := strings.TrimSpace(string(output))
+	remoteURL, err := gitrepo.RemoteURL(repo, "origin")
+	if err != nil {
+		return "", fmt.Errorf("failed to get remote URL: %w", err)
+	}
 
 	// Remove .git no final se existir
-	remoteURL = strings.TrimSuffix(remoteURL, ".git")
-
-	return remoteURL, nil
+	return strings.TrimSuffix(remoteURL, ".git"), nil
 }
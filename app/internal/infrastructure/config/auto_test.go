@@ -1,11 +1,14 @@
 package config
 
 import (
+	"context"
+	"errors"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/PHRaulino/phengineer/internal/infrastructure/gitrepo"
 )
 
 // TestExtractRepoNameFromURL testa a extração do nome do repositório de URLs
@@ -89,36 +92,48 @@ func TestExtractRepoNameFromURL(t *testing.T) {
 
 // TestGetRepositoryName testa a obtenção do nome do repositório
 func TestGetRepositoryName(t *testing.T) {
-	// Verifica se estamos em um repositório Git antes de prosseguir
-	if !isInGitRepository() {
-		t.Skip("Skipping test: not in a Git repository")
-	}
-
-	name, err := getRepositoryName()
-	// Se o comando git falhar, pode ser ambiente de teste
-	if err != nil {
-		t.Logf("getRepositoryName failed (may be expected in test environment): %v", err)
-		return
-	}
-
-	// Verifica se retornou um nome válido
-	if name == "" {
-		t.Error("Repository name should not be empty")
+	tests := []struct {
+		name     string
+		repo     gitrepo.Repository
+		expected string
+		wantErr  bool
+	}{
+		{
+			name:     "Name from origin",
+			repo:     gitrepo.NewMemoryRepository("/work/checkout").WithRemote("origin", "git@github.com:user/my-repo.git"),
+			expected: "my-repo",
+		},
+		{
+			name:    "Outside Git",
+			wantErr: true,
+		},
 	}
 
-	// Verifica se o nome não contém caracteres inválidos
-	if strings.Contains(name, "/") || strings.Contains(name, "\\") {
-		t.Errorf("Repository name should not contain path separators: %s", name)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, err := getRepositoryName(tt.repo)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("getRepositoryName() error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if name != tt.expected {
+				t.Errorf("getRepositoryName() = %q, expected %q", name, tt.expected)
+			}
+		})
 	}
-
-	t.Logf("Repository name: %s", name)
 }
 
 // TestGetRepositoryNameFallback testa o fallback para nome da pasta
 func TestGetRepositoryNameFallback(t *testing.T) {
-	// Este teste simula quando o remote falha mas git root funciona
-	// Difícil de testar sem mockar os comandos git
-	t.Skip("Skipping fallback test - requires mocking git commands")
+	// Sem origin (só outro remote) o nome vem da raiz do repositório
+	repo := gitrepo.NewMemoryRepository(filepath.Join("work", "checkout")).WithRemote("upstream", "https://github.com/org/other.git")
+
+	name, err := getRepositoryName(repo)
+	if err != nil {
+		t.Fatalf("getRepositoryName() error = %v", err)
+	}
+	if name != "checkout" {
+		t.Errorf("getRepositoryName() = %q, expected %q", name, "checkout")
+	}
 }
 
 // TestGetConfigDirPath testa a obtenção do caminho da pasta de config
@@ -128,7 +143,7 @@ func TestGetConfigDirPath(t *testing.T) {
 	}
 
 	configFolderName := "test-config"
-	path, err := getConfigDirPath(configFolderName)
+	path, err := getConfigDirPath(context.Background(), configFolderName)
 	if err != nil {
 		t.Logf("getConfigDirPath failed (may be expected in test environment): %v", err)
 		return
@@ -147,79 +162,131 @@ func TestGetConfigDirPath(t *testing.T) {
 	t.Logf("Config dir path: %s", path)
 }
 
-// TestGetRemoteURL testa a obtenção da URL do remote
-func TestGetRemoteURL(t *testing.T) {
-	if !isInGitRepository() {
-		t.Skip("Skipping test: not in a Git repository")
+// TestRepositoryFromContext testa que o repositório injetado no context é usado no lugar do diretório atual
+func TestRepositoryFromContext(t *testing.T) {
+	root := t.TempDir()
+	repo := gitrepo.NewMemoryRepository(root).WithRemote("origin", "https://github.com/org/app.git")
+	ctx := gitrepo.WithRepository(context.Background(), repo)
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	path, err := ProjectSettingsPath(ctx, "config")
+	if want := filepath.Join(root, "config", "settings.yml"); err != nil || path != want {
+		t.Errorf("ProjectSettingsPath() = %q, %v, want %q", path, err, want)
 	}
 
-	// Verifica se há remotes configurados
-	if !hasGitRemotes() {
-		t.Skip("Skipping test: no Git remotes configured")
+	if url, err := RemoteURL(ctx); err != nil || url != "https://github.com/org/app" {
+		t.Errorf("RemoteURL() = %q, %v", url, err)
 	}
 
-	url, err := getRemoteURL()
-	if err != nil {
-		t.Logf("getRemoteURL failed (may be expected in test environment): %v", err)
-		return
+	d := Diagnose(ctx, "config")
+	if d.Auto == nil || d.Auto.RootAppPath != root || d.Auto.AppName != "app" {
+		t.Errorf("Diagnose().Auto = %+v, want the context repository", d.Auto)
+	}
+
+	validator := NewRequirementsValidator("config").WithContext(ctx)
+	if gitRoot, err := validator.GetGitRoot(); err != nil || gitRoot != root {
+		t.Errorf("GetGitRoot() = %q, %v, want %q", gitRoot, err, root)
 	}
+}
 
-	// Verifica se a URL não está vazia
-	if url == "" {
-		t.Error("Remote URL should not be empty")
+// TestRemoteURLRequirement testa que a falta do remote é reportada como o requirement de FeatureRemote
+func TestRemoteURLRequirement(t *testing.T) {
+	tests := []struct {
+		name string
+		repo gitrepo.Repository
+	}{
+		{
+			name: "Without remotes",
+			repo: gitrepo.NewMemoryRepository("/repo"),
+		},
+		{
+			name: "Without origin",
+			repo: gitrepo.NewMemoryRepository("/repo").WithRemote("upstream", "https://github.com/user/repo.git"),
+		},
 	}
 
-	// Verifica se não termina com .git
-	if strings.HasSuffix(url, ".git") {
-		t.Errorf("Remote URL should not end with .git: %s", url)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := RemoteURL(gitrepo.WithRepository(context.Background(), tt.repo))
+			var validationErr ValidationError
+			if !errors.As(err, &validationErr) || validationErr.Requirement != "Git Remote" {
+				t.Errorf("RemoteURL() error = %v, want the Git Remote requirement", err)
+			}
+		})
 	}
+}
 
-	// Verifica se parece com uma URL válida
-	if !strings.Contains(url, "://") && !strings.Contains(url, "@") {
-		t.Errorf("Remote URL should look like a valid URL: %s", url)
+// TestGetRemoteURL testa a obtenção da URL do remote
+func TestGetRemoteURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		repo     gitrepo.Repository
+		expected string
+		wantErr  bool
+	}{
+		{
+			name:     "HTTPS with .git",
+			repo:     gitrepo.NewMemoryRepository("/repo").WithRemote("origin", "https://github.com/user/repo.git"),
+			expected: "https://github.com/user/repo",
+		},
+		{
+			name:     "SSH",
+			repo:     gitrepo.NewMemoryRepository("/repo").WithRemote("origin", "git@github.com:user/repo.git"),
+			expected: "git@github.com:user/repo",
+		},
+		{
+			name:    "Without origin",
+			repo:    gitrepo.NewMemoryRepository("/repo").WithRemote("upstream", "https://github.com/user/repo.git"),
+			wantErr: true,
+		},
+		{
+			name:    "Outside Git",
+			wantErr: true,
+		},
 	}
 
-	t.Logf("Remote URL: %s", url)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url, err := getRemoteURL(tt.repo)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("getRemoteURL() error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if url != tt.expected {
+				t.Errorf("getRemoteURL() = %q, expected %q", url, tt.expected)
+			}
+		})
+	}
 }
 
 // TestCollectAutoConfig testa a coleta completa de configurações automáticas
 func TestCollectAutoConfig(t *testing.T) {
-	if !isInGitRepository() {
-		t.Skip("Skipping test: not in a Git repository")
-	}
+	root := filepath.Join(t.TempDir(), "checkout")
+	repo := gitrepo.NewMemoryRepository(root).WithRemote("origin", "https://github.com/org/my-app.git")
 
 	configFolderName := "test-config"
-	autoConfig, err := collectAutoConfig(configFolderName)
+	autoConfig, err := collectAutoConfig(repo, configFolderName)
 	if err != nil {
-		t.Logf("collectAutoConfig failed (may be expected in test environment): %v", err)
-		return
-	}
-
-	// Verifica se todas as configurações foram coletadas
-	if autoConfig.AppName == "" {
-		t.Error("AppName should not be empty")
+		t.Fatalf("collectAutoConfig() error = %v", err)
 	}
 
-	if autoConfig.ConfigDirPath == "" {
-		t.Error("ConfigDirPath should not be empty")
+	expected := &AutoConfig{
+		AppName:       "my-app",
+		RootAppPath:   root,
+		ConfigDirPath: filepath.Join(root, configFolderName),
+		RemoteURL:     "https://github.com/org/my-app",
 	}
-
-	if autoConfig.RemoteURL == "" {
-		t.Error("RemoteURL should not be empty")
+	if *autoConfig != *expected {
+		t.Errorf("collectAutoConfig() = %+v, expected %+v", autoConfig, expected)
 	}
 
-	// Verifica se ConfigDirPath termina com o nome da pasta
-	if !strings.HasSuffix(autoConfig.ConfigDirPath, configFolderName) {
-		t.Errorf("ConfigDirPath should end with '%s': %s", configFolderName, autoConfig.ConfigDirPath)
+	// Sem remote configurado a URL fica vazia e o nome vem da pasta
+	autoConfig, err = collectAutoConfig(gitrepo.NewMemoryRepository(root), configFolderName)
+	if err != nil {
+		t.Fatalf("collectAutoConfig() without remote error = %v", err)
 	}
-
-	// Verifica se RemoteURL não termina com .git
-	if strings.HasSuffix(autoConfig.RemoteURL, ".git") {
-		t.Errorf("RemoteURL should not end with .git: %s", autoConfig.RemoteURL)
+	if autoConfig.RemoteURL != "" || autoConfig.AppName != "checkout" {
+		t.Errorf("collectAutoConfig() without remote = %+v", autoConfig)
 	}
-
-	t.Logf("Auto Config - App: %s, Config: %s, Remote: %s",
-		autoConfig.AppName, autoConfig.ConfigDirPath, autoConfig.RemoteURL)
 }
 
 // TestCollectAutoConfigInvalidEnvironment testa comportamento em ambiente inválido
@@ -238,13 +305,37 @@ func TestCollectAutoConfigInvalidEnvironment(t *testing.T) {
 	}
 	defer os.Chdir(originalDir)
 
-	// Deve falhar pois não está em um repositório Git
-	_, err = collectAutoConfig("config")
-	if err == nil {
-		t.Error("Expected collectAutoConfig to fail outside Git repository")
+	// Fora do git usa o diretório atual, sem remote
+	autoConfig, err := collectAutoConfig(nil, "config")
+	if err != nil {
+		t.Fatalf("Expected collectAutoConfig to work outside Git repository, got: %v", err)
+	}
+	if autoConfig.RemoteURL != "" {
+		t.Errorf("Expected empty RemoteURL outside Git, got %q", autoConfig.RemoteURL)
+	}
+	if autoConfig.AppName != filepath.Base(tempDir) {
+		t.Errorf("Expected AppName %q, got %q", filepath.Base(tempDir), autoConfig.AppName)
 	}
 
-	t.Logf("Expected error in non-Git environment: %v", err)
+	// A pasta de configuração de um diretório acima define a raiz do projeto
+	if err := os.Mkdir(filepath.Join(tempDir, "config"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	subDir := filepath.Join(tempDir, "src", "pkg")
+	if err := os.MkdirAll(subDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(subDir); err != nil {
+		t.Fatal(err)
+	}
+	configDir, err := getConfigDirPath(context.Background(), "config")
+	if err != nil {
+		t.Fatalf("getConfigDirPath failed: %v", err)
+	}
+	want, _ := filepath.EvalSymlinks(filepath.Join(tempDir, "config"))
+	if got, _ := filepath.EvalSymlinks(configDir); got != want {
+		t.Errorf("Expected config dir %q, got %q", want, configDir)
+	}
 }
 
 // TestAutoConfigWithDifferentFolderNames testa com diferentes nomes de pasta
@@ -257,7 +348,7 @@ func TestAutoConfigWithDifferentFolderNames(t *testing.T) {
 
 	for _, folderName := range folderNames {
 		t.Run(folderName, func(t *testing.T) {
-			path, err := getConfigDirPath(folderName)
+			path, err := getConfigDirPath(context.Background(), folderName)
 			if err != nil {
 				t.Logf("getConfigDirPath failed for '%s': %v", folderName, err)
 				return
@@ -274,22 +365,8 @@ func TestAutoConfigWithDifferentFolderNames(t *testing.T) {
 
 // isInGitRepository verifica se estamos em um repositório Git
 func isInGitRepository() bool {
-	cmd := exec.Command("git", "rev-parse", "--is-inside-work-tree")
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return false
-	}
-	return strings.TrimSpace(string(output)) == "true"
-}
-
-// hasGitRemotes verifica se há remotes configurados
-func hasGitRemotes() bool {
-	cmd := exec.Command("git", "remote")
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return false
-	}
-	return strings.TrimSpace(string(output)) != ""
+	_, err := gitrepo.Current()
+	return err == nil
 }
 
 // Benchmarks
@@ -310,9 +387,11 @@ func BenchmarkGetRepositoryName(b *testing.B) {
 		b.Skip("Skipping benchmark: not in a Git repository")
 	}
 
+	repo, _ := gitrepo.Current()
+
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		getRepositoryName()
+		getRepositoryName(repo)
 	}
 }
 
@@ -324,7 +403,7 @@ func BenchmarkGetConfigDirPath(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		getConfigDirPath("config")
+		getConfigDirPath(context.Background(), "config")
 	}
 }
 
@@ -334,9 +413,11 @@ func BenchmarkCollectAutoConfig(b *testing.B) {
 		b.Skip("Skipping benchmark: not in a Git repository")
 	}
 
+	repo, _ := gitrepo.Current()
+
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		collectAutoConfig("config")
+		collectAutoConfig(repo, "config")
 	}
 }
 
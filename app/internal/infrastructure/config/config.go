@@ -1,35 +1,47 @@
 package config
 
 import (
-	"strings"
+	"context"
+	"sync"
 
 	"github.com/spf13/viper"
 )
 
-func InitConfig() error {
-	slug := "phengineer"
-
-	repoName, _, rootPath := detectGitInfo()
-
-	viper.SetConfigName(slug)
-	viper.SetConfigType("yaml")
-	viper.AddConfigPath(".")
-
-	// Defaults
-	viper.SetDefault("project.type", "lambda")
-	viper.SetDefault("project.repo_name", repoName)
-	viper.SetDefault("project.root_path", rootPath)
+var (
+	flagsMu       sync.RWMutex
+	flagOverrides map[string]string
+)
 
-	viper.SetDefault("project.language.name", "python")
-	viper.SetDefault("project.language.version", "3.13")
+// SetFlagOverrides registra os valores passados na linha de comando (--config-set chave=valor),
+// a camada de maior precedência em todas as leituras da configuração
+func SetFlagOverrides(flags map[string]string) {
+	flagsMu.Lock()
+	defer flagsMu.Unlock()
+	flagOverrides = flags
+}
 
-	viper.SetDefault("analysis.file_limits.max_file_size", "10MB")
-	viper.SetDefault("analysis.file_limits.max_files", 100)
+// LoadLayered resolve a configuração do repositório do context com todas as camadas
+func LoadLayered(ctx context.Context, configFolderName string) (*LayeredSettings, error) {
+	return NewLoader(ctx, configFolderName).WithFlags(currentFlags()).Load()
+}
 
-	// Env vars
-	viper.SetEnvPrefix(strings.ToUpper(slug))
-	viper.AutomaticEnv()
-	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+func currentFlags() map[string]string {
+	flagsMu.RLock()
+	defer flagsMu.RUnlock()
+	return flagOverrides
+}
 
-	return viper.ReadInConfig()
+// InitConfig registra as flags, resolve as camadas e publica as chaves no viper,
+// onde são lidas auth.mode e auth.token_refresh_skew
+func InitConfig(ctx context.Context, configFolderName string, flags map[string]string) (*LayeredSettings, error) {
+	SetFlagOverrides(flags)
+
+	layered, err := LoadLayered(ctx, configFolderName)
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range layered.Keys() {
+		viper.Set(key, layered.values[key])
+	}
+	return layered, nil
 }
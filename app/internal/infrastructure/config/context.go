@@ -2,7 +2,11 @@ package config
 
 import (
 	"context"
+	"errors"
+	"os"
+	"path/filepath"
 
+	"github.com/PHRaulino/phengineer/internal/infrastructure/gitrepo"
 	"go.uber.org/zap"
 )
 
@@ -11,13 +15,39 @@ type configKey struct{}
 
 // WithConfig adiciona a configuração ao context com saída detalhada
 func WithConfig(ctx context.Context, configFolderName string) (context.Context, error) {
-	// Valida requirements com detalhes
-	validator := NewRequirementsValidator(configFolderName)
-	results, err := validator.ValidateWithDetails()
-	// Mostra o status de cada requirement
+	// Abre o repositório uma única vez; ele segue no context para a descoberta
+	repo, err := gitrepo.FromContext(ctx)
+	if err != nil && !errors.Is(err, gitrepo.ErrNotRepository) {
+		zap.L().Error("failed to open git repository", zap.Error(err))
+		return nil, err
+	}
+	if repo != nil {
+		ctx = gitrepo.WithRepository(ctx, repo)
+	}
+
+	// Coleta configurações automáticas
+	autoConfig, err := collectAutoConfig(repo, configFolderName)
 	if err != nil {
+		zap.L().Error("failed to collect auto config", zap.Error(err))
+		return nil, err
+	}
+
+	// Cria o settings.yml padrão (e a pasta de configuração) na primeira execução
+	settingsPath := filepath.Join(autoConfig.ConfigDirPath, "settings.yml")
+	if _, err := os.Stat(settingsPath); os.IsNotExist(err) {
+		if _, err := LoadOrCreateSettings(autoConfig.ConfigDirPath, configFolderName); err != nil {
+			zap.L().Error("failed to create settings", zap.Error(err))
+			return nil, err
+		}
+	}
+
+	// Só a descoberta é obrigatória: sem git ou sem remote, as funcionalidades que
+	// dependem deles ficam indisponíveis e falham apenas quando usadas
+	validator := NewRequirementsValidator(configFolderName).WithRepository(repo)
+	if err := validator.Require(FeatureDiscovery); err != nil {
 		zap.L().Error("requirements validation failed", zap.Error(err))
 
+		results, _ := validator.ValidateWithDetails()
 		for _, result := range results {
 			status := "OK"
 			if !result.Passed {
@@ -32,16 +62,21 @@ func WithConfig(ctx context.Context, configFolderName string) (context.Context,
 		return nil, err
 	}
 
-	// Coleta configurações automáticas
-	autoConfig, err := collectAutoConfig(configFolderName)
+	// Atualiza layouts antigos no próprio arquivo, com backup
+	migration, _, err := MigrateSettingsFile(settingsPath, configFolderName, false)
 	if err != nil {
-		zap.L().Error("failed to collect auto config", zap.Error(err))
+		zap.L().Error("failed to migrate settings", zap.Error(err))
 		return nil, err
 	}
+	if !migration.Changed() {
+		migration = nil
+	}
 
-	// Carrega configurações do settings.yml
-
-	settings, err := LoadOrCreateSettings(autoConfig.ConfigDirPath, configFolderName)
+	// Resolve as camadas: padrões, usuário, settings.yml, PHENGINEER_* e flags
+	layered, err := NewLoader(ctx, configFolderName).
+		WithRepoFile(settingsPath).
+		WithFlags(currentFlags()).
+		Load()
 	if err != nil {
 		zap.L().Error("failed to load settings", zap.Error(err))
 		return nil, err
@@ -49,9 +84,11 @@ func WithConfig(ctx context.Context, configFolderName string) (context.Context,
 
 	// Cria a configuração completa
 	config := &Config{
-		Settings:   settings,
+		Settings:   layered.Settings,
+		Origins:    layered.Origins,
 		Auto:       autoConfig,
 		ConfigPath: autoConfig.ConfigDirPath,
+		Migration:  migration,
 	}
 
 	return context.WithValue(ctx, configKey{}, config), nil
@@ -75,21 +112,3 @@ func GetSettings(ctx context.Context) *Settings {
 func GetAutoConfig(ctx context.Context) *AutoConfig {
 	return FromContext(ctx).Auto
 }
-
-// PrintDiagnostics imprime informações de diagnóstico da configuração
-func PrintDiagnostics(ctx context.Context) {
-	config := FromContext(ctx)
-
-	zap.L().Debug("config", zap.String("key", "app_name"), zap.String("value", config.Auto.AppName))
-	zap.L().Debug("config", zap.String("key", "config_path"), zap.String("value", config.ConfigPath))
-	zap.L().Debug("config", zap.String("key", "remote_url"), zap.String("value", config.Auto.RemoteURL))
-	zap.L().Debug("config", zap.String("key", "root_app_path"), zap.String("value", config.Auto.RootAppPath))
-
-	zap.L().Debug("config", zap.String("key", "project_type"), zap.String("value", config.Settings.Project.Type))
-	zap.L().Debug("config", zap.String("key", "language"), zap.String("value", config.Settings.Project.Language.Name))
-	zap.L().Debug("config", zap.String("key", "language_version"), zap.String("value", config.Settings.Project.Language.Version))
-
-	zap.L().Debug("config", zap.String("key", "analyse_files"), zap.String("value", config.Settings.Analysis.AnalysisFilesPath))
-	zap.L().Debug("config", zap.String("key", "max_file_size"), zap.String("value", config.Settings.Analysis.FileLimits.MaxFileSize))
-	zap.L().Debug("config", zap.String("key", "max_files"), zap.Int64("value", config.Settings.Analysis.FileLimits.MaxFiles))
-}
@@ -16,12 +16,45 @@ func TestWithConfig(t *testing.T) {
 	}
 	defer os.RemoveAll(tempDir)
 
-	// Pode falhar por não ter git real, mas testa a estrutura
-	ctx := context.Background()
-	_, err = WithConfig(ctx, "config")
-	// O teste pode falhar por requirements, mas não deve panic
+	// Fora do git a configuração usa o diretório atual como raiz do projeto
+	originalDir, _ := os.Getwd()
+	if err := os.Chdir(tempDir); err != nil {
+		t.Fatalf("Failed to change directory: %v", err)
+	}
+	defer os.Chdir(originalDir)
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	ctx, err := WithConfig(context.Background(), "config")
 	if err != nil {
-		t.Logf("WithConfig failed (expected in test environment): %v", err)
+		t.Fatalf("WithConfig failed outside git: %v", err)
+	}
+
+	cfg := FromContext(ctx)
+	root, _ := filepath.EvalSymlinks(tempDir)
+	if got, _ := filepath.EvalSymlinks(cfg.Auto.RootAppPath); got != root {
+		t.Errorf("RootAppPath = %q, want %q", cfg.Auto.RootAppPath, tempDir)
+	}
+	if cfg.Auto.RemoteURL != "" || cfg.Auto.AppName != filepath.Base(root) {
+		t.Errorf("Auto = %+v, want folder name and no remote", cfg.Auto)
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, "config", "settings.yml")); err != nil {
+		t.Errorf("Expected default settings.yml to be created: %v", err)
+	}
+	if cfg.Migration != nil {
+		t.Errorf("Migration = %+v, want nil for a new settings.yml", cfg.Migration)
+	}
+
+	// Um layout antigo é migrado e o resultado fica na configuração para a CLI avisar
+	legacy := "project:\n  type: cli\n  language: go\n"
+	if err := os.WriteFile(filepath.Join(tempDir, "config", "settings.yml"), []byte(legacy), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ctx, err = WithConfig(context.Background(), "config")
+	if err != nil {
+		t.Fatalf("WithConfig failed with legacy settings: %v", err)
+	}
+	if migration := FromContext(ctx).Migration; migration == nil || migration.FromVersion != 0 || migration.BackupPath == "" {
+		t.Errorf("Migration = %+v, want migration from version 0 with backup", migration)
 	}
 }
 
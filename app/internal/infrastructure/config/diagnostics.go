@@ -0,0 +1,91 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Diagnostics situação dos arquivos e do repositório usados para montar a configuração
+type Diagnostics struct {
+	SettingsPath    string
+	SettingsFound   bool
+	SettingsVersion int // versão do formato gravada no settings.yml
+	SchemaLinked    bool
+	UserConfigPath  string
+	UserConfigFound bool
+	Auto            *AutoConfig // nil se a raiz do projeto não puder ser determinada
+	Warnings        []string
+}
+
+// Diagnose coleta o diagnóstico da configuração do repositório do context sem falhar:
+// o que não puder ser verificado vira um aviso
+func Diagnose(ctx context.Context, configFolderName string) *Diagnostics {
+	d := &Diagnostics{}
+
+	repo, err := contextRepository(ctx)
+	if err != nil {
+		d.Warnings = append(d.Warnings, fmt.Sprintf("failed to open git repository: %v", err))
+	}
+	auto, err := collectAutoConfig(repo, configFolderName)
+	if err != nil {
+		d.Warnings = append(d.Warnings, fmt.Sprintf("repository info unavailable: %v", err))
+	} else {
+		d.Auto = auto
+		d.SettingsPath = filepath.Join(auto.ConfigDirPath, "settings.yml")
+	}
+
+	if d.SettingsPath != "" {
+		data, err := os.ReadFile(d.SettingsPath)
+		switch {
+		case err == nil:
+			d.SettingsFound = true
+			d.SettingsVersion = settingsVersion(data)
+			d.SchemaLinked = bytes.Contains(data, []byte(schemaModeline))
+			if d.SettingsVersion < CurrentSettingsVersion {
+				d.Warnings = append(d.Warnings, fmt.Sprintf(
+					"settings.yml is at version %d, run 'phengineer config migrate' to update it to %d",
+					d.SettingsVersion, CurrentSettingsVersion))
+			}
+		case os.IsNotExist(err):
+			d.Warnings = append(d.Warnings, "settings.yml not found, defaults are in use until the first analysis creates it")
+		default:
+			d.Warnings = append(d.Warnings, fmt.Sprintf("failed to read settings file: %v", err))
+		}
+	}
+
+	if dir, err := UserConfigDir(); err == nil {
+		d.UserConfigPath = filepath.Join(dir, UserConfigFileName)
+		_, err := os.Stat(d.UserConfigPath)
+		d.UserConfigFound = err == nil
+	}
+
+	return d
+}
+
+// settingsVersion lê o campo version sem validar o restante do arquivo
+func settingsVersion(data []byte) int {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return 0
+	}
+	root := documentRoot(&doc)
+	if root == nil {
+		// Arquivo vazio não tem o que migrar
+		return CurrentSettingsVersion
+	}
+	if root.Kind != yaml.MappingNode {
+		return 0
+	}
+	node := mappingValue(root, "version")
+	if node == nil {
+		return 0
+	}
+	version, _ := strconv.Atoi(node.Value)
+	return version
+}
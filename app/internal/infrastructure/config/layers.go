@@ -0,0 +1,411 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Camadas da configuração, da menor para a maior precedência
+const (
+	LayerDefault = "default"
+	LayerUser    = "user"
+	LayerRepo    = "repo"
+	LayerEnv     = "env"
+	LayerFlag    = "flag"
+)
+
+// EnvPrefix prefixo das variáveis de ambiente que sobrescrevem chaves (ex.: PHENGINEER_AUTH_MODE)
+const EnvPrefix = "PHENGINEER_"
+
+// Modos de autenticação aceitos em auth.mode
+const (
+	AuthModeUser    = "stackspot_user"
+	AuthModeService = "stackspot_service"
+)
+
+// builtinDefaults padrões que não são gravados no settings.yml criado pela CLI
+var builtinDefaults = map[string]any{
+	"auth.mode": AuthModeUser,
+}
+
+// Origin indica a camada que definiu uma chave e de onde veio o valor
+type Origin struct {
+	Layer  string
+	Source string // arquivo, variável de ambiente ou flag; vazio nos padrões
+	Line   int    // linha da chave no arquivo, nas camadas user e repo
+}
+
+func (o Origin) String() string {
+	if o.Source == "" {
+		return o.Layer
+	}
+	if o.Line > 0 {
+		return fmt.Sprintf("%s: %s:%d", o.Layer, o.Source, o.Line)
+	}
+	return o.Layer + ": " + o.Source
+}
+
+// LayeredSettings configuração resolvida com a origem de cada chave
+type LayeredSettings struct {
+	Settings *Settings
+	Origins  map[string]Origin // chave (ex.: project.type) → camada que a definiu
+	values   map[string]any
+}
+
+// Keys retorna as chaves definidas em alguma camada, em ordem alfabética
+func (l *LayeredSettings) Keys() []string {
+	keys := make([]string, 0, len(l.values))
+	for key := range l.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Value retorna o valor resolvido de uma chave
+func (l *LayeredSettings) Value(key string) (any, bool) {
+	value, ok := l.values[key]
+	return value, ok
+}
+
+// FormatValue formata o valor de uma chave para exibição
+func (l *LayeredSettings) FormatValue(key string) string {
+	switch value := l.values[key].(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case []any, map[string]any:
+		data, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Sprint(value)
+		}
+		return string(data)
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+// originOf retorna a origem de uma chave; itens de listas (knowledge.sources[0].name)
+// usam a origem da lista inteira
+func (l *LayeredSettings) originOf(path string) (Origin, bool) {
+	if origin, ok := l.Origins[path]; ok {
+		return origin, true
+	}
+	if list, _, ok := strings.Cut(path, "["); ok {
+		origin, ok := l.Origins[list]
+		return origin, ok
+	}
+	return Origin{}, false
+}
+
+// Loader resolve a configuração em camadas: padrões, arquivo do usuário,
+// settings.yml do repositório, variáveis PHENGINEER_* e flags da CLI
+type Loader struct {
+	configFolderName string
+	userFile         string
+	repoFile         string
+	environ          []string
+	flags            map[string]string
+}
+
+// NewLoader cria o loader com os arquivos do usuário e do repositório do context e o ambiente do processo
+func NewLoader(ctx context.Context, configFolderName string) *Loader {
+	loader := newLoader(configFolderName)
+	if path, err := ProjectSettingsPath(ctx, configFolderName); err == nil {
+		loader.repoFile = path
+	}
+	return loader
+}
+
+// newLoader cria o loader com o arquivo do usuário e o ambiente do processo, sem settings.yml
+func newLoader(configFolderName string) *Loader {
+	loader := &Loader{configFolderName: configFolderName, environ: os.Environ()}
+	if dir, err := UserConfigDir(); err == nil {
+		loader.userFile = filepath.Join(dir, UserConfigFileName)
+	}
+	return loader
+}
+
+// WithUserFile substitui o arquivo de configuração do usuário
+func (l *Loader) WithUserFile(path string) *Loader {
+	l.userFile = path
+	return l
+}
+
+// WithRepoFile substitui o settings.yml do repositório
+func (l *Loader) WithRepoFile(path string) *Loader {
+	l.repoFile = path
+	return l
+}
+
+// WithEnv substitui as variáveis de ambiente (formato KEY=VALUE)
+func (l *Loader) WithEnv(environ []string) *Loader {
+	l.environ = environ
+	return l
+}
+
+// WithFlags define os valores passados na linha de comando (chave → valor)
+func (l *Loader) WithFlags(flags map[string]string) *Loader {
+	l.flags = flags
+	return l
+}
+
+// Load aplica as camadas em ordem e valida o resultado
+func (l *Loader) Load() (*LayeredSettings, error) {
+	fields := settingsFields()
+	layered := &LayeredSettings{
+		Origins: make(map[string]Origin),
+		values:  make(map[string]any),
+	}
+	tree := make(map[string]any)
+
+	apply := func(origin Origin, values map[string]any, lines map[string]int) {
+		for key, value := range values {
+			// Chaves vazias (ex.: seção só com comentários) não sobrescrevem as camadas anteriores
+			if value == nil {
+				continue
+			}
+			setTreeValue(tree, strings.Split(key, "."), value)
+			layered.values[key] = value
+			origin.Line = lines[key]
+			layered.Origins[key] = origin
+		}
+	}
+
+	defaults, err := toMap(GetDefaultSettings(l.configFolderName))
+	if err != nil {
+		return nil, err
+	}
+	apply(Origin{Layer: LayerDefault}, flatten(defaults), nil)
+	apply(Origin{Layer: LayerDefault}, builtinDefaults, nil)
+
+	if l.userFile != "" {
+		user, lines, err := readSettingsMap(l.userFile, l.configFolderName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse user config: %w", err)
+		}
+		// As fontes de conhecimento do usuário formam um registro próprio (LoadUserKnowledge),
+		// somado ao do repositório em vez de substituí-lo
+		delete(user, "knowledge")
+		apply(Origin{Layer: LayerUser, Source: l.userFile}, flatten(user), lines)
+	}
+
+	if l.repoFile != "" {
+		repo, lines, err := readSettingsMap(l.repoFile, l.configFolderName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse settings YAML: %w", err)
+		}
+		apply(Origin{Layer: LayerRepo, Source: l.repoFile}, flatten(repo), lines)
+	}
+
+	env := make(map[string]string)
+	for _, entry := range l.environ {
+		if name, value, ok := strings.Cut(entry, "="); ok && strings.HasPrefix(name, EnvPrefix) {
+			env[name] = value
+		}
+	}
+	for _, key := range sortedKeys(fields) {
+		name := EnvName(key)
+		raw := env[name]
+		if raw == "" {
+			continue
+		}
+		value, err := parseValue(fields[key], raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value in %s: %w", name, err)
+		}
+		apply(Origin{Layer: LayerEnv, Source: name}, map[string]any{key: value}, nil)
+	}
+
+	for _, key := range sortedKeys(l.flags) {
+		fieldType, ok := fields[key]
+		if !ok {
+			return nil, fmt.Errorf("unknown configuration key '%s'", key)
+		}
+		value, err := parseValue(fieldType, l.flags[key])
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for --config-set %s: %w", key, err)
+		}
+		apply(Origin{Layer: LayerFlag, Source: "--config-set " + key}, map[string]any{key: value}, nil)
+	}
+
+	data, err := yaml.Marshal(tree)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal settings to YAML: %w", err)
+	}
+	var settings Settings
+	if err := yaml.Unmarshal(data, &settings); err != nil {
+		return nil, fmt.Errorf("failed to parse settings YAML: %w", err)
+	}
+	if err := settings.Validate(); err != nil {
+		// Cada problema indica a camada (e a linha) de onde veio o valor
+		var settingsErr *SettingsError
+		if errors.As(err, &settingsErr) {
+			for i, problem := range settingsErr.Problems {
+				if origin, ok := layered.originOf(problem.Path); ok {
+					settingsErr.Problems[i].Origin = origin.String()
+				}
+			}
+		}
+		return nil, fmt.Errorf("settings validation failed: %w", err)
+	}
+
+	layered.Settings = &settings
+	return layered, nil
+}
+
+// EnvName retorna a variável de ambiente que sobrescreve a chave (auth.vault.address → PHENGINEER_AUTH_VAULT_ADDRESS)
+func EnvName(key string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// readSettingsMap lê um arquivo de configuração como mapa, já migrado para a versão atual
+// e sem chaves desconhecidas, com a linha de cada chave; arquivo ausente ou vazio resulta em mapa vazio
+func readSettingsMap(path, configFolderName string) (map[string]any, map[string]int, error) {
+	values := make(map[string]any)
+	lines := make(map[string]int)
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return values, lines, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	doc, _, err := parseSettingsDocument(data, path, configFolderName)
+	if err != nil {
+		return nil, nil, err
+	}
+	root := documentRoot(doc)
+	if root == nil {
+		return values, lines, nil
+	}
+	if err := doc.Decode(&values); err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", path, err)
+	}
+	keyLines(root, "", lines)
+	return values, lines, nil
+}
+
+// keyLines registra a linha de cada chave folha do YAML, no mesmo formato de flatten
+func keyLines(node *yaml.Node, prefix string, lines map[string]int) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		if value.Kind == yaml.MappingNode {
+			keyLines(value, prefix+key.Value+".", lines)
+			continue
+		}
+		lines[prefix+key.Value] = key.Line
+	}
+}
+
+// toMap converte uma struct em mapa pelo YAML, respeitando as tags
+func toMap(v any) (map[string]any, error) {
+	data, err := yaml.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal settings to YAML: %w", err)
+	}
+	values := make(map[string]any)
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("failed to parse settings YAML: %w", err)
+	}
+	return values, nil
+}
+
+// flatten converte mapas aninhados em chaves separadas por ponto; listas são valores únicos
+// e mapas vazios não geram chaves
+func flatten(values map[string]any) map[string]any {
+	flat := make(map[string]any)
+	var walk func(prefix string, values map[string]any)
+	walk = func(prefix string, values map[string]any) {
+		for key, value := range values {
+			if nested, ok := value.(map[string]any); ok {
+				walk(prefix+key+".", nested)
+				continue
+			}
+			flat[prefix+key] = value
+		}
+	}
+	walk("", values)
+	return flat
+}
+
+// setTreeValue grava o valor no mapa aninhado, criando os níveis que faltarem
+func setTreeValue(tree map[string]any, path []string, value any) {
+	for _, key := range path[:len(path)-1] {
+		next, ok := tree[key].(map[string]any)
+		if !ok {
+			next = make(map[string]any)
+			tree[key] = next
+		}
+		tree = next
+	}
+	tree[path[len(path)-1]] = value
+}
+
+// SettingsKeys retorna as chaves aceitas no settings.yml (ex.: http.timeout_seconds), em ordem alfabética
+func SettingsKeys() []string {
+	return sortedKeys(settingsFields())
+}
+
+// settingsFields lista as chaves de Settings com o tipo de cada uma, a partir das tags yaml
+func settingsFields() map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+	var walk func(prefix string, t reflect.Type)
+	walk = func(prefix string, t reflect.Type) {
+		for name, fieldType := range yamlFields(t) {
+			if fieldType.Kind() == reflect.Struct {
+				walk(prefix+name+".", fieldType)
+				continue
+			}
+			fields[prefix+name] = fieldType
+		}
+	}
+	walk("", reflect.TypeOf(Settings{}))
+	return fields
+}
+
+// parseValue converte o texto de uma variável ou flag para o tipo da chave
+func parseValue(t reflect.Type, raw string) (any, error) {
+	switch t.Kind() {
+	case reflect.String:
+		return raw, nil
+	case reflect.Int, reflect.Int64:
+		return strconv.ParseInt(strings.TrimSpace(raw), 10, 64)
+	case reflect.Bool:
+		return strconv.ParseBool(strings.TrimSpace(raw))
+	case reflect.Slice:
+		if t.Elem().Kind() != reflect.String {
+			break
+		}
+		items := make([]any, 0)
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		return items, nil
+	}
+	return nil, fmt.Errorf("%s values can only be set in YAML files", t)
+}
+
+func sortedKeys[V any](values map[string]V) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
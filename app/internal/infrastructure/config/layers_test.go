@@ -0,0 +1,195 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestLoaderLayers testa a precedência das camadas e a origem registrada para cada chave
+func TestLoaderLayers(t *testing.T) {
+	tests := []struct {
+		name       string
+		user       string
+		repo       string
+		env        []string
+		flags      map[string]string
+		key        string
+		wantValue  string
+		wantLayer  string
+		wantSource string
+		wantErr    string
+	}{
+		{
+			name:      "Built-in default",
+			key:       "analysis.file_limits.max_file_size",
+			wantValue: "10MB",
+			wantLayer: LayerDefault,
+		},
+		{
+			name:      "Auth mode default",
+			key:       "auth.mode",
+			wantValue: AuthModeUser,
+			wantLayer: LayerDefault,
+		},
+		{
+			name:      "User overrides default",
+			user:      "project:\n  type: lambda\n",
+			key:       "project.type",
+			wantValue: "lambda",
+			wantLayer: LayerUser,
+		},
+		{
+			name:      "Repo overrides user",
+			user:      "project:\n  type: lambda\n",
+			repo:      "project:\n  type: api\n",
+			key:       "project.type",
+			wantValue: "api",
+			wantLayer: LayerRepo,
+		},
+		{
+			name:       "Env overrides repo",
+			repo:       "auth:\n  mode: stackspot_user\n",
+			env:        []string{"PHENGINEER_AUTH_MODE=stackspot_service"},
+			key:        "auth.mode",
+			wantValue:  AuthModeService,
+			wantLayer:  LayerEnv,
+			wantSource: "PHENGINEER_AUTH_MODE",
+		},
+		{
+			name:       "Flag overrides env",
+			env:        []string{"PHENGINEER_ANALYSIS_FILE_LIMITS_MAX_FILES=50"},
+			flags:      map[string]string{"analysis.file_limits.max_files": "70"},
+			key:        "analysis.file_limits.max_files",
+			wantValue:  "70",
+			wantLayer:  LayerFlag,
+			wantSource: "--config-set analysis.file_limits.max_files",
+		},
+		{
+			name:      "Env list is split by comma",
+			env:       []string{"PHENGINEER_HTTP_CA_CERT_FILES=/a.pem, /b.pem"},
+			key:       "http.ca_cert_files",
+			wantValue: `["/a.pem","/b.pem"]`,
+			wantLayer: LayerEnv,
+		},
+		{
+			name:      "Empty env is ignored",
+			repo:      "project:\n  type: api\n",
+			env:       []string{"PHENGINEER_PROJECT_TYPE="},
+			key:       "project.type",
+			wantValue: "api",
+			wantLayer: LayerRepo,
+		},
+		{
+			name:      "Empty section keeps defaults",
+			repo:      "auth:\n",
+			key:       "auth.mode",
+			wantValue: AuthModeUser,
+			wantLayer: LayerDefault,
+		},
+		{
+			name:    "Invalid env value",
+			env:     []string{"PHENGINEER_HTTP_TIMEOUT_SECONDS=abc"},
+			wantErr: "PHENGINEER_HTTP_TIMEOUT_SECONDS",
+		},
+		{
+			name:    "Unknown flag key",
+			flags:   map[string]string{"project.name": "x"},
+			wantErr: "unknown configuration key 'project.name'",
+		},
+		{
+			name:    "Resolved settings are validated",
+			env:     []string{"PHENGINEER_AUTH_MODE=admin"},
+			wantErr: "auth.mode 'admin' is invalid",
+		},
+		{
+			name:    "Problems report origin and line",
+			repo:    "version: 1\nhttp:\n  timeout_seconds: -5\n",
+			env:     []string{"PHENGINEER_AUTH_MODE=admin"},
+			wantErr: "(env: PHENGINEER_AUTH_MODE)\nhttp.timeout_seconds '-5' is invalid, expected a positive value (repo: ",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			userFile := filepath.Join(dir, "config.yml")
+			repoFile := filepath.Join(dir, "settings.yml")
+			if tt.user != "" {
+				writeFile(t, userFile, tt.user)
+			}
+			if tt.repo != "" {
+				writeFile(t, repoFile, tt.repo)
+			}
+
+			layered, err := NewLoader(context.Background(), ".phengineer").
+				WithUserFile(userFile).
+				WithRepoFile(repoFile).
+				WithEnv(tt.env).
+				WithFlags(tt.flags).
+				Load()
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Load() error = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+
+			if got := layered.FormatValue(tt.key); got != tt.wantValue {
+				t.Errorf("%s = %q, want %q", tt.key, got, tt.wantValue)
+			}
+			origin := layered.Origins[tt.key]
+			if origin.Layer != tt.wantLayer {
+				t.Errorf("%s origin = %q, want layer %q", tt.key, origin, tt.wantLayer)
+			}
+			if tt.wantSource != "" && origin.Source != tt.wantSource {
+				t.Errorf("%s source = %q, want %q", tt.key, origin.Source, tt.wantSource)
+			}
+		})
+	}
+}
+
+// TestLoaderSettings testa a conversão das camadas em Settings
+func TestLoaderSettings(t *testing.T) {
+	dir := t.TempDir()
+	userFile := filepath.Join(dir, "config.yml")
+	repoFile := filepath.Join(dir, "settings.yml")
+	writeFile(t, userFile, "http:\n  timeout_seconds: 10\nknowledge:\n  sources:\n    - name: user-docs\n      path: docs\n")
+	writeFile(t, repoFile, "project:\n  language:\n    name: python\n    version: \"3.13\"\n")
+
+	layered, err := NewLoader(context.Background(), ".phengineer").
+		WithUserFile(userFile).
+		WithRepoFile(repoFile).
+		WithEnv([]string{"PHENGINEER_HTTP_MAX_RETRIES=-1"}).
+		Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	settings := layered.Settings
+	if settings.Project.Type != "application" || settings.Project.Language.Name != "python" {
+		t.Errorf("Project = %+v, want default type with repo language", settings.Project)
+	}
+	if settings.HTTP.TimeoutSeconds != 10 || settings.HTTP.MaxRetries != -1 {
+		t.Errorf("HTTP = %+v, want timeout from user and retries from env", settings.HTTP)
+	}
+	if len(settings.Knowledge.Sources) != 0 {
+		t.Errorf("Knowledge.Sources = %v, want user sources kept out of project settings", settings.Knowledge.Sources)
+	}
+	if _, ok := layered.Origins["knowledge.sources"]; ok {
+		t.Error("knowledge.sources has an origin, want user knowledge ignored")
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -1,14 +1,19 @@
 package config
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 )
 
-// LoadSettingsFromFile carrega as configurações de um arquivo YAML
+// LoadSettingsFromFile carrega as configurações de um arquivo YAML.
+// Layouts de versões anteriores são migrados em memória; o arquivo não é alterado.
 func LoadSettingsFromFile(filePath string) (*Settings, error) {
 	// Verifica se o arquivo existe
 	if _, err := os.Stat(filePath); os.IsNotExist(err) {
@@ -21,9 +26,9 @@ func LoadSettingsFromFile(filePath string) (*Settings, error) {
 		return nil, fmt.Errorf("failed to read settings file: %w", err)
 	}
 
-	// Parse do YAML
-	var settings Settings
-	if err := yaml.Unmarshal(data, &settings); err != nil {
+	// Parse do YAML: layouts antigos são migrados em memória e chaves desconhecidas rejeitadas
+	settings, err := decodeSettings(data, filePath, filepath.Base(filepath.Dir(filePath)))
+	if err != nil {
 		return nil, fmt.Errorf("failed to parse settings YAML: %w", err)
 	}
 
@@ -32,7 +37,7 @@ func LoadSettingsFromFile(filePath string) (*Settings, error) {
 		return nil, fmt.Errorf("settings validation failed: %w", err)
 	}
 
-	return &settings, nil
+	return settings, nil
 }
 
 // SaveSettingsToFile salva as configurações em um arquivo YAML
@@ -72,7 +77,7 @@ func LoadOrCreateSettings(configFolderPath, configFolderName string) (*Settings,
 	}
 
 	// Arquivo não existe, cria um padrão
-	fmt.Printf("Settings file not found, creating default: %s\n", settingsPath)
+	fmt.Fprintf(os.Stderr, "Settings file not found, creating default: %s\n", settingsPath)
 
 	defaultSettings := GetDefaultSettings(configFolderName)
 	if err := SaveSettingsToFile(defaultSettings, settingsPath); err != nil {
@@ -80,4 +85,246 @@ func LoadOrCreateSettings(configFolderPath, configFolderName string) (*Settings,
 	}
 
 	return defaultSettings, nil
-}
\ No newline at end of file
+}
+
+// LoadProjectSettings carrega o settings.yml do repositório do context sem validar os requirements.
+// Retorna nil se o comando não estiver em um repositório ou se o arquivo não existir.
+func LoadProjectSettings(ctx context.Context, configFolderName string) (*Settings, error) {
+	settingsPath, err := ProjectSettingsPath(ctx, configFolderName)
+	if err != nil {
+		return nil, nil
+	}
+
+	if _, err := os.Stat(settingsPath); os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	return LoadSettingsFromFile(settingsPath)
+}
+
+// ProjectSettingsPath retorna o caminho do settings.yml na raiz do repositório do context
+func ProjectSettingsPath(ctx context.Context, configFolderName string) (string, error) {
+	configDirPath, err := getConfigDirPath(ctx, configFolderName)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDirPath, "settings.yml"), nil
+}
+
+// SetSettingsValue altera uma chave (ex.: auth.profile) do settings.yml preservando
+// comentários e a ordem das demais chaves. Valor vazio remove a chave.
+func SetSettingsValue(filePath, key, value string) error {
+	return SetSettingsValues(filePath, map[string]string{key: value})
+}
+
+// SetSettingsValues altera várias chaves do settings.yml de uma vez. Cada valor é convertido
+// para o tipo da chave (listas separadas por vírgula) e a configuração resultante, somada aos
+// padrões e ao arquivo do usuário, é validada; se for inválida o arquivo original é mantido.
+func SetSettingsValues(filePath string, values map[string]string) error {
+	fields := settingsFields()
+	nodes := make(map[string]*yaml.Node, len(values))
+	for key, value := range values {
+		fieldType, ok := fields[key]
+		if !ok {
+			return fmt.Errorf("unknown configuration key '%s'", key)
+		}
+		if value == "" {
+			nodes[key] = nil
+			continue
+		}
+		node, err := valueNode(fieldType, value)
+		if err != nil {
+			return fmt.Errorf("invalid value for %s: %w", key, err)
+		}
+		nodes[key] = node
+	}
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to read settings file: %w", err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("failed to parse settings YAML: %w", err)
+	}
+	if len(doc.Content) == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	markBlankLines(&doc, strings.Split(string(data), "\n"))
+
+	// Chaves novas entram em ordem alfabética
+	for _, key := range sortedKeys(nodes) {
+		if err := setNodeValue(doc.Content[0], strings.Split(key, "."), nodes[key]); err != nil {
+			return fmt.Errorf("failed to set %s: %w", key, err)
+		}
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return fmt.Errorf("failed to marshal settings to YAML: %w", err)
+	}
+
+	updated := restoreBlankLines(buf.Bytes())
+
+	configFolderName := filepath.Base(filepath.Dir(filePath))
+	if _, err := decodeSettings(updated, filePath, configFolderName); err != nil {
+		return fmt.Errorf("failed to parse settings YAML: %w", err)
+	}
+
+	if err := os.WriteFile(filePath, updated, 0o644); err != nil {
+		return fmt.Errorf("failed to write settings file: %w", err)
+	}
+
+	// Valida o resultado já nas camadas; em caso de erro o conteúdo anterior volta
+	if err := ValidateSettingsFile(filePath); err != nil {
+		if restoreErr := os.WriteFile(filePath, data, 0o644); restoreErr != nil {
+			return fmt.Errorf("%w (failed to restore settings file: %v)", err, restoreErr)
+		}
+		return err
+	}
+	return nil
+}
+
+// ValidateSettingsFile valida um settings.yml somado aos padrões e ao arquivo do usuário,
+// sem as variáveis de ambiente e flags da execução atual
+func ValidateSettingsFile(filePath string) error {
+	_, err := newLoader(filepath.Base(filepath.Dir(filePath))).
+		WithRepoFile(filePath).
+		WithEnv(nil).
+		WithFlags(nil).
+		Load()
+	return err
+}
+
+// ResetSettingsFile recria o settings.yml com os padrões da CLI. O arquivo atual é copiado
+// para <arquivo>.bak; retorna o caminho do backup, vazio se o arquivo não existia.
+func ResetSettingsFile(filePath, configFolderName string) (string, error) {
+	var backupPath string
+	data, err := os.ReadFile(filePath)
+	switch {
+	case err == nil:
+		backupPath = filePath + ".bak"
+		if err := os.WriteFile(backupPath, data, 0o644); err != nil {
+			return "", fmt.Errorf("failed to write settings backup: %w", err)
+		}
+	case !os.IsNotExist(err):
+		return "", fmt.Errorf("failed to read settings file: %w", err)
+	}
+
+	if err := SaveSettingsToFile(GetDefaultSettings(configFolderName), filePath); err != nil {
+		return "", err
+	}
+	return backupPath, nil
+}
+
+// valueNode monta o nó YAML de um valor no tipo da chave
+func valueNode(t reflect.Type, raw string) (*yaml.Node, error) {
+	parsed, err := parseValue(t, raw)
+	if err != nil {
+		return nil, err
+	}
+
+	switch value := parsed.(type) {
+	case []any:
+		list := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for _, item := range value {
+			list.Content = append(list.Content, stringNode(fmt.Sprint(item)))
+		}
+		return list, nil
+	case string:
+		return stringNode(value), nil
+	default:
+		return &yaml.Node{Kind: yaml.ScalarNode, Value: fmt.Sprint(value)}, nil
+	}
+}
+
+// stringNode cria um texto no YAML; valores que seriam lidos como número ou booleano
+// (ex.: versão 3.10) ficam entre aspas
+func stringNode(value string) *yaml.Node {
+	node := &yaml.Node{Kind: yaml.ScalarNode, Value: value}
+	if node.ShortTag() != "!!str" {
+		node.Style = yaml.DoubleQuotedStyle
+	}
+	return node
+}
+
+// setNodeValue percorre os mapas do YAML criando os níveis que faltarem; value nil remove a chave
+func setNodeValue(node *yaml.Node, path []string, value *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("'%s' is not a mapping", path[0])
+	}
+
+	for i := 0; i < len(node.Content); i += 2 {
+		if node.Content[i].Value != path[0] {
+			continue
+		}
+		if len(path) > 1 {
+			return setNodeValue(node.Content[i+1], path[1:], value)
+		}
+		if value == nil {
+			node.Content = append(node.Content[:i], node.Content[i+2:]...)
+			return nil
+		}
+		// Mantém o comentário de linha do valor anterior
+		value.LineComment = node.Content[i+1].LineComment
+		node.Content[i+1] = value
+		return nil
+	}
+
+	if value == nil {
+		return nil
+	}
+
+	child := value
+	if len(path) > 1 {
+		child = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		if err := setNodeValue(child, path[1:], value); err != nil {
+			return err
+		}
+	}
+	node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: path[0]}, child)
+	return nil
+}
+
+// blankLineMarker comentário provisório que guarda uma linha em branco durante a reescrita
+const blankLineMarker = "#phengineer:blank-line"
+
+// markBlankLines marca as chaves precedidas por uma linha em branco no arquivo original,
+// que o encoder do yaml.v3 descartaria; restoreBlankLines troca as marcas de volta
+func markBlankLines(node *yaml.Node, lines []string) {
+	if node.Kind == yaml.MappingNode {
+		for i := 0; i < len(node.Content); i += 2 {
+			key := node.Content[i]
+			// Linha acima da chave e do seu comentário (Line começa em 1)
+			above := key.Line - 2
+			if key.HeadComment != "" {
+				above -= strings.Count(key.HeadComment, "\n") + 1
+			}
+			if key.Line > 0 && above >= 0 && above < len(lines) && strings.TrimSpace(lines[above]) == "" {
+				key.HeadComment = strings.TrimSuffix(blankLineMarker+"\n"+key.HeadComment, "\n")
+			}
+		}
+	}
+	for _, child := range node.Content {
+		markBlankLines(child, lines)
+	}
+}
+
+// restoreBlankLines troca as marcas de markBlankLines por linhas em branco
+func restoreBlankLines(data []byte) []byte {
+	lines := strings.Split(string(data), "\n")
+	result := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if strings.TrimSpace(line) == blankLineMarker {
+			if len(result) > 0 && result[len(result)-1] != "" {
+				result = append(result, "")
+			}
+			continue
+		}
+		result = append(result, line)
+	}
+	return []byte(strings.Join(result, "\n"))
+}
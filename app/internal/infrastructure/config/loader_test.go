@@ -126,7 +126,7 @@ func TestSaveSettingsToFile(t *testing.T) {
 			"type: cli",
 			"name: rust",
 			"version: \"1.70\"",
-			"files_include_path: src/**/*.rs",
+			"analysis_files_path: src/**/*.rs",
 			"max_file_size: 20MB",
 		}
 
@@ -178,6 +178,222 @@ func TestLoadOrCreateSettingsLoader(t *testing.T) {
 	})
 }
 
+// TestSetSettingsValue testa a alteração de chaves do settings.yml preservando comentários
+func TestSetSettingsValue(t *testing.T) {
+	original := `# Configuração do projeto
+project:
+  type: cli # tipo arquitetural
+  language:
+    name: go
+    version: "1.24"
+analysis:
+  analysis_files_path: .phengineer/.analyzeFiles
+  file_limits:
+    max_file_size: 1MB
+    max_files: 100
+`
+
+	tests := []struct {
+		name        string
+		key         string
+		value       string
+		contains    []string
+		notContains []string
+		wantErr     bool
+	}{
+		{
+			name:     "Create nested key",
+			key:      "auth.profile",
+			value:    "work",
+			contains: []string{"# Configuração do projeto", "type: cli # tipo arquitetural", "auth:\n  profile: work"},
+		},
+		{
+			name:     "Replace existing key",
+			key:      "project.type",
+			value:    "lambda",
+			contains: []string{"type: lambda", "# Configuração do projeto"},
+		},
+		{
+			name:        "Remove key falls back to default",
+			key:         "analysis.file_limits.max_files",
+			value:       "",
+			notContains: []string{"max_files"},
+		},
+		{
+			name:     "Keep text that looks like a number",
+			key:      "project.language.version",
+			value:    "3.10",
+			contains: []string{`version: "3.10"`},
+		},
+		{
+			name:     "Split list values",
+			key:      "http.ca_cert_files",
+			value:    "/a.pem, /b.pem",
+			contains: []string{"http:\n  ca_cert_files:\n    - /a.pem\n    - /b.pem"},
+		},
+		{
+			name:    "Reject unknown key",
+			key:     "project.type.name",
+			value:   "x",
+			wantErr: true,
+		},
+		{
+			name:    "Reject value of the wrong type",
+			key:     "http.timeout_seconds",
+			value:   "abc",
+			wantErr: true,
+		},
+		{
+			name:    "Restore file when resolved settings are invalid",
+			key:     "http.timeout_seconds",
+			value:   "-1",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+			settingsPath := filepath.Join(t.TempDir(), "settings.yml")
+			if err := os.WriteFile(settingsPath, []byte(original), 0o644); err != nil {
+				t.Fatalf("Failed to write settings: %v", err)
+			}
+
+			err := SetSettingsValue(settingsPath, tt.key, tt.value)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Expected error")
+				}
+				data, _ := os.ReadFile(settingsPath)
+				if string(data) != original {
+					t.Error("File must not change when the update fails")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SetSettingsValue failed: %v", err)
+			}
+
+			data, _ := os.ReadFile(settingsPath)
+			for _, expected := range tt.contains {
+				if !strings.Contains(string(data), expected) {
+					t.Errorf("Expected %q in:\n%s", expected, data)
+				}
+			}
+			for _, unexpected := range tt.notContains {
+				if strings.Contains(string(data), unexpected) {
+					t.Errorf("Unexpected %q in:\n%s", unexpected, data)
+				}
+			}
+		})
+	}
+}
+
+// TestSetSettingsValues testa que as chaves são gravadas juntas ou nenhuma é
+func TestSetSettingsValues(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	settingsPath := filepath.Join(t.TempDir(), "settings.yml")
+	original := "version: 1\nproject:\n  type: cli\n"
+	if err := os.WriteFile(settingsPath, []byte(original), 0o644); err != nil {
+		t.Fatalf("Failed to write settings: %v", err)
+	}
+
+	err := SetSettingsValues(settingsPath, map[string]string{
+		"project.type":         "lambda",
+		"http.timeout_seconds": "-1",
+	})
+	if err == nil {
+		t.Fatal("Expected error for invalid timeout")
+	}
+	if data, _ := os.ReadFile(settingsPath); string(data) != original {
+		t.Errorf("File changed on failed update:\n%s", data)
+	}
+
+	err = SetSettingsValues(settingsPath, map[string]string{
+		"project.type":         "lambda",
+		"http.timeout_seconds": "15",
+		"auth.mode":            AuthModeService,
+	})
+	if err != nil {
+		t.Fatalf("SetSettingsValues failed: %v", err)
+	}
+	data, _ := os.ReadFile(settingsPath)
+	expected := "version: 1\nproject:\n  type: lambda\nauth:\n  mode: stackspot_service\nhttp:\n  timeout_seconds: 15\n"
+	if string(data) != expected {
+		t.Errorf("Settings = %q, want %q", data, expected)
+	}
+}
+
+// TestSetSettingsValuesLayout testa que a edição mantém comentários e linhas em branco entre seções
+func TestSetSettingsValuesLayout(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	settingsPath := filepath.Join(t.TempDir(), "settings.yml")
+	original := `version: 1
+project:
+  type: cli # tipo do projeto
+
+# padrões da análise
+analysis:
+  file_limits:
+    max_file_size: 1MB
+
+    max_files: 1000
+
+auth:
+  mode: stackspot_user
+`
+	if err := os.WriteFile(settingsPath, []byte(original), 0o644); err != nil {
+		t.Fatalf("Failed to write settings: %v", err)
+	}
+
+	err := SetSettingsValues(settingsPath, map[string]string{
+		"analysis.file_limits.max_files": "500",
+		"http.timeout_seconds":           "15",
+	})
+	if err != nil {
+		t.Fatalf("SetSettingsValues failed: %v", err)
+	}
+
+	data, _ := os.ReadFile(settingsPath)
+	expected := strings.Replace(original, "max_files: 1000", "max_files: 500", 1) + "http:\n  timeout_seconds: 15\n"
+	if string(data) != expected {
+		t.Errorf("Settings = %q, want %q", data, expected)
+	}
+}
+
+// TestResetSettingsFile testa a recriação do settings.yml com os padrões e o backup do original
+func TestResetSettingsFile(t *testing.T) {
+	settingsPath := filepath.Join(t.TempDir(), "settings.yml")
+
+	backupPath, err := ResetSettingsFile(settingsPath, ".phengineer")
+	if err != nil {
+		t.Fatalf("ResetSettingsFile failed: %v", err)
+	}
+	if backupPath != "" {
+		t.Errorf("Backup = %q for a missing file, want none", backupPath)
+	}
+
+	original := "version: 1\nproject:\n  type: cli\n"
+	if err := os.WriteFile(settingsPath, []byte(original), 0o644); err != nil {
+		t.Fatalf("Failed to write settings: %v", err)
+	}
+	backupPath, err = ResetSettingsFile(settingsPath, ".phengineer")
+	if err != nil {
+		t.Fatalf("ResetSettingsFile failed: %v", err)
+	}
+	if data, _ := os.ReadFile(backupPath); string(data) != original {
+		t.Errorf("Backup = %q, want original content", data)
+	}
+
+	settings, err := LoadSettingsFromFile(settingsPath)
+	if err != nil {
+		t.Fatalf("LoadSettingsFromFile failed: %v", err)
+	}
+	if settings.Project.Type != GetDefaultSettings(".phengineer").Project.Type {
+		t.Errorf("Project.Type = %q, want default", settings.Project.Type)
+	}
+}
+
 // BenchmarkSaveSettingsToFile testa performance do salvamento
 func BenchmarkSaveSettingsToFile(b *testing.B) {
 	tempDir, _ := os.MkdirTemp("", "benchmark-save-*")
@@ -0,0 +1,262 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CurrentSettingsVersion versão do formato do settings.yml gerado por esta CLI.
+// Arquivos sem o campo version são da versão 0.
+const CurrentSettingsVersion = 1
+
+// migration atualiza o documento da versão from para from+1 e descreve o que mudou
+type migration struct {
+	from  int
+	apply func(root *yaml.Node, configFolderName string) []string
+}
+
+// migrations cadeia de migrações, na ordem das versões
+var migrations = []migration{
+	{from: 0, apply: migrateV0ToV1},
+}
+
+// MigrationResult resumo de uma migração do settings.yml
+type MigrationResult struct {
+	FromVersion int
+	ToVersion   int
+	Changes     []string
+	BackupPath  string // vazio quando nada foi gravado
+}
+
+// Changed indica se o arquivo estava em uma versão antiga
+func (r *MigrationResult) Changed() bool {
+	return r.FromVersion != r.ToVersion
+}
+
+// MigrateSettingsFile atualiza o settings.yml para a versão atual preservando comentários.
+// O original é copiado para <arquivo>.v<versão>.bak antes de ser sobrescrito; com dryRun
+// nada é gravado e o conteúdo migrado é retornado.
+func MigrateSettingsFile(filePath, configFolderName string, dryRun bool) (*MigrationResult, []byte, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read settings file: %w", err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse settings YAML: %s: %w", filePath, err)
+	}
+	markBlankLines(&doc, strings.Split(string(data), "\n"))
+
+	result, err := migrateDocument(&doc, configFolderName)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", filePath, err)
+	}
+	if !result.Changed() {
+		return result, data, nil
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal settings to YAML: %w", err)
+	}
+	migrated := restoreBlankLines(buf.Bytes())
+	if dryRun {
+		return result, migrated, nil
+	}
+
+	// O resultado precisa ser um settings.yml válido antes de substituir o original
+	if _, err := decodeSettings(migrated, filePath, configFolderName); err != nil {
+		return nil, nil, fmt.Errorf("migrated settings are invalid: %w", err)
+	}
+
+	backupPath := fmt.Sprintf("%s.v%d.bak", filePath, result.FromVersion)
+	if err := os.WriteFile(backupPath, data, 0o644); err != nil {
+		return nil, nil, fmt.Errorf("failed to write settings backup: %w", err)
+	}
+	if err := os.WriteFile(filePath, migrated, 0o644); err != nil {
+		return nil, nil, fmt.Errorf("failed to write settings file: %w", err)
+	}
+
+	result.BackupPath = backupPath
+	return result, migrated, nil
+}
+
+// migrateDocument aplica em memória as migrações pendentes do documento
+func migrateDocument(doc *yaml.Node, configFolderName string) (*MigrationResult, error) {
+	root := documentRoot(doc)
+	if root == nil {
+		return &MigrationResult{FromVersion: CurrentSettingsVersion, ToVersion: CurrentSettingsVersion}, nil
+	}
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("line %d: expected a mapping at the top level", root.Line)
+	}
+
+	version := 0
+	if node := mappingValue(root, "version"); node != nil {
+		parsed, err := strconv.Atoi(node.Value)
+		if err != nil || parsed < 0 {
+			return nil, fmt.Errorf("line %d: version '%s' is invalid, expected a positive integer", node.Line, node.Value)
+		}
+		version = parsed
+	}
+	if version > CurrentSettingsVersion {
+		return nil, fmt.Errorf("settings version %d is newer than the supported version %d, update phengineer", version, CurrentSettingsVersion)
+	}
+
+	result := &MigrationResult{FromVersion: version, ToVersion: version}
+	for _, m := range migrations {
+		if m.from != result.ToVersion {
+			continue
+		}
+		result.Changes = append(result.Changes, m.apply(root, configFolderName)...)
+		result.ToVersion = m.from + 1
+	}
+
+	if result.Changed() {
+		setMappingValue(root, "version", &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: strconv.Itoa(result.ToVersion)})
+		// version fica no topo do arquivo
+		moveKeyFirst(root, "version")
+	}
+	return result, nil
+}
+
+// migrateV0ToV1 unifica os layouts anteriores ao campo version:
+//   - analysis.files_include_path e analysis.analyze_files_path viram analysis.analysis_files_path,
+//     relativo à pasta de configuração
+//   - analysis.files_exclude_path é removido: a descoberta não usa lista de exclusão
+//   - project.language em texto, com project.version, vira {name, version}
+func migrateV0ToV1(root *yaml.Node, configFolderName string) []string {
+	var changes []string
+
+	if analysis := mappingValue(root, "analysis"); analysis != nil && analysis.Kind == yaml.MappingNode {
+		for _, legacy := range []string{"files_include_path", "analyze_files_path"} {
+			node := mappingValue(analysis, legacy)
+			if node == nil {
+				continue
+			}
+			if mappingValue(analysis, "analysis_files_path") != nil {
+				deleteMappingKey(analysis, legacy)
+				changes = append(changes, fmt.Sprintf("analysis.%s removed: analysis.analysis_files_path is already set", legacy))
+				continue
+			}
+			if legacy == "files_include_path" {
+				// O layout antigo usava caminhos relativos à raiz do repositório
+				node.Value = relativeToConfigFolder(node.Value, configFolderName)
+			}
+			renameMappingKey(analysis, legacy, "analysis_files_path")
+			changes = append(changes, fmt.Sprintf("analysis.%s renamed to analysis.analysis_files_path (%s)", legacy, node.Value))
+		}
+
+		if node := mappingValue(analysis, "files_exclude_path"); node != nil {
+			deleteMappingKey(analysis, "files_exclude_path")
+			changes = append(changes, fmt.Sprintf("analysis.files_exclude_path (%s) removed: exclusions are not supported, the value is kept in the backup", node.Value))
+		}
+	}
+
+	if project := mappingValue(root, "project"); project != nil && project.Kind == yaml.MappingNode {
+		language := mappingValue(project, "language")
+		if language != nil && language.Kind == yaml.ScalarNode {
+			mapping := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			setMappingValue(mapping, "name", &yaml.Node{Kind: yaml.ScalarNode, Value: language.Value})
+			if version := mappingValue(project, "version"); version != nil {
+				setMappingValue(mapping, "version", &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: version.Value})
+				deleteMappingKey(project, "version")
+			}
+			setMappingValue(project, "language", mapping)
+			changes = append(changes, "project.language and project.version moved to project.language.{name,version}")
+		}
+	}
+
+	return changes
+}
+
+// relativeToConfigFolder converte um caminho relativo à raiz do repositório em relativo à pasta de configuração
+func relativeToConfigFolder(path, configFolderName string) string {
+	if path == "" || filepath.IsAbs(path) || configFolderName == "" {
+		return path
+	}
+	if rel, ok := strings.CutPrefix(filepath.ToSlash(path), filepath.ToSlash(configFolderName)+"/"); ok {
+		return rel
+	}
+	if rel, err := filepath.Rel(configFolderName, path); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return path
+}
+
+// documentRoot retorna o nó raiz do documento, ou nil se o arquivo estiver vazio
+func documentRoot(doc *yaml.Node) *yaml.Node {
+	if doc.Kind == 0 {
+		return nil
+	}
+	if doc.Kind == yaml.DocumentNode {
+		if len(doc.Content) == 0 {
+			return nil
+		}
+		return doc.Content[0]
+	}
+	return doc
+}
+
+// mappingValue retorna o valor de uma chave do mapa, ou nil se não existir
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// setMappingValue altera o valor de uma chave ou a acrescenta ao final do mapa
+func setMappingValue(node *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			node.Content[i+1] = value
+			return
+		}
+	}
+	node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, value)
+}
+
+// renameMappingKey troca o nome de uma chave mantendo a posição e os comentários
+func renameMappingKey(node *yaml.Node, from, to string) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == from {
+			node.Content[i].Value = to
+			return
+		}
+	}
+}
+
+// deleteMappingKey remove uma chave do mapa
+func deleteMappingKey(node *yaml.Node, key string) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			node.Content = append(node.Content[:i], node.Content[i+2:]...)
+			return
+		}
+	}
+}
+
+// moveKeyFirst move uma chave para o início do mapa
+func moveKeyFirst(node *yaml.Node, key string) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value != key {
+			continue
+		}
+		pair := []*yaml.Node{node.Content[i], node.Content[i+1]}
+		rest := append(node.Content[:i:i], node.Content[i+2:]...)
+		node.Content = append(pair, rest...)
+		return
+	}
+}
@@ -0,0 +1,194 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestMigrateSettingsFile testa a migração dos layouts antigos do settings.yml
+func TestMigrateSettingsFile(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		wantFrom    int
+		wantChanged bool
+		wantYAML    []string
+		notWantYAML []string
+		wantErr     string
+	}{
+		{
+			name: "Include and exclude paths",
+			content: `project:
+  type: "cli"
+  language:
+    name: go
+    version: 1.24.3
+
+analysis:
+  # padrões da análise
+  files_include_path: ".phengineer/.includefiles"
+  files_exclude_path: ".phengineer/.ignorefiles"
+  file_limits:
+    max_file_size: "1MB"
+    max_files: 1000
+`,
+			wantChanged: true,
+			wantYAML:    []string{"version: 1\nproject:", "1.24.3\n\nanalysis:", "# padrões da análise\n  analysis_files_path: \".includefiles\""},
+			notWantYAML: []string{"files_include_path", "files_exclude_path"},
+		},
+		{
+			name: "Language as text",
+			content: `project:
+  type: lambda
+  language: python
+  version: "3.13"
+analysis:
+  analysis_files_path: .analyzefiles
+`,
+			wantChanged: true,
+			wantYAML:    []string{"language:\n    name: python\n    version: \"3.13\""},
+		},
+		{
+			name: "Misnamed analyze_files_path",
+			content: `analysis:
+  analyze_files_path: .analyzefiles
+`,
+			wantChanged: true,
+			wantYAML:    []string{"analysis_files_path: .analyzefiles"},
+		},
+		{
+			name:     "Current version is kept",
+			content:  "version: 1\nproject:\n  type: api\n",
+			wantFrom: 1,
+		},
+		{
+			name:    "Newer version",
+			content: "version: 9\n",
+			wantErr: "newer than the supported version",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "settings.yml")
+			writeFile(t, path, tt.content)
+
+			result, data, err := MigrateSettingsFile(path, ".phengineer", false)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("MigrateSettingsFile() error = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("MigrateSettingsFile() error = %v", err)
+			}
+
+			if result.FromVersion != tt.wantFrom || result.Changed() != tt.wantChanged {
+				t.Errorf("result = %+v, want from %d changed %t", result, tt.wantFrom, tt.wantChanged)
+			}
+
+			written, _ := os.ReadFile(path)
+			if string(written) != string(data) {
+				t.Errorf("file content differs from returned data")
+			}
+			for _, want := range tt.wantYAML {
+				if !strings.Contains(string(written), want) {
+					t.Errorf("migrated YAML missing %q, got:\n%s", want, written)
+				}
+			}
+			for _, notWant := range tt.notWantYAML {
+				if strings.Contains(string(written), notWant) {
+					t.Errorf("migrated YAML still contains %q", notWant)
+				}
+			}
+
+			backup, err := os.ReadFile(path + ".v0.bak")
+			switch {
+			case tt.wantChanged && (err != nil || string(backup) != tt.content):
+				t.Errorf("backup = %q, %v, want original content", backup, err)
+			case !tt.wantChanged && err == nil:
+				t.Error("backup written for a current file")
+			}
+		})
+	}
+}
+
+// TestMigrateSettingsDryRun testa que o dry run não altera o arquivo
+func TestMigrateSettingsDryRun(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "settings.yml")
+	content := "analysis:\n  files_include_path: .phengineer/.analyzefiles\n"
+	writeFile(t, path, content)
+
+	result, data, err := MigrateSettingsFile(path, ".phengineer", true)
+	if err != nil {
+		t.Fatalf("MigrateSettingsFile() error = %v", err)
+	}
+	if !result.Changed() || result.BackupPath != "" {
+		t.Errorf("result = %+v, want changed without backup", result)
+	}
+	if !strings.Contains(string(data), "analysis_files_path: .analyzefiles") {
+		t.Errorf("data = %q, want migrated content", data)
+	}
+	if written, _ := os.ReadFile(path); string(written) != content {
+		t.Errorf("file changed on dry run: %q", written)
+	}
+}
+
+// TestLoadSettingsStrict testa a rejeição de chaves desconhecidas com a posição no arquivo
+func TestLoadSettingsStrict(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "settings.yml")
+	writeFile(t, path, `version: 1
+project:
+  type: api
+  name: my-api
+  language:
+    name: go
+    version: "1.24"
+analysis:
+  analysis_files_path: .analyzefiles
+  file_limits:
+    max_file_size: 10MB
+    max_files: 100
+knowledge:
+  sources:
+    - name: docs
+      path: docs
+      branch: main
+`)
+
+	_, err := LoadSettingsFromFile(path)
+	if err == nil {
+		t.Fatal("LoadSettingsFromFile() error = nil, want unknown keys")
+	}
+	for _, want := range []string{
+		path + ":4:3: unknown key 'project.name'",
+		path + ":17:7: unknown key 'knowledge.sources[0].branch'",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error = %v, want containing %q", err, want)
+		}
+	}
+
+	// Layout antigo é migrado em memória sem alterar o arquivo
+	legacy := filepath.Join(dir, ".phengineer", "settings.yml")
+	if err := os.MkdirAll(filepath.Dir(legacy), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	content := "project:\n  type: api\n  language: go\n  version: \"1.24\"\nanalysis:\n  files_include_path: .phengineer/.analyzefiles\n  file_limits:\n    max_file_size: 10MB\n    max_files: 100\n"
+	writeFile(t, legacy, content)
+
+	settings, err := LoadSettingsFromFile(legacy)
+	if err != nil {
+		t.Fatalf("LoadSettingsFromFile() legacy error = %v", err)
+	}
+	if settings.Version != CurrentSettingsVersion || settings.Project.Language.Name != "go" || settings.Analysis.AnalysisFilesPath != ".analyzefiles" {
+		t.Errorf("settings = %+v, want migrated values", settings)
+	}
+	if written, _ := os.ReadFile(legacy); string(written) != content {
+		t.Errorf("legacy file changed on load: %q", written)
+	}
+}
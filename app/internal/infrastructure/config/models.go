@@ -2,37 +2,178 @@ package config
 
 import (
 	"fmt"
-	"path/filepath"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
 )
 
+// DefaultAnalysisFilesName arquivo de padrões da análise criado na pasta de configuração
+const DefaultAnalysisFilesName = ".analyzefiles"
+
+// ProjectTypes tipos de projeto oferecidos pelo JSON Schema em project.type. A CLI aceita
+// outros valores, usados para filtrar fontes de conhecimento (project_types).
+var ProjectTypes = []string{"application", "lambda", "api", "frontend", "backend", "cli", "library"}
+
+// AuthModes modos de autenticação aceitos em auth.mode
+var AuthModes = []string{AuthModeUser, AuthModeService}
+
+// SizePattern formato dos tamanhos de arquivo (ex.: 500KB, 10MB, 1.5GB; sem unidade são bytes)
+const SizePattern = `^[0-9]+(\.[0-9]+)?([KkMmGg][Bb])?$`
+
+// As structs abaixo são a fonte do JSON Schema do settings.yml (JSONSchema): a tag desc
+// vira a descrição exibida pelo editor; enums e padrões ficam em schemaEnums e schemaPatterns.
+
 // Settings representa a estrutura do arquivo settings.yml
 type Settings struct {
-	Project  Project  `yaml:"project"`
-	Analysis Analysis `yaml:"analysis"`
+	Version   int       `yaml:"version,omitempty" desc:"Versão do formato do arquivo; ausente equivale a 0 e é migrado ao carregar"`
+	Project   Project   `yaml:"project" desc:"Informações do projeto analisado"`
+	Analysis  Analysis  `yaml:"analysis" desc:"Comportamento da descoberta de arquivos"`
+	Knowledge Knowledge `yaml:"knowledge,omitempty" desc:"Fontes de conhecimento do projeto"`
+	Auth      Auth      `yaml:"auth,omitempty" desc:"Autenticação com StackSpot, GitHub e Vault"`
+	HTTP      HTTP      `yaml:"http,omitempty" desc:"Rede usada nas chamadas aos provedores"`
 }
 
 // Project representa as configurações do projeto
 type Project struct {
-	Type     string   `yaml:"type"`
-	Language Language `yaml:"language"`
+	Type     string   `yaml:"type" desc:"Tipo arquitetural do projeto"`
+	Language Language `yaml:"language" desc:"Linguagem principal"`
 }
 
 // Language representa as configurações da linguagem
 type Language struct {
-	Name    string `yaml:"name"`
-	Version string `yaml:"version"`
+	Name    string `yaml:"name" desc:"Nome da linguagem (ex.: go, python)"`
+	Version string `yaml:"version" desc:"Versão da linguagem (ex.: \"1.24\"; use aspas para não virar número)"`
 }
 
 // Analysis representa as configurações de análise
 type Analysis struct {
-	AnalysisFilesPath string `yaml:"analysis_files_path"`
-	FileLimits        Limits `yaml:"file_limits"`
+	AnalysisFilesPath string `yaml:"analysis_files_path" desc:"Arquivo de padrões da análise, relativo à pasta de configuração"`
+	FileLimits        Limits `yaml:"file_limits" desc:"Limites da descoberta"`
 }
 
 // Limits representa os limites de arquivos
 type Limits struct {
-	MaxFileSize string `yaml:"max_file_size"`
-	MaxFiles    int64  `yaml:"max_files"`
+	MaxFileSize string `yaml:"max_file_size" desc:"Tamanho máximo por arquivo (ex.: 500KB, 10MB)"`
+	MaxFiles    int64  `yaml:"max_files" desc:"Quantidade máxima de arquivos analisados"`
+}
+
+// Knowledge representa o registro de fontes de conhecimento globais
+type Knowledge struct {
+	Sources []KnowledgeSource `yaml:"sources,omitempty" desc:"Diretórios locais ou repositórios git com documentos de referência"`
+}
+
+// KnowledgeSource representa uma fonte de conhecimento (diretório local ou repositório git)
+type KnowledgeSource struct {
+	Name            string   `yaml:"name" desc:"Nome único da fonte"`
+	Path            string   `yaml:"path,omitempty" desc:"Diretório local (exclusivo com git)"`
+	Git             string   `yaml:"git,omitempty" desc:"URL do repositório (exclusivo com path)"`
+	Ref             string   `yaml:"ref,omitempty" desc:"Tag, branch ou commit fixado"`
+	Subdir          string   `yaml:"subdir,omitempty" desc:"Subdiretório dentro da fonte"`
+	Tags            []string `yaml:"tags,omitempty" desc:"Tags aplicadas aos documentos da fonte"`
+	ProjectTypes    []string `yaml:"project_types,omitempty" desc:"Tipos de projeto em que a fonte é usada; vazio vale para todos"`
+	GenerationTypes []string `yaml:"generation_types,omitempty" desc:"Tipos de geração em que a fonte é usada; vazio vale para todos"`
+}
+
+// Métodos de autenticação suportados no Vault
+const (
+	VaultMethodAWS        = "aws"
+	VaultMethodAppRole    = "approle"
+	VaultMethodJWT        = "jwt"
+	VaultMethodKubernetes = "kubernetes"
+	VaultMethodToken      = "token"
+)
+
+// VaultMethods lista os métodos de autenticação suportados no Vault
+var VaultMethods = []string{VaultMethodAWS, VaultMethodAppRole, VaultMethodJWT, VaultMethodKubernetes, VaultMethodToken}
+
+// HTTP representa a configuração de rede usada nas chamadas aos provedores
+type HTTP struct {
+	Proxy          string   `yaml:"proxy,omitempty" desc:"Proxy das requisições; sobrescreve HTTPS_PROXY/HTTP_PROXY"`
+	NoProxy        string   `yaml:"no_proxy,omitempty" desc:"Hosts sem proxy, no formato de NO_PROXY"`
+	CACertFiles    []string `yaml:"ca_cert_files,omitempty" desc:"Certificados PEM somados aos do sistema"`
+	ClientCertFile string   `yaml:"client_cert_file,omitempty" desc:"Certificado do cliente para mTLS (com client_key_file)"`
+	ClientKeyFile  string   `yaml:"client_key_file,omitempty" desc:"Chave do certificado do cliente"`
+	TimeoutSeconds int      `yaml:"timeout_seconds,omitempty" desc:"Timeout de cada tentativa em segundos; 0 usa o padrão (30)"`
+	MaxRetries     int      `yaml:"max_retries,omitempty" desc:"Retentativas em 5xx e 429; 0 usa o padrão (3), negativo desativa"`
+}
+
+// Auth representa as configurações de autenticação do projeto
+type Auth struct {
+	Mode             string            `yaml:"mode,omitempty" desc:"stackspot_user (credenciais do usuário, padrão) ou stackspot_service (via Vault)"`
+	TokenRefreshSkew string            `yaml:"token_refresh_skew,omitempty" desc:"Antecedência da renovação dos tokens (ex.: 2m)"`
+	Profile          string            `yaml:"profile,omitempty" desc:"Perfil de credenciais usado neste repositório"`
+	StackSpot        StackSpotSettings `yaml:"stackspot,omitempty" desc:"IdM da StackSpot e client do login pelo navegador"`
+	GitHub           GitHubSettings    `yaml:"github,omitempty" desc:"GitHub (github.com ou GHES)"`
+	Vault            VaultSettings     `yaml:"vault,omitempty" desc:"Vault do modo service; segredos ficam no keyring ou em variáveis de ambiente"`
+}
+
+// StackSpotSettings endereço do IdM da StackSpot e client usado no login pelo navegador
+type StackSpotSettings struct {
+	IdMURL   string `yaml:"idm_url,omitempty" desc:"Realm do IdM (padrão: https://idm.stackspot.com/realms/stackspot)"`
+	ClientID string `yaml:"client_id,omitempty" desc:"Client público com device authorization habilitado"`
+}
+
+// GitHubSettings endereços do GitHub (github.com ou GHES) e app usado no login pelo navegador
+type GitHubSettings struct {
+	URL      string            `yaml:"url,omitempty" desc:"Endereço do GitHub (padrão: https://github.com)"`
+	APIURL   string            `yaml:"api_url,omitempty" desc:"Endereço da API (padrão: https://api.github.com)"`
+	ClientID string            `yaml:"client_id,omitempty" desc:"OAuth App ou GitHub App com device flow habilitado"`
+	App      GitHubAppSettings `yaml:"app,omitempty" desc:"GitHub App usado pelo pipeline automatizado"`
+}
+
+// GitHubAppSettings GitHub App usado pelo pipeline automatizado. A chave privada não fica
+// no settings.yml: vem do keyring, de GITHUB_APP_PRIVATE_KEY ou do arquivo indicado.
+type GitHubAppSettings struct {
+	AppID          int64  `yaml:"app_id,omitempty" desc:"ID do GitHub App"`
+	InstallationID int64  `yaml:"installation_id,omitempty" desc:"ID da instalação; 0 descobre pela URL do remote origin"`
+	PrivateKeyFile string `yaml:"private_key_file,omitempty" desc:"Arquivo PEM com a chave privada do app"`
+}
+
+// VaultSettings representa a integração com o Vault no modo service.
+// Segredos (secret_id, token) não são lidos daqui: ficam no keyring ou em variáveis de ambiente.
+type VaultSettings struct {
+	Address    string          `yaml:"address,omitempty" desc:"Endereço do Vault"`
+	Namespace  string          `yaml:"namespace,omitempty" desc:"Namespace do Vault Enterprise"`
+	Method     string          `yaml:"method,omitempty" desc:"Método de autenticação"`
+	Mount      string          `yaml:"mount,omitempty" desc:"Caminho do método de auth (padrão: nome do método)"`
+	Role       string          `yaml:"role,omitempty" desc:"Role usada no login"`
+	SecretPath string          `yaml:"secret_path,omitempty" desc:"Caminho do secret com as credenciais StackSpot"`
+	KVVersion  int             `yaml:"kv_version,omitempty" desc:"Versão do KV (1 ou 2); 0 detecta via sys/mounts"`
+	Fields     VaultFields     `yaml:"fields,omitempty" desc:"Nomes dos campos do secret"`
+	AWS        VaultAWS        `yaml:"aws,omitempty" desc:"Opções do login IAM"`
+	AppRole    VaultAppRole    `yaml:"approle,omitempty" desc:"Opções do login AppRole"`
+	JWT        VaultJWT        `yaml:"jwt,omitempty" desc:"Opções do login JWT/OIDC"`
+	Kubernetes VaultKubernetes `yaml:"kubernetes,omitempty" desc:"Opções do login Kubernetes"`
+}
+
+// VaultFields nomes dos campos do secret com as credenciais StackSpot
+type VaultFields struct {
+	ClientID     string `yaml:"client_id,omitempty" desc:"Campo com o client_id (padrão: client_id)"`
+	ClientSecret string `yaml:"client_secret,omitempty" desc:"Campo com o client_secret (padrão: client_secret)"`
+}
+
+// VaultAWS opções do login IAM
+type VaultAWS struct {
+	ServerID  string `yaml:"server_id,omitempty" desc:"Valor do X-Vault-AWS-IAM-Server-ID"`
+	STSRegion string `yaml:"sts_region,omitempty" desc:"Região do STS configurada no mount"`
+}
+
+// VaultAppRole opções do login AppRole
+type VaultAppRole struct {
+	RoleID string `yaml:"role_id,omitempty" desc:"Role ID do AppRole; o secret_id vem do keyring ou de VAULT_SECRET_ID"`
+}
+
+// VaultJWT opções do login JWT/OIDC
+type VaultJWT struct {
+	TokenFile      string `yaml:"token_file,omitempty" desc:"Arquivo com o JWT"`
+	GitHubAudience string `yaml:"github_audience,omitempty" desc:"Audience do ID token do GitHub Actions"`
+}
+
+// VaultKubernetes opções do login Kubernetes
+type VaultKubernetes struct {
+	TokenFile string `yaml:"token_file,omitempty" desc:"Token da service account (padrão: o montado no pod)"`
 }
 
 // AutoConfig representa as configurações automáticas coletadas do ambiente
@@ -46,13 +187,17 @@ type AutoConfig struct {
 // Config representa a configuração completa da aplicação
 type Config struct {
 	Settings   *Settings
+	Origins    map[string]Origin // Camada que definiu cada chave de Settings
 	Auto       *AutoConfig
 	ConfigPath string
+	Migration  *MigrationResult // Migração do settings.yml feita ao carregar; nil sem mudanças
 }
 
-// GetDefaultSettings retorna as configurações padrão
+// GetDefaultSettings retorna as configurações padrão. analysis_files_path é relativo
+// à pasta de configuração (configFolderName), onde fica o settings.yml.
 func GetDefaultSettings(configFolderName string) *Settings {
 	return &Settings{
+		Version: CurrentSettingsVersion,
 		Project: Project{
 			Type: "application",
 			Language: Language{
@@ -61,7 +206,7 @@ func GetDefaultSettings(configFolderName string) *Settings {
 			},
 		},
 		Analysis: Analysis{
-			AnalysisFilesPath: filepath.Join(configFolderName, ".analyzeFiles"),
+			AnalysisFilesPath: DefaultAnalysisFilesName,
 			FileLimits: Limits{
 				MaxFileSize: "10MB",
 				MaxFiles:    1000,
@@ -70,33 +215,186 @@ func GetDefaultSettings(configFolderName string) *Settings {
 	}
 }
 
-// Validate valida as configurações
+// FieldError problema de validação em uma chave do settings.yml
+type FieldError struct {
+	Path    string // chave no YAML, ex.: auth.vault.kv_version ou knowledge.sources[0].name
+	Message string
+	Origin  string // camada que definiu o valor (ex.: repo: .phengineer/settings.yml:12), quando conhecida
+}
+
+func (e FieldError) Error() string {
+	msg := e.Path + " " + e.Message
+	if e.Origin != "" {
+		msg += " (" + e.Origin + ")"
+	}
+	return msg
+}
+
+// SettingsError reúne todos os problemas encontrados na validação, um por linha
+type SettingsError struct {
+	Problems []FieldError
+}
+
+func (e *SettingsError) Error() string {
+	lines := make([]string, len(e.Problems))
+	for i, problem := range e.Problems {
+		lines[i] = problem.Error()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// problems acumula os problemas de validação
+type problems []FieldError
+
+func (p *problems) add(path, format string, args ...any) {
+	*p = append(*p, FieldError{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// err retorna nil sem problemas, ou um *SettingsError com todos eles
+func (p problems) err() error {
+	if len(p) == 0 {
+		return nil
+	}
+	return &SettingsError{Problems: p}
+}
+
+// Validate valida as configurações e reporta todos os problemas de uma vez
 func (s *Settings) Validate() error {
+	return s.problems().err()
+}
+
+func (s *Settings) problems() problems {
+	var p problems
+	if s.Version < 0 || s.Version > CurrentSettingsVersion {
+		p.add("version", "'%d' is not supported, expected up to %d", s.Version, CurrentSettingsVersion)
+	}
+
 	// Valida Project
 	if s.Project.Type == "" {
-		return fmt.Errorf("project.type is required")
+		p.add("project.type", "is required")
 	}
 
 	if s.Project.Language.Name == "" {
-		return fmt.Errorf("project.language.name is required")
+		p.add("project.language.name", "is required")
 	}
 
 	if s.Project.Language.Version == "" {
-		return fmt.Errorf("project.language.version is required")
+		p.add("project.language.version", "is required")
 	}
 
 	// Valida Analysis
 	if s.Analysis.AnalysisFilesPath == "" {
-		return fmt.Errorf("analysis.analyze_files_path is required")
+		p.add("analysis.analysis_files_path", "is required")
 	}
 
 	if s.Analysis.FileLimits.MaxFileSize == "" {
-		return fmt.Errorf("analysis.file_limits.max_file_size is required")
+		p.add("analysis.file_limits.max_file_size", "is required")
+	} else if !sizeRegexp.MatchString(s.Analysis.FileLimits.MaxFileSize) {
+		p.add("analysis.file_limits.max_file_size", "'%s' is invalid, expected a size like 500KB or 10MB", s.Analysis.FileLimits.MaxFileSize)
 	}
 
 	if s.Analysis.FileLimits.MaxFiles == 0 {
-		return fmt.Errorf("analysis.file_limits.max_files is required")
+		p.add("analysis.file_limits.max_files", "is required")
+	} else if s.Analysis.FileLimits.MaxFiles < 0 {
+		p.add("analysis.file_limits.max_files", "'%d' is invalid, expected a positive value", s.Analysis.FileLimits.MaxFiles)
+	}
+
+	p = append(p, s.Knowledge.problems()...)
+	p = append(p, s.Auth.problems()...)
+	return append(p, s.HTTP.problems()...)
+}
+
+// sizeRegexp valida tamanhos no formato de SizePattern
+var sizeRegexp = regexp.MustCompile(SizePattern)
+
+// Validate valida a configuração de rede
+func (h *HTTP) Validate() error {
+	return h.problems().err()
+}
+
+func (h *HTTP) problems() problems {
+	var p problems
+	validateHTTPURL(&p, "http.proxy", h.Proxy)
+	if (h.ClientCertFile == "") != (h.ClientKeyFile == "") {
+		p.add("http.client_cert_file", "and http.client_key_file must be set together")
 	}
+	if h.TimeoutSeconds < 0 {
+		p.add("http.timeout_seconds", "'%d' is invalid, expected a positive value", h.TimeoutSeconds)
+	}
+	return p
+}
 
-	return nil
+// Validate valida as configurações de autenticação
+func (a *Auth) Validate() error {
+	return a.problems().err()
+}
+
+func (a *Auth) problems() problems {
+	var p problems
+	if a.Mode != "" && !slices.Contains(AuthModes, a.Mode) {
+		p.add("auth.mode", "'%s' is invalid, expected %s or %s", a.Mode, AuthModeUser, AuthModeService)
+	}
+	if a.TokenRefreshSkew != "" {
+		if skew, err := time.ParseDuration(a.TokenRefreshSkew); err != nil || skew < 0 {
+			p.add("auth.token_refresh_skew", "'%s' is invalid, expected a duration like 2m", a.TokenRefreshSkew)
+		}
+	}
+	validateHTTPURL(&p, "auth.stackspot.idm_url", a.StackSpot.IdMURL)
+	validateHTTPURL(&p, "auth.github.url", a.GitHub.URL)
+	validateHTTPURL(&p, "auth.github.api_url", a.GitHub.APIURL)
+	return append(p, a.Vault.problems()...)
+}
+
+// validateHTTPURL aceita vazio ou uma URL absoluta http(s)
+func validateHTTPURL(p *problems, field, value string) {
+	if value == "" {
+		return
+	}
+	parsed, err := url.Parse(value)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		p.add(field, "'%s' is invalid, expected an http(s) URL", value)
+	}
+}
+
+// Validate valida a configuração do Vault
+func (v *VaultSettings) Validate() error {
+	return v.problems().err()
+}
+
+func (v *VaultSettings) problems() problems {
+	var p problems
+	if v.KVVersion != 0 && v.KVVersion != 1 && v.KVVersion != 2 {
+		p.add("auth.vault.kv_version", "'%d' is invalid, expected 1 or 2", v.KVVersion)
+	}
+	if v.Method != "" && !slices.Contains(VaultMethods, v.Method) {
+		p.add("auth.vault.method", "'%s' is invalid, expected one of: %s", v.Method, strings.Join(VaultMethods, ", "))
+	}
+	return p
+}
+
+// Validate valida as fontes de conhecimento
+func (k *Knowledge) Validate() error {
+	return k.problems().err()
+}
+
+func (k *Knowledge) problems() problems {
+	var p problems
+	names := make(map[string]bool)
+	for i, source := range k.Sources {
+		path := fmt.Sprintf("knowledge.sources[%d]", i)
+		if source.Name == "" {
+			p.add(path+".name", "is required")
+		} else if names[source.Name] {
+			p.add(path+".name", "'%s' is duplicated", source.Name)
+		}
+		names[source.Name] = true
+
+		if (source.Path == "") == (source.Git == "") {
+			p.add(path, "must define exactly one of path or git")
+		}
+		if source.Ref != "" && source.Git == "" {
+			p.add(path+".ref", "requires git")
+		}
+	}
+	return p
 }
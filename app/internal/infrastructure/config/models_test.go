@@ -42,8 +42,11 @@ func TestGetDefaultSettings(t *testing.T) {
 	if defaults.Analysis.AnalysisFilesPath == "" {
 		t.Error("Default files include path should not be empty")
 	}
-	if defaults.Analysis.AnalysisFilesPath != ".phengineer/.analyzeFiles" {
-		t.Errorf("Expected default analyze path '.phengineer/.analyzeFiles', got '%s'", defaults.Analysis.AnalysisFilesPath)
+	if defaults.Analysis.AnalysisFilesPath != ".analyzefiles" {
+		t.Errorf("Expected default analysis path '.analyzefiles', got '%s'", defaults.Analysis.AnalysisFilesPath)
+	}
+	if defaults.Version != CurrentSettingsVersion {
+		t.Errorf("Expected default version %d, got %d", CurrentSettingsVersion, defaults.Version)
 	}
 
 	// Verifica valores dos Limits
@@ -159,7 +162,7 @@ func TestSettingsValidate(t *testing.T) {
 			errMsg:  "project.language.version is required",
 		},
 		{
-			name: "Missing files include path",
+			name: "Missing analysis files path",
 			settings: &Settings{
 				Project: Project{
 					Type: "application",
@@ -177,7 +180,7 @@ func TestSettingsValidate(t *testing.T) {
 				},
 			},
 			wantErr: true,
-			errMsg:  "analysis.files_include_path is required",
+			errMsg:  "analysis.analysis_files_path is required",
 		},
 		{
 			name: "Missing max file size",
@@ -221,6 +224,94 @@ func TestSettingsValidate(t *testing.T) {
 			wantErr: true,
 			errMsg:  "analysis.file_limits.max_files is required",
 		},
+		{
+			name: "Invalid vault method",
+			settings: &Settings{
+				Project: Project{
+					Type: "application",
+					Language: Language{
+						Name:    "go",
+						Version: "1.21",
+					},
+				},
+				Analysis: Analysis{
+					AnalysisFilesPath: "**/*.go",
+					FileLimits: Limits{
+						MaxFileSize: "10MB",
+						MaxFiles:    1000,
+					},
+				},
+				Auth: Auth{Vault: VaultSettings{Method: "ldap"}},
+			},
+			wantErr: true,
+			errMsg:  "auth.vault.method 'ldap' is invalid, expected one of: aws, approle, jwt, kubernetes, token",
+		},
+		{
+			name: "Invalid vault KV version",
+			settings: &Settings{
+				Project: Project{
+					Type: "application",
+					Language: Language{
+						Name:    "go",
+						Version: "1.21",
+					},
+				},
+				Analysis: Analysis{
+					AnalysisFilesPath: "**/*.go",
+					FileLimits: Limits{
+						MaxFileSize: "10MB",
+						MaxFiles:    1000,
+					},
+				},
+				Auth: Auth{Vault: VaultSettings{KVVersion: 3}},
+			},
+			wantErr: true,
+			errMsg:  "auth.vault.kv_version '3' is invalid, expected 1 or 2",
+		},
+		{
+			name: "Invalid StackSpot IdM URL",
+			settings: &Settings{
+				Project: Project{
+					Type: "application",
+					Language: Language{
+						Name:    "go",
+						Version: "1.21",
+					},
+				},
+				Analysis: Analysis{
+					AnalysisFilesPath: "**/*.go",
+					FileLimits: Limits{
+						MaxFileSize: "10MB",
+						MaxFiles:    1000,
+					},
+				},
+				Auth: Auth{StackSpot: StackSpotSettings{IdMURL: "idm.local/realms/test"}},
+			},
+			wantErr: true,
+			errMsg:  "auth.stackspot.idm_url 'idm.local/realms/test' is invalid, expected an http(s) URL",
+		},
+		{
+			name: "Client certificate without key",
+			settings: &Settings{
+				Project: Project{
+					Type: "application",
+					Language: Language{
+						Name:    "go",
+						Version: "1.21",
+					},
+				},
+				Analysis: Analysis{
+					AnalysisFilesPath: "**/*.go",
+					FileLimits: Limits{
+						MaxFileSize: "10MB",
+						MaxFiles:    1000,
+					},
+				},
+				HTTP: HTTP{ClientCertFile: "/certs/client.pem"},
+			},
+			wantErr: true,
+			errMsg:  "http.client_cert_file and http.client_key_file must be set together",
+		},
 	}
 
 	for _, tt := range tests {
@@ -277,8 +368,7 @@ func TestSettingsYAMLSerialization(t *testing.T) {
 		"name: rust",
 		"version: \"1.70\"",
 		"analysis:",
-		"files_include_path: src/**/*.rs",
-		"files_exclude_path: target/**",
+		"analysis_files_path: src/**/*.rs",
 		"file_limits:",
 		"max_file_size: 15MB",
 		"max_files: 1500",
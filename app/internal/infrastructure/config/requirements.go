@@ -1,16 +1,25 @@
 package config
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
-	"strings"
+	"slices"
+
+	"github.com/PHRaulino/phengineer/internal/infrastructure/gitrepo"
 )
 
 // RequirementsValidator é responsável por validar os pré-requisitos da CLI
 type RequirementsValidator struct {
 	ConfigFolderName string
+
+	ctx        context.Context
+	repository gitrepo.Repository
+	repoErr    error
+	repoOpened bool
 }
 
 // NewRequirementsValidator cria uma nova instância do validador
@@ -20,6 +29,34 @@ func NewRequirementsValidator(configFolderName string) *RequirementsValidator {
 	}
 }
 
+// WithContext lê o repositório do context (gitrepo.WithRepository) em vez de abrir o do diretório atual
+func (rv *RequirementsValidator) WithContext(ctx context.Context) *RequirementsValidator {
+	rv.ctx = ctx
+	return rv
+}
+
+// WithRepository usa o repositório já aberto em vez do diretório atual; nil indica fora do git
+func (rv *RequirementsValidator) WithRepository(repo gitrepo.Repository) *RequirementsValidator {
+	rv.repository, rv.repoErr, rv.repoOpened = repo, nil, true
+	if repo == nil {
+		rv.repoErr = gitrepo.ErrNotRepository
+	}
+	return rv
+}
+
+// repo retorna o repositório validado, lido do context na primeira chamada
+func (rv *RequirementsValidator) repo() (gitrepo.Repository, error) {
+	if !rv.repoOpened {
+		ctx := rv.ctx
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		rv.repository, rv.repoErr = gitrepo.FromContext(ctx)
+		rv.repoOpened = true
+	}
+	return rv.repository, rv.repoErr
+}
+
 // ValidationError representa um erro de validação de requirement
 type ValidationError struct {
 	Requirement string
@@ -30,69 +67,129 @@ func (e ValidationError) Error() string {
 	return fmt.Sprintf("Requirement '%s' failed: %s", e.Requirement, e.Message)
 }
 
+// Feature funcionalidade da CLI que depende de parte dos requirements
+type Feature string
+
+const (
+	// FeatureDiscovery descoberta e análise de arquivos; funciona fora do git
+	FeatureDiscovery Feature = "discovery"
+	// FeatureGitHistory detecção de mudanças pelo commit de cada arquivo
+	FeatureGitHistory Feature = "git-history"
+	// FeatureRemote recursos ligados ao repositório remoto (instalação do GitHub App,
+	// permissões do token no repositório, issues e pull requests)
+	FeatureRemote Feature = "remote"
+)
+
+// Features todas as funcionalidades, na ordem em que são exibidas
+var Features = []Feature{FeatureDiscovery, FeatureGitHistory, FeatureRemote}
+
+// Description descreve a funcionalidade e o que acontece sem ela
+func (f Feature) Description() string {
+	switch f {
+	case FeatureDiscovery:
+		return "File discovery and analysis"
+	case FeatureGitHistory:
+		return "Change detection by commit (without it, by modification time and content digest)"
+	case FeatureRemote:
+		return "Remote repository features: GitHub App installation, token permissions, issues and pull requests"
+	}
+	return string(f)
+}
+
 // RequirementStatus representa o status de um requirement
 type RequirementStatus struct {
 	Name        string
 	Passed      bool
 	ErrorMsg    string
 	Description string
+	Features    []Feature // funcionalidades que dependem do requirement
 }
 
-// ValidateAll executa todas as validações necessárias
-func (rv *RequirementsValidator) ValidateAll() error {
-	validators := []func() error{
-		rv.validateGitInstalled,
-		rv.validateInGitRepository,
-		rv.validateGitRemoteExists,
-		rv.validateConfigFolder,
-	}
-
-	for _, validator := range validators {
-		if err := validator(); err != nil {
-			return err
-		}
-	}
+// Capability indica se uma funcionalidade está disponível no ambiente atual
+type Capability struct {
+	Feature   Feature
+	Available bool
+	Missing   []RequirementStatus // requirements que falharam
+}
 
-	return nil
+// requirement pré-requisito e as funcionalidades que dependem dele
+type requirement struct {
+	name        string
+	description string
+	features    []Feature
+	validator   func() error
 }
 
-// ValidateWithDetails executa todas as validações e retorna detalhes completos
-func (rv *RequirementsValidator) ValidateWithDetails() ([]RequirementStatus, error) {
-	requirements := []struct {
-		name        string
-		description string
-		validator   func() error
-	}{
+// requirements lista os pré-requisitos na ordem em que são verificados
+func (rv *RequirementsValidator) requirements() []requirement {
+	// O repositório é lido com go-git; o binário só é exigido com PHENGINEER_GIT=cli
+	var gitBinaryFeatures []Feature
+	if gitrepo.Backend(os.Getenv(gitrepo.BackendEnv)) == gitrepo.BackendCLI {
+		gitBinaryFeatures = []Feature{FeatureGitHistory, FeatureRemote}
+	}
+
+	return []requirement{
 		{
 			name:        "Git Installation",
 			description: "Git must be installed and available in PATH",
+			features:    gitBinaryFeatures,
 			validator:   rv.validateGitInstalled,
 		},
 		{
 			name:        "Git Repository",
 			description: "Must be executed within a Git repository",
+			features:    []Feature{FeatureGitHistory, FeatureRemote},
 			validator:   rv.validateInGitRepository,
 		},
 		{
 			name:        "Git Remote",
 			description: "Repository must have at least one remote configured",
+			features:    []Feature{FeatureRemote},
 			validator:   rv.validateGitRemoteExists,
 		},
 		{
 			name:        "Config Folder",
-			description: fmt.Sprintf("Config folder '%s' must exist in repository root", rv.ConfigFolderName),
+			description: fmt.Sprintf("Config folder '%s' must exist in the project root (created by the first analysis)", rv.ConfigFolderName),
+			features:    []Feature{FeatureDiscovery},
 			validator:   rv.validateConfigFolder,
 		},
 	}
+}
+
+// ValidateAll executa todas as validações, inclusive as de funcionalidades opcionais
+func (rv *RequirementsValidator) ValidateAll() error {
+	for _, req := range rv.requirements() {
+		if err := req.validator(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
+// Require valida apenas os requirements das funcionalidades informadas
+func (rv *RequirementsValidator) Require(features ...Feature) error {
+	for _, req := range rv.requirements() {
+		if !slices.ContainsFunc(req.features, func(f Feature) bool { return slices.Contains(features, f) }) {
+			continue
+		}
+		if err := req.validator(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidateWithDetails executa todas as validações e retorna detalhes completos
+func (rv *RequirementsValidator) ValidateWithDetails() ([]RequirementStatus, error) {
 	var results []RequirementStatus
 	var firstError error
 
-	for _, req := range requirements {
+	for _, req := range rv.requirements() {
 		status := RequirementStatus{
 			Name:        req.name,
 			Description: req.description,
 			Passed:      true,
+			Features:    req.features,
 		}
 
 		if err := req.validator(); err != nil {
@@ -110,6 +207,24 @@ func (rv *RequirementsValidator) ValidateWithDetails() ([]RequirementStatus, err
 	return results, firstError
 }
 
+// Capabilities indica quais funcionalidades estão disponíveis e, para as demais, o que falta
+func (rv *RequirementsValidator) Capabilities() []Capability {
+	results, _ := rv.ValidateWithDetails()
+
+	capabilities := make([]Capability, 0, len(Features))
+	for _, feature := range Features {
+		capability := Capability{Feature: feature, Available: true}
+		for _, result := range results {
+			if !result.Passed && slices.Contains(result.Features, feature) {
+				capability.Available = false
+				capability.Missing = append(capability.Missing, result)
+			}
+		}
+		capabilities = append(capabilities, capability)
+	}
+	return capabilities
+}
+
 // validateGitInstalled verifica se o Git está instalado no sistema
 func (rv *RequirementsValidator) validateGitInstalled() error {
 	_, err := exec.LookPath("git")
@@ -124,19 +239,17 @@ func (rv *RequirementsValidator) validateGitInstalled() error {
 
 // validateInGitRepository verifica se estamos dentro de um repositório Git
 func (rv *RequirementsValidator) validateInGitRepository() error {
-	cmd := exec.Command("git", "rev-parse", "--is-inside-work-tree")
-	output, err := cmd.CombinedOutput()
-	if err != nil {
+	_, err := rv.repo()
+	if errors.Is(err, gitrepo.ErrNotRepository) {
 		return ValidationError{
 			Requirement: "Git Repository",
 			Message:     "Not inside a Git repository",
 		}
 	}
-
-	if strings.TrimSpace(string(output)) != "true" {
+	if err != nil {
 		return ValidationError{
 			Requirement: "Git Repository",
-			Message:     "Not inside a Git working tree",
+			Message:     fmt.Sprintf("Failed to open Git repository: %v", err),
 		}
 	}
 
@@ -145,8 +258,15 @@ func (rv *RequirementsValidator) validateInGitRepository() error {
 
 // validateGitRemoteExists verifica se há pelo menos um remote configurado
 func (rv *RequirementsValidator) validateGitRemoteExists() error {
-	cmd := exec.Command("git", "remote")
-	output, err := cmd.CombinedOutput()
+	repo, err := rv.repo()
+	if err != nil {
+		return ValidationError{
+			Requirement: "Git Remote",
+			Message:     "Failed to check Git remotes",
+		}
+	}
+
+	remotes, err := repo.Remotes()
 	if err != nil {
 		return ValidationError{
 			Requirement: "Git Remote",
@@ -154,8 +274,7 @@ func (rv *RequirementsValidator) validateGitRemoteExists() error {
 		}
 	}
 
-	remotes := strings.TrimSpace(string(output))
-	if remotes == "" {
+	if len(remotes) == 0 {
 		return ValidationError{
 			Requirement: "Git Remote",
 			Message:     "No Git remotes configured",
@@ -165,22 +284,24 @@ func (rv *RequirementsValidator) validateGitRemoteExists() error {
 	return nil
 }
 
-// validateConfigFolder verifica se a pasta de config existe na raiz do repositório
+// validateConfigFolder verifica se a pasta de config existe na raiz do projeto
+// (a raiz do repositório git ou, fora do git, o diretório que contém a pasta)
 func (rv *RequirementsValidator) validateConfigFolder() error {
-	gitRoot, err := rv.getGitRoot()
+	repo, _ := rv.repo()
+	root, err := getRootPath(repo, rv.ConfigFolderName)
 	if err != nil {
 		return ValidationError{
 			Requirement: "Config Folder",
-			Message:     fmt.Sprintf("Failed to find Git root: %v", err),
+			Message:     fmt.Sprintf("Failed to find project root: %v", err),
 		}
 	}
 
-	configPath := filepath.Join(gitRoot, rv.ConfigFolderName)
+	configPath := filepath.Join(root, rv.ConfigFolderName)
 
 	if _, err := os.Stat(configPath); os.IsNotExist(err) {
 		return ValidationError{
 			Requirement: "Config Folder",
-			Message:     fmt.Sprintf("Config folder '%s' not found in Git root (%s)", rv.ConfigFolderName, gitRoot),
+			Message:     fmt.Sprintf("Config folder '%s' not found in project root (%s)", rv.ConfigFolderName, root),
 		}
 	}
 
@@ -189,13 +310,12 @@ func (rv *RequirementsValidator) validateConfigFolder() error {
 
 // getGitRoot retorna o caminho para a raiz do repositório Git
 func (rv *RequirementsValidator) getGitRoot() (string, error) {
-	cmd := exec.Command("git", "rev-parse", "--show-toplevel")
-	output, err := cmd.CombinedOutput()
+	repo, err := rv.repo()
 	if err != nil {
-		return "", fmt.Errorf("failed to get Git root: %v", err)
+		return "", fmt.Errorf("failed to get Git root: %w", err)
 	}
 
-	return strings.TrimSpace(string(output)), nil
+	return repo.Root(), nil
 }
 
 // GetGitRoot é a versão pública do método para obter a raiz do Git
@@ -206,18 +326,9 @@ func (rv *RequirementsValidator) GetGitRoot() (string, error) {
 // GetValidationSummary retorna um resumo do status de todos os requirements
 func (rv *RequirementsValidator) GetValidationSummary() map[string]bool {
 	summary := make(map[string]bool)
-
-	validators := map[string]func() error{
-		"Git Installation": rv.validateGitInstalled,
-		"Git Repository":   rv.validateInGitRepository,
-		"Git Remote":       rv.validateGitRemoteExists,
-		"Config Folder":    rv.validateConfigFolder,
-	}
-
-	for name, validator := range validators {
-		summary[name] = validator() == nil
+	for _, req := range rv.requirements() {
+		summary[req.name] = req.validator() == nil
 	}
-
 	return summary
 }
 
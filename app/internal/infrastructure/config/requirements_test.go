@@ -6,6 +6,8 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/PHRaulino/phengineer/internal/infrastructure/gitrepo"
 )
 
 // TestNewRequirementsValidator testa a criação de uma nova instância
@@ -329,6 +331,91 @@ func TestValidateAll_WithMockConfigFolder(t *testing.T) {
 	}
 }
 
+// TestRequireOutsideGit testa que a descoberta funciona fora do git e os recursos do remote não
+func TestRequireOutsideGit(t *testing.T) {
+	tempDir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(tempDir, "config"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	originalDir, _ := os.Getwd()
+	if err := os.Chdir(tempDir); err != nil {
+		t.Fatalf("Failed to change directory: %v", err)
+	}
+	defer os.Chdir(originalDir)
+
+	validator := NewRequirementsValidator("config")
+	if err := validator.Require(FeatureDiscovery); err != nil {
+		t.Errorf("Require(discovery) error = %v, want nil outside git", err)
+	}
+	if err := validator.Require(FeatureRemote); err == nil {
+		t.Error("Require(remote) error = nil, want failure outside git")
+	}
+
+	available := make(map[Feature]bool)
+	for _, capability := range validator.Capabilities() {
+		available[capability.Feature] = capability.Available
+		if capability.Available != (len(capability.Missing) == 0) {
+			t.Errorf("capability %s: Available = %t with %d missing requirements", capability.Feature, capability.Available, len(capability.Missing))
+		}
+	}
+	want := map[Feature]bool{FeatureDiscovery: true, FeatureGitHistory: false, FeatureRemote: false}
+	for feature, wantAvailable := range want {
+		if available[feature] != wantAvailable {
+			t.Errorf("capability %s available = %t, want %t", feature, available[feature], wantAvailable)
+		}
+	}
+}
+
+// TestRequirementsWithRepository testa os requirements com o repositório injetado
+func TestRequirementsWithRepository(t *testing.T) {
+	root := t.TempDir()
+	if err := os.Mkdir(filepath.Join(root, "config"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		repo        gitrepo.Repository
+		wantPassed  map[string]bool
+		wantFeature map[Feature]bool
+	}{
+		{
+			name:        "Repository with remote",
+			repo:        gitrepo.NewMemoryRepository(root).WithRemote("origin", "https://github.com/org/app.git"),
+			wantPassed:  map[string]bool{"Git Repository": true, "Git Remote": true, "Config Folder": true},
+			wantFeature: map[Feature]bool{FeatureDiscovery: true, FeatureGitHistory: true, FeatureRemote: true},
+		},
+		{
+			name:        "Repository without remote",
+			repo:        gitrepo.NewMemoryRepository(root),
+			wantPassed:  map[string]bool{"Git Repository": true, "Git Remote": false, "Config Folder": true},
+			wantFeature: map[Feature]bool{FeatureDiscovery: true, FeatureGitHistory: true, FeatureRemote: false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			validator := NewRequirementsValidator("config").WithRepository(tt.repo)
+
+			summary := validator.GetValidationSummary()
+			for name, want := range tt.wantPassed {
+				if summary[name] != want {
+					t.Errorf("requirement %s passed = %t, want %t", name, summary[name], want)
+				}
+			}
+			for _, capability := range validator.Capabilities() {
+				if want := tt.wantFeature[capability.Feature]; capability.Available != want {
+					t.Errorf("capability %s available = %t, want %t", capability.Feature, capability.Available, want)
+				}
+			}
+			if gitRoot, err := validator.GetGitRoot(); err != nil || gitRoot != root {
+				t.Errorf("GetGitRoot() = %q, %v, want %q", gitRoot, err, root)
+			}
+		})
+	}
+}
+
 // TestRequirementStatus testa a estrutura RequirementStatus
 func TestRequirementStatus(t *testing.T) {
 	status := RequirementStatus{
@@ -0,0 +1,190 @@
+package config
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+// SchemaFileName nome do JSON Schema gravado ao lado do settings.yml
+const SchemaFileName = "settings.schema.json"
+
+// schemaModeline comentário que liga o settings.yml ao schema na extensão YAML do VS Code
+const schemaModeline = "# yaml-language-server: $schema="
+
+//go:embed settings.schema.json
+var embeddedSchema []byte
+
+// durationPattern formato aceito por time.ParseDuration (ex.: 90s, 2m, 1h30m)
+const durationPattern = `^([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$`
+
+// urlPattern URLs http(s) aceitas pelos provedores
+const urlPattern = `^https?://[^/]+`
+
+// schemaEnums valores aceitos por chave; as mesmas listas usadas pelo Validate
+var schemaEnums = map[string][]string{
+	"project.type":      ProjectTypes,
+	"auth.mode":         AuthModes,
+	"auth.vault.method": VaultMethods,
+}
+
+// schemaPatterns expressões regulares por chave
+var schemaPatterns = map[string]string{
+	"analysis.file_limits.max_file_size": SizePattern,
+	"auth.token_refresh_skew":            durationPattern,
+	"auth.stackspot.idm_url":             urlPattern,
+	"auth.github.url":                    urlPattern,
+	"auth.github.api_url":                urlPattern,
+	"auth.vault.address":                 urlPattern,
+	"http.proxy":                         urlPattern,
+}
+
+// schemaRanges limites das chaves numéricas ([mínimo, máximo])
+var schemaRanges = map[string][2]int{
+	"version":               {0, CurrentSettingsVersion},
+	"auth.vault.kv_version": {0, 2},
+	"http.timeout_seconds":  {0, 3600},
+}
+
+// SettingsSchema retorna o JSON Schema do settings.yml embutido no binário
+func SettingsSchema() []byte {
+	return embeddedSchema
+}
+
+// GenerateSettingsSchema gera o JSON Schema do settings.yml a partir das structs de Settings:
+// a tag desc vira a descrição e schemaEnums, schemaPatterns e schemaRanges as restrições.
+// Nenhuma chave é obrigatória porque as camadas de configuração completam o arquivo.
+func GenerateSettingsSchema() ([]byte, error) {
+	schema := schemaFor(reflect.TypeOf(Settings{}), "")
+	schema.Schema = "http://json-schema.org/draft-07/schema#"
+	schema.ID = "https://github.com/PHRaulino/phengineer/settings.schema.json"
+	schema.Title = "PHEngineer settings.yml"
+	schema.Description = "Configuração do PHEngineer (.phengineer/settings.yml e ~/.config/phengineer/config.yml)"
+
+	data, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal settings schema: %w", err)
+	}
+	return append(data, '\n'), nil
+}
+
+// jsonSchema subconjunto do JSON Schema (draft-07) usado pelo settings.yml
+type jsonSchema struct {
+	Schema               string           `json:"$schema,omitempty"`
+	ID                   string           `json:"$id,omitempty"`
+	Title                string           `json:"title,omitempty"`
+	Description          string           `json:"description,omitempty"`
+	Type                 string           `json:"type,omitempty"`
+	Enum                 []string         `json:"enum,omitempty"`
+	Pattern              string           `json:"pattern,omitempty"`
+	Minimum              *int             `json:"minimum,omitempty"`
+	Maximum              *int             `json:"maximum,omitempty"`
+	Items                *jsonSchema      `json:"items,omitempty"`
+	Properties           schemaProperties `json:"properties,omitempty"`
+	AdditionalProperties *bool            `json:"additionalProperties,omitempty"`
+}
+
+// schemaProperties propriedades na ordem dos campos da struct
+type schemaProperties []schemaProperty
+
+type schemaProperty struct {
+	name   string
+	schema *jsonSchema
+}
+
+func (p schemaProperties) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, property := range p {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		name, _ := json.Marshal(property.name)
+		value, err := json.Marshal(property.schema)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// schemaFor descreve um tipo Go; path é a chave no YAML, usada para achar as restrições
+func schemaFor(t reflect.Type, path string) *jsonSchema {
+	schema := &jsonSchema{}
+	switch t.Kind() {
+	case reflect.Struct:
+		closed := false
+		schema.Type = "object"
+		schema.AdditionalProperties = &closed
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+			if name == "" || name == "-" {
+				continue
+			}
+			property := schemaFor(field.Type, joinKeyPath(path, name))
+			property.Description = field.Tag.Get("desc")
+			schema.Properties = append(schema.Properties, schemaProperty{name: name, schema: property})
+		}
+	case reflect.Slice:
+		schema.Type = "array"
+		schema.Items = schemaFor(t.Elem(), path+"[]")
+	case reflect.Int, reflect.Int64:
+		schema.Type = "integer"
+	case reflect.Bool:
+		schema.Type = "boolean"
+	default:
+		schema.Type = "string"
+	}
+
+	schema.Enum = schemaEnums[path]
+	schema.Pattern = schemaPatterns[path]
+	if limits, ok := schemaRanges[path]; ok {
+		schema.Minimum, schema.Maximum = &limits[0], &limits[1]
+	}
+	return schema
+}
+
+// WriteSettingsSchema grava o schema embutido e liga o settings.yml a ele com o comentário
+// lido pela extensão YAML do VS Code. Retorna se o settings.yml foi alterado.
+func WriteSettingsSchema(schemaPath, settingsPath string) (bool, error) {
+	if err := os.MkdirAll(filepath.Dir(schemaPath), 0o755); err != nil {
+		return false, fmt.Errorf("failed to create schema directory: %w", err)
+	}
+	if err := os.WriteFile(schemaPath, SettingsSchema(), 0o644); err != nil {
+		return false, fmt.Errorf("failed to write settings schema: %w", err)
+	}
+
+	if settingsPath == "" {
+		return false, nil
+	}
+	data, err := os.ReadFile(settingsPath)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read settings file: %w", err)
+	}
+	if bytes.Contains(data, []byte(schemaModeline)) {
+		return false, nil
+	}
+
+	ref, err := filepath.Rel(filepath.Dir(settingsPath), schemaPath)
+	if err != nil {
+		ref = schemaPath
+	}
+	modeline := schemaModeline + filepath.ToSlash(ref) + "\n"
+	if err := os.WriteFile(settingsPath, append([]byte(modeline), data...), 0o644); err != nil {
+		return false, fmt.Errorf("failed to write settings file: %w", err)
+	}
+	return true, nil
+}
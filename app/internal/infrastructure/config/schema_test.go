@@ -0,0 +1,97 @@
+package config
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var updateSchema = flag.Bool("update", false, "regenera settings.schema.json a partir das structs")
+
+// TestSettingsSchemaUpToDate testa se o schema embutido corresponde às structs.
+// Para regenerar: go test ./internal/infrastructure/config -run TestSettingsSchemaUpToDate -update
+func TestSettingsSchemaUpToDate(t *testing.T) {
+	generated, err := GenerateSettingsSchema()
+	if err != nil {
+		t.Fatalf("GenerateSettingsSchema() error = %v", err)
+	}
+
+	if *updateSchema {
+		if err := os.WriteFile(SchemaFileName, generated, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	if string(generated) != string(SettingsSchema()) {
+		t.Error("settings.schema.json is outdated, run the test with -update")
+	}
+}
+
+// TestSettingsSchemaContent testa descrições, enums, padrões e o bloqueio de chaves desconhecidas
+func TestSettingsSchemaContent(t *testing.T) {
+	var schema map[string]any
+	if err := json.Unmarshal(SettingsSchema(), &schema); err != nil {
+		t.Fatalf("schema is not valid JSON: %v", err)
+	}
+
+	property := func(path string) map[string]any {
+		node := schema
+		for _, key := range strings.Split(path, ".") {
+			properties, _ := node["properties"].(map[string]any)
+			node, _ = properties[key].(map[string]any)
+			if node == nil {
+				t.Fatalf("property %s not found", path)
+			}
+		}
+		return node
+	}
+
+	projectType := property("project.type")
+	if enum, _ := projectType["enum"].([]any); len(enum) != len(ProjectTypes) {
+		t.Errorf("project.type enum = %v, want %v", projectType["enum"], ProjectTypes)
+	}
+	if projectType["description"] == "" {
+		t.Error("project.type has no description")
+	}
+	if property("analysis.file_limits.max_file_size")["pattern"] != SizePattern {
+		t.Errorf("max_file_size pattern = %v, want %s", property("analysis.file_limits.max_file_size")["pattern"], SizePattern)
+	}
+	if property("analysis")["additionalProperties"] != false {
+		t.Error("analysis allows unknown keys, want additionalProperties false")
+	}
+	if _, ok := schema["required"]; ok {
+		t.Error("schema has required keys, want partial files allowed")
+	}
+}
+
+// TestWriteSettingsSchema testa a gravação do schema e o comentário lido pelo VS Code
+func TestWriteSettingsSchema(t *testing.T) {
+	dir := t.TempDir()
+	settingsPath := filepath.Join(dir, "settings.yml")
+	schemaPath := filepath.Join(dir, SchemaFileName)
+	writeFile(t, settingsPath, "version: 1\nproject:\n  type: api\n")
+
+	for i, wantLinked := range []bool{true, false} {
+		linked, err := WriteSettingsSchema(schemaPath, settingsPath)
+		if err != nil {
+			t.Fatalf("WriteSettingsSchema() error = %v", err)
+		}
+		if linked != wantLinked {
+			t.Errorf("call %d linked = %t, want %t", i, linked, wantLinked)
+		}
+	}
+
+	data, _ := os.ReadFile(settingsPath)
+	if !strings.HasPrefix(string(data), "# yaml-language-server: $schema=settings.schema.json\nversion: 1") {
+		t.Errorf("settings.yml = %q, want modeline on top", data)
+	}
+	if _, err := LoadSettingsFromFile(settingsPath); err != nil && strings.Contains(err.Error(), "parse") {
+		t.Errorf("modeline breaks parsing: %v", err)
+	}
+	if schema, _ := os.ReadFile(schemaPath); string(schema) != string(SettingsSchema()) {
+		t.Error("written schema differs from the embedded one")
+	}
+}
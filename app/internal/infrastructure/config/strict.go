@@ -0,0 +1,110 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// parseSettingsDocument lê um settings.yml, aplica em memória as migrações pendentes e
+// rejeita chaves desconhecidas, indicando arquivo, linha e coluna de cada uma
+func parseSettingsDocument(data []byte, filePath, configFolderName string) (*yaml.Node, *MigrationResult, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", filePath, err)
+	}
+
+	result, err := migrateDocument(&doc, configFolderName)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", filePath, err)
+	}
+
+	root := documentRoot(&doc)
+	if root == nil {
+		return &doc, result, nil
+	}
+	if err := checkKnownKeys(root, reflect.TypeOf(Settings{}), "", filePath); err != nil {
+		return nil, nil, err
+	}
+	return &doc, result, nil
+}
+
+// decodeSettings decodifica um settings.yml no formato atual, sem validar os valores
+func decodeSettings(data []byte, filePath, configFolderName string) (*Settings, error) {
+	doc, _, err := parseSettingsDocument(data, filePath, configFolderName)
+	if err != nil {
+		return nil, err
+	}
+
+	var settings Settings
+	if documentRoot(doc) == nil {
+		return &settings, nil
+	}
+	if err := doc.Decode(&settings); err != nil {
+		return nil, fmt.Errorf("%s: %w", filePath, err)
+	}
+	return &settings, nil
+}
+
+// checkKnownKeys confere as chaves do YAML contra as tags yaml da struct e reúne todas as desconhecidas
+func checkKnownKeys(node *yaml.Node, t reflect.Type, path, filePath string) error {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case t.Kind() == reflect.Struct && node.Kind == yaml.MappingNode:
+		fields := yamlFields(t)
+		var errs []error
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			keyPath := joinKeyPath(path, key.Value)
+
+			field, ok := fields[key.Value]
+			if !ok {
+				errs = append(errs, fmt.Errorf("%s:%d:%d: unknown key '%s'", filePath, key.Line, key.Column, keyPath))
+				continue
+			}
+			if err := checkKnownKeys(value, field, keyPath, filePath); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		return errors.Join(errs...)
+
+	case t.Kind() == reflect.Slice && node.Kind == yaml.SequenceNode:
+		var errs []error
+		for i, item := range node.Content {
+			if err := checkKnownKeys(item, t.Elem(), fmt.Sprintf("%s[%d]", path, i), filePath); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		return errors.Join(errs...)
+	}
+
+	// Escalares e tipos incompatíveis ficam a cargo do decoder do YAML
+	return nil
+}
+
+// yamlFields mapeia as chaves yaml da struct para o tipo de cada campo
+func yamlFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		fields[name] = field.Type
+	}
+	return fields
+}
+
+func joinKeyPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
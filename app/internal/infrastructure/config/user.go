@@ -0,0 +1,99 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// UserConfigFileName é o nome do arquivo de configuração do usuário
+const UserConfigFileName = "config.yml"
+
+// userSettings representa as seções suportadas no arquivo do usuário
+type userSettings struct {
+	Knowledge Knowledge `yaml:"knowledge"`
+}
+
+// UserConfigDir retorna o diretório de configuração do usuário ($XDG_CONFIG_HOME/phengineer)
+func UserConfigDir() (string, error) {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "phengineer"), nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, ".config", "phengineer"), nil
+}
+
+// UserCacheDir retorna o diretório de cache do usuário ($XDG_CACHE_HOME/phengineer)
+func UserCacheDir() (string, error) {
+	if dir := os.Getenv("XDG_CACHE_HOME"); dir != "" {
+		return filepath.Join(dir, "phengineer"), nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, ".cache", "phengineer"), nil
+}
+
+// LoadUserKnowledge carrega as fontes de conhecimento do arquivo do usuário.
+// Caminhos relativos são resolvidos a partir do diretório de configuração.
+func LoadUserKnowledge() (Knowledge, error) {
+	dir, err := UserConfigDir()
+	if err != nil {
+		return Knowledge{}, err
+	}
+
+	filePath := filepath.Join(dir, UserConfigFileName)
+	data, err := os.ReadFile(filePath)
+	if os.IsNotExist(err) {
+		return Knowledge{}, nil
+	}
+	if err != nil {
+		return Knowledge{}, fmt.Errorf("failed to read user config: %w", err)
+	}
+
+	var user userSettings
+	if err := yaml.Unmarshal(data, &user); err != nil {
+		return Knowledge{}, fmt.Errorf("failed to parse user config: %w", err)
+	}
+
+	if err := user.Knowledge.Validate(); err != nil {
+		return Knowledge{}, fmt.Errorf("user config validation failed: %w", err)
+	}
+
+	user.Knowledge.ResolvePaths(dir)
+	return user.Knowledge, nil
+}
+
+// ResolvePaths converte os caminhos locais das fontes em absolutos
+func (k *Knowledge) ResolvePaths(baseDir string) {
+	for i := range k.Sources {
+		k.Sources[i].Path = ExpandPath(k.Sources[i].Path, baseDir)
+	}
+}
+
+// ExpandPath expande ~ e resolve caminhos relativos a partir de baseDir
+func ExpandPath(path, baseDir string) string {
+	if path == "" {
+		return ""
+	}
+
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, strings.TrimPrefix(path, "~"))
+		}
+	}
+
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(baseDir, path)
+	}
+	return filepath.Clean(path)
+}
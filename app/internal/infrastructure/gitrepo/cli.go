@@ -0,0 +1,151 @@
+package gitrepo
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// CLIRepository lê o repositório executando o binário do git
+type CLIRepository struct {
+	root string
+}
+
+// OpenCLI abre com o binário do git o repositório que contém dir
+func OpenCLI(dir string) (*CLIRepository, error) {
+	if _, err := exec.LookPath("git"); err != nil {
+		return nil, fmt.Errorf("git is not installed or not available in PATH: %w", err)
+	}
+
+	output, err := runGit(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, ErrNotRepository
+	}
+	return &CLIRepository{root: output}, nil
+}
+
+func (r *CLIRepository) Root() string {
+	return r.root
+}
+
+func (r *CLIRepository) Head() (string, error) {
+	output, err := runGit(r.root, "rev-parse", "--verify", "-q", "HEAD")
+	if err != nil {
+		return "", ErrNoCommits
+	}
+	return output, nil
+}
+
+func (r *CLIRepository) Branch() (string, error) {
+	output, err := runGit(r.root, "symbolic-ref", "--short", "-q", "HEAD")
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		// HEAD destacado
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return output, nil
+}
+
+func (r *CLIRepository) Remotes() ([]Remote, error) {
+	output, err := runGit(r.root, "config", "--get-regexp", `^remote\..*\.url$`)
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		// Nenhum remote configurado
+		return []Remote{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	remotes := make([]Remote, 0)
+	for _, line := range strings.Split(output, "\n") {
+		key, url, ok := strings.Cut(line, " ")
+		if !ok {
+			continue
+		}
+		name := strings.TrimSuffix(strings.TrimPrefix(key, "remote."), ".url")
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		remotes = append(remotes, Remote{Name: name, URL: url})
+	}
+	sort.Slice(remotes, func(i, j int) bool { return remotes[i].Name < remotes[j].Name })
+	return remotes, nil
+}
+
+func (r *CLIRepository) IsDirty() (bool, error) {
+	output, err := runGit(r.root, "status", "--porcelain")
+	if err != nil {
+		return false, err
+	}
+	return output != "", nil
+}
+
+// LastCommits executa um git log por caminho
+func (r *CLIRepository) LastCommits(paths ...string) (map[string]string, error) {
+	result := make(map[string]string, len(paths))
+	if _, err := r.Head(); err != nil {
+		return result, nil
+	}
+
+	for _, path := range paths {
+		output, err := runGit(r.root, "log", "-1", "--format=%H", "--", filepath.ToSlash(path))
+		if err != nil {
+			return nil, err
+		}
+		if output != "" {
+			result[path] = output
+		}
+	}
+	return result, nil
+}
+
+func (r *CLIRepository) Diff(from, to string) ([]Change, error) {
+	output, err := runGit(r.root, "diff", "--name-status", "--no-renames", from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make([]Change, 0)
+	for _, line := range strings.Split(output, "\n") {
+		status, path, ok := strings.Cut(line, "\t")
+		if !ok {
+			continue
+		}
+		action := ChangeModified
+		switch status {
+		case "A":
+			action = ChangeAdded
+		case "D":
+			action = ChangeDeleted
+		}
+		changes = append(changes, Change{Path: path, Action: action})
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes, nil
+}
+
+// runGit executa o git no diretório e retorna a saída sem espaços nas pontas
+func runGit(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return "", fmt.Errorf("git %s: %w: %s", args[0], err, message)
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
@@ -0,0 +1,258 @@
+package gitrepo
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/go-git/go-git/v5/utils/merkletrie"
+)
+
+// GoGitRepository lê o repositório com go-git, sem depender do binário do git
+type GoGitRepository struct {
+	repo *git.Repository
+	root string
+}
+
+// OpenGoGit abre com go-git o repositório que contém dir
+func OpenGoGit(dir string) (*GoGitRepository, error) {
+	repo, err := git.PlainOpenWithOptions(dir, &git.PlainOpenOptions{
+		DetectDotGit:          true,
+		EnableDotGitCommonDir: true,
+	})
+	if errors.Is(err, git.ErrRepositoryNotExists) {
+		return nil, ErrNotRepository
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open git repository: %w", err)
+	}
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		// Repositórios bare não têm arquivos para analisar
+		return nil, ErrNotRepository
+	}
+	return &GoGitRepository{repo: repo, root: worktree.Filesystem.Root()}, nil
+}
+
+func (r *GoGitRepository) Root() string {
+	return r.root
+}
+
+func (r *GoGitRepository) Head() (string, error) {
+	head, err := r.repo.Head()
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return "", ErrNoCommits
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+	return head.Hash().String(), nil
+}
+
+func (r *GoGitRepository) Branch() (string, error) {
+	// Lê a referência simbólica para funcionar também antes do primeiro commit
+	head, err := r.repo.Storer.Reference(plumbing.HEAD)
+	if err != nil {
+		return "", fmt.Errorf("failed to read HEAD: %w", err)
+	}
+	if head.Type() != plumbing.SymbolicReference || !head.Target().IsBranch() {
+		return "", nil
+	}
+	return head.Target().Short(), nil
+}
+
+func (r *GoGitRepository) Remotes() ([]Remote, error) {
+	remotes, err := r.repo.Remotes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list remotes: %w", err)
+	}
+
+	result := make([]Remote, 0, len(remotes))
+	for _, remote := range remotes {
+		cfg := remote.Config()
+		if len(cfg.URLs) == 0 {
+			continue
+		}
+		result = append(result, Remote{Name: cfg.Name, URL: cfg.URLs[0]})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result, nil
+}
+
+func (r *GoGitRepository) IsDirty() (bool, error) {
+	worktree, err := r.repo.Worktree()
+	if err != nil {
+		return false, fmt.Errorf("failed to open worktree: %w", err)
+	}
+	status, err := worktree.Status()
+	if err != nil {
+		return false, fmt.Errorf("failed to get worktree status: %w", err)
+	}
+	return !status.IsClean(), nil
+}
+
+// LastCommits percorre o histórico uma única vez, do commit mais recente para o mais
+// antigo, até encontrar todos os caminhos. Um caminho conta como alterado em um commit
+// quando difere de todos os pais, como no git log -- <caminho>.
+func (r *GoGitRepository) LastCommits(paths ...string) (map[string]string, error) {
+	result := make(map[string]string, len(paths))
+	pending := make(map[string][]string, len(paths)) // caminho com "/" → caminhos pedidos
+	for _, path := range paths {
+		slashed := filepath.ToSlash(path)
+		pending[slashed] = append(pending[slashed], path)
+	}
+	if len(pending) == 0 {
+		return result, nil
+	}
+
+	head, err := r.repo.Head()
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return result, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+
+	commits, err := r.repo.Log(&git.LogOptions{From: head.Hash(), Order: git.LogOrderCommitterTime})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+	defer commits.Close()
+
+	err = commits.ForEach(func(commit *object.Commit) error {
+		changed, err := changedPaths(commit, pending)
+		if err != nil {
+			return err
+		}
+		for _, path := range changed {
+			for _, requested := range pending[path] {
+				result[requested] = commit.Hash.String()
+			}
+			delete(pending, path)
+		}
+		if len(pending) == 0 {
+			return storer.ErrStop
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+	return result, nil
+}
+
+// changedPaths caminhos pendentes alterados pelo commit em relação a todos os pais
+func changedPaths(commit *object.Commit, pending map[string][]string) ([]string, error) {
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, err
+	}
+
+	// Commit inicial: todos os arquivos presentes foram adicionados por ele
+	if commit.NumParents() == 0 {
+		var changed []string
+		for path := range pending {
+			if _, err := tree.File(path); err == nil {
+				changed = append(changed, path)
+			}
+		}
+		return changed, nil
+	}
+
+	counts := make(map[string]int)
+	parents := 0
+	err = commit.Parents().ForEach(func(parent *object.Commit) error {
+		parentTree, err := parent.Tree()
+		if err != nil {
+			return err
+		}
+		changes, err := object.DiffTree(parentTree, tree)
+		if err != nil {
+			return err
+		}
+		parents++
+		for _, change := range changes {
+			path := changePath(change)
+			if _, ok := pending[path]; ok {
+				counts[path]++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var changed []string
+	for path, count := range counts {
+		if count == parents {
+			changed = append(changed, path)
+		}
+	}
+	return changed, nil
+}
+
+func (r *GoGitRepository) Diff(from, to string) ([]Change, error) {
+	fromTree, err := r.tree(from)
+	if err != nil {
+		return nil, err
+	}
+	toTree, err := r.tree(to)
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := object.DiffTree(fromTree, toTree)
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff %s..%s: %w", from, to, err)
+	}
+
+	result := make([]Change, 0, len(changes))
+	for _, change := range changes {
+		action, err := change.Action()
+		if err != nil {
+			return nil, fmt.Errorf("failed to diff %s..%s: %w", from, to, err)
+		}
+		result = append(result, Change{Path: changePath(change), Action: changeAction(action)})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Path < result[j].Path })
+	return result, nil
+}
+
+// tree resolve a revisão (hash, branch ou tag) para a árvore do commit
+func (r *GoGitRepository) tree(revision string) (*object.Tree, error) {
+	hash, err := r.repo.ResolveRevision(plumbing.Revision(revision))
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve revision '%s': %w", revision, err)
+	}
+	commit, err := r.repo.CommitObject(*hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read commit '%s': %w", revision, err)
+	}
+	return commit.Tree()
+}
+
+// changePath caminho do arquivo alterado (o antigo, se foi removido)
+func changePath(change *object.Change) string {
+	if change.To.Name != "" {
+		return change.To.Name
+	}
+	return change.From.Name
+}
+
+func changeAction(action merkletrie.Action) ChangeAction {
+	switch action {
+	case merkletrie.Insert:
+		return ChangeAdded
+	case merkletrie.Delete:
+		return ChangeDeleted
+	default:
+		return ChangeModified
+	}
+}
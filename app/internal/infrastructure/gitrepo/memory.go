@@ -0,0 +1,111 @@
+package gitrepo
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+)
+
+// MemoryRepository repositório em memória para testes, montado com os métodos With*
+type MemoryRepository struct {
+	root    string
+	head    string
+	branch  string
+	remotes map[string]string
+	dirty   bool
+	commits map[string]string   // caminho com "/" → último commit
+	diffs   map[string][]Change // "from..to" → alterações
+}
+
+// NewMemoryRepository cria um repositório vazio (sem commits) na branch main
+func NewMemoryRepository(root string) *MemoryRepository {
+	return &MemoryRepository{
+		root:    root,
+		branch:  "main",
+		remotes: make(map[string]string),
+		commits: make(map[string]string),
+		diffs:   make(map[string][]Change),
+	}
+}
+
+// WithHead define o commit atual
+func (m *MemoryRepository) WithHead(hash string) *MemoryRepository {
+	m.head = hash
+	return m
+}
+
+// WithBranch define a branch atual; vazio simula HEAD destacado
+func (m *MemoryRepository) WithBranch(branch string) *MemoryRepository {
+	m.branch = branch
+	return m
+}
+
+// WithRemote adiciona um remote
+func (m *MemoryRepository) WithRemote(name, url string) *MemoryRepository {
+	m.remotes[name] = url
+	return m
+}
+
+// WithDirty marca a working tree com alterações não commitadas
+func (m *MemoryRepository) WithDirty(dirty bool) *MemoryRepository {
+	m.dirty = dirty
+	return m
+}
+
+// WithFileCommit define o último commit que alterou o caminho
+func (m *MemoryRepository) WithFileCommit(path, hash string) *MemoryRepository {
+	m.commits[filepath.ToSlash(path)] = hash
+	return m
+}
+
+// WithDiff define as alterações retornadas por Diff(from, to)
+func (m *MemoryRepository) WithDiff(from, to string, changes ...Change) *MemoryRepository {
+	m.diffs[from+".."+to] = changes
+	return m
+}
+
+func (m *MemoryRepository) Root() string {
+	return m.root
+}
+
+func (m *MemoryRepository) Head() (string, error) {
+	if m.head == "" {
+		return "", ErrNoCommits
+	}
+	return m.head, nil
+}
+
+func (m *MemoryRepository) Branch() (string, error) {
+	return m.branch, nil
+}
+
+func (m *MemoryRepository) Remotes() ([]Remote, error) {
+	remotes := make([]Remote, 0, len(m.remotes))
+	for name, url := range m.remotes {
+		remotes = append(remotes, Remote{Name: name, URL: url})
+	}
+	sort.Slice(remotes, func(i, j int) bool { return remotes[i].Name < remotes[j].Name })
+	return remotes, nil
+}
+
+func (m *MemoryRepository) IsDirty() (bool, error) {
+	return m.dirty, nil
+}
+
+func (m *MemoryRepository) LastCommits(paths ...string) (map[string]string, error) {
+	result := make(map[string]string, len(paths))
+	for _, path := range paths {
+		if hash, ok := m.commits[filepath.ToSlash(path)]; ok {
+			result[path] = hash
+		}
+	}
+	return result, nil
+}
+
+func (m *MemoryRepository) Diff(from, to string) ([]Change, error) {
+	changes, ok := m.diffs[from+".."+to]
+	if !ok {
+		return nil, fmt.Errorf("failed to resolve revision '%s..%s'", from, to)
+	}
+	return changes, nil
+}
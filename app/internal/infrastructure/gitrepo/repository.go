@@ -0,0 +1,148 @@
+package gitrepo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// BackendEnv escolhe a implementação do repositório: go-git (padrão) ou cli
+const BackendEnv = "PHENGINEER_GIT"
+
+// Backend implementação usada para ler o repositório
+type Backend string
+
+const (
+	BackendGoGit Backend = "go-git"
+	BackendCLI   Backend = "cli"
+)
+
+var (
+	// ErrNotRepository o diretório não está dentro de um repositório git
+	ErrNotRepository = errors.New("not inside a git repository")
+	// ErrNoCommits o repositório ainda não tem commits
+	ErrNoCommits = errors.New("repository has no commits")
+	// ErrRemoteNotFound o remote pedido não está configurado
+	ErrRemoteNotFound = errors.New("remote not found")
+)
+
+// Remote remote configurado no repositório
+type Remote struct {
+	Name string
+	URL  string // primeira URL do remote
+}
+
+// ChangeAction tipo de alteração de um arquivo entre dois commits
+type ChangeAction string
+
+const (
+	ChangeAdded    ChangeAction = "added"
+	ChangeModified ChangeAction = "modified"
+	ChangeDeleted  ChangeAction = "deleted"
+)
+
+// Change arquivo alterado entre dois commits; renomeações aparecem como remoção e inclusão
+type Change struct {
+	Path   string // relativo à raiz, com "/"
+	Action ChangeAction
+}
+
+// Repository leitura do repositório git do projeto. Caminhos são relativos à raiz.
+type Repository interface {
+	// Root caminho absoluto da raiz da working tree
+	Root() string
+	// Head hash do commit atual; ErrNoCommits em um repositório sem commits
+	Head() (string, error)
+	// Branch nome curto da branch atual; vazio com HEAD destacado
+	Branch() (string, error)
+	// Remotes remotes configurados, ordenados pelo nome
+	Remotes() ([]Remote, error)
+	// IsDirty indica alterações não commitadas, inclusive arquivos não versionados
+	IsDirty() (bool, error)
+	// LastCommits hash do último commit que alterou cada caminho; caminhos sem
+	// histórico (não versionados) ficam fora do mapa
+	LastCommits(paths ...string) (map[string]string, error)
+	// Diff arquivos alterados entre dois commits (hashes, branches ou tags)
+	Diff(from, to string) ([]Change, error)
+}
+
+// Open abre o repositório que contém dir com o backend de PHENGINEER_GIT
+func Open(dir string) (Repository, error) {
+	switch backend := Backend(os.Getenv(BackendEnv)); backend {
+	case BackendGoGit, "":
+		repo, err := OpenGoGit(dir)
+		if err != nil {
+			return nil, err
+		}
+		return repo, nil
+	case BackendCLI:
+		repo, err := OpenCLI(dir)
+		if err != nil {
+			return nil, err
+		}
+		return repo, nil
+	default:
+		return nil, fmt.Errorf("invalid %s '%s': use go-git or cli", BackendEnv, backend)
+	}
+}
+
+var (
+	currentMu sync.Mutex
+	current   = make(map[string]Repository)
+)
+
+// Current retorna o repositório do diretório atual, aberto uma única vez por diretório.
+// Fora do git retorna ErrNotRepository; falhas não ficam em cache.
+func Current() (Repository, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get working directory: %w", err)
+	}
+	cwd, _ = filepath.Abs(cwd)
+
+	currentMu.Lock()
+	defer currentMu.Unlock()
+	if repo, ok := current[cwd]; ok {
+		return repo, nil
+	}
+	repo, err := Open(cwd)
+	if err != nil {
+		return nil, err
+	}
+	current[cwd] = repo
+	return repo, nil
+}
+
+// repositoryKey chave do repositório no context
+type repositoryKey struct{}
+
+// WithRepository adiciona o repositório ao context
+func WithRepository(ctx context.Context, repo Repository) context.Context {
+	return context.WithValue(ctx, repositoryKey{}, repo)
+}
+
+// FromContext retorna o repositório do context ou, se não houver, o do diretório atual.
+// Fora do git retorna ErrNotRepository.
+func FromContext(ctx context.Context) (Repository, error) {
+	if repo, ok := ctx.Value(repositoryKey{}).(Repository); ok {
+		return repo, nil
+	}
+	return Current()
+}
+
+// RemoteURL retorna a URL do remote com o nome informado (ex.: origin)
+func RemoteURL(repo Repository, name string) (string, error) {
+	remotes, err := repo.Remotes()
+	if err != nil {
+		return "", err
+	}
+	for _, remote := range remotes {
+		if remote.Name == name {
+			return remote.URL, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s", ErrRemoteNotFound, name)
+}
@@ -0,0 +1,233 @@
+package gitrepo
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// testRepo repositório criado com go-git e os commits do cenário
+type testRepo struct {
+	root    string
+	commits []string
+}
+
+// newTestRepo cria um repositório com três commits:
+//  1. a.txt e dir/b.txt
+//  2. altera a.txt e adiciona c.txt
+//  3. remove dir/b.txt
+func newTestRepo(t *testing.T) *testRepo {
+	t.Helper()
+	root := t.TempDir()
+	repo, err := git.PlainInit(root, false)
+	if err != nil {
+		t.Fatalf("Failed to init repository: %v", err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tr := &testRepo{root: root}
+	when := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	commit := func(message string, files map[string]string, removed ...string) {
+		for name, content := range files {
+			path := filepath.Join(root, name)
+			if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := worktree.Add(name); err != nil {
+				t.Fatalf("Failed to add %s: %v", name, err)
+			}
+		}
+		for _, name := range removed {
+			if _, err := worktree.Remove(name); err != nil {
+				t.Fatalf("Failed to remove %s: %v", name, err)
+			}
+		}
+		when = when.Add(time.Hour)
+		signature := &object.Signature{Name: "Test", Email: "test@example.com", When: when}
+		hash, err := worktree.Commit(message, &git.CommitOptions{Author: signature, Committer: signature})
+		if err != nil {
+			t.Fatalf("Failed to commit: %v", err)
+		}
+		tr.commits = append(tr.commits, hash.String())
+	}
+
+	commit("first", map[string]string{"a.txt": "a", "dir/b.txt": "b"})
+	commit("second", map[string]string{"a.txt": "a2", "c.txt": "c"})
+	commit("third", nil, "dir/b.txt")
+
+	for name, url := range map[string]string{"origin": "https://github.com/org/app.git", "upstream": "git@github.com:up/app.git"} {
+		if _, err := repo.CreateRemote(&gitconfig.RemoteConfig{Name: name, URLs: []string{url}}); err != nil {
+			t.Fatalf("Failed to create remote: %v", err)
+		}
+	}
+	return tr
+}
+
+// backends implementações testadas com o mesmo cenário
+func backends(t *testing.T) map[string]func(dir string) (Repository, error) {
+	t.Helper()
+	result := map[string]func(dir string) (Repository, error){
+		"go-git": func(dir string) (Repository, error) { return OpenGoGit(dir) },
+	}
+	if _, err := exec.LookPath("git"); err == nil {
+		result["cli"] = func(dir string) (Repository, error) { return OpenCLI(dir) }
+	}
+	return result
+}
+
+// TestRepositoryBackends testa que go-git e o binário do git leem o repositório da mesma forma
+func TestRepositoryBackends(t *testing.T) {
+	tr := newTestRepo(t)
+	root, _ := filepath.EvalSymlinks(tr.root)
+
+	for name, open := range backends(t) {
+		t.Run(name, func(t *testing.T) {
+			// Abre a partir de um subdiretório
+			repo, err := open(filepath.Join(tr.root, "dir"))
+			if err != nil {
+				t.Fatalf("Open() error = %v", err)
+			}
+			if got, _ := filepath.EvalSymlinks(repo.Root()); got != root {
+				t.Errorf("Root() = %q, want %q", repo.Root(), root)
+			}
+
+			if head, err := repo.Head(); err != nil || head != tr.commits[2] {
+				t.Errorf("Head() = %q, %v, want %q", head, err, tr.commits[2])
+			}
+			if branch, err := repo.Branch(); err != nil || branch != "master" {
+				t.Errorf("Branch() = %q, %v, want master", branch, err)
+			}
+
+			remotes, err := repo.Remotes()
+			wantRemotes := []Remote{
+				{Name: "origin", URL: "https://github.com/org/app.git"},
+				{Name: "upstream", URL: "git@github.com:up/app.git"},
+			}
+			if err != nil || !reflect.DeepEqual(remotes, wantRemotes) {
+				t.Errorf("Remotes() = %+v, %v, want %+v", remotes, err, wantRemotes)
+			}
+
+			if dirty, err := repo.IsDirty(); err != nil || dirty {
+				t.Errorf("IsDirty() = %t, %v, want clean", dirty, err)
+			}
+
+			commits, err := repo.LastCommits("a.txt", "c.txt", filepath.Join("dir", "b.txt"), "untracked.txt")
+			wantCommits := map[string]string{
+				"a.txt":                       tr.commits[1],
+				"c.txt":                       tr.commits[1],
+				filepath.Join("dir", "b.txt"): tr.commits[2],
+			}
+			if err != nil || !reflect.DeepEqual(commits, wantCommits) {
+				t.Errorf("LastCommits() = %v, %v, want %v", commits, err, wantCommits)
+			}
+
+			changes, err := repo.Diff(tr.commits[0], tr.commits[2])
+			wantChanges := []Change{
+				{Path: "a.txt", Action: ChangeModified},
+				{Path: "c.txt", Action: ChangeAdded},
+				{Path: "dir/b.txt", Action: ChangeDeleted},
+			}
+			if err != nil || !reflect.DeepEqual(changes, wantChanges) {
+				t.Errorf("Diff() = %+v, %v, want %+v", changes, err, wantChanges)
+			}
+
+			if _, err := repo.Diff("unknown", tr.commits[2]); err == nil {
+				t.Error("Diff() with unknown revision error = nil")
+			}
+		})
+	}
+
+	// Arquivo não versionado deixa a working tree suja
+	if err := os.WriteFile(filepath.Join(tr.root, "untracked.txt"), []byte("u"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for name, open := range backends(t) {
+		repo, err := open(tr.root)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if dirty, err := repo.IsDirty(); err != nil || !dirty {
+			t.Errorf("%s: IsDirty() = %t, %v, want dirty", name, dirty, err)
+		}
+	}
+}
+
+// TestRepositoryWithoutCommits testa um repositório recém-criado e um diretório fora do git
+func TestRepositoryWithoutCommits(t *testing.T) {
+	root := t.TempDir()
+	if _, err := git.PlainInit(root, false); err != nil {
+		t.Fatal(err)
+	}
+	outside := t.TempDir()
+
+	for name, open := range backends(t) {
+		t.Run(name, func(t *testing.T) {
+			repo, err := open(root)
+			if err != nil {
+				t.Fatalf("Open() error = %v", err)
+			}
+			if _, err := repo.Head(); !errors.Is(err, ErrNoCommits) {
+				t.Errorf("Head() error = %v, want ErrNoCommits", err)
+			}
+			if branch, err := repo.Branch(); err != nil || branch != "master" {
+				t.Errorf("Branch() = %q, %v, want master", branch, err)
+			}
+			if commits, err := repo.LastCommits("a.txt"); err != nil || len(commits) != 0 {
+				t.Errorf("LastCommits() = %v, %v, want empty", commits, err)
+			}
+			if remotes, err := repo.Remotes(); err != nil || len(remotes) != 0 {
+				t.Errorf("Remotes() = %v, %v, want none", remotes, err)
+			}
+
+			if _, err := open(outside); !errors.Is(err, ErrNotRepository) {
+				t.Errorf("Open() outside git error = %v, want ErrNotRepository", err)
+			}
+		})
+	}
+}
+
+// TestRemoteURL testa a busca de um remote pelo nome
+func TestRemoteURL(t *testing.T) {
+	repo := NewMemoryRepository("/repo").WithRemote("origin", "https://github.com/org/app.git")
+
+	if url, err := RemoteURL(repo, "origin"); err != nil || url != "https://github.com/org/app.git" {
+		t.Errorf("RemoteURL(origin) = %q, %v", url, err)
+	}
+	if _, err := RemoteURL(repo, "upstream"); !errors.Is(err, ErrRemoteNotFound) {
+		t.Errorf("RemoteURL(upstream) error = %v, want ErrRemoteNotFound", err)
+	}
+}
+
+// TestCurrentRetriesFailedOpen testa que a falha ao abrir fora do git não fica em cache
+func TestCurrentRetriesFailedOpen(t *testing.T) {
+	dir := t.TempDir()
+	wd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	if _, err := Current(); !errors.Is(err, ErrNotRepository) {
+		t.Fatalf("Current() outside git error = %v, want ErrNotRepository", err)
+	}
+	if _, err := git.PlainInit(dir, false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Current(); err != nil {
+		t.Errorf("Current() after git init error = %v", err)
+	}
+}
@@ -0,0 +1,199 @@
+// Package httpx fornece o cliente HTTP compartilhado pelos provedores: proxy corporativo,
+// certificados adicionais, mTLS, retentativas com backoff e espera por rate limit.
+package httpx
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/PHRaulino/phengineer/internal/infrastructure/config"
+	"golang.org/x/net/http/httpproxy"
+)
+
+const (
+	DefaultTimeout          = 30 * time.Second
+	DefaultMaxRetries       = 3
+	DefaultBaseBackoff      = 500 * time.Millisecond
+	DefaultMaxBackoff       = 10 * time.Second
+	DefaultMaxRateLimitWait = 60 * time.Second
+
+	// UserAgent identifica a CLI nas APIs (o GitHub rejeita requisições sem User-Agent)
+	UserAgent = "phengineer-cli"
+)
+
+// Config configuração do cliente HTTP
+type Config struct {
+	Timeout time.Duration // por tentativa, sem contar as esperas entre elas
+
+	// Proxy vazio usa HTTPS_PROXY/HTTP_PROXY/NO_PROXY do ambiente
+	Proxy   string
+	NoProxy string
+
+	CACertFiles    []string // somados aos certificados do sistema
+	ClientCertFile string   // mTLS
+	ClientKeyFile  string
+
+	MaxRetries       int // negativo desativa as retentativas
+	BaseBackoff      time.Duration
+	MaxBackoff       time.Duration
+	MaxRateLimitWait time.Duration // esperas maiores devolvem a resposta 429/403 a quem chamou
+}
+
+// DefaultConfig configuração usada quando não há seção http no settings.yml
+func DefaultConfig() Config {
+	return Config{
+		Timeout:          DefaultTimeout,
+		MaxRetries:       DefaultMaxRetries,
+		BaseBackoff:      DefaultBaseBackoff,
+		MaxBackoff:       DefaultMaxBackoff,
+		MaxRateLimitWait: DefaultMaxRateLimitWait,
+	}
+}
+
+// FromSettings aplica a seção http do settings.yml sobre a configuração padrão
+func FromSettings(settings config.HTTP) Config {
+	cfg := DefaultConfig()
+	cfg.Proxy = settings.Proxy
+	cfg.NoProxy = settings.NoProxy
+	cfg.CACertFiles = settings.CACertFiles
+	cfg.ClientCertFile = settings.ClientCertFile
+	cfg.ClientKeyFile = settings.ClientKeyFile
+	if settings.TimeoutSeconds > 0 {
+		cfg.Timeout = time.Duration(settings.TimeoutSeconds) * time.Second
+	}
+	if settings.MaxRetries != 0 {
+		cfg.MaxRetries = settings.MaxRetries
+	}
+	return cfg
+}
+
+// NewClient monta um cliente com a configuração informada
+func NewClient(cfg Config) (*http.Client, error) {
+	base, err := newBaseTransport(cfg)
+	if err != nil {
+		return nil, err
+	}
+	// Sem Client.Timeout: ele cobriria as retentativas e as esperas por rate limit,
+	// então o transporte aplica cfg.Timeout a cada tentativa
+	return &http.Client{Transport: NewTransport(base, cfg)}, nil
+}
+
+var (
+	defaultMu     sync.RWMutex
+	defaultClient *http.Client
+)
+
+// Configure substitui o cliente compartilhado; provedores criados depois passam a usá-lo
+func Configure(cfg Config) error {
+	client, err := NewClient(cfg)
+	if err != nil {
+		return err
+	}
+
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultClient = client
+	return nil
+}
+
+// Default retorna o cliente compartilhado, com a configuração padrão se Configure não foi chamado
+func Default() *http.Client {
+	defaultMu.RLock()
+	client := defaultClient
+	defaultMu.RUnlock()
+	if client != nil {
+		return client
+	}
+
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	if defaultClient == nil {
+		// A configuração padrão não lê arquivos, então não falha
+		defaultClient, _ = NewClient(DefaultConfig())
+	}
+	return defaultClient
+}
+
+// newBaseTransport monta o transporte de rede com proxy e TLS
+func newBaseTransport(cfg Config) (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	proxy, err := proxyFunc(cfg)
+	if err != nil {
+		return nil, err
+	}
+	transport.Proxy = proxy
+
+	tlsConfig, err := tlsConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	transport.TLSClientConfig = tlsConfig
+	return transport, nil
+}
+
+// proxyFunc usa o proxy do settings.yml ou, sem ele, as variáveis de ambiente
+func proxyFunc(cfg Config) (func(*http.Request) (*url.URL, error), error) {
+	if cfg.Proxy == "" {
+		return http.ProxyFromEnvironment, nil
+	}
+	if _, err := url.Parse(cfg.Proxy); err != nil {
+		return nil, fmt.Errorf("proxy inválido %q: %w", cfg.Proxy, err)
+	}
+
+	noProxy := cfg.NoProxy
+	if noProxy == "" {
+		noProxy = firstEnv("NO_PROXY", "no_proxy")
+	}
+	proxy := (&httpproxy.Config{HTTPProxy: cfg.Proxy, HTTPSProxy: cfg.Proxy, NoProxy: noProxy}).ProxyFunc()
+	return func(req *http.Request) (*url.URL, error) {
+		return proxy(req.URL)
+	}, nil
+}
+
+// tlsConfig soma os certificados adicionais aos do sistema e carrega o certificado do cliente
+func tlsConfig(cfg Config) (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if len(cfg.CACertFiles) > 0 {
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		for _, file := range cfg.CACertFiles {
+			pem, err := os.ReadFile(file)
+			if err != nil {
+				return nil, fmt.Errorf("erro ao ler certificado %s: %w", file, err)
+			}
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("nenhum certificado PEM encontrado em %s", file)
+			}
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if cfg.ClientCertFile != "" || cfg.ClientKeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.ClientCertFile, cfg.ClientKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao carregar certificado do cliente: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+func firstEnv(keys ...string) string {
+	for _, key := range keys {
+		if value := strings.TrimSpace(os.Getenv(key)); value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,99 @@
+package httpx
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PHRaulino/phengineer/internal/infrastructure/config"
+)
+
+// TestNewClientTLS testa a confiança em certificados adicionais do settings.yml
+func TestNewClientTLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	caFile := filepath.Join(dir, "ca.pem")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caFile, certPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	invalidFile := filepath.Join(dir, "invalid.pem")
+	if err := os.WriteFile(invalidFile, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		settings   config.HTTP
+		wantErr    string // erro ao montar o cliente
+		wantReqErr bool   // erro ao chamar o servidor
+	}{
+		{name: "Extra CA trusted", settings: config.HTTP{CACertFiles: []string{caFile}}},
+		{name: "System pool only", settings: config.HTTP{}, wantReqErr: true},
+		{name: "Invalid CA file", settings: config.HTTP{CACertFiles: []string{invalidFile}}, wantErr: "nenhum certificado PEM"},
+		{name: "Missing CA file", settings: config.HTTP{CACertFiles: []string{filepath.Join(dir, "missing.pem")}}, wantErr: "erro ao ler certificado"},
+		{name: "Missing client key", settings: config.HTTP{ClientCertFile: caFile, ClientKeyFile: filepath.Join(dir, "missing.key")}, wantErr: "certificado do cliente"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := FromSettings(tt.settings)
+			cfg.MaxRetries = -1
+
+			client, err := NewClient(cfg)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("NewClient() error = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+
+			resp, err := client.Get(server.URL)
+			if (err != nil) != tt.wantReqErr {
+				t.Fatalf("Get() error = %v, wantReqErr %v", err, tt.wantReqErr)
+			}
+			if resp != nil {
+				resp.Body.Close()
+			}
+		})
+	}
+}
+
+// TestNewClientProxy testa o proxy do settings.yml e a lista de exceções
+func TestNewClientProxy(t *testing.T) {
+	var proxied []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = append(proxied, r.URL.Host)
+	}))
+	defer proxy.Close()
+
+	client, err := NewClient(FromSettings(config.HTTP{Proxy: proxy.URL, NoProxy: "interno.empresa.com"}))
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	resp, err := client.Get("http://api.empresa.com/health")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	resp.Body.Close()
+
+	if len(proxied) != 1 || proxied[0] != "api.empresa.com" {
+		t.Errorf("proxied = %v, want [api.empresa.com]", proxied)
+	}
+
+	transport := client.Transport.(*Transport).base.(*http.Transport)
+	req, _ := http.NewRequest(http.MethodGet, "http://interno.empresa.com/", nil)
+	if proxyURL, _ := transport.Proxy(req); proxyURL != nil {
+		t.Errorf("Proxy(no_proxy host) = %v, want direct", proxyURL)
+	}
+}
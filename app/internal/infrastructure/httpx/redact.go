@@ -0,0 +1,109 @@
+package httpx
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+const redacted = "[REDACTED]"
+
+// sensitiveHeaders headers que nunca aparecem nos logs
+var sensitiveHeaders = map[string]bool{
+	"Authorization":        true,
+	"Proxy-Authorization":  true,
+	"Cookie":               true,
+	"Set-Cookie":           true,
+	"X-Vault-Token":        true,
+	"X-Amz-Security-Token": true,
+}
+
+// sensitiveParams trechos de nomes de parâmetros cujo valor é segredo
+var sensitiveParams = []string{"token", "secret", "password", "code", "key", "signature", "credential", "jwt"}
+
+// RedactURL remove credenciais do userinfo e valores de parâmetros sensíveis da query
+func RedactURL(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+	clean := *u
+	if clean.User != nil {
+		clean.User = url.User(redacted)
+	}
+
+	query := clean.Query()
+	for name := range query {
+		if isSensitiveParam(name) {
+			query.Set(name, redacted)
+		}
+	}
+	clean.RawQuery = query.Encode()
+	return clean.String()
+}
+
+// RedactHeaders copia os headers trocando os valores sensíveis
+func RedactHeaders(header http.Header) map[string]string {
+	out := make(map[string]string, len(header))
+	for name, values := range header {
+		canonical := http.CanonicalHeaderKey(name)
+		if sensitiveHeaders[canonical] || isSensitiveParam(canonical) {
+			out[canonical] = redacted
+			continue
+		}
+		out[canonical] = strings.Join(values, ", ")
+	}
+	return out
+}
+
+// secretPattern tokens conhecidos (GitHub, Vault, JWT) que podem aparecer em texto livre
+var secretPattern = regexp.MustCompile(`\b(gh[pousr]_[A-Za-z0-9]{10,}|github_pat_[A-Za-z0-9_]{10,}|hv[sbr]\.[A-Za-z0-9_-]{10,}|eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*)`)
+
+// SanitizeBody resume o corpo de uma resposta para mensagens de erro: campos JSON
+// sensíveis e tokens conhecidos são removidos e o texto é limitado a limit caracteres
+func SanitizeBody(body []byte, limit int) string {
+	text := strings.TrimSpace(string(body))
+
+	var parsed any
+	if json.Unmarshal(body, &parsed) == nil {
+		if data, err := json.Marshal(redactJSON(parsed)); err == nil {
+			text = string(data)
+		}
+	}
+
+	text = secretPattern.ReplaceAllString(strings.Join(strings.Fields(text), " "), redacted)
+	if runes := []rune(text); limit > 0 && len(runes) > limit {
+		text = string(runes[:limit]) + "…"
+	}
+	return text
+}
+
+// redactJSON troca os valores de chaves sensíveis em qualquer nível do documento
+func redactJSON(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, item := range v {
+			if isSensitiveParam(key) {
+				v[key] = redacted
+				continue
+			}
+			v[key] = redactJSON(item)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = redactJSON(item)
+		}
+	}
+	return value
+}
+
+func isSensitiveParam(name string) bool {
+	name = strings.ToLower(name)
+	for _, fragment := range sensitiveParams {
+		if strings.Contains(name, fragment) {
+			return true
+		}
+	}
+	return false
+}
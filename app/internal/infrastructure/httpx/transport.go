@@ -0,0 +1,257 @@
+package httpx
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/PHRaulino/phengineer/internal/infrastructure/utils/logger"
+	"go.uber.org/zap"
+)
+
+// Transport adiciona User-Agent e X-Request-ID, repete falhas transitórias com backoff
+// exponencial e espera o fim do rate limit informado pela API
+type Transport struct {
+	base             http.RoundTripper
+	timeout          time.Duration
+	maxRetries       int
+	baseBackoff      time.Duration
+	maxBackoff       time.Duration
+	maxRateLimitWait time.Duration
+
+	sleep func(ctx context.Context, d time.Duration) error
+	now   func() time.Time
+}
+
+// NewTransport envolve base com as políticas de cfg
+func NewTransport(base http.RoundTripper, cfg Config) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &Transport{
+		base:             base,
+		timeout:          cfg.Timeout,
+		maxRetries:       max(cfg.MaxRetries, 0),
+		baseBackoff:      cfg.BaseBackoff,
+		maxBackoff:       cfg.MaxBackoff,
+		maxRateLimitWait: cfg.MaxRateLimitWait,
+		sleep:            sleepContext,
+		now:              time.Now,
+	}
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// RoundTrip não deve alterar a requisição original
+	req = req.Clone(req.Context())
+	if req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", UserAgent)
+	}
+	if req.Header.Get("X-Request-ID") == "" {
+		if id := RequestID(req.Context()); id != "" {
+			req.Header.Set("X-Request-ID", id)
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 && req.Body != nil && req.Body != http.NoBody {
+			// Corpo consumido na tentativa anterior: só repete se puder ser recriado
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, fmt.Errorf("erro ao recriar corpo da requisição: %w", err)
+			}
+			req.Body = body
+		}
+
+		attemptReq, cancel := t.withTimeout(req)
+		start := t.now()
+		resp, err := t.base.RoundTrip(attemptReq)
+		logRequest(req, resp, err, attempt, t.now().Sub(start))
+
+		wait, retry := t.retryAfter(req, resp, err, attempt)
+		if !retry {
+			if resp == nil {
+				cancel()
+				return nil, err
+			}
+			// O timeout da tentativa vale até o corpo ser lido e fechado
+			resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+			return resp, err
+		}
+		if resp != nil {
+			// Libera a conexão para a próxima tentativa
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		cancel()
+
+		if err := t.sleep(req.Context(), wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// withTimeout aplica o timeout de uma tentativa sem alterar o contexto de quem chamou
+func (t *Transport) withTimeout(req *http.Request) (*http.Request, context.CancelFunc) {
+	if t.timeout <= 0 {
+		return req, func() {}
+	}
+	ctx, cancel := context.WithTimeout(req.Context(), t.timeout)
+	return req.WithContext(ctx), cancel
+}
+
+// cancelBody encerra o contexto da tentativa quando o corpo da resposta é fechado
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// retryAfter decide se a tentativa deve ser repetida e quanto esperar antes
+func (t *Transport) retryAfter(req *http.Request, resp *http.Response, err error, attempt int) (time.Duration, bool) {
+	if attempt >= t.maxRetries || req.Context().Err() != nil {
+		return 0, false
+	}
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return 0, false
+	}
+
+	if err != nil {
+		// Falha de rede: apenas métodos idempotentes, para não duplicar efeitos no servidor
+		if !idempotent(req.Method) {
+			return 0, false
+		}
+		return t.backoff(attempt), true
+	}
+
+	if wait, limited := t.rateLimitWait(resp); limited {
+		if wait > t.maxRateLimitWait {
+			return 0, false
+		}
+		return wait, true
+	}
+
+	switch resp.StatusCode {
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		// O servidor pode ter processado a requisição antes de falhar
+		if !idempotent(req.Method) {
+			return 0, false
+		}
+		if wait, ok := t.parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+			return min(wait, t.maxRateLimitWait), true
+		}
+		return t.backoff(attempt), true
+	}
+	return 0, false
+}
+
+// rateLimitWait identifica respostas de rate limit (429, ou 403 do GitHub com a cota zerada)
+// e o tempo até a cota ser renovada
+func (t *Transport) rateLimitWait(resp *http.Response) (time.Duration, bool) {
+	exhausted := resp.Header.Get("X-RateLimit-Remaining") == "0"
+	if resp.StatusCode != http.StatusTooManyRequests && !(resp.StatusCode == http.StatusForbidden && exhausted) {
+		return 0, false
+	}
+
+	if wait, ok := t.parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+		return wait, true
+	}
+	if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil && exhausted {
+		return max(time.Unix(reset, 0).Sub(t.now()), 0), true
+	}
+	// Rate limit secundário sem indicação de espera
+	return t.maxBackoff, true
+}
+
+// parseRetryAfter aceita segundos ou data HTTP
+func (t *Transport) parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(max(seconds, 0)) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(date.Sub(t.now()), 0), true
+	}
+	return 0, false
+}
+
+// backoff exponencial com jitter, limitado a maxBackoff
+func (t *Transport) backoff(attempt int) time.Duration {
+	wait := t.baseBackoff << attempt
+	if wait <= 0 || wait > t.maxBackoff {
+		wait = t.maxBackoff
+	}
+	// Jitter de até 50% evita que clientes paralelos repitam juntos
+	return wait/2 + rand.N(wait/2+1)
+}
+
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+type requestIDKey struct{}
+
+// WithRequestID associa um ID às requisições feitas com o contexto
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID retorna o ID do contexto ou, sem ele, o traceID do logger
+func RequestID(ctx context.Context) string {
+	if id, ok := ctx.Value(requestIDKey{}).(string); ok && id != "" {
+		return id
+	}
+	return logger.TraceID()
+}
+
+// logRequest registra a requisição em debug, sem segredos na URL ou nos headers
+func logRequest(req *http.Request, resp *http.Response, err error, attempt int, elapsed time.Duration) {
+	log := zap.L()
+	if !log.Core().Enabled(zap.DebugLevel) {
+		return
+	}
+
+	fields := []zap.Field{
+		zap.String("method", req.Method),
+		zap.String("url", RedactURL(req.URL)),
+		zap.Int("attempt", attempt),
+		zap.Duration("elapsed", elapsed),
+		zap.String("request_id", req.Header.Get("X-Request-ID")),
+		zap.Any("headers", RedactHeaders(req.Header)),
+	}
+	if resp != nil {
+		fields = append(fields, zap.Int("status", resp.StatusCode))
+		if remaining := resp.Header.Get("X-RateLimit-Remaining"); remaining != "" {
+			fields = append(fields, zap.String("rate_limit_remaining", remaining))
+		}
+	}
+	if err != nil {
+		fields = append(fields, zap.Error(err))
+	}
+	log.Debug("http request", fields...)
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,156 @@
+package symbols
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"path/filepath"
+	"strings"
+)
+
+// BuildGoIndex indexa as funções e métodos dos arquivos Go informados.
+// Os caminhos são relativos a root; arquivos que não são .go ou são
+// arquivos de teste são ignorados.
+func BuildGoIndex(root string, files []string) (*Index, error) {
+	index := &Index{
+		Root:    root,
+		Symbols: make([]Symbol, 0),
+		byFile:  make(map[string][]Symbol),
+	}
+
+	fset := token.NewFileSet()
+	for _, relativePath := range files {
+		if !isGoSource(relativePath) {
+			continue
+		}
+
+		fileSymbols, err := parseFile(fset, root, relativePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to index %s: %w", relativePath, err)
+		}
+
+		index.Symbols = append(index.Symbols, fileSymbols...)
+		index.byFile[filepath.ToSlash(relativePath)] = fileSymbols
+	}
+
+	return index, nil
+}
+
+// ForFile retorna os símbolos de um arquivo
+func (i *Index) ForFile(relativePath string) []Symbol {
+	return i.byFile[filepath.ToSlash(relativePath)]
+}
+
+// InLines retorna os símbolos de um arquivo que intersectam o intervalo de linhas
+func (i *Index) InLines(relativePath string, start, end int) []Symbol {
+	result := make([]Symbol, 0)
+	for _, symbol := range i.ForFile(relativePath) {
+		if symbol.StartLine <= end && symbol.EndLine >= start {
+			result = append(result, symbol)
+		}
+	}
+	return result
+}
+
+// Lookup busca um símbolo pelo nome qualificado ("Func" ou "Type.Method")
+func (i *Index) Lookup(relativePath, qualifiedName string) (Symbol, bool) {
+	for _, symbol := range i.ForFile(relativePath) {
+		if symbol.QualifiedName() == qualifiedName {
+			return symbol, true
+		}
+	}
+	return Symbol{}, false
+}
+
+// QualifiedName retorna o nome do símbolo prefixado pelo tipo do receiver
+func (s Symbol) QualifiedName() string {
+	if s.Receiver == "" {
+		return s.Name
+	}
+	return strings.TrimPrefix(s.Receiver, "*") + "." + s.Name
+}
+
+// ReturnsError indica se o último retorno da função é um error
+func (s Symbol) ReturnsError() bool {
+	return len(s.Results) > 0 && s.Results[len(s.Results)-1].Type == "error"
+}
+
+// parseFile extrai os símbolos de um arquivo Go
+func parseFile(fset *token.FileSet, root, relativePath string) ([]Symbol, error) {
+	fullPath := filepath.Join(root, relativePath)
+	file, err := parser.ParseFile(fset, fullPath, nil, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	symbols := make([]Symbol, 0)
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok {
+			continue
+		}
+
+		symbol := Symbol{
+			Name:      fn.Name.Name,
+			Kind:      KindFunction,
+			Package:   file.Name.Name,
+			File:      filepath.ToSlash(relativePath),
+			StartLine: fset.Position(fn.Pos()).Line,
+			EndLine:   fset.Position(fn.End()).Line,
+			Params:    fieldsToParams(fset, fn.Type.Params),
+			Results:   fieldsToParams(fset, fn.Type.Results),
+			Exported:  fn.Name.IsExported(),
+		}
+
+		if fn.Doc != nil {
+			symbol.Doc = strings.TrimSpace(fn.Doc.Text())
+		}
+
+		if fn.Recv != nil && len(fn.Recv.List) > 0 {
+			symbol.Kind = KindMethod
+			symbol.Receiver = exprString(fset, fn.Recv.List[0].Type)
+		}
+
+		symbols = append(symbols, symbol)
+	}
+
+	return symbols, nil
+}
+
+// fieldsToParams converte uma lista de campos da AST em parâmetros
+func fieldsToParams(fset *token.FileSet, fields *ast.FieldList) []Param {
+	params := make([]Param, 0)
+	if fields == nil {
+		return params
+	}
+
+	for _, field := range fields.List {
+		fieldType := exprString(fset, field.Type)
+		if len(field.Names) == 0 {
+			params = append(params, Param{Type: fieldType})
+			continue
+		}
+		for _, name := range field.Names {
+			params = append(params, Param{Name: name.Name, Type: fieldType})
+		}
+	}
+
+	return params
+}
+
+// exprString imprime uma expressão da AST como código Go
+func exprString(fset *token.FileSet, expr ast.Expr) string {
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, fset, expr); err != nil {
+		return ""
+	}
+	return buf.String()
+}
+
+// isGoSource verifica se o caminho é um arquivo Go que não é de teste
+func isGoSource(relativePath string) bool {
+	return strings.HasSuffix(relativePath, ".go") && !strings.HasSuffix(relativePath, "_test.go")
+}
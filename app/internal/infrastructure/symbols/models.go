@@ -0,0 +1,37 @@
+package symbols
+
+// SymbolKind define o tipo de símbolo indexado
+type SymbolKind string
+
+const (
+	KindFunction SymbolKind = "function"
+	KindMethod   SymbolKind = "method"
+)
+
+// Param representa um parâmetro ou retorno de uma função
+type Param struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// Symbol representa uma função ou método encontrado na AST
+type Symbol struct {
+	Name      string     `json:"name"`
+	Kind      SymbolKind `json:"kind"`
+	Receiver  string     `json:"receiver,omitempty"` // Tipo do receiver, ex: "*Service"
+	Package   string     `json:"package"`
+	File      string     `json:"file"` // Caminho relativo à raiz do projeto
+	StartLine int        `json:"start_line"`
+	EndLine   int        `json:"end_line"`
+	Params    []Param    `json:"params"`
+	Results   []Param    `json:"results"`
+	Exported  bool       `json:"exported"`
+	Doc       string     `json:"doc,omitempty"`
+}
+
+// Index é o índice de símbolos por arquivo
+type Index struct {
+	Root    string              `json:"root"`
+	Symbols []Symbol            `json:"symbols"`
+	byFile  map[string][]Symbol // key: caminho relativo
+}
@@ -12,6 +12,14 @@ import (
 
 var Logger *zap.Logger
 
+// traceID identifica a execução nos logs e nas requisições HTTP
+var traceID string
+
+// TraceID retorna o identificador da execução atual (vazio antes de SetupLogger)
+func TraceID() string {
+	return traceID
+}
+
 func getLogLevel() zapcore.Level {
 	switch strings.ToLower(os.Getenv("LOG_LEVEL")) {
 	case "debug":
@@ -57,7 +65,7 @@ func SetupLogger() {
 		_ = logger.Sync()
 	}()
 
-	traceID := uuid.New().String()
+	traceID = uuid.New().String()
 
 	logger = logger.With(
 		zap.String("traceID", traceID),
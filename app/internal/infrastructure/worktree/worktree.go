@@ -0,0 +1,74 @@
+package worktree
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Worktree representa um git worktree temporário usado para verificação
+type Worktree struct {
+	RepoRoot string // Raiz da working tree do repositório de origem
+	Path     string // Caminho do worktree
+	Ref      string // Commit ou branch em que o worktree foi criado
+}
+
+// Create cria um worktree destacado (detached) no ref informado. dir pode ser qualquer
+// pasta do repositório de origem, como a raiz do projeto em um monorepo.
+func Create(dir, ref string) (*Worktree, error) {
+	if ref == "" {
+		ref = "HEAD"
+	}
+
+	cmd := exec.Command("git", "rev-parse", "--show-toplevel")
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find repository root of %s: %w", dir, err)
+	}
+	repoRoot := strings.TrimSpace(string(output))
+
+	path, err := os.MkdirTemp("", "phengineer-worktree-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create worktree directory: %w", err)
+	}
+
+	// git worktree add exige que o diretório não exista ou esteja vazio
+	cmd = exec.Command("git", "worktree", "add", "--detach", path, ref)
+	cmd.Dir = repoRoot
+	if output, err := cmd.CombinedOutput(); err != nil {
+		os.RemoveAll(path)
+		return nil, fmt.Errorf("failed to create worktree at %s: %v: %s", ref, err, strings.TrimSpace(string(output)))
+	}
+
+	return &Worktree{
+		RepoRoot: repoRoot,
+		Path:     path,
+		Ref:      ref,
+	}, nil
+}
+
+// Remove remove o worktree e seus arquivos
+func (w *Worktree) Remove() error {
+	cmd := exec.Command("git", "worktree", "remove", "--force", w.Path)
+	cmd.Dir = w.RepoRoot
+	if output, err := cmd.CombinedOutput(); err != nil {
+		os.RemoveAll(w.Path)
+		return fmt.Errorf("failed to remove worktree: %v: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// Resolve converte um caminho do repositório de origem no caminho equivalente do worktree
+func (w *Worktree) Resolve(path string) (string, error) {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	rel, err := filepath.Rel(w.RepoRoot, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s is outside the repository %s", path, w.RepoRoot)
+	}
+	return filepath.Join(w.Path, rel), nil
+}
@@ -1,7 +1,12 @@
 package screens
 
 import (
+	"context"
+	"strings"
+
 	"github.com/PHRaulino/phengineer/internal/infrastructure/auth"
+	"github.com/PHRaulino/phengineer/internal/infrastructure/auth/providers"
+	"github.com/PHRaulino/phengineer/internal/infrastructure/config"
 	"github.com/PHRaulino/phengineer/internal/presentation/tui/components/forms"
 	"github.com/PHRaulino/phengineer/internal/presentation/tui/messages"
 	"github.com/PHRaulino/phengineer/internal/presentation/tui/models"
@@ -16,6 +21,42 @@ type AuthSetupScreen struct {
 	authType    string
 	step        int
 	credentials map[string]string
+
+	// Login pelo navegador
+	device       *providers.DeviceAuthorization
+	cancelDevice context.CancelFunc
+
+	// Verificação de permissões do token GitHub antes de salvar
+	githubCheck  bool
+	githubReport *providers.GitHubTokenReport
+}
+
+// deviceFlow provedor com login pelo navegador (OAuth device authorization)
+type deviceFlow interface {
+	StartDeviceLogin(ctx context.Context) (providers.DeviceAuthorization, error)
+	CompleteDeviceLogin(ctx context.Context, authorization providers.DeviceAuthorization) error
+}
+
+// deviceCodeMsg código de verificação recebido do servidor de autorização
+type deviceCodeMsg struct {
+	authorization providers.DeviceAuthorization
+	err           error
+}
+
+// deviceDoneMsg fim do polling do login pelo navegador
+type deviceDoneMsg struct {
+	err error
+}
+
+// credentialsSavedMsg resultado do salvamento das credenciais
+type credentialsSavedMsg struct {
+	err error
+}
+
+// githubVerifiedMsg resultado da verificação do token GitHub informado
+type githubVerifiedMsg struct {
+	report *providers.GitHubTokenReport
+	err    error
 }
 
 func NewAuthSetupScreen() *AuthSetupScreen {
@@ -36,10 +77,12 @@ func (s *AuthSetupScreen) initForm() {
 	case 0: // Seleção do tipo de auth
 		s.form = forms.NewForm(
 			"🔐 Configuração de Autenticação",
-			"Escolha o tipo de autenticação que deseja configurar",
+			"Escolha o tipo de autenticação que deseja configurar (perfil: "+auth.ActiveProfile()+")",
 		).AddField("Tipo", forms.NewSelect([]string{
+			"Stackspot User (Login pelo navegador)",
 			"Stackspot User (Desenvolvedor)",
-			"Stackspot Service (Sistema via Vault)", 
+			"Stackspot Service (Sistema via Vault)",
+			"GitHub (Login pelo navegador)",
 			"GitHub (Personal Access Token)",
 		}))
 
@@ -63,9 +106,9 @@ func (s *AuthSetupScreen) initForm() {
 				AddField("Vault URL", forms.NewInput().
 					WithPlaceholder("https://vault.empresa.com").
 					WithValidation(forms.ValidateURL)).
-				AddField("AWS Role", forms.NewInput().
-					WithPlaceholder("stackspot-role").
-					WithValidation(forms.Required)).
+				AddField("Namespace", forms.NewInput().
+					WithPlaceholder("time/projeto (opcional, Vault Enterprise)")).
+				AddField("Método", forms.NewSelect(vaultMethodOptions)).
 				AddField("StackSpot Secret Path", forms.NewInput().
 					WithPlaceholder("secret/data/stackspot").
 					WithValidation(forms.Required))
@@ -78,6 +121,77 @@ func (s *AuthSetupScreen) initForm() {
 					WithPlaceholder("ghp_xxxxxxxxxxxxxxxxxxxx").
 					WithValidation(forms.Required))
 		}
+
+	case 2: // Campos específicos do método de auth do Vault
+		s.initVaultMethodForm()
+	}
+}
+
+// vaultMethodOptions opções de método de autenticação no Vault
+var vaultMethodOptions = []string{
+	"AWS IAM",
+	"AppRole",
+	"JWT/OIDC (GitHub Actions)",
+	"Kubernetes",
+	"Token",
+}
+
+// vaultMethods mapeia as opções da tela para os métodos do settings.yml
+var vaultMethods = map[string]string{
+	"AWS IAM":                   config.VaultMethodAWS,
+	"AppRole":                   config.VaultMethodAppRole,
+	"JWT/OIDC (GitHub Actions)": config.VaultMethodJWT,
+	"Kubernetes":                config.VaultMethodKubernetes,
+	"Token":                     config.VaultMethodToken,
+}
+
+func (s *AuthSetupScreen) initVaultMethodForm() {
+	method := vaultMethods[s.credentials["Método"]]
+	s.form = forms.NewForm(
+		"🔑 Autenticação no Vault",
+		"Método: "+s.credentials["Método"],
+	)
+
+	switch method {
+	case config.VaultMethodAWS:
+		s.form.
+			AddField("Role", forms.NewInput().
+				WithPlaceholder("stackspot-role").
+				WithValidation(forms.Required)).
+			AddField("IAM Server ID", forms.NewInput().
+				WithPlaceholder("vault.empresa.com (opcional)"))
+	case config.VaultMethodAppRole:
+		s.form.
+			AddField("Role ID", forms.NewInput().
+				WithValidation(forms.Required)).
+			AddField("Secret ID", forms.NewPassword().
+				WithValidation(forms.Required))
+	case config.VaultMethodJWT:
+		s.form.
+			AddField("Role", forms.NewInput().
+				WithPlaceholder("github-actions").
+				WithValidation(forms.Required)).
+			AddField("Token File", forms.NewInput().
+				WithPlaceholder("vazio para usar o ID token do GitHub Actions")).
+			AddField("Audience", forms.NewInput().
+				WithPlaceholder("https://github.com/empresa (opcional)"))
+	case config.VaultMethodKubernetes:
+		s.form.
+			AddField("Role", forms.NewInput().
+				WithPlaceholder("stackspot").
+				WithValidation(forms.Required)).
+			AddField("Token File", forms.NewInput().
+				WithPlaceholder(providers.DefaultKubernetesTokenFile))
+	case config.VaultMethodToken:
+		s.form.
+			AddField("Token", forms.NewPassword().
+				WithPlaceholder("hvs.xxxxxxxx").
+				WithValidation(forms.Required))
+	}
+
+	if method != config.VaultMethodToken {
+		s.form.AddField("Mount", forms.NewInput().
+			WithPlaceholder(method+" (opcional)"))
 	}
 }
 
@@ -90,26 +204,113 @@ func (s *AuthSetupScreen) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.KeyMsg:
 		switch msg.String() {
 		case "esc":
+			if s.cancelDevice != nil {
+				s.cancelDevice()
+			}
 			return s, func() tea.Msg {
 				return messages.PopScreenMsg{}
 			}
 		}
+		if s.isDeviceLogin() {
+			return s, nil
+		}
+		if s.githubCheck {
+			if msg.String() == "enter" {
+				switch {
+				case s.Error != nil:
+					// Token recusado: voltar ao formulário para informar outro
+					s.githubCheck = false
+					s.Error = nil
+					s.initForm()
+					return s, s.form.Init()
+				case s.githubReport != nil:
+					// Com permissões ausentes o usuário confirma antes de salvar
+					return s, s.saveCredentials()
+				}
+			}
+			return s, nil
+		}
+
+	case deviceCodeMsg:
+		if msg.err != nil {
+			s.Loading = false
+			s.Error = msg.err
+			return s, nil
+		}
+		s.device = &msg.authorization
+		s.LoadingMsg = "Aguardando autorização no navegador..."
+		return s, s.completeDeviceLogin(msg.authorization)
+
+	case deviceDoneMsg:
+		s.Loading = false
+		if msg.err != nil {
+			s.Error = msg.err
+			return s, nil
+		}
+		return s, func() tea.Msg {
+			return messages.PopScreenMsg{}
+		}
+
+	case githubVerifiedMsg:
+		s.Loading = false
+		if msg.err != nil {
+			s.Error = msg.err
+			return s, nil
+		}
+		if msg.report.OK() {
+			return s, s.saveCredentials()
+		}
+		s.githubReport = msg.report
+		return s, nil
+
+	case credentialsSavedMsg:
+		s.Loading = false
+		if msg.err == nil {
+			return s, func() tea.Msg {
+				return messages.PopScreenMsg{}
+			}
+		}
+		// Manter a tela com o erro e a orientação para corrigir os dados
+		s.Error = msg.err
+		s.githubCheck = false
+		s.githubReport = nil
+		s.initForm()
+		return s, s.form.Init()
+
 	case forms.SubmitMsg:
+		s.Error = nil
 		// Processar valores do formulário
-		s.credentials = msg.Values
+		for key, value := range msg.Values {
+			s.credentials[key] = value
+		}
 		if s.step == 0 {
 			// Avançar para próximo step
 			switch msg.Values["Tipo"] {
+			case "Stackspot User (Login pelo navegador)":
+				s.authType = "user_device"
 			case "Stackspot User (Desenvolvedor)":
 				s.authType = "user"
 			case "Stackspot Service (Sistema via Vault)":
 				s.authType = "service"
+			case "GitHub (Login pelo navegador)":
+				s.authType = "github_device"
 			case "GitHub (Personal Access Token)":
 				s.authType = "github"
 			}
 			s.step = 1
+			if s.isDeviceLogin() {
+				return s, s.startDeviceLogin()
+			}
 			s.initForm()
 			return s, s.form.Init()
+		} else if s.step == 1 && s.authType == "service" {
+			// Avançar para os campos do método de auth do Vault
+			s.step = 2
+			s.initForm()
+			return s, s.form.Init()
+		} else if s.authType == "github" {
+			// Verificar escopos e permissões do token antes de salvar
+			return s, s.verifyGitHubToken()
 		} else {
 			// Finalizar configuração - salvar credenciais
 			return s, s.saveCredentials()
@@ -132,6 +333,14 @@ func (s *AuthSetupScreen) View() string {
 		Padding(1, 2)
 
 	content := s.form.View()
+	if s.isDeviceLogin() {
+		content = s.deviceView()
+	}
+	if s.githubCheck {
+		content = s.githubCheckView()
+	} else if s.Error != nil && !s.isDeviceLogin() {
+		content += "\n" + s.errorView(s.Error)
+	}
 
 	// Adicionar navegação
 	helpStyle := s.Theme.GetStyles().Info.
@@ -166,6 +375,125 @@ func (s *AuthSetupScreen) HandleError(err error) tea.Cmd {
 	return nil
 }
 
+func (s *AuthSetupScreen) isDeviceLogin() bool {
+	return s.authType == "user_device" || s.authType == "github_device"
+}
+
+func (s *AuthSetupScreen) deviceFlow() deviceFlow {
+	if s.authType == "github_device" {
+		return auth.GetGitHubProvider(context.Background())
+	}
+	return auth.GetStackSpotProvider(context.Background())
+}
+
+// startDeviceLogin solicita o código de verificação fora do loop da interface
+func (s *AuthSetupScreen) startDeviceLogin() tea.Cmd {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancelDevice = cancel
+	s.Loading = true
+	s.LoadingMsg = "Solicitando código de verificação..."
+
+	flow := s.deviceFlow()
+	return func() tea.Msg {
+		authorization, err := flow.StartDeviceLogin(ctx)
+		return deviceCodeMsg{authorization: authorization, err: err}
+	}
+}
+
+// completeDeviceLogin aguarda a autorização do usuário; ESC cancela o polling
+func (s *AuthSetupScreen) completeDeviceLogin(authorization providers.DeviceAuthorization) tea.Cmd {
+	ctx, cancel := context.WithCancel(context.Background())
+	if s.cancelDevice != nil {
+		s.cancelDevice()
+	}
+	s.cancelDevice = cancel
+
+	flow := s.deviceFlow()
+	return func() tea.Msg {
+		return deviceDoneMsg{err: flow.CompleteDeviceLogin(ctx, authorization)}
+	}
+}
+
+func (s *AuthSetupScreen) deviceView() string {
+	theme := s.Theme.GetStyles()
+	var doc strings.Builder
+
+	title := "🌐 Login StackSpot pelo navegador"
+	if s.authType == "github_device" {
+		title = "🌐 Login GitHub pelo navegador"
+	}
+	doc.WriteString(theme.Title.Render(title))
+	doc.WriteString("\n")
+
+	if s.device != nil {
+		doc.WriteString("Acesse " + theme.Info.Render(s.device.VerificationURI) + " e informe o código:\n\n")
+		doc.WriteString(theme.Box.Bold(true).Foreground(s.Theme.Primary).Render(s.device.UserCode))
+		doc.WriteString("\n\n")
+	}
+
+	switch {
+	case s.Error != nil:
+		doc.WriteString(s.errorView(s.Error))
+	case s.Loading:
+		doc.WriteString(theme.Subtitle.Render(s.LoadingMsg))
+	}
+	return doc.String()
+}
+
+// errorView mostra o erro e, quando houver, como resolvê-lo
+func (s *AuthSetupScreen) errorView(err error) string {
+	theme := s.Theme.GetStyles()
+	view := theme.Error.Render("✗ " + err.Error())
+	if hint := auth.Remediation(err); hint != "" {
+		view += "\n" + theme.Info.Render("💡 "+hint)
+	}
+	return view
+}
+
+// verifyGitHubToken compara as permissões do token com as exigidas pelas funcionalidades
+func (s *AuthSetupScreen) verifyGitHubToken() tea.Cmd {
+	s.githubCheck = true
+	s.Loading = true
+	s.LoadingMsg = "Verificando permissões do token..."
+
+	githubToken := s.credentials["Personal Access Token"]
+	return func() tea.Msg {
+		ctx := context.Background()
+		report, err := auth.GetGitHubProvider(ctx).VerifyToken(ctx, githubToken)
+		return githubVerifiedMsg{report: report, err: err}
+	}
+}
+
+func (s *AuthSetupScreen) githubCheckView() string {
+	theme := s.Theme.GetStyles()
+	var doc strings.Builder
+
+	doc.WriteString(theme.Title.Render("🐙 Permissões do token GitHub"))
+	doc.WriteString("\n")
+
+	switch {
+	case s.Error != nil:
+		doc.WriteString(s.errorView(s.Error))
+		doc.WriteString("\n\n")
+		doc.WriteString(theme.Info.Render("Enter para informar outro token"))
+	case s.Loading:
+		doc.WriteString(theme.Subtitle.Render(s.LoadingMsg))
+	case s.githubReport != nil:
+		report := s.githubReport
+		if report.Repository != "" {
+			doc.WriteString(theme.Subtitle.Render("Token " + report.Kind + " • repositório " + report.Repository))
+			doc.WriteString("\n\n")
+		}
+		for _, warning := range report.Warnings() {
+			doc.WriteString(theme.Warning.Render("! " + warning))
+			doc.WriteString("\n")
+		}
+		doc.WriteString("\n")
+		doc.WriteString(theme.Info.Render("Enter para salvar mesmo assim"))
+	}
+	return doc.String()
+}
+
 func (s *AuthSetupScreen) saveCredentials() tea.Cmd {
 	return func() tea.Msg {
 		var err error
@@ -173,7 +501,7 @@ func (s *AuthSetupScreen) saveCredentials() tea.Cmd {
 		switch s.authType {
 		case "user":
 			// Salvar credenciais StackSpot diretas
-			provider := auth.GetStackSpotProvider()
+			provider := auth.GetStackSpotProvider(context.Background())
 			err = provider.SaveCredentials(
 				s.credentials["Client ID"],
 				s.credentials["Client Secret"],
@@ -181,24 +509,35 @@ func (s *AuthSetupScreen) saveCredentials() tea.Cmd {
 			
 		case "service":
 			// Salvar configuração do Vault
-			provider := auth.GetVaultProvider()
-			err = provider.SaveConfig(
-				s.credentials["Vault URL"],
-				s.credentials["AWS Role"],
-				s.credentials["StackSpot Secret Path"],
-			)
+			provider := auth.GetVaultProvider(context.Background())
+			err = provider.SaveConfig(s.vaultSettings(), s.credentials["Secret ID"]+s.credentials["Token"])
 			
 		case "github":
 			// Salvar token GitHub
-			provider := auth.GetGitHubProvider()
+			provider := auth.GetGitHubProvider(context.Background())
 			err = provider.SaveToken(s.credentials["Personal Access Token"])
 		}
 		
-		if err != nil {
-			// TODO: Retornar erro para a UI
-			return messages.PopScreenMsg{}
-		}
-		
-		return messages.PopScreenMsg{}
+		return credentialsSavedMsg{err: err}
+	}
+}
+
+// vaultSettings monta a configuração do Vault a partir dos campos preenchidos
+func (s *AuthSetupScreen) vaultSettings() config.VaultSettings {
+	method := vaultMethods[s.credentials["Método"]]
+	return config.VaultSettings{
+		Address:    s.credentials["Vault URL"],
+		Namespace:  s.credentials["Namespace"],
+		Method:     method,
+		Mount:      s.credentials["Mount"],
+		Role:       s.credentials["Role"],
+		SecretPath: s.credentials["StackSpot Secret Path"],
+		AWS:        config.VaultAWS{ServerID: s.credentials["IAM Server ID"]},
+		AppRole:    config.VaultAppRole{RoleID: s.credentials["Role ID"]},
+		JWT: config.VaultJWT{
+			TokenFile:      s.credentials["Token File"],
+			GitHubAudience: s.credentials["Audience"],
+		},
+		Kubernetes: config.VaultKubernetes{TokenFile: s.credentials["Token File"]},
 	}
 }
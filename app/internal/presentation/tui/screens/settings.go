@@ -0,0 +1,275 @@
+package screens
+
+import (
+	"context"
+	"errors"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/PHRaulino/phengineer/internal/infrastructure/config"
+	"github.com/PHRaulino/phengineer/internal/presentation/tui/components/forms"
+	"github.com/PHRaulino/phengineer/internal/presentation/tui/messages"
+	"github.com/PHRaulino/phengineer/internal/presentation/tui/models"
+	"github.com/PHRaulino/phengineer/internal/presentation/tui/styles"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// settingsField campo da tela ligado a uma chave do settings.yml
+type settingsField struct {
+	label   string
+	key     string
+	options []string // vazio para texto livre
+	check   forms.ValidationFunc
+}
+
+// settingsFields chaves editáveis pela tela; as demais ficam com config set/edit
+var settingsFields = []settingsField{
+	{label: "Tipo do projeto", key: "project.type", options: config.ProjectTypes},
+	{label: "Linguagem", key: "project.language.name", check: forms.Required},
+	{label: "Versão da linguagem", key: "project.language.version", check: forms.Required},
+	{label: "Tamanho máximo por arquivo", key: "analysis.file_limits.max_file_size",
+		check: forms.Pattern(config.SizePattern, "use um tamanho como 500KB ou 10MB")},
+	{label: "Máximo de arquivos", key: "analysis.file_limits.max_files", check: forms.Range(0, 1000000)},
+	{label: "Modo de autenticação", key: "auth.mode", options: config.AuthModes},
+	{label: "Timeout HTTP (segundos)", key: "http.timeout_seconds", check: forms.Range(0, 3600)},
+	{label: "Proxy HTTP", key: "http.proxy", check: optionalURL},
+}
+
+// optionalURL aceita vazio ou uma URL válida
+func optionalURL(value string) error {
+	if value == "" {
+		return nil
+	}
+	return forms.ValidateURL(value)
+}
+
+// settingsLoadedMsg configuração resolvida carregada em segundo plano
+type settingsLoadedMsg struct {
+	path    string
+	layered *config.LayeredSettings
+	err     error
+}
+
+// settingsSavedMsg resultado da gravação do settings.yml
+type settingsSavedMsg struct {
+	err error
+}
+
+// SettingsScreen edita as principais chaves do .phengineer/settings.yml
+type SettingsScreen struct {
+	models.BaseModel
+	form    *forms.Form
+	path    string
+	current map[string]string // valores resolvidos ao abrir a tela
+}
+
+func NewSettingsScreen() *SettingsScreen {
+	return &SettingsScreen{
+		BaseModel: models.BaseModel{
+			Theme:      styles.DefaultTheme,
+			Loading:    true,
+			LoadingMsg: "Carregando configuração...",
+		},
+	}
+}
+
+func (s *SettingsScreen) Init() tea.Cmd {
+	return s.load()
+}
+
+// load resolve as camadas e localiza o settings.yml fora do loop da interface
+func (s *SettingsScreen) load() tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		path, err := config.ProjectSettingsPath(ctx, ".phengineer")
+		if err != nil {
+			return settingsLoadedMsg{err: err}
+		}
+		layered, err := config.LoadLayered(ctx, ".phengineer")
+		return settingsLoadedMsg{path: path, layered: layered, err: err}
+	}
+}
+
+func (s *SettingsScreen) initForm(layered *config.LayeredSettings) {
+	s.current = make(map[string]string, len(settingsFields))
+	s.form = forms.NewForm(
+		"⚙️  Configurações",
+		s.path,
+	)
+
+	for _, field := range settingsFields {
+		value := layered.FormatValue(field.key)
+		s.current[field.key] = value
+
+		if len(field.options) > 0 {
+			options := field.options
+			if value != "" && !slices.Contains(options, value) {
+				options = append(slices.Clone(options), value)
+			}
+			s.form.AddField(field.label, forms.NewSelect(options).WithDefault(slices.Index(options, value)))
+			continue
+		}
+
+		input := forms.NewInput().WithValue(value)
+		if origin, ok := layered.Origins[field.key]; ok {
+			input.WithHelp("origem: " + origin.String())
+		}
+		if field.check != nil {
+			input.WithValidation(field.check)
+		}
+		s.form.AddField(field.label, input)
+	}
+
+	s.form.SetSubmitLabel("Salvar").SetTheme(s.Theme)
+	if s.Width > 0 {
+		s.form.SetWidth(s.Width - 8)
+	}
+}
+
+func (s *SettingsScreen) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if msg.String() == "esc" {
+			return s, func() tea.Msg {
+				return messages.PopScreenMsg{}
+			}
+		}
+		if s.form == nil {
+			return s, nil
+		}
+
+	case settingsLoadedMsg:
+		s.Loading = false
+		if msg.err != nil {
+			s.Error = msg.err
+			return s, nil
+		}
+		s.path = msg.path
+		s.initForm(msg.layered)
+		return s, s.form.Init()
+
+	case settingsSavedMsg:
+		s.Loading = false
+		if msg.err != nil {
+			// Mantém o formulário com os valores digitados para correção
+			s.Error = msg.err
+			return s, nil
+		}
+		return s, func() tea.Msg {
+			return messages.PopScreenMsg{}
+		}
+
+	case forms.SubmitMsg:
+		s.Error = nil
+		return s, s.save(msg.Values)
+	}
+
+	if s.form == nil {
+		return s, nil
+	}
+	newForm, cmd := s.form.Update(msg)
+	s.form = newForm.(*forms.Form)
+	return s, cmd
+}
+
+// save grava só as chaves alteradas, todas de uma vez
+func (s *SettingsScreen) save(values map[string]string) tea.Cmd {
+	changed := make(map[string]string)
+	for _, field := range settingsFields {
+		if value := strings.TrimSpace(values[field.label]); value != s.current[field.key] {
+			changed[field.key] = value
+		}
+	}
+	if len(changed) == 0 {
+		return func() tea.Msg {
+			return messages.PopScreenMsg{}
+		}
+	}
+
+	s.Loading = true
+	s.LoadingMsg = "Salvando..."
+	path := s.path
+	return func() tea.Msg {
+		// Primeira configuração do repositório: parte do settings.yml padrão
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			if _, err := config.ResetSettingsFile(path, ".phengineer"); err != nil {
+				return settingsSavedMsg{err: err}
+			}
+		}
+		return settingsSavedMsg{err: config.SetSettingsValues(path, changed)}
+	}
+}
+
+func (s *SettingsScreen) View() string {
+	containerStyle := lipgloss.NewStyle().
+		Width(s.Width).
+		Height(s.Height).
+		Align(lipgloss.Center, lipgloss.Center).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(s.Theme.Border).
+		Padding(1, 2)
+
+	theme := s.Theme.GetStyles()
+	var content string
+	switch {
+	case s.form == nil && s.Error != nil:
+		content = theme.Title.Render("⚙️  Configurações") + "\n" + s.errorView(s.Error)
+	case s.form == nil:
+		content = theme.Subtitle.Render(s.LoadingMsg)
+	default:
+		content = s.form.View()
+		if s.Loading {
+			content += "\n" + theme.Subtitle.Render(s.LoadingMsg)
+		} else if s.Error != nil {
+			content += "\n" + s.errorView(s.Error)
+		}
+	}
+
+	helpStyle := theme.Info.
+		MarginTop(2).
+		Align(lipgloss.Center)
+
+	help := helpStyle.Render("ESC para voltar • Tab/Enter para navegar • demais chaves: phengineer config edit")
+
+	return containerStyle.Render(content + "\n" + help)
+}
+
+// errorView lista um problema de validação por linha
+func (s *SettingsScreen) errorView(err error) string {
+	theme := s.Theme.GetStyles()
+	var settingsErr *config.SettingsError
+	if !errors.As(err, &settingsErr) {
+		return theme.Error.Render("✗ " + err.Error())
+	}
+
+	lines := make([]string, 0, len(settingsErr.Problems))
+	for _, problem := range settingsErr.Problems {
+		lines = append(lines, theme.Error.Render("✗ "+problem.Error()))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (s *SettingsScreen) SetSize(width, height int) {
+	s.BaseModel.SetSize(width, height)
+	if s.form != nil {
+		s.form.SetWidth(width - 8)
+	}
+}
+
+func (s *SettingsScreen) SetTheme(theme *styles.Theme) {
+	s.BaseModel.Theme = theme
+	if s.form != nil {
+		s.form.SetTheme(theme)
+	}
+}
+
+func (s *SettingsScreen) GetTitle() string {
+	return "Settings"
+}
+
+func (s *SettingsScreen) HandleError(err error) tea.Cmd {
+	s.BaseModel.Error = err
+	return nil
+}
@@ -0,0 +1,174 @@
+package screens
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/PHRaulino/phengineer/internal/infrastructure/auth"
+	"github.com/PHRaulino/phengineer/internal/presentation/tui/messages"
+	"github.com/PHRaulino/phengineer/internal/presentation/tui/models"
+	"github.com/PHRaulino/phengineer/internal/presentation/tui/styles"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// statusLoadedMsg resultado da coleta do status em segundo plano
+type statusLoadedMsg struct {
+	status auth.Status
+}
+
+// StatusScreen mostra o perfil ativo, os provedores configurados e os tokens em cache
+type StatusScreen struct {
+	models.BaseModel
+	status *auth.Status
+}
+
+func NewStatusScreen() *StatusScreen {
+	return &StatusScreen{
+		BaseModel: models.BaseModel{
+			Theme:      styles.DefaultTheme,
+			Loading:    true,
+			LoadingMsg: "Carregando status...",
+		},
+	}
+}
+
+func (s *StatusScreen) Init() tea.Cmd {
+	return s.load()
+}
+
+func (s *StatusScreen) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "esc":
+			return s, func() tea.Msg {
+				return messages.PopScreenMsg{}
+			}
+		case "r":
+			if !s.Loading {
+				s.Loading = true
+				return s, s.load()
+			}
+		}
+
+	case statusLoadedMsg:
+		s.Loading = false
+		s.status = &msg.status
+	}
+
+	return s, nil
+}
+
+// load coleta o status fora do loop da interface, já que o GitHub pode ser consultado
+func (s *StatusScreen) load() tea.Cmd {
+	return func() tea.Msg {
+		return statusLoadedMsg{status: auth.GetStatus(context.Background(), auth.StatusOptions{})}
+	}
+}
+
+func (s *StatusScreen) View() string {
+	theme := s.Theme.GetStyles()
+	var doc strings.Builder
+
+	doc.WriteString(theme.Title.Render("📋 Status da Autenticação"))
+	doc.WriteString("\n")
+
+	if s.Loading || s.status == nil {
+		doc.WriteString(theme.Subtitle.Render(s.LoadingMsg))
+		return s.container(doc.String())
+	}
+
+	status := s.status
+	muted := lipgloss.NewStyle().Foreground(s.Theme.Muted)
+	doc.WriteString(theme.Subtitle.Render(fmt.Sprintf("Perfil %s (%s) • armazenamento %s • modo %s",
+		status.Profile, status.ProfileSource, status.Backend, status.Mode)))
+	doc.WriteString("\n\n")
+
+	doc.WriteString(theme.Info.Render("Provedores"))
+	doc.WriteString("\n")
+	for _, provider := range status.Providers {
+		mark := theme.Error.Render("✗")
+		if provider.Configured {
+			mark = theme.Success.Render("✓")
+		}
+		line := mark + " " + provider.Provider
+		if clientID := provider.Details["client_id"]; clientID != "" {
+			line += muted.Render(" " + clientID)
+		} else if address := provider.Details["address"]; address != "" {
+			line += muted.Render(" " + address)
+		}
+		doc.WriteString(line + "\n")
+	}
+
+	doc.WriteString("\n")
+	doc.WriteString(theme.Info.Render("Tokens"))
+	doc.WriteString("\n")
+	for _, entry := range status.Tokens {
+		label := fmt.Sprintf("%s [%s] ", entry.Alias, entry.Scope)
+
+		var state string
+		switch {
+		case !entry.Cached:
+			state = muted.Render("sem token")
+		case !entry.Valid:
+			state = theme.Warning.Render("expirado")
+		default:
+			state = theme.Success.Render("expira em " + entry.Remaining().String())
+		}
+
+		if identity := entry.Identity; identity != nil {
+			switch {
+			case identity.Error != "":
+				state += muted.Render(" • identidade indisponível")
+			case identity.Login != "":
+				state += muted.Render(" • " + identity.Login)
+			case identity.Subject != "":
+				state += muted.Render(" • " + identity.Subject)
+			}
+		}
+		doc.WriteString(label + state + "\n")
+	}
+
+	doc.WriteString("\n")
+	doc.WriteString(muted.Render("Atualizado às " + status.GeneratedAt.Format(time.TimeOnly)))
+
+	return s.container(doc.String())
+}
+
+func (s *StatusScreen) container(content string) string {
+	helpStyle := s.Theme.GetStyles().Info.
+		MarginTop(2).
+		Align(lipgloss.Center)
+
+	help := helpStyle.Render("ESC para voltar • r para atualizar")
+
+	containerStyle := lipgloss.NewStyle().
+		Width(s.Width).
+		Height(s.Height).
+		Align(lipgloss.Center, lipgloss.Center).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(s.Theme.Border).
+		Padding(1, 2)
+
+	return containerStyle.Render(content + "\n" + help)
+}
+
+func (s *StatusScreen) SetSize(width, height int) {
+	s.BaseModel.SetSize(width, height)
+}
+
+func (s *StatusScreen) SetTheme(theme *styles.Theme) {
+	s.BaseModel.Theme = theme
+}
+
+func (s *StatusScreen) GetTitle() string {
+	return "Status"
+}
+
+func (s *StatusScreen) HandleError(err error) tea.Cmd {
+	s.BaseModel.Error = err
+	return nil
+}
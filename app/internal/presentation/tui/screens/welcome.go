@@ -59,9 +59,13 @@ func (s *WelcomeScreen) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					return messages.ChangeScreenMsg{Screen: NewAuthSetupScreen()}
 				}
 			case 1:
-				// TODO: Implementar tela de configurações
+				return s, func() tea.Msg {
+					return messages.ChangeScreenMsg{Screen: NewSettingsScreen()}
+				}
 			case 2:
-				// TODO: Implementar tela de status
+				return s, func() tea.Msg {
+					return messages.ChangeScreenMsg{Screen: NewStatusScreen()}
+				}
 			case 3:
 				return s, tea.Quit
 			}